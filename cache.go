@@ -12,4 +12,17 @@ type CacheService interface {
 	Set(key string, value []byte) error
 	SetEx(key string, value []byte, expirationInSeconds time.Duration) error
 	Get(key string) ([]byte, error)
+	Del(keys ...string) error
+	Keys(pattern string) ([]string, error)
+
+	// hashes and sets, used to buffer counters
+	HIncrBy(key string, field string, increment int64) (int64, error)
+	HGetAll(key string) (map[string]string, error)
+	// every field of each hash key in one round trip, read at one instant
+	HGetAllBatch(keys ...string) ([]map[string]string, error)
+	SAdd(key string, members ...string) error
+	SRem(key string, members ...string) error
+	SMembers(key string) ([]string, error)
+	// atomically move key to newKey, false when key does not exist
+	Rename(key string, newKey string) (bool, error)
 }
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"time"
+
+	"github.com/AdhityaRamadhanus/chronicle/engagement"
+	log "github.com/sirupsen/logrus"
+)
+
+// flushes older than this are from a process that died mid flush, a flush take seconds at most
+const abandonedFlushAge = 10 * time.Minute
+
+/*
+engagementFlusher periodically write engagements buffered in cache to the database,
+so a traffic spike is applied as one batch per interval instead of one update per request.
+Flushes abandoned by dead processes are recovered on start and every abandonedFlushAge
+*/
+type engagementFlusher struct {
+	engagementService engagement.Service
+	interval          time.Duration
+	done              chan struct{}
+	stopped           chan struct{}
+}
+
+func newEngagementFlusher(engagementService engagement.Service, interval time.Duration) *engagementFlusher {
+	return &engagementFlusher{
+		engagementService: engagementService,
+		interval:          interval,
+		done:              make(chan struct{}),
+		stopped:           make(chan struct{}),
+	}
+}
+
+//Run block until Stop is called, pending engagements are flushed once more before returning
+func (f *engagementFlusher) Run() {
+	defer close(f.stopped)
+
+	f.recover()
+
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+	recoverTicker := time.NewTicker(abandonedFlushAge)
+	defer recoverTicker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			f.flush()
+		case <-recoverTicker.C:
+			f.recover()
+		case <-f.done:
+			f.flush()
+			return
+		}
+	}
+}
+
+//Stop the flusher and wait for the last flush
+func (f *engagementFlusher) Stop() {
+	close(f.done)
+	<-f.stopped
+}
+
+func (f *engagementFlusher) flush() {
+	flushedEngagements, err := f.engagementService.FlushEngagements()
+	if err != nil {
+		log.WithError(err).Error("Error Flusher Flushing Engagements")
+		return
+	}
+
+	if len(flushedEngagements) > 0 {
+		log.WithField("stories", len(flushedEngagements)).Info("Flusher flushed engagements")
+	}
+}
+
+func (f *engagementFlusher) recover() {
+	recoveredEngagements, err := f.engagementService.RecoverFlushes(abandonedFlushAge)
+	if err != nil {
+		log.WithError(err).Error("Error Flusher Recovering Engagements")
+		return
+	}
+
+	if len(recoveredEngagements) > 0 {
+		log.WithField("stories", len(recoveredEngagements)).Info("Flusher recovered engagements")
+	}
+}
@@ -5,13 +5,23 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/AdhityaRamadhanus/chronicle"
+	"github.com/AdhityaRamadhanus/chronicle/collection"
 	"github.com/AdhityaRamadhanus/chronicle/config"
+	"github.com/AdhityaRamadhanus/chronicle/contributor"
+	"github.com/AdhityaRamadhanus/chronicle/engagement"
+	"github.com/AdhityaRamadhanus/chronicle/media"
+	"github.com/AdhityaRamadhanus/chronicle/pin"
 	"github.com/AdhityaRamadhanus/chronicle/server"
 	"github.com/AdhityaRamadhanus/chronicle/server/handlers"
+	"github.com/AdhityaRamadhanus/chronicle/sitemap"
+	"github.com/AdhityaRamadhanus/chronicle/storage/filesystem"
 	"github.com/AdhityaRamadhanus/chronicle/storage/postgre"
 	_redis "github.com/AdhityaRamadhanus/chronicle/storage/redis"
 	"github.com/AdhityaRamadhanus/chronicle/story"
+	"github.com/AdhityaRamadhanus/chronicle/tag"
 	"github.com/AdhityaRamadhanus/chronicle/topic"
 	"github.com/go-redis/redis"
 	"github.com/jmoiron/sqlx"
@@ -85,32 +95,120 @@ func main() {
 	// Repositories
 	storyRepository := postgre.NewStoryRepository(db, "stories")
 	topicRepository := postgre.NewTopicRepository(db, "topics")
-
-	storyService := story.NewService(storyRepository)
+	contributorRepository := postgre.NewContributorRepository(db, "contributors")
+	tagRepository := postgre.NewTagRepository(db, "tags")
+	collectionRepository := postgre.NewCollectionRepository(db, "collections")
+	pinRepository := postgre.NewStoryPinRepository(db, "story_pins")
+	sitemapRepository := postgre.NewSitemapRepository(db, "stories")
+	mediaAssetRepository := postgre.NewMediaAssetRepository(db, "media_assets")
+	storyRevisionRepository := postgre.NewStoryRevisionRepository(db, "story_revisions")
+	storyTransitionRepository := postgre.NewStoryTransitionRepository(db, "story_transitions")
+	transactor := postgre.NewTransactor(db)
+
+	storyService := story.NewService(storyRepository, storyRevisionRepository, storyTransitionRepository, transactor)
 	topicService := topic.NewService(topicRepository)
+	contributorService := contributor.NewService(contributorRepository, storyRepository)
+	tagService := tag.NewService(tagRepository, storyRepository)
+	collectionService := collection.NewService(collectionRepository)
+	pinService := pin.NewService(pinRepository, storyRepository, topicRepository)
+	sitemapService := sitemap.NewService(sitemapRepository)
+	blobStore := filesystem.NewBlobStore(viper.GetString("media.directory"))
+	renditions := chronicle.RenditionSpecs{}
+	if err := viper.UnmarshalKey("media.renditions", &renditions); err != nil {
+		log.WithError(err).Error("Failed to read media renditions, default renditions are used")
+	}
+	mediaService := media.NewService(mediaAssetRepository, blobStore, renditions)
 	cacheService := _redis.NewCacheService(redisClient)
+	engagementService := engagement.NewService(storyRepository, cacheService)
 
 	storyHandler := handlers.StoryHandler{
-		StoryService: storyService,
-		CacheService: cacheService,
+		StoryService:      storyService,
+		EngagementService: engagementService,
+		CacheService:      cacheService,
 	}
 	topicHandler := handlers.TopicHandler{
 		TopicService: topicService,
 		CacheService: cacheService,
 	}
+	contributorHandler := handlers.ContributorHandler{
+		ContributorService: contributorService,
+		EngagementService:  engagementService,
+		CacheService:       cacheService,
+	}
+	tagHandler := handlers.TagHandler{
+		TagService:        tagService,
+		EngagementService: engagementService,
+		CacheService:      cacheService,
+	}
+	collectionHandler := handlers.CollectionHandler{
+		CollectionService: collectionService,
+		EngagementService: engagementService,
+		CacheService:      cacheService,
+	}
+	pinHandler := handlers.PinHandler{
+		PinService:   pinService,
+		CacheService: cacheService,
+	}
+	feedHandler := handlers.FeedHandler{
+		StoryService: storyService,
+		TopicService: topicService,
+		CacheService: cacheService,
+	}
+	sitemapHandler := handlers.SitemapHandler{
+		SitemapService: sitemapService,
+		CacheService:   cacheService,
+	}
+	mediaHandler := handlers.MediaHandler{
+		MediaService: mediaService,
+		CacheService: cacheService,
+	}
 	handlers := []server.Handler{
 		storyHandler,
 		topicHandler,
+		contributorHandler,
+		tagHandler,
+		collectionHandler,
+		pinHandler,
+		feedHandler,
+		sitemapHandler,
+		mediaHandler,
 	}
 	server := server.NewServer(handlers)
 	srv := server.CreateHttpServer()
 
+	// Scheduled publishing
+	schedulerInterval, err := time.ParseDuration(viper.GetString("scheduler.interval"))
+	if err != nil {
+		schedulerInterval = 30 * time.Second
+	}
+	scheduler := newStoryScheduler(storyService, cacheService, schedulerInterval)
+	go scheduler.Run()
+
+	// Buffered engagements
+	flushInterval, err := time.ParseDuration(viper.GetString("engagement.flush_interval"))
+	if err != nil {
+		flushInterval = 10 * time.Second
+	}
+	flusher := newEngagementFlusher(engagementService, flushInterval)
+	go flusher.Run()
+
+	// Trending stories
+	trendingInterval, err := time.ParseDuration(viper.GetString("trending.interval"))
+	if err != nil {
+		trendingInterval = 5 * time.Minute
+	}
+	refresher := newTrendingRefresher(storyService, trendingInterval)
+	go refresher.Run()
+
 	// Handle SIGINT, SIGTERN, SIGHUP signal from OS
 	termChan := make(chan os.Signal, 1)
 	signal.Notify(termChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 	go func() {
 		<-termChan
 		log.Warn("Receiving signal, Shutting down server")
+		scheduler.Stop()
+		flusher.Stop()
+		refresher.Stop()
 		srv.Close()
 	}()
 
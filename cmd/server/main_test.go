@@ -3,7 +3,11 @@ package main_test
 import (
 	"bytes"
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
+	"image"
+	"image/png"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -11,12 +15,21 @@ import (
 	"time"
 
 	"github.com/AdhityaRamadhanus/chronicle"
+	"github.com/AdhityaRamadhanus/chronicle/collection"
 	"github.com/AdhityaRamadhanus/chronicle/config"
+	"github.com/AdhityaRamadhanus/chronicle/contributor"
+	"github.com/AdhityaRamadhanus/chronicle/engagement"
+	"github.com/AdhityaRamadhanus/chronicle/feed"
+	"github.com/AdhityaRamadhanus/chronicle/media"
+	"github.com/AdhityaRamadhanus/chronicle/pin"
 	cs "github.com/AdhityaRamadhanus/chronicle/server"
 	"github.com/AdhityaRamadhanus/chronicle/server/handlers"
+	"github.com/AdhityaRamadhanus/chronicle/sitemap"
+	"github.com/AdhityaRamadhanus/chronicle/storage/filesystem"
 	"github.com/AdhityaRamadhanus/chronicle/storage/postgre"
 	_redis "github.com/AdhityaRamadhanus/chronicle/storage/redis"
 	"github.com/AdhityaRamadhanus/chronicle/story"
+	"github.com/AdhityaRamadhanus/chronicle/tag"
 	"github.com/AdhityaRamadhanus/chronicle/topic"
 	jwt "github.com/dgrijalva/jwt-go"
 	"github.com/go-redis/redis"
@@ -60,11 +73,32 @@ type (
 		Story  chronicle.Story
 	}
 
+	DetailContributorBody struct {
+		Status      int
+		Contributor chronicle.Contributor
+	}
+
+	DetailMediaBody struct {
+		Status int
+		Media  chronicle.MediaAsset
+	}
+
 	ListStoriesBody struct {
 		Status     int
 		Stories    chronicle.Stories
 		Pagination DefaultInnerPagination
 	}
+
+	ListStoryRevisionsBody struct {
+		Status     int
+		Revisions  chronicle.StoryRevisions
+		Pagination DefaultInnerPagination
+	}
+
+	DetailStoryEngagementBody struct {
+		Status     int
+		Engagement chronicle.StoryEngagement
+	}
 )
 
 var (
@@ -125,6 +159,21 @@ func setupDatabase(db *sqlx.DB) {
 	if err != nil {
 		log.Fatal("Failed to setup database ", errors.Wrap(err, "Failed in delete from topics"))
 	}
+
+	_, err = db.Query("DELETE FROM contributors")
+	if err != nil {
+		log.Fatal("Failed to setup database ", errors.Wrap(err, "Failed in delete from contributors"))
+	}
+
+	_, err = db.Query("DELETE FROM tags")
+	if err != nil {
+		log.Fatal("Failed to setup database ", errors.Wrap(err, "Failed in delete from tags"))
+	}
+
+	_, err = db.Query("DELETE FROM collections")
+	if err != nil {
+		log.Fatal("Failed to setup database ", errors.Wrap(err, "Failed in delete from collections"))
+	}
 }
 
 func TestMain(m *testing.M) {
@@ -152,23 +201,80 @@ func TestMain(m *testing.M) {
 	// Repositories
 	storyRepository := postgre.NewStoryRepository(db, "stories")
 	topicRepository := postgre.NewTopicRepository(db, "topics")
-
-	storyService := story.NewService(storyRepository)
+	contributorRepository := postgre.NewContributorRepository(db, "contributors")
+	tagRepository := postgre.NewTagRepository(db, "tags")
+	collectionRepository := postgre.NewCollectionRepository(db, "collections")
+	pinRepository := postgre.NewStoryPinRepository(db, "story_pins")
+	sitemapRepository := postgre.NewSitemapRepository(db, "stories")
+	mediaAssetRepository := postgre.NewMediaAssetRepository(db, "media_assets")
+	storyRevisionRepository := postgre.NewStoryRevisionRepository(db, "story_revisions")
+	storyTransitionRepository := postgre.NewStoryTransitionRepository(db, "story_transitions")
+	transactor := postgre.NewTransactor(db)
+
+	storyService := story.NewService(storyRepository, storyRevisionRepository, storyTransitionRepository, transactor)
 	topicService := topic.NewService(topicRepository)
+	contributorService := contributor.NewService(contributorRepository, storyRepository)
+	tagService := tag.NewService(tagRepository, storyRepository)
+	collectionService := collection.NewService(collectionRepository)
+	pinService := pin.NewService(pinRepository, storyRepository, topicRepository)
+	sitemapService := sitemap.NewService(sitemapRepository)
+	blobStore := filesystem.NewBlobStore(viper.GetString("media.directory"))
+	mediaService := media.NewService(mediaAssetRepository, blobStore, media.DefaultRenditions)
 	cacheService := _redis.NewCacheService(redisClient)
+	engagementService := engagement.NewService(storyRepository, cacheService)
 
 	storyHandler := handlers.StoryHandler{
-		StoryService: storyService,
-		CacheService: cacheService,
+		StoryService:      storyService,
+		EngagementService: engagementService,
+		CacheService:      cacheService,
 	}
 	topicHandler := handlers.TopicHandler{
 		TopicService: topicService,
 		CacheService: cacheService,
 	}
+	contributorHandler := handlers.ContributorHandler{
+		ContributorService: contributorService,
+		EngagementService:  engagementService,
+		CacheService:       cacheService,
+	}
+	tagHandler := handlers.TagHandler{
+		TagService:        tagService,
+		EngagementService: engagementService,
+		CacheService:      cacheService,
+	}
+	collectionHandler := handlers.CollectionHandler{
+		CollectionService: collectionService,
+		EngagementService: engagementService,
+		CacheService:      cacheService,
+	}
+	pinHandler := handlers.PinHandler{
+		PinService:   pinService,
+		CacheService: cacheService,
+	}
+	feedHandler := handlers.FeedHandler{
+		StoryService: storyService,
+		TopicService: topicService,
+		CacheService: cacheService,
+	}
+	sitemapHandler := handlers.SitemapHandler{
+		SitemapService: sitemapService,
+		CacheService:   cacheService,
+	}
+	mediaHandler := handlers.MediaHandler{
+		MediaService: mediaService,
+		CacheService: cacheService,
+	}
 
 	handlers := []cs.Handler{
 		storyHandler,
 		topicHandler,
+		contributorHandler,
+		tagHandler,
+		collectionHandler,
+		pinHandler,
+		feedHandler,
+		sitemapHandler,
+		mediaHandler,
 	}
 	server = cs.NewServer(handlers).CreateHttpServer()
 
@@ -418,6 +524,20 @@ func TestUpdateTopicIntegration(t *testing.T) {
 	}
 }
 
+func TestGetTopicByFormerSlugIntegration(t *testing.T) {
+	url := "/api/topics/pilkada-2019"
+	method := "GET"
+
+	t.Logf("Testing %s %s", method, url)
+	request, err := createHttpJSONRequest(method, url, map[string]interface{}{})
+	assert.NoError(t, err, "Expected No Error in create request")
+
+	response := httptest.NewRecorder()
+	server.Handler.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusMovedPermanently, response.Code, "Expected to return 301")
+	assert.Equal(t, "/api/topics/pemilih-2019", response.Header().Get("Location"))
+}
+
 func TestDeleteTopicIntegration(t *testing.T) {
 	baseUrl := "/api/topics"
 	method := "DELETE"
@@ -458,6 +578,135 @@ func TestDeleteTopicIntegration(t *testing.T) {
 	}
 }
 
+// CONTRIBUTORS ENDPOINT TESTS
+
+func TestContributorStoriesIntegration(t *testing.T) {
+	url := "/api/contributors/insert"
+	method := "POST"
+
+	t.Logf("Testing %s %s", method, url)
+	request, err := createHttpJSONRequest(method, url, map[string]interface{}{
+		"name": "Adhitya Ramadhanus",
+		"bio":  "Backend Engineer",
+	})
+	assert.NoError(t, err, "Expected No Error in create request")
+
+	response := httptest.NewRecorder()
+	server.Handler.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusCreated, response.Code, "Expected to return 201")
+
+	contributorBody := DetailContributorBody{}
+	err = decodeResponseJSON(t, response, &contributorBody)
+	assert.NoError(t, err, "Expected No Error in decode response")
+	assert.Equal(t, "adhitya-ramadhanus", contributorBody.Contributor.Slug)
+
+	testCases := []struct {
+		Slug           string
+		ExpectedStatus int
+	}{
+		{
+			Slug:           contributorBody.Contributor.Slug,
+			ExpectedStatus: 200,
+		},
+		{
+			Slug:           "random-slug",
+			ExpectedStatus: 404,
+		},
+	}
+
+	method = "GET"
+	for _, test := range testCases {
+		url := fmt.Sprintf("/api/contributors/%s/stories", test.Slug)
+		t.Logf("Testing %s %s", method, url)
+		request, err := createHttpJSONRequest(method, url, map[string]interface{}{})
+		assert.NoError(t, err, "Expected No Error in create request")
+
+		response := httptest.NewRecorder()
+		server.Handler.ServeHTTP(response, request)
+		assert.Equal(t, test.ExpectedStatus, response.Code, fmt.Sprintf("Expected to return %d", test.ExpectedStatus))
+
+		if response.Code == 200 {
+			requestBody := ListStoriesBody{}
+			err = decodeResponseJSON(t, response, &requestBody)
+			assert.NoError(t, err, "Expected No Error in decode response")
+		} else {
+			requestBody := DefaultErrorBody{}
+			err = decodeResponseJSON(t, response, &requestBody)
+			assert.NoError(t, err, "Expected No Error in decode response")
+		}
+	}
+}
+
+// MEDIA ENDPOINT TESTS
+
+func TestUploadMediaIntegration(t *testing.T) {
+	url := "/api/media/upload"
+	method := "POST"
+
+	requestBody := bytes.Buffer{}
+	writer := multipart.NewWriter(&requestBody)
+	writer.WriteField("caption", "Empty image")
+	writer.WriteField("altText", "Nothing")
+	fileWriter, err := writer.CreateFormFile("file", "empty.png")
+	assert.NoError(t, err, "Expected No Error in create form file")
+	png.Encode(fileWriter, image.NewRGBA(image.Rect(0, 0, 40, 30)))
+	writer.Close()
+
+	t.Logf("Testing %s %s", method, url)
+	request, err := http.NewRequest(method, url, &requestBody)
+	assert.NoError(t, err, "Expected No Error in create request")
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+	request.Header.Set("Authorization", "Bearer "+accessToken)
+
+	response := httptest.NewRecorder()
+	server.Handler.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusCreated, response.Code, "Expected to return 201")
+
+	mediaBody := DetailMediaBody{}
+	err = decodeResponseJSON(t, response, &mediaBody)
+	assert.NoError(t, err, "Expected No Error in decode response")
+	assert.Equal(t, "image/png", mediaBody.Media.MimeType)
+	assert.Equal(t, 40, mediaBody.Media.Width)
+	assert.Equal(t, 30, mediaBody.Media.Height)
+	assert.Len(t, mediaBody.Media.Renditions, len(media.DefaultRenditions))
+
+	testCases := []struct {
+		Rendition      string
+		ExpectedStatus int
+	}{
+		{
+			Rendition:      "original",
+			ExpectedStatus: 200,
+		},
+		{
+			Rendition:      "thumbnail",
+			ExpectedStatus: 200,
+		},
+		{
+			Rendition:      "poster",
+			ExpectedStatus: 404,
+		},
+	}
+
+	// served without access token
+	method = "GET"
+	for _, test := range testCases {
+		url := fmt.Sprintf("/api/media/%d/%s", mediaBody.Media.ID, test.Rendition)
+		t.Logf("Testing %s %s", method, url)
+		request, err := http.NewRequest(method, url, nil)
+		assert.NoError(t, err, "Expected No Error in create request")
+
+		response := httptest.NewRecorder()
+		server.Handler.ServeHTTP(response, request)
+		assert.Equal(t, test.ExpectedStatus, response.Code, fmt.Sprintf("Expected to return %d", test.ExpectedStatus))
+
+		if response.Code == 200 {
+			assert.Equal(t, "image/png", response.Header().Get("Content-Type"))
+			assert.Contains(t, response.Header().Get("Cache-Control"), "immutable")
+		}
+	}
+}
+
 // STORIES ENDPOINT TESTS
 
 func TestCreateStoriesIntegration(t *testing.T) {
@@ -583,6 +832,49 @@ func TestGetStoriesIntegration(t *testing.T) {
 	}
 }
 
+func TestNegotiateStoriesIntegration(t *testing.T) {
+	method := "GET"
+
+	testCases := []struct {
+		URL                 string
+		Accept              string
+		ExpectedStatus      int
+		ExpectedContentType string
+	}{
+		{
+			URL:                 "/api/stories/?status=Draft",
+			Accept:              "text/csv",
+			ExpectedStatus:      200,
+			ExpectedContentType: "text/csv; charset=utf-8",
+		},
+		{
+			URL:                 "/api/stories/?status=Draft&format=msgpack",
+			ExpectedStatus:      200,
+			ExpectedContentType: "application/msgpack",
+		},
+		{
+			URL:                 "/api/topics/",
+			Accept:              "application/xml",
+			ExpectedStatus:      406,
+			ExpectedContentType: "application/json; charset=utf-8",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("Testing %s %s %s", method, test.URL, test.Accept)
+		request, err := createHttpJSONRequest(method, test.URL, map[string]interface{}{})
+		assert.NoError(t, err, "Expected No Error in create request")
+		if test.Accept != "" {
+			request.Header.Set("Accept", test.Accept)
+		}
+
+		response := httptest.NewRecorder()
+		server.Handler.ServeHTTP(response, request)
+		assert.Equal(t, test.ExpectedStatus, response.Code, fmt.Sprintf("Expected to return %d", test.ExpectedStatus))
+		assert.Equal(t, test.ExpectedContentType, response.Header().Get("Content-Type"))
+	}
+}
+
 func TestGetStoryBySlugIntegration(t *testing.T) {
 	baseUrl := "/api/stories"
 	method := "GET"
@@ -672,13 +964,34 @@ func TestUpdateStoryIntegration(t *testing.T) {
 		ID             int
 		RequestBody    interface{}
 	}{
+		{
+			RequestBody: map[string]interface{}{
+				"excerpt": "Timnas Gagal melaju ke piala dunia 2019",
+			},
+			ID:             storyId,
+			ExpectedStatus: 200,
+		},
 		{
 			RequestBody: map[string]interface{}{
 				"status": "Publish",
 			},
 			ID:             storyId,
+			ExpectedStatus: 422,
+		},
+		{
+			RequestBody: map[string]interface{}{
+				"topics": []int{topicId},
+			},
+			ID:             storyId,
 			ExpectedStatus: 200,
 		},
+		{
+			RequestBody: map[string]interface{}{
+				"addTopics": []int{topicId + 100},
+			},
+			ID:             storyId,
+			ExpectedStatus: 422,
+		},
 		{
 			RequestBody: map[string]interface{}{
 				"name": "Pemilih 2019",
@@ -710,6 +1023,291 @@ func TestUpdateStoryIntegration(t *testing.T) {
 	}
 }
 
+func TestTransitionStoryIntegration(t *testing.T) {
+	baseUrl := "/api/stories"
+	method := "POST"
+
+	testCases := []struct {
+		ExpectedStatus int
+		ID             int
+		Transition     string
+	}{
+		{
+			ID:             storyId,
+			Transition:     "publish",
+			ExpectedStatus: 409,
+		},
+		{
+			ID:             storyId,
+			Transition:     "submit",
+			ExpectedStatus: 200,
+		},
+		{
+			ID:             storyId,
+			Transition:     "teleport",
+			ExpectedStatus: 422,
+		},
+		{
+			ID:             storyId + 1,
+			Transition:     "submit",
+			ExpectedStatus: 404,
+		},
+	}
+
+	for _, test := range testCases {
+		url := baseUrl + fmt.Sprintf("/%d/transitions/%s", test.ID, test.Transition)
+		t.Logf("Testing %s %s", method, url)
+		request, err := createHttpJSONRequest(method, url, map[string]interface{}{})
+		assert.NoError(t, err, "Expected No Error in create request")
+
+		response := httptest.NewRecorder()
+		server.Handler.ServeHTTP(response, request)
+		assert.Equal(t, test.ExpectedStatus, response.Code, fmt.Sprintf("Expected to return %d", test.ExpectedStatus))
+
+		if response.Code == 200 || response.Code == 201 {
+			requestBody := DetailStoryBody{}
+			err = decodeResponseJSON(t, response, &requestBody)
+			assert.NoError(t, err, "Expected No Error in decode response")
+		} else {
+			requestBody := DefaultErrorBody{}
+			err = decodeResponseJSON(t, response, &requestBody)
+			assert.NoError(t, err, "Expected No Error in decode response")
+		}
+	}
+}
+
+func TestGetStoryRevisionsIntegration(t *testing.T) {
+	baseUrl := "/api/stories"
+	method := "GET"
+
+	testCases := []struct {
+		ExpectedStatus          int
+		ID                      int
+		ExpectedRevisionsCount  int
+		ExpectedRevisionsLength int
+	}{
+		{
+			ID:                      storyId,
+			ExpectedStatus:          200,
+			ExpectedRevisionsCount:  4,
+			ExpectedRevisionsLength: 4,
+		},
+		{
+			ID:             storyId + 1,
+			ExpectedStatus: 404,
+		},
+	}
+
+	for _, test := range testCases {
+		url := baseUrl + fmt.Sprintf("/%d/revisions", test.ID)
+		t.Logf("Testing %s %s", method, url)
+		request, err := createHttpJSONRequest(method, url, map[string]interface{}{})
+		assert.NoError(t, err, "Expected No Error in create request")
+
+		response := httptest.NewRecorder()
+		server.Handler.ServeHTTP(response, request)
+		assert.Equal(t, test.ExpectedStatus, response.Code, fmt.Sprintf("Expected to return %d", test.ExpectedStatus))
+
+		if response.Code == 200 || response.Code == 201 {
+			requestBody := ListStoryRevisionsBody{}
+			err = decodeResponseJSON(t, response, &requestBody)
+			assert.NoError(t, err, "Expected No Error in decode response")
+			assert.Equal(t, requestBody.Pagination.TotalItems, test.ExpectedRevisionsCount, fmt.Sprintf("Should return %d revisions count", test.ExpectedRevisionsCount))
+			assert.Equal(t, len(requestBody.Revisions), test.ExpectedRevisionsLength, fmt.Sprintf("Should return %d revisions", test.ExpectedRevisionsLength))
+		} else {
+			requestBody := DefaultErrorBody{}
+			err = decodeResponseJSON(t, response, &requestBody)
+			assert.NoError(t, err, "Expected No Error in decode response")
+		}
+	}
+}
+
+func TestEngageStoryIntegration(t *testing.T) {
+	baseUrl := "/api/stories"
+	method := "POST"
+
+	testCases := []struct {
+		ExpectedStatus int
+		ID             int
+		Engagement     string
+		ExpectedViews  int
+	}{
+		{
+			ID:             storyId,
+			Engagement:     "views",
+			ExpectedStatus: 200,
+			ExpectedViews:  1,
+		},
+		{
+			ID:             storyId,
+			Engagement:     "views",
+			ExpectedStatus: 200,
+			ExpectedViews:  2,
+		},
+		{
+			ID:             storyId,
+			Engagement:     "likes",
+			ExpectedStatus: 200,
+			ExpectedViews:  2,
+		},
+		{
+			ID:             storyId + 100,
+			Engagement:     "views",
+			ExpectedStatus: 404,
+		},
+	}
+
+	for _, test := range testCases {
+		url := baseUrl + fmt.Sprintf("/%d/%s", test.ID, test.Engagement)
+		t.Logf("Testing %s %s", method, url)
+		request, err := createHttpJSONRequest(method, url, map[string]interface{}{})
+		assert.NoError(t, err, "Expected No Error in create request")
+
+		response := httptest.NewRecorder()
+		server.Handler.ServeHTTP(response, request)
+		assert.Equal(t, test.ExpectedStatus, response.Code, fmt.Sprintf("Expected to return %d", test.ExpectedStatus))
+
+		if response.Code == 200 {
+			requestBody := DetailStoryEngagementBody{}
+			err = decodeResponseJSON(t, response, &requestBody)
+			assert.NoError(t, err, "Expected No Error in decode response")
+			assert.Equal(t, test.ExpectedViews, requestBody.Engagement.Views)
+		} else {
+			requestBody := DefaultErrorBody{}
+			err = decodeResponseJSON(t, response, &requestBody)
+			assert.NoError(t, err, "Expected No Error in decode response")
+		}
+	}
+}
+
+func TestGetStoryEngagementsIntegration(t *testing.T) {
+	// the story response is cached, engagements are live
+	for _, engagement := range []string{"", "views"} {
+		if engagement != "" {
+			request, err := createHttpJSONRequest("POST", fmt.Sprintf("/api/stories/%d/%s", storyId, engagement), map[string]interface{}{})
+			assert.NoError(t, err, "Expected No Error in create request")
+			server.Handler.ServeHTTP(httptest.NewRecorder(), request)
+		}
+
+		request, err := createHttpJSONRequest("GET", fmt.Sprintf("/api/stories/%d", storyId), nil)
+		assert.NoError(t, err, "Expected No Error in create request")
+		server.Handler.ServeHTTP(httptest.NewRecorder(), request)
+	}
+
+	request, err := createHttpJSONRequest("GET", fmt.Sprintf("/api/stories/%d/engagements", storyId), nil)
+	assert.NoError(t, err, "Expected No Error in create request")
+	response := httptest.NewRecorder()
+	server.Handler.ServeHTTP(response, request)
+	assert.Equal(t, 200, response.Code, "Expected to return 200")
+
+	requestBody := DetailStoryEngagementBody{}
+	err = decodeResponseJSON(t, response, &requestBody)
+	assert.NoError(t, err, "Expected No Error in decode response")
+	assert.Equal(t, 3, requestBody.Engagement.Views)
+
+	request, err = createHttpJSONRequest("GET", fmt.Sprintf("/api/stories/%d/engagements", storyId+100), nil)
+	assert.NoError(t, err, "Expected No Error in create request")
+	response = httptest.NewRecorder()
+	server.Handler.ServeHTTP(response, request)
+	assert.Equal(t, 404, response.Code, "Expected to return 404")
+}
+
+func TestStoriesFeedIntegration(t *testing.T) {
+	method := "GET"
+
+	testCases := []struct {
+		URL                 string
+		ExpectedStatus      int
+		ExpectedContentType string
+	}{
+		{
+			URL:                 "/feeds/stories.rss",
+			ExpectedStatus:      200,
+			ExpectedContentType: feed.RSSContentType,
+		},
+		{
+			URL:                 "/feeds/stories.atom",
+			ExpectedStatus:      200,
+			ExpectedContentType: feed.AtomContentType,
+		},
+		{
+			URL:                 "/feeds/topics/unknown-topic.rss",
+			ExpectedStatus:      404,
+			ExpectedContentType: "application/json; charset=utf-8",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("Testing %s %s", method, test.URL)
+		// feeds are public, no bearer token is sent
+		request, err := http.NewRequest(method, test.URL, nil)
+		assert.NoError(t, err, "Expected No Error in create request")
+
+		response := httptest.NewRecorder()
+		server.Handler.ServeHTTP(response, request)
+		assert.Equal(t, test.ExpectedStatus, response.Code, fmt.Sprintf("Expected to return %d", test.ExpectedStatus))
+		assert.Equal(t, test.ExpectedContentType, response.Header().Get("Content-Type"))
+
+		if response.Code == 200 {
+			var document struct{}
+			err = xml.NewDecoder(response.Body).Decode(&document)
+			assert.NoError(t, err, "Expected No Error in decode response")
+		}
+	}
+}
+
+func TestSitemapsIntegration(t *testing.T) {
+	method := "GET"
+
+	testCases := []struct {
+		URL            string
+		ExpectedStatus int
+	}{
+		{
+			URL:            "/sitemap.xml",
+			ExpectedStatus: 200,
+		},
+		{
+			URL:            "/sitemap-news.xml",
+			ExpectedStatus: 200,
+		},
+		{
+			URL:            "/sitemap-stories-1.xml",
+			ExpectedStatus: 200,
+		},
+		{
+			URL:            "/sitemap-topics-1.xml",
+			ExpectedStatus: 200,
+		},
+		{
+			URL:            "/sitemap-stories-1000.xml",
+			ExpectedStatus: 404,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Logf("Testing %s %s", method, test.URL)
+		// sitemaps are public, no bearer token is sent
+		request, err := http.NewRequest(method, test.URL, nil)
+		assert.NoError(t, err, "Expected No Error in create request")
+
+		response := httptest.NewRecorder()
+		server.Handler.ServeHTTP(response, request)
+		assert.Equal(t, test.ExpectedStatus, response.Code, fmt.Sprintf("Expected to return %d", test.ExpectedStatus))
+
+		if response.Code == 200 {
+			assert.Equal(t, sitemap.ContentType, response.Header().Get("Content-Type"))
+			var document struct{}
+			err = xml.NewDecoder(response.Body).Decode(&document)
+			assert.NoError(t, err, "Expected No Error in decode response")
+		} else {
+			requestBody := DefaultErrorBody{}
+			err = decodeResponseJSON(t, response, &requestBody)
+			assert.NoError(t, err, "Expected No Error in decode response")
+		}
+	}
+}
+
 func TestDeleteStoryIntegration(t *testing.T) {
 	baseUrl := "/api/stories"
 	method := "DELETE"
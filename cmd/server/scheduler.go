@@ -0,0 +1,79 @@
+package main
+
+import (
+	"time"
+
+	"github.com/AdhityaRamadhanus/chronicle"
+	"github.com/AdhityaRamadhanus/chronicle/server/middlewares"
+	"github.com/AdhityaRamadhanus/chronicle/story"
+	log "github.com/sirupsen/logrus"
+)
+
+/*
+storyScheduler periodically publish and unpublish stories whose schedule is due,
+every instance can run its own scheduler because the repository claim due stories atomically
+*/
+type storyScheduler struct {
+	storyService story.Service
+	cacheService chronicle.CacheService
+	interval     time.Duration
+	done         chan struct{}
+}
+
+func newStoryScheduler(storyService story.Service, cacheService chronicle.CacheService, interval time.Duration) *storyScheduler {
+	return &storyScheduler{
+		storyService: storyService,
+		cacheService: cacheService,
+		interval:     interval,
+		done:         make(chan struct{}),
+	}
+}
+
+//Run block until Stop is called
+func (s *storyScheduler) Run() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.tick(time.Now().UTC())
+		case <-s.done:
+			return
+		}
+	}
+}
+
+//Stop the scheduler
+func (s *storyScheduler) Stop() {
+	close(s.done)
+}
+
+func (s *storyScheduler) tick(now time.Time) {
+	publishedStories, err := s.storyService.PublishScheduledStories(now)
+	if err != nil {
+		log.WithError(err).Error("Error Scheduler Publishing Stories")
+	}
+
+	for _, publishedStory := range publishedStories {
+		log.WithField("story", publishedStory.ID).Info("Scheduler published story")
+	}
+
+	unpublishedStories, err := s.storyService.UnpublishScheduledStories(now)
+	if err != nil {
+		log.WithError(err).Error("Error Scheduler Unpublishing Stories")
+	}
+
+	for _, unpublishedStory := range unpublishedStories {
+		log.WithField("story", unpublishedStory.ID).Info("Scheduler unpublished story")
+	}
+
+	if len(publishedStories) == 0 && len(unpublishedStories) == 0 {
+		return
+	}
+
+	// listings, feeds and sitemaps change with the status of their stories
+	if err := middlewares.InvalidateStoriesCache(s.cacheService); err != nil {
+		log.WithError(err).Warn("Error Scheduler Invalidating Stories Cache")
+	}
+}
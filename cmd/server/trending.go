@@ -0,0 +1,53 @@
+package main
+
+import (
+	"time"
+
+	"github.com/AdhityaRamadhanus/chronicle/story"
+	log "github.com/sirupsen/logrus"
+)
+
+/*
+trendingRefresher periodically recompute trending score of stories for every window,
+every instance can run its own refresher because the repository skip a window another instance is refreshing
+*/
+type trendingRefresher struct {
+	storyService story.Service
+	interval     time.Duration
+	done         chan struct{}
+}
+
+func newTrendingRefresher(storyService story.Service, interval time.Duration) *trendingRefresher {
+	return &trendingRefresher{
+		storyService: storyService,
+		interval:     interval,
+		done:         make(chan struct{}),
+	}
+}
+
+//Run refresh right away then block until Stop is called
+func (r *trendingRefresher) Run() {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	r.refresh(time.Now().UTC())
+	for {
+		select {
+		case <-ticker.C:
+			r.refresh(time.Now().UTC())
+		case <-r.done:
+			return
+		}
+	}
+}
+
+//Stop the refresher
+func (r *trendingRefresher) Stop() {
+	close(r.done)
+}
+
+func (r *trendingRefresher) refresh(now time.Time) {
+	if err := r.storyService.RefreshTrendingStories(now); err != nil {
+		log.WithError(err).Error("Error Refresher Refreshing Trending Stories")
+	}
+}
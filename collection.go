@@ -0,0 +1,60 @@
+package chronicle
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	//ErrUnknownStory returned by repositories when an entity reference a story that does not exist
+	ErrUnknownStory = errors.New("Unknown Story")
+)
+
+//Collection is domain entity, an ordered series of stories curated by editors
+type Collection struct {
+	ID          int
+	Title       string
+	Slug        string
+	Description string
+	// stories in the order of their position, without topics, tags, bylines and media
+	Stories   Stories
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+//Collections short way to define array of collection
+type Collections []Collection
+
+//StoryLink is a story referenced from another story
+type StoryLink struct {
+	ID    int
+	Title string
+	Slug  string
+}
+
+//StoryCollection is a collection containing a story, with the stories before and after it
+type StoryCollection struct {
+	ID       int
+	Title    string
+	Slug     string
+	Position int
+	Previous *StoryLink
+	Next     *StoryLink
+}
+
+//StoryCollections short way to define array of story collection
+type StoryCollections []StoryCollection
+
+//CollectionRepository provide an interface to get collection entities
+type CollectionRepository interface {
+	Find(id int) (Collection, error)
+	FindBySlug(slug string) (Collection, error)
+	IsSlugTaken(slug string, exceptID int) (bool, error)
+	All(option PagingOptions) (collections Collections, collectionsCount int, err error)
+	Insert(collection Collection) (createdCollection Collection, err error)
+	Update(collection Collection) (updatedCollection Collection, err error)
+	Delete(id int) error
+	InsertStory(id int, storyID int, position int) error
+	RemoveStory(id int, storyID int) error
+	ReorderStories(id int, storyIDs []int) error
+}
@@ -0,0 +1,269 @@
+package collection
+
+import (
+	"database/sql"
+
+	"github.com/AdhityaRamadhanus/chronicle"
+	"github.com/AdhityaRamadhanus/chronicle/function"
+	"github.com/pkg/errors"
+)
+
+var (
+	//ErrNoCollectionFound sub-domain specific error
+	ErrNoCollectionFound = errors.New("Cannot find Collection")
+	//ErrUnknownStory sub-domain specific error
+	ErrUnknownStory = errors.New("Cannot find one or more Stories of Collection")
+	//ErrStoryAlreadyCollected sub-domain specific error
+	ErrStoryAlreadyCollected = errors.New("Story is already in Collection")
+	//ErrStoryNotCollected sub-domain specific error
+	ErrStoryNotCollected = errors.New("Story is not in Collection")
+	//ErrInvalidCollectionOrder sub-domain specific error
+	ErrInvalidCollectionOrder = errors.New("Order must list every Story of Collection exactly once")
+)
+
+//Service provide an interface to collection domain service
+type Service interface {
+	CreateCollection(collection chronicle.Collection) (createdCollection chronicle.Collection, err error)
+	UpdateCollection(collection chronicle.Collection) (updatedCollection chronicle.Collection, err error)
+	GetCollections(option chronicle.PagingOptions) (chronicle.Collections, int, error)
+	GetCollectionByID(id int) (chronicle.Collection, error)
+	GetCollectionBySlug(slug string) (chronicle.Collection, error)
+	DeleteCollectionByID(id int) error
+	InsertCollectionStory(id int, storyID int, position int) (updatedCollection chronicle.Collection, err error)
+	RemoveCollectionStory(id int, storyID int) (updatedCollection chronicle.Collection, err error)
+	ReorderCollectionStories(id int, storyIDs []int) (updatedCollection chronicle.Collection, err error)
+}
+
+func NewService(collectionRepository chronicle.CollectionRepository) Service {
+	return &service{
+		collectionRepository: collectionRepository,
+	}
+}
+
+type service struct {
+	collectionRepository chronicle.CollectionRepository
+}
+
+func (s *service) CreateCollection(collection chronicle.Collection) (createdCollection chronicle.Collection, err error) {
+	defer func() {
+		if err != nil && err != ErrUnknownStory {
+			err = errors.Wrap(err, function.GetFunctionName(s.CreateCollection))
+		}
+	}()
+
+	// a slug taken by another write after it was checked is resolved again
+	err = chronicle.RetrySlugConflict(func() error {
+		collection := collection
+		if err := s.resolveCollectionSlug(&collection); err != nil {
+			return err
+		}
+
+		createdCollection, err = s.collectionRepository.Insert(collection)
+		return err
+	})
+	if err != nil {
+		switch errors.Cause(err) {
+		case chronicle.ErrUnknownStory:
+			return chronicle.Collection{}, ErrUnknownStory
+		default:
+			return chronicle.Collection{}, err
+		}
+	}
+
+	return createdCollection, nil
+}
+
+func (s *service) UpdateCollection(collection chronicle.Collection) (updatedCollection chronicle.Collection, err error) {
+	defer func() {
+		if err != nil && err != ErrNoCollectionFound {
+			err = errors.Wrap(err, function.GetFunctionName(s.UpdateCollection))
+		}
+	}()
+
+	err = chronicle.RetrySlugConflict(func() error {
+		collection := collection
+		if err := s.resolveCollectionSlug(&collection); err != nil {
+			return err
+		}
+
+		updatedCollection, err = s.collectionRepository.Update(collection)
+		return err
+	})
+	if err != nil {
+		switch errors.Cause(err) {
+		case sql.ErrNoRows:
+			return chronicle.Collection{}, ErrNoCollectionFound
+		default:
+			return chronicle.Collection{}, err
+		}
+	}
+
+	return updatedCollection, nil
+}
+
+func (s *service) GetCollections(option chronicle.PagingOptions) (collections chronicle.Collections, collectionsCount int, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Wrap(err, function.GetFunctionName(s.GetCollections))
+		}
+	}()
+
+	return s.collectionRepository.All(option)
+}
+
+func (s *service) GetCollectionByID(id int) (collection chronicle.Collection, err error) {
+	defer func() {
+		if err != nil && err != ErrNoCollectionFound {
+			err = errors.Wrap(err, function.GetFunctionName(s.GetCollectionByID))
+		}
+	}()
+
+	collection, err = s.collectionRepository.Find(id)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return collection, ErrNoCollectionFound
+		default:
+			return collection, err
+		}
+	}
+
+	return collection, nil
+}
+
+func (s *service) GetCollectionBySlug(slug string) (collection chronicle.Collection, err error) {
+	defer func() {
+		if err != nil && err != ErrNoCollectionFound {
+			err = errors.Wrap(err, function.GetFunctionName(s.GetCollectionBySlug))
+		}
+	}()
+
+	collection, err = s.collectionRepository.FindBySlug(slug)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return collection, ErrNoCollectionFound
+		default:
+			return collection, err
+		}
+	}
+
+	return collection, nil
+}
+
+func (s *service) DeleteCollectionByID(id int) (err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Wrap(err, function.GetFunctionName(s.DeleteCollectionByID))
+		}
+	}()
+
+	return s.collectionRepository.Delete(id)
+}
+
+// InsertCollectionStory put story at position, a position out of range append it
+func (s *service) InsertCollectionStory(id int, storyID int, position int) (updatedCollection chronicle.Collection, err error) {
+	defer func() {
+		if err != nil && err != ErrNoCollectionFound && err != ErrUnknownStory && err != ErrStoryAlreadyCollected {
+			err = errors.Wrap(err, function.GetFunctionName(s.InsertCollectionStory))
+		}
+	}()
+
+	collection, err := s.GetCollectionByID(id)
+	if err != nil {
+		return chronicle.Collection{}, err
+	}
+
+	if storyPosition(collection, storyID) >= 0 {
+		return chronicle.Collection{}, ErrStoryAlreadyCollected
+	}
+
+	if position < 0 || position > len(collection.Stories) {
+		position = len(collection.Stories)
+	}
+
+	if err := s.collectionRepository.InsertStory(id, storyID, position); err != nil {
+		switch errors.Cause(err) {
+		case chronicle.ErrUnknownStory:
+			return chronicle.Collection{}, ErrUnknownStory
+		default:
+			return chronicle.Collection{}, err
+		}
+	}
+
+	return s.GetCollectionByID(id)
+}
+
+func (s *service) RemoveCollectionStory(id int, storyID int) (updatedCollection chronicle.Collection, err error) {
+	defer func() {
+		if err != nil && err != ErrNoCollectionFound && err != ErrStoryNotCollected {
+			err = errors.Wrap(err, function.GetFunctionName(s.RemoveCollectionStory))
+		}
+	}()
+
+	collection, err := s.GetCollectionByID(id)
+	if err != nil {
+		return chronicle.Collection{}, err
+	}
+
+	if storyPosition(collection, storyID) < 0 {
+		return chronicle.Collection{}, ErrStoryNotCollected
+	}
+
+	if err := s.collectionRepository.RemoveStory(id, storyID); err != nil {
+		return chronicle.Collection{}, err
+	}
+
+	return s.GetCollectionByID(id)
+}
+
+// ReorderCollectionStories only move stories around, adding or removing stories has its own operation
+func (s *service) ReorderCollectionStories(id int, storyIDs []int) (updatedCollection chronicle.Collection, err error) {
+	defer func() {
+		if err != nil && err != ErrNoCollectionFound && err != ErrInvalidCollectionOrder {
+			err = errors.Wrap(err, function.GetFunctionName(s.ReorderCollectionStories))
+		}
+	}()
+
+	collection, err := s.GetCollectionByID(id)
+	if err != nil {
+		return chronicle.Collection{}, err
+	}
+
+	if len(storyIDs) != len(collection.Stories) {
+		return chronicle.Collection{}, ErrInvalidCollectionOrder
+	}
+
+	seenStoryIDs := map[int]bool{}
+	for _, storyID := range storyIDs {
+		if seenStoryIDs[storyID] || storyPosition(collection, storyID) < 0 {
+			return chronicle.Collection{}, ErrInvalidCollectionOrder
+		}
+		seenStoryIDs[storyID] = true
+	}
+
+	if err := s.collectionRepository.ReorderStories(id, storyIDs); err != nil {
+		return chronicle.Collection{}, err
+	}
+
+	return s.GetCollectionByID(id)
+}
+
+// resolveCollectionSlug suffix the slug of collection when another collection use it
+func (s *service) resolveCollectionSlug(collection *chronicle.Collection) (err error) {
+	collection.Slug, err = chronicle.UniqueSlug(collection.Slug, func(candidate string) (bool, error) {
+		return s.collectionRepository.IsSlugTaken(candidate, collection.ID)
+	})
+	return err
+}
+
+// storyPosition return position of story in collection, -1 when it is not there
+func storyPosition(collection chronicle.Collection, storyID int) int {
+	for position, story := range collection.Stories {
+		if story.ID == storyID {
+			return position
+		}
+	}
+
+	return -1
+}
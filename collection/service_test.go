@@ -0,0 +1,254 @@
+package collection_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	"github.com/AdhityaRamadhanus/chronicle"
+	"github.com/AdhityaRamadhanus/chronicle/collection"
+	"github.com/AdhityaRamadhanus/chronicle/config"
+	"github.com/AdhityaRamadhanus/chronicle/storage/postgre"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	collectionService    collection.Service
+	collectionRepository *postgre.CollectionRepository
+	storyRepository      *postgre.StoryRepository
+
+	// specific test case var
+	collectionId int
+	storyIds     []int
+)
+
+// make test kind of idempotent
+func setupDatabase(db *sqlx.DB) {
+	_, err := db.Query("DELETE FROM collection_stories")
+	if err != nil {
+		log.Fatal("Failed to setup database ", errors.Wrap(err, "Failed in delete from collection_stories"))
+	}
+
+	_, err = db.Query("DELETE FROM collections")
+	if err != nil {
+		log.Fatal("Failed to setup database ", errors.Wrap(err, "Failed in delete from collections"))
+	}
+
+	_, err = db.Query("DELETE FROM stories")
+	if err != nil {
+		log.Fatal("Failed to setup database ", errors.Wrap(err, "Failed in delete from stories"))
+	}
+}
+
+func TestMain(m *testing.M) {
+	log.SetLevel(log.WarnLevel)
+	if err := config.Init("testing", []string{"../config/testing"}); err != nil {
+		log.Fatal(err)
+	}
+
+	pgConnString := postgre.GetConnString()
+
+	db, err := sqlx.Open("postgres", pgConnString)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	setupDatabase(db)
+
+	// Repositories
+	collectionRepository = postgre.NewCollectionRepository(db, "collections")
+	storyRepository = postgre.NewStoryRepository(db, "stories")
+	collectionService = collection.NewService(collectionRepository)
+
+	code := m.Run()
+	os.Exit(code)
+}
+
+func collectedStoryIds(collection chronicle.Collection) []int {
+	ids := []int{}
+	for _, story := range collection.Stories {
+		ids = append(ids, story.ID)
+	}
+	return ids
+}
+
+func TestCreateCollectionIntegration(t *testing.T) {
+	// the first story is a draft, the others are published
+	stories := chronicle.Stories{}
+	for idx := 0; idx < 4; idx++ {
+		status := chronicle.StoryPublishStatus
+		if idx == 0 {
+			status = chronicle.StoryDraftStatus
+		}
+
+		createdStory, err := storyRepository.Insert(chronicle.Story{
+			Title:  fmt.Sprintf("Mudik Lebaran Bagian %d", idx+1),
+			Slug:   fmt.Sprintf("mudik-lebaran-bagian-%d", idx+1),
+			Media:  []byte("{}"),
+			Status: status,
+		})
+		if err != nil {
+			t.Fatal("Failed to create story", err)
+		}
+		storyIds = append(storyIds, createdStory.ID)
+		stories = append(stories, createdStory)
+	}
+
+	_, err := collectionService.CreateCollection(chronicle.Collection{
+		Title:   "Mudik Lebaran",
+		Slug:    "mudik-lebaran",
+		Stories: chronicle.Stories{stories[0], {ID: -1}},
+	})
+	assert.Equal(t, collection.ErrUnknownStory, err)
+
+	createdCollection, err := collectionService.CreateCollection(chronicle.Collection{
+		Title:       "Mudik Lebaran",
+		Slug:        "mudik-lebaran",
+		Description: "Liputan arus mudik",
+		Stories:     chronicle.Stories{stories[1], stories[0], stories[1]},
+	})
+	if err != nil {
+		t.Fatal("Failed to create collection", err)
+	}
+	collectionId = createdCollection.ID
+
+	// a story is collected once, in the order given
+	assert.Equal(t, []int{storyIds[1], storyIds[0]}, collectedStoryIds(createdCollection))
+
+	foundCollection, err := collectionService.GetCollectionBySlug("mudik-lebaran")
+	if assert.NoError(t, err) {
+		assert.Equal(t, collectionId, foundCollection.ID)
+	}
+}
+
+func TestInsertCollectionStoryIntegration(t *testing.T) {
+	testCases := []struct {
+		StoryID       int
+		Position      int
+		ExpectedError error
+		ExpectedOrder []int
+	}{
+		{
+			StoryID:       storyIds[2],
+			Position:      1,
+			ExpectedOrder: []int{storyIds[1], storyIds[2], storyIds[0]},
+		},
+		{
+			StoryID:       storyIds[3],
+			Position:      -1,
+			ExpectedOrder: []int{storyIds[1], storyIds[2], storyIds[0], storyIds[3]},
+		},
+		{
+			StoryID:       storyIds[3],
+			Position:      0,
+			ExpectedError: collection.ErrStoryAlreadyCollected,
+		},
+		{
+			StoryID:       -1,
+			Position:      0,
+			ExpectedError: collection.ErrUnknownStory,
+		},
+	}
+
+	for _, testCase := range testCases {
+		updatedCollection, err := collectionService.InsertCollectionStory(collectionId, testCase.StoryID, testCase.Position)
+		if testCase.ExpectedError != nil {
+			assert.Equal(t, testCase.ExpectedError, err)
+			continue
+		}
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, testCase.ExpectedOrder, collectedStoryIds(updatedCollection))
+		}
+	}
+}
+
+func TestStoryCollectionNeighboursIntegration(t *testing.T) {
+	// collection is [1, 2, 0, 3]
+	foundStory, err := storyRepository.Find(storyIds[2])
+	if err != nil {
+		t.Fatal("Failed to find story", err)
+	}
+
+	if assert.Len(t, foundStory.Collections, 1) {
+		storyCollection := foundStory.Collections[0]
+		assert.Equal(t, collectionId, storyCollection.ID)
+		assert.Equal(t, 1, storyCollection.Position)
+		if assert.NotNil(t, storyCollection.Previous) {
+			assert.Equal(t, storyIds[1], storyCollection.Previous.ID)
+		}
+		// the draft in between is skipped
+		if assert.NotNil(t, storyCollection.Next) {
+			assert.Equal(t, storyIds[3], storyCollection.Next.ID)
+		}
+	}
+
+	foundStory, err = storyRepository.Find(storyIds[1])
+	if err != nil {
+		t.Fatal("Failed to find story", err)
+	}
+
+	if assert.Len(t, foundStory.Collections, 1) {
+		assert.Nil(t, foundStory.Collections[0].Previous)
+	}
+
+	// a draft still get its published neighbours
+	foundStory, err = storyRepository.Find(storyIds[0])
+	if err != nil {
+		t.Fatal("Failed to find story", err)
+	}
+
+	if assert.Len(t, foundStory.Collections, 1) {
+		if assert.NotNil(t, foundStory.Collections[0].Previous) {
+			assert.Equal(t, storyIds[2], foundStory.Collections[0].Previous.ID)
+		}
+		if assert.NotNil(t, foundStory.Collections[0].Next) {
+			assert.Equal(t, storyIds[3], foundStory.Collections[0].Next.ID)
+		}
+	}
+}
+
+func TestReorderCollectionStoriesIntegration(t *testing.T) {
+	_, err := collectionService.ReorderCollectionStories(collectionId, []int{storyIds[0], storyIds[1]})
+	assert.Equal(t, collection.ErrInvalidCollectionOrder, err)
+
+	updatedCollection, err := collectionService.ReorderCollectionStories(collectionId, storyIds)
+	if assert.NoError(t, err) {
+		assert.Equal(t, storyIds, collectedStoryIds(updatedCollection))
+	}
+}
+
+func TestRemoveCollectionStoryIntegration(t *testing.T) {
+	updatedCollection, err := collectionService.RemoveCollectionStory(collectionId, storyIds[1])
+	if assert.NoError(t, err) {
+		assert.Equal(t, []int{storyIds[0], storyIds[2], storyIds[3]}, collectedStoryIds(updatedCollection))
+	}
+
+	_, err = collectionService.RemoveCollectionStory(collectionId, storyIds[1])
+	assert.Equal(t, collection.ErrStoryNotCollected, err)
+
+	// positions are closed up after removal
+	foundStory, err := storyRepository.Find(storyIds[2])
+	if err != nil {
+		t.Fatal("Failed to find story", err)
+	}
+
+	// collection is [0, 2, 3] and the draft before it is not a neighbour
+	if assert.Len(t, foundStory.Collections, 1) {
+		assert.Equal(t, 1, foundStory.Collections[0].Position)
+		assert.Nil(t, foundStory.Collections[0].Previous)
+	}
+}
+
+func TestDeleteCollectionIntegration(t *testing.T) {
+	err := collectionService.DeleteCollectionByID(collectionId)
+	assert.NoError(t, err)
+
+	_, err = collectionService.GetCollectionByID(collectionId)
+	assert.Equal(t, collection.ErrNoCollectionFound, err)
+}
@@ -0,0 +1,28 @@
+/*
+Package content render story content of every format into html that is safe to serve
+and turn it back into other representations
+*/
+package content
+
+import (
+	"errors"
+
+	chronicle "github.com/AdhityaRamadhanus/chronicle"
+)
+
+//ErrUnknownFormat content format is not one of chronicle.StoryContent*
+var ErrUnknownFormat = errors.New("Unknown content format")
+
+//Render turn source written in format into sanitized html, an empty format means html
+func Render(format, source string) (string, error) {
+	switch format {
+	case "", chronicle.StoryContentHTML:
+		return Sanitize(source), nil
+	case chronicle.StoryContentMarkdown:
+		return Sanitize(Markdown(source)), nil
+	case chronicle.StoryContentText:
+		return TextToHTML(source), nil
+	default:
+		return "", ErrUnknownFormat
+	}
+}
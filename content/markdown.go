@@ -0,0 +1,634 @@
+package content
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	headingPattern = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	hrPattern      = regexp.MustCompile(`^ {0,3}(?:(?:-[ \t]*){3,}|(?:\*[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	fencePattern   = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})[ \t]*([^`\\s]*)")
+	quotePattern   = regexp.MustCompile(`^ {0,3}> ?(.*)$`)
+	bulletPattern  = regexp.MustCompile(`^( {0,3})([-*+])(?:[ \t]+(.*))?$`)
+	orderedPattern = regexp.MustCompile(`^( {0,3})(\d{1,9})([.)])(?:[ \t]+(.*))?$`)
+
+	// html blocks, raw text elements end at their end tag, comments at "-->" and block level tags at a blank line
+	rawHTMLBlockPattern     = regexp.MustCompile(`(?i)^ {0,3}<(script|pre|style|textarea)(?:[ \t>]|$)`)
+	commentHTMLBlockPattern = regexp.MustCompile(`^ {0,3}<!--`)
+	tagHTMLBlockPattern     = regexp.MustCompile(`(?i)^ {0,3}</?(?:address|article|aside|base|basefont|blockquote|body|caption|center|col|colgroup|dd|details|dialog|dir|div|dl|dt|fieldset|figcaption|figure|footer|form|frame|frameset|h[1-6]|head|header|hr|html|iframe|legend|li|link|main|menu|menuitem|nav|noframes|ol|optgroup|option|p|param|section|source|summary|table|tbody|td|tfoot|th|thead|title|tr|track|ul)(?:[ \t>]|/>|$)`)
+)
+
+/*
+Markdown render the commonly used part of markdown to html: atx headings, paragraphs with hard breaks,
+emphasis, strikethrough, code spans and blocks, block quotes, nested lists, rules, links, images and autolinks.
+Raw html blocks and inline tags are passed through as they are, the output must go through Sanitize before it is served
+*/
+func Markdown(input string) string {
+	return strings.Join(renderBlocks(splitLines(input)), "\n")
+}
+
+// splitLines normalize line endings and tabs, NUL is replaced as it mark hard breaks while rendering
+func splitLines(input string) []string {
+	input = strings.Replace(input, "\r\n", "\n", -1)
+	input = strings.Replace(input, "\r", "\n", -1)
+	input = strings.Replace(input, "\t", "    ", -1)
+	input = strings.Replace(input, "\x00", "�", -1)
+	return strings.Split(input, "\n")
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// startsBlock tell whether line interrupt a paragraph
+func startsBlock(line string) bool {
+	return isBlank(line) ||
+		rawHTMLBlockPattern.MatchString(line) ||
+		commentHTMLBlockPattern.MatchString(line) ||
+		tagHTMLBlockPattern.MatchString(line) ||
+		fencePattern.MatchString(line) ||
+		headingPattern.MatchString(line) ||
+		hrPattern.MatchString(line) ||
+		quotePattern.MatchString(line) ||
+		isListItem(line)
+}
+
+func isListItem(line string) bool {
+	if m := bulletPattern.FindStringSubmatch(line); m != nil && m[3] != "" {
+		return true
+	}
+	if m := orderedPattern.FindStringSubmatch(line); m != nil && m[4] != "" {
+		return true
+	}
+	return false
+}
+
+func renderBlocks(lines []string) []string {
+	blocks := []string{}
+	for idx := 0; idx < len(lines); {
+		line := lines[idx]
+
+		if isBlank(line) {
+			idx++
+			continue
+		}
+
+		if m := fencePattern.FindStringSubmatch(line); m != nil {
+			fence := m[1]
+			code := []string{}
+			for idx++; idx < len(lines); idx++ {
+				if trimmed := strings.TrimSpace(lines[idx]); strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+					idx++
+					break
+				}
+				code = append(code, lines[idx])
+			}
+
+			class := ""
+			if m[2] != "" {
+				class = ` class="language-` + html.EscapeString(m[2]) + `"`
+			}
+			blocks = append(blocks, "<pre><code"+class+">"+html.EscapeString(strings.Join(code, "\n"))+"</code></pre>")
+			continue
+		}
+
+		if end, ok := htmlBlockEnd(lines, idx); ok {
+			blocks = append(blocks, strings.Join(lines[idx:end], "\n"))
+			idx = end
+			continue
+		}
+
+		if indentOf(line) >= 4 {
+			code := []string{}
+			for ; idx < len(lines) && (isBlank(lines[idx]) || indentOf(lines[idx]) >= 4); idx++ {
+				if isBlank(lines[idx]) {
+					code = append(code, "")
+					continue
+				}
+				code = append(code, lines[idx][4:])
+			}
+
+			for len(code) > 0 && code[len(code)-1] == "" {
+				code = code[:len(code)-1]
+			}
+			blocks = append(blocks, "<pre><code>"+html.EscapeString(strings.Join(code, "\n"))+"</code></pre>")
+			continue
+		}
+
+		if m := headingPattern.FindStringSubmatch(line); m != nil {
+			level := len(m[1])
+			blocks = append(blocks, fmt.Sprintf("<h%d>%s</h%d>", level, renderInline(m[2]), level))
+			idx++
+			continue
+		}
+
+		if hrPattern.MatchString(line) {
+			blocks = append(blocks, "<hr>")
+			idx++
+			continue
+		}
+
+		if quotePattern.MatchString(line) {
+			// lines without '>' continue the quoted paragraph
+			quoted := []string{}
+			for ; idx < len(lines); idx++ {
+				if m := quotePattern.FindStringSubmatch(lines[idx]); m != nil {
+					quoted = append(quoted, m[1])
+					continue
+				}
+				if startsBlock(lines[idx]) {
+					break
+				}
+				quoted = append(quoted, lines[idx])
+			}
+			blocks = append(blocks, "<blockquote>\n"+strings.Join(renderBlocks(quoted), "\n")+"\n</blockquote>")
+			continue
+		}
+
+		if isListItem(line) {
+			var list string
+			list, idx = renderList(lines, idx)
+			blocks = append(blocks, list)
+			continue
+		}
+
+		paragraph := []string{}
+		for ; idx < len(lines) && (len(paragraph) == 0 || !startsBlock(lines[idx])); idx++ {
+			paragraph = append(paragraph, lines[idx])
+		}
+		blocks = append(blocks, "<p>"+renderInline(joinParagraph(paragraph))+"</p>")
+	}
+
+	return blocks
+}
+
+/*
+htmlBlockEnd tell whether an html block start at lines[idx] and return the index after it. Besides block level tags,
+a line holding only a complete tag start a block too, but unlike the others it does not interrupt a paragraph
+*/
+func htmlBlockEnd(lines []string, idx int) (int, bool) {
+	line := lines[idx]
+	endsWith := func(closing string) (int, bool) {
+		for end := idx; end < len(lines); end++ {
+			if strings.Contains(strings.ToLower(lines[end]), closing) {
+				return end + 1, true
+			}
+		}
+		return len(lines), true
+	}
+
+	if m := rawHTMLBlockPattern.FindStringSubmatch(line); m != nil {
+		return endsWith("</" + strings.ToLower(m[1]) + ">")
+	}
+
+	if commentHTMLBlockPattern.MatchString(line) {
+		return endsWith("-->")
+	}
+
+	trimmed := strings.TrimSpace(line)
+	isTagLine := false
+	if indentOf(line) < 4 && strings.HasPrefix(trimmed, "<") {
+		_, length, ok := parseTag(trimmed)
+		isTagLine = ok && length == len(trimmed)
+	}
+
+	if !tagHTMLBlockPattern.MatchString(line) && !isTagLine {
+		return 0, false
+	}
+
+	end := idx
+	for end < len(lines) && !isBlank(lines[end]) {
+		end++
+	}
+	return end, true
+}
+
+// joinParagraph join lines of a paragraph, marking hard breaks (two trailing spaces or a backslash) with NUL
+func joinParagraph(lines []string) string {
+	for idx, line := range lines {
+		line = strings.TrimLeft(line, " ")
+		if idx < len(lines)-1 {
+			switch {
+			case strings.HasSuffix(line, "  "):
+				line = strings.TrimRight(line, " ") + "\x00"
+			case strings.HasSuffix(line, "\\"):
+				line = strings.TrimSuffix(line, "\\") + "\x00"
+			}
+		}
+		lines[idx] = strings.TrimRight(line, " ")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+type listItem struct {
+	ordered bool
+	// bullet character or ordered delimiter
+	marker        string
+	number        int
+	contentIndent int
+	content       string
+}
+
+func parseListItem(line string) (item listItem, ok bool) {
+	if loc := bulletPattern.FindStringSubmatchIndex(line); loc != nil && loc[6] >= 0 && loc[6] != loc[7] {
+		return listItem{marker: line[loc[4]:loc[5]], contentIndent: loc[6], content: line[loc[6]:loc[7]]}, true
+	}
+
+	if loc := orderedPattern.FindStringSubmatchIndex(line); loc != nil && loc[8] >= 0 && loc[8] != loc[9] {
+		number, _ := strconv.Atoi(line[loc[4]:loc[5]])
+		return listItem{ordered: true, marker: line[loc[6]:loc[7]], number: number, contentIndent: loc[8], content: line[loc[8]:loc[9]]}, true
+	}
+
+	return listItem{}, false
+}
+
+/*
+renderList render the list starting at lines[idx] and return the index after it, items are
+wrapped in paragraphs only when a blank line separate them or their blocks
+*/
+func renderList(lines []string, idx int) (string, int) {
+	first, _ := parseListItem(lines[idx])
+	markerIndent := indentOf(lines[idx])
+
+	items := [][]string{}
+	contentIndent := 0
+	pendingBlank := false
+	loose := false
+	for idx < len(lines) {
+		line := lines[idx]
+
+		// an item indented past the marker of the first item is nested in the current one
+		if item, ok := parseListItem(line); ok && (len(items) == 0 || indentOf(line) < contentIndent && indentOf(line) <= markerIndent+1) {
+			if item.ordered != first.ordered || item.marker != first.marker {
+				break
+			}
+			loose = loose || pendingBlank
+			pendingBlank = false
+			items = append(items, []string{item.content})
+			contentIndent = item.contentIndent
+			idx++
+			continue
+		}
+
+		if isBlank(line) {
+			pendingBlank = true
+			items[len(items)-1] = append(items[len(items)-1], "")
+			idx++
+			continue
+		}
+
+		// indented lines belong to the item, a nested list may be indented less than the item content
+		if indent := indentOf(line); indent >= contentIndent || indent >= 2 && isListItem(line) {
+			if indent > contentIndent {
+				indent = contentIndent
+			}
+			loose = loose || pendingBlank && !isListItem(line)
+			pendingBlank = false
+			items[len(items)-1] = append(items[len(items)-1], line[indent:])
+			idx++
+			continue
+		}
+
+		// lazy continuation of the paragraph of the item
+		if !pendingBlank && !startsBlock(line) {
+			items[len(items)-1] = append(items[len(items)-1], strings.TrimSpace(line))
+			idx++
+			continue
+		}
+
+		break
+	}
+
+	tag := "ul"
+	openTag := "<ul>"
+	if first.ordered {
+		tag = "ol"
+		openTag = "<ol>"
+		if first.number != 1 {
+			openTag = fmt.Sprintf(`<ol start="%d">`, first.number)
+		}
+	}
+
+	renderedItems := []string{}
+	for _, item := range items {
+		blocks := renderBlocks(item)
+		if !loose {
+			for blockIdx, block := range blocks {
+				if strings.HasPrefix(block, "<p>") && strings.HasSuffix(block, "</p>") {
+					blocks[blockIdx] = strings.TrimSuffix(strings.TrimPrefix(block, "<p>"), "</p>")
+				}
+			}
+		}
+		renderedItems = append(renderedItems, "<li>"+strings.Join(blocks, "\n")+"</li>")
+	}
+
+	return openTag + "\n" + strings.Join(renderedItems, "\n") + "\n</" + tag + ">", idx
+}
+
+// renderInline render emphasis, code spans, links, images and autolinks of text, html tags are kept and the rest escaped
+func renderInline(text string) string {
+	var out strings.Builder
+	for idx := 0; idx < len(text); {
+		c := text[idx]
+		switch {
+		case c == '\\' && idx+1 < len(text) && isASCIIPunctuation(text[idx+1]):
+			writeEscapedByte(&out, text[idx+1])
+			idx += 2
+		case c == '\x00':
+			out.WriteString("<br>")
+			idx++
+		case c == '`':
+			run := len(text[idx:]) - len(strings.TrimLeft(text[idx:], "`"))
+			end := findCodeSpanEnd(text, idx+run, run)
+			if end < 0 {
+				out.WriteString(text[idx : idx+run])
+				idx += run
+				continue
+			}
+
+			code := strings.Replace(text[idx+run:end], "\n", " ", -1)
+			if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.TrimSpace(code) != "" {
+				code = code[1 : len(code)-1]
+			}
+			out.WriteString("<code>" + html.EscapeString(code) + "</code>")
+			idx = end + run
+		case c == '!' && idx+1 < len(text) && text[idx+1] == '[':
+			label, destination, title, length, ok := parseLink(text[idx+1:])
+			if !ok {
+				out.WriteByte('!')
+				idx++
+				continue
+			}
+
+			out.WriteString(`<img src="` + html.EscapeString(destination) + `" alt="` + html.EscapeString(HTMLToText(renderInline(label))) + `"`)
+			if title != "" {
+				out.WriteString(` title="` + html.EscapeString(title) + `"`)
+			}
+			out.WriteString(">")
+			idx += 1 + length
+		case c == '[':
+			label, destination, title, length, ok := parseLink(text[idx:])
+			if !ok {
+				out.WriteByte('[')
+				idx++
+				continue
+			}
+
+			out.WriteString(`<a href="` + html.EscapeString(destination) + `"`)
+			if title != "" {
+				out.WriteString(` title="` + html.EscapeString(title) + `"`)
+			}
+			out.WriteString(">" + renderInline(label) + "</a>")
+			idx += length
+		case c == '<':
+			end := strings.IndexByte(text[idx:], '>')
+			if end < 0 {
+				out.WriteString("&lt;")
+				idx++
+				continue
+			}
+
+			destination := text[idx+1 : idx+end]
+			lowerDestination := strings.ToLower(destination)
+			isAutolink := strings.HasPrefix(lowerDestination, "http://") || strings.HasPrefix(lowerDestination, "https://") || strings.HasPrefix(lowerDestination, "mailto:")
+			if isAutolink && !strings.ContainsAny(destination, " \n<") {
+				out.WriteString(`<a href="` + html.EscapeString(destination) + `">` + html.EscapeString(destination) + "</a>")
+				idx += end + 1
+				continue
+			}
+
+			// inline tags and comments are kept, Sanitize decide what survive
+			if strings.HasPrefix(text[idx:], "<!--") {
+				if commentEnd := strings.Index(text[idx+4:], "-->"); commentEnd >= 0 {
+					out.WriteString(text[idx : idx+4+commentEnd+3])
+					idx += 4 + commentEnd + 3
+					continue
+				}
+			}
+
+			if _, length, ok := parseTag(text[idx:]); ok {
+				out.WriteString(text[idx : idx+length])
+				idx += length
+				continue
+			}
+
+			out.WriteString("&lt;")
+			idx++
+		case c == '*' || c == '_' || c == '~':
+			length, rendered := renderEmphasis(text, idx)
+			out.WriteString(rendered)
+			idx += length
+		default:
+			writeEscapedByte(&out, c)
+			idx++
+		}
+	}
+
+	return out.String()
+}
+
+// renderEmphasis render the emphasis opened at text[idx], or the delimiter run as text when it is not closed
+func renderEmphasis(text string, idx int) (length int, rendered string) {
+	c := text[idx]
+	run := len(text[idx:]) - len(strings.TrimLeft(text[idx:], string(c)))
+
+	tags := map[int]string{1: "em", 2: "strong"}
+	if c == '~' {
+		tags = map[int]string{2: "del"}
+	}
+
+	// '_' does not open or close within a word
+	leftFlanking := idx+run < len(text) && !isWhitespace(text[idx+run])
+	if c == '_' && idx > 0 && isAlphanumeric(text[idx-1]) {
+		leftFlanking = false
+	}
+
+	for _, size := range []int{2, 1} {
+		tag, ok := tags[size]
+		if !ok || run < size || !leftFlanking {
+			continue
+		}
+
+		delimiter := strings.Repeat(string(c), size)
+		end := findEmphasisEnd(text, idx+size, delimiter)
+		if end < 0 {
+			continue
+		}
+
+		return end + size - idx, "<" + tag + ">" + renderInline(text[idx+size:end]) + "</" + tag + ">"
+	}
+
+	return run, text[idx : idx+run]
+}
+
+// findEmphasisEnd find the closing delimiter at or after start, skipping code spans, escapes and longer delimiter runs
+func findEmphasisEnd(text string, start int, delimiter string) int {
+	c := delimiter[0]
+	for idx := start; idx < len(text); idx++ {
+		switch text[idx] {
+		case '\\':
+			idx++
+			continue
+		case '`':
+			run := len(text[idx:]) - len(strings.TrimLeft(text[idx:], "`"))
+			if end := findCodeSpanEnd(text, idx+run, run); end >= 0 {
+				idx = end + run - 1
+			} else {
+				idx += run - 1
+			}
+			continue
+		}
+
+		if text[idx] != c {
+			continue
+		}
+
+		run := len(text[idx:]) - len(strings.TrimLeft(text[idx:], string(c)))
+		rightFlanking := idx > start && !isWhitespace(text[idx-1])
+		if c == '_' && idx+run < len(text) && isAlphanumeric(text[idx+run]) {
+			rightFlanking = false
+		}
+
+		// a single delimiter is not closed by a double one, those belong to nested strong emphasis
+		// the inner delimiters of a longer run close nested emphasis first
+		if rightFlanking && run == len(delimiter) {
+			return idx
+		}
+		if rightFlanking && len(delimiter) == 2 && run > 2 {
+			return idx + run - len(delimiter)
+		}
+		idx += run - 1
+	}
+
+	return -1
+}
+
+// findCodeSpanEnd find a backtick run of exactly run length at or after start
+func findCodeSpanEnd(text string, start int, run int) int {
+	for idx := start; idx < len(text); {
+		if text[idx] != '`' {
+			idx++
+			continue
+		}
+
+		length := len(text[idx:]) - len(strings.TrimLeft(text[idx:], "`"))
+		if length == run {
+			return idx
+		}
+		idx += length
+	}
+
+	return -1
+}
+
+// parseLink parse [label](destination "title") at the start of s
+func parseLink(s string) (label string, destination string, title string, length int, ok bool) {
+	depth := 0
+	labelEnd := -1
+	for idx := 0; idx < len(s) && labelEnd < 0; idx++ {
+		switch s[idx] {
+		case '\\':
+			idx++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				labelEnd = idx
+			}
+		}
+	}
+
+	if labelEnd < 0 || labelEnd+1 >= len(s) || s[labelEnd+1] != '(' {
+		return "", "", "", 0, false
+	}
+
+	pos := labelEnd + 2
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+
+	if pos < len(s) && s[pos] == '<' {
+		end := strings.IndexAny(s[pos:], ">\n")
+		if end < 0 || s[pos+end] != '>' {
+			return "", "", "", 0, false
+		}
+		destination = s[pos+1 : pos+end]
+		pos += end + 1
+	} else {
+		destinationStart := pos
+		parens := 0
+		for ; pos < len(s) && !isWhitespace(s[pos]); pos++ {
+			if s[pos] == '(' {
+				parens++
+			}
+			if s[pos] == ')' {
+				if parens == 0 {
+					break
+				}
+				parens--
+			}
+		}
+		destination = s[destinationStart:pos]
+	}
+
+	for pos < len(s) && isWhitespace(s[pos]) {
+		pos++
+	}
+
+	if pos < len(s) && (s[pos] == '"' || s[pos] == '\'') {
+		end := strings.IndexByte(s[pos+1:], s[pos])
+		if end < 0 {
+			return "", "", "", 0, false
+		}
+		title = s[pos+1 : pos+1+end]
+		pos += end + 2
+		for pos < len(s) && isWhitespace(s[pos]) {
+			pos++
+		}
+	}
+
+	if pos >= len(s) || s[pos] != ')' {
+		return "", "", "", 0, false
+	}
+
+	return s[1:labelEnd], destination, title, pos + 1, true
+}
+
+func writeEscapedByte(out *strings.Builder, c byte) {
+	switch c {
+	case '<':
+		out.WriteString("&lt;")
+	case '>':
+		out.WriteString("&gt;")
+	case '&':
+		out.WriteString("&amp;")
+	case '"':
+		out.WriteString("&#34;")
+	case '\'':
+		out.WriteString("&#39;")
+	default:
+		out.WriteByte(c)
+	}
+}
+
+func isASCIIPunctuation(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
+
+func isWhitespace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\t'
+}
+
+func isAlphanumeric(c byte) bool {
+	return isASCIILetter(c) || (c >= '0' && c <= '9') || c >= 0x80
+}
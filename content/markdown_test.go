@@ -0,0 +1,96 @@
+package content_test
+
+import (
+	"testing"
+
+	"github.com/AdhityaRamadhanus/chronicle/content"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkdown(t *testing.T) {
+	testCases := []struct {
+		Input          string
+		ExpectedOutput string
+	}{
+		{
+			Input:          "# Title\n\nSome *emphasis* and **strong** text",
+			ExpectedOutput: "<h1>Title</h1>\n<p>Some <em>emphasis</em> and <strong>strong</strong> text</p>",
+		},
+		{
+			// lines of a paragraph are joined, two trailing spaces make a hard break
+			Input:          "first line\nsecond line  \nthird line",
+			ExpectedOutput: "<p>first line\nsecond line<br>\nthird line</p>",
+		},
+		{
+			Input:          "- one\n- two\n\n1. first\n2. second",
+			ExpectedOutput: "<ul>\n<li>one</li>\n<li>two</li>\n</ul>\n<ol>\n<li>first</li>\n<li>second</li>\n</ol>",
+		},
+		{
+			Input:          "> quoted\n\n---",
+			ExpectedOutput: "<blockquote>\n<p>quoted</p>\n</blockquote>\n<hr>",
+		},
+		{
+			Input:          "```go\nfmt.Println(\"<b>\")\n```",
+			ExpectedOutput: "<pre><code class=\"language-go\">fmt.Println(&#34;&lt;b&gt;&#34;)</code></pre>",
+		},
+		{
+			Input:          "[link](https://example.com \"title\") ![alt](/image.png) `a < b`",
+			ExpectedOutput: "<p><a href=\"https://example.com\" title=\"title\">link</a> <img src=\"/image.png\" alt=\"alt\"> <code>a &lt; b</code></p>",
+		},
+		{
+			Input:          "\\*not emphasis\\* and <https://example.com>",
+			ExpectedOutput: "<p>*not emphasis* and <a href=\"https://example.com\">https://example.com</a></p>",
+		},
+		{
+			// html blocks end at a blank line and inline tags are kept, a lone '<' is still text
+			Input:          "<div class=\"note\">\n*not markdown*\n</div>\n\nSome <sup>1</sup> and 1 < 2 <!-- note -->",
+			ExpectedOutput: "<div class=\"note\">\n*not markdown*\n</div>\n<p>Some <sup>1</sup> and 1 &lt; 2 <!-- note --></p>",
+		},
+		{
+			// raw text elements run to their end tag even across blank lines, block tags interrupt a paragraph
+			Input:          "text\n<pre>\na\n\n  b\n</pre>\n<figure>\n<img src=\"/a.png\">\n</figure>",
+			ExpectedOutput: "<p>text</p>\n<pre>\na\n\n  b\n</pre>\n<figure>\n<img src=\"/a.png\">\n</figure>",
+		},
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.ExpectedOutput, content.Markdown(testCase.Input), testCase.Input)
+	}
+}
+
+func TestRender(t *testing.T) {
+	testCases := []struct {
+		Format         string
+		Input          string
+		ExpectedOutput string
+		ExpectedError  error
+	}{
+		{
+			// raw html in markdown is passed to Sanitize, which drop unsafe links and elements
+			Format:         "markdown",
+			Input:          "[click](javascript:alert(1)) <b onclick=\"alert(1)\">bold</b> <script>alert(1)</script>\n\n<script>\nalert(2)\n</script>",
+			ExpectedOutput: "<p><a>click</a> <b>bold</b> </p>\n",
+		},
+		{
+			Format:         "text",
+			Input:          "first <line>\nsecond line\n\nnext paragraph",
+			ExpectedOutput: "<p>first &lt;line&gt;<br>second line</p>\n<p>next paragraph</p>",
+		},
+		{
+			Format:         "",
+			Input:          "<p onclick=\"alert(1)\">hello</p>",
+			ExpectedOutput: "<p>hello</p>",
+		},
+		{
+			Format:        "rtf",
+			Input:         "hello",
+			ExpectedError: content.ErrUnknownFormat,
+		},
+	}
+
+	for _, testCase := range testCases {
+		output, err := content.Render(testCase.Format, testCase.Input)
+		assert.Equal(t, testCase.ExpectedError, err)
+		assert.Equal(t, testCase.ExpectedOutput, output)
+	}
+}
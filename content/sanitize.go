@@ -0,0 +1,174 @@
+package content
+
+import (
+	"html"
+	"strings"
+)
+
+var (
+	// allowed tags and the attributes allowed on each of them, anything else is stripped while its text is kept
+	allowedTags = map[string]map[string]bool{
+		"a":          {"href": true, "title": true},
+		"abbr":       {"title": true},
+		"b":          {},
+		"blockquote": {"cite": true},
+		"br":         {},
+		"caption":    {},
+		"code":       {"class": true},
+		"del":        {},
+		"div":        {},
+		"em":         {},
+		"figcaption": {},
+		"figure":     {},
+		"h1":         {},
+		"h2":         {},
+		"h3":         {},
+		"h4":         {},
+		"h5":         {},
+		"h6":         {},
+		"hr":         {},
+		"i":          {},
+		"img":        {"src": true, "alt": true, "title": true, "width": true, "height": true},
+		"ins":        {},
+		"li":         {},
+		"ol":         {"start": true},
+		"p":          {},
+		"pre":        {},
+		"s":          {},
+		"small":      {},
+		"span":       {},
+		"strong":     {},
+		"sub":        {},
+		"sup":        {},
+		"table":      {},
+		"tbody":      {},
+		"td":         {"colspan": true, "rowspan": true},
+		"tfoot":      {},
+		"th":         {"colspan": true, "rowspan": true},
+		"thead":      {},
+		"tr":         {},
+		"u":          {},
+		"ul":         {},
+	}
+
+	// tags without content or end tag
+	voidTags = map[string]bool{
+		"br":  true,
+		"hr":  true,
+		"img": true,
+	}
+
+	// tags stripped along with everything inside them
+	droppedTags = map[string]bool{
+		"applet":   true,
+		"embed":    true,
+		"frame":    true,
+		"frameset": true,
+		"head":     true,
+		"iframe":   true,
+		"math":     true,
+		"noscript": true,
+		"object":   true,
+		"script":   true,
+		"select":   true,
+		"style":    true,
+		"svg":      true,
+		"template": true,
+		"textarea": true,
+		"title":    true,
+	}
+
+	// attributes holding urls, only relative urls and these schemes are kept
+	urlAttributes  = map[string]bool{"href": true, "src": true, "cite": true}
+	allowedSchemes = map[string]bool{"http": true, "https": true, "mailto": true}
+)
+
+/*
+Sanitize rewrite html keeping only allowlisted tags and attributes, scripts and other active content
+are dropped with their content, event handlers and urls with unsafe schemes are dropped and unclosed tags are closed
+*/
+func Sanitize(input string) string {
+	var output strings.Builder
+	openTags := []string{}
+
+	t := newTokenizer(input)
+	for {
+		tok, ok := t.next()
+		if !ok {
+			break
+		}
+
+		switch tok.kind {
+		case textToken:
+			output.WriteString(html.EscapeString(tok.text))
+		case startTagToken:
+			if droppedTags[tok.name] {
+				if !tok.selfClosing {
+					t.skipUntilEndTag(tok.name)
+				}
+				continue
+			}
+
+			allowedAttributes, allowed := allowedTags[tok.name]
+			if !allowed {
+				continue
+			}
+
+			output.WriteString("<" + tok.name)
+			for _, attr := range tok.attributes {
+				if !allowedAttributes[attr.name] {
+					continue
+				}
+				if urlAttributes[attr.name] && !isSafeURL(attr.value) {
+					continue
+				}
+				output.WriteString(" " + attr.name + `="` + html.EscapeString(attr.value) + `"`)
+			}
+			output.WriteString(">")
+
+			if !voidTags[tok.name] {
+				openTags = append(openTags, tok.name)
+			}
+		case endTagToken:
+			// close tags left open inside it, a stray end tag is dropped
+			for idx := len(openTags) - 1; idx >= 0; idx-- {
+				if openTags[idx] != tok.name {
+					continue
+				}
+				for len(openTags) > idx {
+					output.WriteString("</" + openTags[len(openTags)-1] + ">")
+					openTags = openTags[:len(openTags)-1]
+				}
+				break
+			}
+		}
+	}
+
+	for idx := len(openTags) - 1; idx >= 0; idx-- {
+		output.WriteString("</" + openTags[idx] + ">")
+	}
+
+	return output.String()
+}
+
+// isSafeURL accept relative urls and urls with an allowed scheme, browsers ignore control characters and spaces within the scheme
+func isSafeURL(url string) bool {
+	normalized := strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, strings.ToLower(url))
+
+	colon := strings.IndexByte(normalized, ':')
+	if colon < 0 {
+		return true
+	}
+
+	// a colon after the path started is not a scheme
+	if firstPathChar := strings.IndexAny(normalized, "/?#"); firstPathChar >= 0 && firstPathChar < colon {
+		return true
+	}
+
+	return allowedSchemes[normalized[:colon]]
+}
@@ -0,0 +1,71 @@
+package content_test
+
+import (
+	"testing"
+
+	"github.com/AdhityaRamadhanus/chronicle/content"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitize(t *testing.T) {
+	testCases := []struct {
+		Input          string
+		ExpectedOutput string
+	}{
+		{
+			Input:          "<p>Hello <b>world</b></p>",
+			ExpectedOutput: "<p>Hello <b>world</b></p>",
+		},
+		{
+			// active content is dropped along with what it contains
+			Input:          "<p>a<script>alert(1)</script>b<iframe src=\"x\"></iframe>c</p>",
+			ExpectedOutput: "<p>abc</p>",
+		},
+		{
+			// unknown tags are stripped but their text is kept
+			Input:          "<article><font color=\"red\">text</font></article>",
+			ExpectedOutput: "text",
+		},
+		{
+			Input:          "<img src=\"x\" onerror=\"alert(1)\"><a href=\"JaVa\tScript:alert(1)\" title=\"t\">a</a>",
+			ExpectedOutput: "<img src=\"x\"><a title=\"t\">a</a>",
+		},
+		{
+			Input:          "<a href=\"/relative?a=1&amp;b=2\">a</a><a href=\"mailto:x@example.com\">b</a>",
+			ExpectedOutput: "<a href=\"/relative?a=1&amp;b=2\">a</a><a href=\"mailto:x@example.com\">b</a>",
+		},
+		{
+			// unclosed tags are closed, stray end tags dropped
+			Input:          "<ul><li><em>one</li></div>",
+			ExpectedOutput: "<ul><li><em>one</em></li></ul>",
+		},
+		{
+			Input:          "1 < 2 & <!-- comment -->3 > 2",
+			ExpectedOutput: "1 &lt; 2 &amp; 3 &gt; 2",
+		},
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.ExpectedOutput, content.Sanitize(testCase.Input), testCase.Input)
+	}
+}
+
+func TestHTMLToText(t *testing.T) {
+	testCases := []struct {
+		Input          string
+		ExpectedOutput string
+	}{
+		{
+			Input:          "<h1>Title</h1><p>Some   <b>bold</b>\ntext &amp; more</p><script>x</script>",
+			ExpectedOutput: "Title\nSome bold text & more",
+		},
+		{
+			Input:          "<ul><li>one</li><li>two</li></ul>line<br>break",
+			ExpectedOutput: "one\ntwo\nline\nbreak",
+		},
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.ExpectedOutput, content.HTMLToText(testCase.Input), testCase.Input)
+	}
+}
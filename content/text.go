@@ -0,0 +1,105 @@
+package content
+
+import (
+	"html"
+	"strings"
+)
+
+// tags that start a new line when html is turned into text
+var blockTags = map[string]bool{
+	"blockquote": true,
+	"br":         true,
+	"div":        true,
+	"figcaption": true,
+	"figure":     true,
+	"h1":         true,
+	"h2":         true,
+	"h3":         true,
+	"h4":         true,
+	"h5":         true,
+	"h6":         true,
+	"hr":         true,
+	"li":         true,
+	"ol":         true,
+	"p":          true,
+	"pre":        true,
+	"table":      true,
+	"tr":         true,
+	"ul":         true,
+}
+
+//HTMLToText strip tags of html leaving its text, a line per block and whitespace within a line collapsed
+func HTMLToText(input string) string {
+	var text strings.Builder
+	// line breaks are only kept within pre, elsewhere they are whitespace like any other
+	preDepth := 0
+
+	t := newTokenizer(input)
+	for {
+		tok, ok := t.next()
+		if !ok {
+			break
+		}
+
+		switch tok.kind {
+		case textToken:
+			if preDepth > 0 {
+				text.WriteString(tok.text)
+			} else {
+				text.WriteString(strings.Replace(tok.text, "\n", " ", -1))
+			}
+		case startTagToken:
+			if droppedTags[tok.name] {
+				if !tok.selfClosing {
+					t.skipUntilEndTag(tok.name)
+				}
+				continue
+			}
+			if tok.name == "pre" {
+				preDepth++
+			}
+			if blockTags[tok.name] {
+				text.WriteString("\n")
+			}
+		case endTagToken:
+			if tok.name == "pre" && preDepth > 0 {
+				preDepth--
+			}
+			if blockTags[tok.name] {
+				text.WriteString("\n")
+			}
+		}
+	}
+
+	lines := []string{}
+	for _, line := range strings.Split(text.String(), "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+//TextToHTML escape plain text into paragraphs separated by blank lines, a single line break become <br>
+func TextToHTML(input string) string {
+	paragraphs := []string{}
+	lines := []string{}
+	for _, line := range splitLines(input) {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, html.EscapeString(line))
+			continue
+		}
+
+		if len(lines) > 0 {
+			paragraphs = append(paragraphs, "<p>"+strings.Join(lines, "<br>")+"</p>")
+			lines = []string{}
+		}
+	}
+
+	if len(lines) > 0 {
+		paragraphs = append(paragraphs, "<p>"+strings.Join(lines, "<br>")+"</p>")
+	}
+
+	return strings.Join(paragraphs, "\n")
+}
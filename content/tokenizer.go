@@ -0,0 +1,197 @@
+package content
+
+import (
+	"html"
+	"strings"
+)
+
+type tokenType int
+
+const (
+	textToken tokenType = iota
+	startTagToken
+	endTagToken
+	// comments, doctypes and processing instructions
+	ignoredToken
+)
+
+type attribute struct {
+	name  string
+	value string
+}
+
+type token struct {
+	kind tokenType
+	// unescaped text of text token
+	text        string
+	name        string
+	attributes  []attribute
+	selfClosing bool
+}
+
+/*
+tokenizer split html into text and tags, it is forgiving like browsers are,
+a '<' that does not start a tag is text and attribute values are unescaped
+*/
+type tokenizer struct {
+	input string
+	pos   int
+}
+
+func newTokenizer(input string) *tokenizer {
+	return &tokenizer{input: input}
+}
+
+func (t *tokenizer) next() (tok token, ok bool) {
+	if t.pos >= len(t.input) {
+		return token{}, false
+	}
+
+	rest := t.input[t.pos:]
+	if rest[0] == '<' {
+		if strings.HasPrefix(rest, "<!--") {
+			end := strings.Index(rest[4:], "-->")
+			if end < 0 {
+				t.pos = len(t.input)
+			} else {
+				t.pos += 4 + end + 3
+			}
+			return token{kind: ignoredToken}, true
+		}
+
+		if len(rest) > 1 && (rest[1] == '!' || rest[1] == '?') {
+			end := strings.IndexByte(rest, '>')
+			if end < 0 {
+				t.pos = len(t.input)
+			} else {
+				t.pos += end + 1
+			}
+			return token{kind: ignoredToken}, true
+		}
+
+		if tok, length, ok := parseTag(rest); ok {
+			t.pos += length
+			return tok, true
+		}
+	}
+
+	// text run up to the next '<' that is not the first character
+	end := strings.IndexByte(rest[1:], '<')
+	if end < 0 {
+		end = len(rest)
+	} else {
+		end++
+	}
+	t.pos += end
+	return token{kind: textToken, text: html.UnescapeString(rest[:end])}, true
+}
+
+//skipUntilEndTag drop everything up to and including the end tag of name, or up to the end of input
+func (t *tokenizer) skipUntilEndTag(name string) {
+	lowerInput := strings.ToLower(t.input[t.pos:])
+	for offset := 0; ; {
+		idx := strings.Index(lowerInput[offset:], "</"+name)
+		if idx < 0 {
+			t.pos = len(t.input)
+			return
+		}
+
+		start := offset + idx
+		if tok, length, ok := parseTag(t.input[t.pos+start:]); ok && tok.kind == endTagToken && tok.name == name {
+			t.pos += start + length
+			return
+		}
+		offset = start + 2
+	}
+}
+
+//parseTag parse the tag at the start of s, returning its length
+func parseTag(s string) (tok token, length int, ok bool) {
+	pos := 1
+	tok.kind = startTagToken
+	if pos < len(s) && s[pos] == '/' {
+		tok.kind = endTagToken
+		pos++
+	}
+
+	nameStart := pos
+	if pos >= len(s) || !isASCIILetter(s[pos]) {
+		return token{}, 0, false
+	}
+	for pos < len(s) && isTagNameChar(s[pos]) {
+		pos++
+	}
+	tok.name = strings.ToLower(s[nameStart:pos])
+
+	for {
+		for pos < len(s) && isSpace(s[pos]) {
+			pos++
+		}
+		if pos >= len(s) {
+			return token{}, 0, false
+		}
+
+		switch s[pos] {
+		case '>':
+			return tok, pos + 1, true
+		case '/':
+			pos++
+			if pos < len(s) && s[pos] == '>' {
+				tok.selfClosing = true
+				return tok, pos + 1, true
+			}
+			continue
+		}
+
+		attrStart := pos
+		for pos < len(s) && !isSpace(s[pos]) && s[pos] != '=' && s[pos] != '>' && s[pos] != '/' {
+			pos++
+		}
+		attr := attribute{name: strings.ToLower(s[attrStart:pos])}
+
+		for pos < len(s) && isSpace(s[pos]) {
+			pos++
+		}
+		if pos < len(s) && s[pos] == '=' {
+			pos++
+			for pos < len(s) && isSpace(s[pos]) {
+				pos++
+			}
+			if pos >= len(s) {
+				return token{}, 0, false
+			}
+
+			if quote := s[pos]; quote == '"' || quote == '\'' {
+				end := strings.IndexByte(s[pos+1:], quote)
+				if end < 0 {
+					return token{}, 0, false
+				}
+				attr.value = s[pos+1 : pos+1+end]
+				pos += end + 2
+			} else {
+				valueStart := pos
+				for pos < len(s) && !isSpace(s[pos]) && s[pos] != '>' {
+					pos++
+				}
+				attr.value = s[valueStart:pos]
+			}
+			attr.value = html.UnescapeString(attr.value)
+		}
+
+		if tok.kind == startTagToken {
+			tok.attributes = append(tok.attributes, attr)
+		}
+	}
+}
+
+func isASCIILetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isTagNameChar(c byte) bool {
+	return isASCIILetter(c) || (c >= '0' && c <= '9') || c == '-'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
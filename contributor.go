@@ -0,0 +1,57 @@
+package chronicle
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	//ErrUnknownContributor returned by repositories when an entity reference a contributor that does not exist
+	ErrUnknownContributor = errors.New("Unknown Contributor")
+)
+
+var (
+	//ContributorAuthorRole provide a uniform way to use author role instead of literal string
+	ContributorAuthorRole = "author"
+	//ContributorReporterRole provide a uniform way to use reporter role instead of literal string
+	ContributorReporterRole = "reporter"
+	//ContributorEditorRole provide a uniform way to use editor role instead of literal string
+	ContributorEditorRole = "editor"
+	//ContributorPhotographerRole provide a uniform way to use photographer role instead of literal string
+	ContributorPhotographerRole = "photographer"
+)
+
+//Contributor is domain entity
+type Contributor struct {
+	ID        int
+	Name      string
+	Slug      string
+	Bio       string
+	Photo     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+//Contributors short way to define array of contributor
+type Contributors []Contributor
+
+//Byline is a contributor credited on a story with role, bylines are ordered by position
+type Byline struct {
+	Contributor
+	Role     string
+	Position int
+}
+
+//Bylines short way to define array of byline
+type Bylines []Byline
+
+//ContributorRepository provide an interface to get contributor entities
+type ContributorRepository interface {
+	Find(id int) (Contributor, error)
+	FindBySlug(slug string) (Contributor, error)
+	IsSlugTaken(slug string, exceptID int) (bool, error)
+	All(option PagingOptions) (contributors Contributors, contributorsCount int, err error)
+	Insert(contributor Contributor) (createdContributor Contributor, err error)
+	Update(contributor Contributor) (updatedContributor Contributor, err error)
+	Delete(id int) error
+}
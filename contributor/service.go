@@ -0,0 +1,148 @@
+package contributor
+
+import (
+	"database/sql"
+
+	"github.com/AdhityaRamadhanus/chronicle"
+	"github.com/AdhityaRamadhanus/chronicle/function"
+	"github.com/pkg/errors"
+)
+
+var (
+	//ErrNoContributorFound sub-domain specific error
+	ErrNoContributorFound = errors.New("Cannot find Contributor")
+)
+
+//Service provide an interface to contributor domain service
+type Service interface {
+	CreateContributor(contributor chronicle.Contributor) (createdContributor chronicle.Contributor, err error)
+	UpdateContributor(contributor chronicle.Contributor) (updatedContributor chronicle.Contributor, err error)
+	GetContributors(option chronicle.PagingOptions) (chronicle.Contributors, int, error)
+	GetContributorByID(id int) (chronicle.Contributor, error)
+	GetContributorBySlug(slug string) (chronicle.Contributor, error)
+	GetContributorStories(slug string, option chronicle.PagingOptions) (chronicle.Stories, int, error)
+	DeleteContributorByID(id int) error
+}
+
+func NewService(contributorRepository chronicle.ContributorRepository, storyRepository chronicle.StoryRepository) Service {
+	return &service{
+		contributorRepository: contributorRepository,
+		storyRepository:       storyRepository,
+	}
+}
+
+type service struct {
+	contributorRepository chronicle.ContributorRepository
+	storyRepository       chronicle.StoryRepository
+}
+
+func (s *service) CreateContributor(contributor chronicle.Contributor) (createdContributor chronicle.Contributor, err error) {
+	defer func() {
+		if err != nil && err != ErrNoContributorFound {
+			err = errors.Wrap(err, function.GetFunctionName(s.CreateContributor))
+		}
+	}()
+
+	if err := s.resolveContributorSlug(&contributor); err != nil {
+		return chronicle.Contributor{}, err
+	}
+
+	return s.contributorRepository.Insert(contributor)
+}
+
+func (s *service) UpdateContributor(contributor chronicle.Contributor) (updatedContributor chronicle.Contributor, err error) {
+	defer func() {
+		if err != nil && err != ErrNoContributorFound {
+			err = errors.Wrap(err, function.GetFunctionName(s.UpdateContributor))
+		}
+	}()
+
+	if err := s.resolveContributorSlug(&contributor); err != nil {
+		return chronicle.Contributor{}, err
+	}
+
+	return s.contributorRepository.Update(contributor)
+}
+
+func (s *service) GetContributors(option chronicle.PagingOptions) (contributors chronicle.Contributors, contributorsCount int, err error) {
+	defer func() {
+		if err != nil && err != ErrNoContributorFound {
+			err = errors.Wrap(err, function.GetFunctionName(s.GetContributors))
+		}
+	}()
+
+	return s.contributorRepository.All(option)
+}
+
+func (s *service) GetContributorByID(id int) (contributor chronicle.Contributor, err error) {
+	defer func() {
+		if err != nil && err != ErrNoContributorFound {
+			err = errors.Wrap(err, function.GetFunctionName(s.GetContributorByID))
+		}
+	}()
+
+	contributor, err = s.contributorRepository.Find(id)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return contributor, ErrNoContributorFound
+		default:
+			return contributor, err
+		}
+	}
+
+	return contributor, nil
+}
+
+func (s *service) GetContributorBySlug(slug string) (contributor chronicle.Contributor, err error) {
+	defer func() {
+		if err != nil && err != ErrNoContributorFound {
+			err = errors.Wrap(err, function.GetFunctionName(s.GetContributorBySlug))
+		}
+	}()
+
+	contributor, err = s.contributorRepository.FindBySlug(slug)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return contributor, ErrNoContributorFound
+		default:
+			return contributor, err
+		}
+	}
+
+	return contributor, nil
+}
+
+func (s *service) GetContributorStories(slug string, option chronicle.PagingOptions) (stories chronicle.Stories, storiesCount int, err error) {
+	defer func() {
+		if err != nil && err != ErrNoContributorFound {
+			err = errors.Wrap(err, function.GetFunctionName(s.GetContributorStories))
+		}
+	}()
+
+	contributor, err := s.GetContributorBySlug(slug)
+	if err != nil {
+		return chronicle.Stories{}, 0, err
+	}
+
+	return s.storyRepository.FindByContributor(contributor.ID, option)
+}
+
+func (s *service) DeleteContributorByID(id int) (err error) {
+	defer func() {
+		if err != nil && err != ErrNoContributorFound {
+			err = errors.Wrap(err, function.GetFunctionName(s.DeleteContributorByID))
+		}
+	}()
+
+	return s.contributorRepository.Delete(id)
+}
+
+// resolveContributorSlug suffix the slug of contributor when another contributor use it
+func (s *service) resolveContributorSlug(contributor *chronicle.Contributor) (err error) {
+	contributor.Slug, err = chronicle.UniqueSlug(contributor.Slug, func(candidate string) (bool, error) {
+		return s.contributorRepository.IsSlugTaken(candidate, contributor.ID)
+	})
+	return err
+}
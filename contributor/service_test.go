@@ -0,0 +1,178 @@
+package contributor_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	"github.com/AdhityaRamadhanus/chronicle"
+	"github.com/AdhityaRamadhanus/chronicle/config"
+	"github.com/AdhityaRamadhanus/chronicle/contributor"
+	"github.com/AdhityaRamadhanus/chronicle/storage/postgre"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	contributorService    contributor.Service
+	contributorRepository *postgre.ContributorRepository
+	storyRepository       *postgre.StoryRepository
+
+	// specific test case var
+	contributorIds []int
+)
+
+// make test kind of idempotent
+func setupDatabase(db *sqlx.DB) {
+	_, err := db.Query("DELETE FROM story_contributors")
+	if err != nil {
+		log.Fatal("Failed to setup database ", errors.Wrap(err, "Failed in delete from story_contributors"))
+	}
+
+	_, err = db.Query("DELETE FROM contributors")
+	if err != nil {
+		log.Fatal("Failed to setup database ", errors.Wrap(err, "Failed in delete from contributors"))
+	}
+}
+
+func TestMain(m *testing.M) {
+	log.SetLevel(log.WarnLevel)
+	if err := config.Init("testing", []string{"../config/testing"}); err != nil {
+		log.Fatal(err)
+	}
+
+	pgConnString := postgre.GetConnString()
+
+	db, err := sqlx.Open("postgres", pgConnString)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	setupDatabase(db)
+
+	// Repositories
+	contributorRepository = postgre.NewContributorRepository(db, "contributors")
+	storyRepository = postgre.NewStoryRepository(db, "stories")
+	contributorService = contributor.NewService(contributorRepository, storyRepository)
+
+	code := m.Run()
+	os.Exit(code)
+}
+
+func TestCreateContributorIntegration(t *testing.T) {
+	testCases := []struct {
+		Contributor  chronicle.Contributor
+		ExpectedSlug string
+	}{
+		{
+			Contributor: chronicle.Contributor{
+				Name: "Adhitya Ramadhanus",
+				Slug: "adhitya-ramadhanus",
+				Bio:  "Backend Engineer",
+			},
+			ExpectedSlug: "adhitya-ramadhanus",
+		},
+		{
+			Contributor: chronicle.Contributor{
+				Name: "Adhitya Ramadhanus",
+				Slug: "adhitya-ramadhanus",
+			},
+			ExpectedSlug: "adhitya-ramadhanus-2",
+		},
+	}
+
+	for _, testCase := range testCases {
+		createdContributor, err := contributorService.CreateContributor(testCase.Contributor)
+		if err != nil {
+			t.Error("Failed to create contributor", err)
+		}
+
+		contributorIds = append(contributorIds, createdContributor.ID)
+		assert.Equal(t, testCase.ExpectedSlug, createdContributor.Slug)
+	}
+}
+
+func TestGetContributorBySlugIntegration(t *testing.T) {
+	testCases := []struct {
+		Slug              string
+		ExpectedError     bool
+		ExpectedErrorType error
+	}{
+		{
+			Slug:          "adhitya-ramadhanus-2",
+			ExpectedError: false,
+		},
+		{
+			Slug:              "random-slug",
+			ExpectedError:     true,
+			ExpectedErrorType: contributor.ErrNoContributorFound,
+		},
+	}
+
+	for _, test := range testCases {
+		_, err := contributorService.GetContributorBySlug(test.Slug)
+
+		if test.ExpectedError {
+			assert.EqualError(t, err, test.ExpectedErrorType.Error(), "Should return ErrNoContributorFound")
+		} else {
+			if err != nil {
+				t.Error("Failed to get contributor by slug", err)
+			}
+		}
+	}
+}
+
+func TestGetContributorStoriesIntegration(t *testing.T) {
+	if len(contributorIds) < 2 {
+		t.Skip("Contributors are not created")
+	}
+
+	story := chronicle.Story{
+		Title:   "Bylines Story",
+		Slug:    "bylines-story",
+		Content: "Story with more than one contributor",
+		Media:   []byte("{}"),
+		Status:  chronicle.StoryDraftStatus,
+		Bylines: chronicle.Bylines{
+			{Contributor: chronicle.Contributor{ID: contributorIds[1]}, Role: chronicle.ContributorReporterRole},
+			{Contributor: chronicle.Contributor{ID: contributorIds[0]}, Role: chronicle.ContributorAuthorRole},
+		},
+	}
+
+	createdStory, err := storyRepository.Insert(story)
+	if err != nil {
+		t.Fatal("Failed to create story", err)
+	}
+
+	if assert.Len(t, createdStory.Bylines, 2) {
+		assert.Equal(t, contributorIds[1], createdStory.Bylines[0].ID)
+		assert.Equal(t, contributorIds[0], createdStory.Bylines[1].ID)
+	}
+
+	stories, storiesCount, err := contributorService.GetContributorStories("adhitya-ramadhanus", chronicle.PagingOptions{
+		SortBy: "createdAt",
+		Order:  "desc",
+		Limit:  10,
+		Offset: 0,
+	})
+	if err != nil {
+		t.Error("Failed to get contributor stories", err)
+	}
+
+	assert.Equal(t, 1, storiesCount)
+	if assert.Len(t, stories, 1) {
+		assert.Equal(t, createdStory.ID, stories[0].ID)
+	}
+
+	_, err = storyRepository.Insert(chronicle.Story{
+		Title:   "Unknown Bylines Story",
+		Slug:    "unknown-bylines-story",
+		Media:   []byte("{}"),
+		Status:  chronicle.StoryDraftStatus,
+		Bylines: chronicle.Bylines{{Contributor: chronicle.Contributor{ID: -1}, Role: chronicle.ContributorAuthorRole}},
+	})
+	assert.Equal(t, chronicle.ErrUnknownContributor, errors.Cause(err))
+}
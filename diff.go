@@ -0,0 +1,172 @@
+package chronicle
+
+import "strings"
+
+var (
+	//DiffEqual mark words that exist in both texts
+	DiffEqual = "equal"
+	//DiffInsert mark words that only exist in the newer text
+	DiffInsert = "insert"
+	//DiffDelete mark words that only exist in the older text
+	DiffDelete = "delete"
+)
+
+//WordDiff is a run of consecutive words sharing the same diff operation
+type WordDiff struct {
+	Operation string
+	Text      string
+}
+
+//WordDiffs short way to define array of word diff
+type WordDiffs []WordDiff
+
+//DiffWords return word level differences between two texts using myers algorithm
+func DiffWords(oldText, newText string) WordDiffs {
+	oldWords := strings.Fields(oldText)
+	newWords := strings.Fields(newText)
+
+	operations := diffOperations(oldWords, newWords)
+
+	// merge consecutive words with the same operation, joined once per run
+	diffs := WordDiffs{}
+	words := []string{}
+	for idx, operation := range operations {
+		words = append(words, operation.Text)
+		if idx+1 < len(operations) && operations[idx+1].Operation == operation.Operation {
+			continue
+		}
+
+		diffs = append(diffs, WordDiff{Operation: operation.Operation, Text: strings.Join(words, " ")})
+		words = words[:0]
+	}
+
+	return diffs
+}
+
+/*
+diffOperations split both texts around the middle snake of their shortest edit script and recurse on both sides,
+myers linear space refinement, so memory stay proportional to the texts however different they are.
+Deletions are put before insertions between equal words
+*/
+func diffOperations(oldWords, newWords []string) WordDiffs {
+	operations := WordDiffs{}
+	diffRange(oldWords, newWords, &operations)
+
+	ordered := WordDiffs{}
+	inserts := WordDiffs{}
+	for _, operation := range operations {
+		switch operation.Operation {
+		case DiffInsert:
+			inserts = append(inserts, operation)
+		case DiffDelete:
+			ordered = append(ordered, operation)
+		default:
+			ordered = append(ordered, inserts...)
+			ordered = append(ordered, operation)
+			inserts = WordDiffs{}
+		}
+	}
+
+	return append(ordered, inserts...)
+}
+
+func diffRange(oldWords, newWords []string, operations *WordDiffs) {
+	// common prefix and suffix need no search
+	prefix := 0
+	for prefix < len(oldWords) && prefix < len(newWords) && oldWords[prefix] == newWords[prefix] {
+		prefix++
+	}
+	appendOperations(operations, DiffEqual, oldWords[:prefix])
+	oldWords, newWords = oldWords[prefix:], newWords[prefix:]
+
+	suffix := 0
+	for suffix < len(oldWords) && suffix < len(newWords) && oldWords[len(oldWords)-suffix-1] == newWords[len(newWords)-suffix-1] {
+		suffix++
+	}
+	commonSuffix := oldWords[len(oldWords)-suffix:]
+	oldWords, newWords = oldWords[:len(oldWords)-suffix], newWords[:len(newWords)-suffix]
+
+	switch {
+	case len(oldWords) == 0:
+		appendOperations(operations, DiffInsert, newWords)
+	case len(newWords) == 0:
+		appendOperations(operations, DiffDelete, oldWords)
+	default:
+		startX, startY, endX, endY := middleSnake(oldWords, newWords)
+		diffRange(oldWords[:startX], newWords[:startY], operations)
+		appendOperations(operations, DiffEqual, oldWords[startX:endX])
+		diffRange(oldWords[endX:], newWords[endY:], operations)
+	}
+
+	appendOperations(operations, DiffEqual, commonSuffix)
+}
+
+/*
+middleSnake search the shortest edit script from both ends at once until the paths overlap,
+the snake where they meet split the script in two halves. Only the furthest x of each diagonal is kept
+*/
+func middleSnake(oldWords, newWords []string) (startX, startY, endX, endY int) {
+	n, m := len(oldWords), len(newWords)
+	delta := n - m
+	odd := delta%2 != 0
+	maxD := (n + m + 1) / 2
+	offset := maxD + 1
+
+	// forward[k] is the furthest x on diagonal k = x - y from the start,
+	// backward[k] the furthest x on diagonal k counted from the end
+	forward := make([]int, 2*maxD+3)
+	backward := make([]int, 2*maxD+3)
+	for d := 0; d <= maxD; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && forward[offset+k-1] < forward[offset+k+1]) {
+				x = forward[offset+k+1]
+			} else {
+				x = forward[offset+k-1] + 1
+			}
+
+			y := x - k
+			fromX, fromY := x, y
+			for x < n && y < m && oldWords[x] == newWords[y] {
+				x++
+				y++
+			}
+			forward[offset+k] = x
+
+			// diagonal k is delta - k counted from the end
+			if odd && delta-k >= -(d-1) && delta-k <= d-1 && x+backward[offset+delta-k] >= n {
+				return fromX, fromY, x, y
+			}
+		}
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && backward[offset+k-1] < backward[offset+k+1]) {
+				x = backward[offset+k+1]
+			} else {
+				x = backward[offset+k-1] + 1
+			}
+
+			y := x - k
+			fromX, fromY := x, y
+			for x < n && y < m && oldWords[n-x-1] == newWords[m-y-1] {
+				x++
+				y++
+			}
+			backward[offset+k] = x
+
+			if !odd && delta-k >= -d && delta-k <= d && x+forward[offset+delta-k] >= n {
+				return n - x, m - y, n - fromX, m - fromY
+			}
+		}
+	}
+
+	// texts always meet by maxD, kept for the compiler
+	return 0, 0, 0, 0
+}
+
+func appendOperations(operations *WordDiffs, operation string, words []string) {
+	for _, word := range words {
+		*operations = append(*operations, WordDiff{Operation: operation, Text: word})
+	}
+}
@@ -0,0 +1,146 @@
+package chronicle
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffWords(t *testing.T) {
+	testCases := []struct {
+		OldText       string
+		NewText       string
+		ExpectedDiffs WordDiffs
+	}{
+		{
+			"",
+			"",
+			WordDiffs{},
+		},
+		{
+			"Timnas Gagal melaju",
+			"Timnas Gagal melaju",
+			WordDiffs{
+				{Operation: DiffEqual, Text: "Timnas Gagal melaju"},
+			},
+		},
+		{
+			"",
+			"Timnas Gagal melaju",
+			WordDiffs{
+				{Operation: DiffInsert, Text: "Timnas Gagal melaju"},
+			},
+		},
+		{
+			"Timnas Gagal melaju",
+			"",
+			WordDiffs{
+				{Operation: DiffDelete, Text: "Timnas Gagal melaju"},
+			},
+		},
+		{
+			"Timnas Gagal melaju ke piala dunia",
+			"Timnas  U-19 gagal melaju ke piala dunia",
+			WordDiffs{
+				{Operation: DiffEqual, Text: "Timnas"},
+				{Operation: DiffDelete, Text: "Gagal"},
+				{Operation: DiffInsert, Text: "U-19 gagal"},
+				{Operation: DiffEqual, Text: "melaju ke piala dunia"},
+			},
+		},
+		{
+			"Dikalahkan Jepang, Timnas U-19 Gagal ke Piala Dunia",
+			"Dikalahkan Jepang, Timnas U-19 Lolos ke Piala Dunia 2019",
+			WordDiffs{
+				{Operation: DiffEqual, Text: "Dikalahkan Jepang, Timnas U-19"},
+				{Operation: DiffDelete, Text: "Gagal"},
+				{Operation: DiffInsert, Text: "Lolos"},
+				{Operation: DiffEqual, Text: "ke Piala Dunia"},
+				{Operation: DiffInsert, Text: "2019"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		diffs := DiffWords(testCase.OldText, testCase.NewText)
+		assert.Equal(t, testCase.ExpectedDiffs, diffs, "Incorrect Diff")
+	}
+}
+
+// lcsLength by dynamic programming, the number of equal words of a shortest diff
+func lcsLength(oldWords, newWords []string) int {
+	lengths := make([][]int, len(oldWords)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(newWords)+1)
+	}
+	for i := len(oldWords) - 1; i >= 0; i-- {
+		for j := len(newWords) - 1; j >= 0; j-- {
+			if oldWords[i] == newWords[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else if lengths[i+1][j] > lengths[i][j+1] {
+				lengths[i][j] = lengths[i+1][j]
+			} else {
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+	return lengths[0][0]
+}
+
+func TestDiffWordsIsShortest(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	randomText := func() string {
+		words := []string{}
+		for i := random.Intn(30); i > 0; i-- {
+			words = append(words, string(rune('a'+random.Intn(4))))
+		}
+		return strings.Join(words, " ")
+	}
+
+	for i := 0; i < 500; i++ {
+		oldText, newText := randomText(), randomText()
+		diffs := DiffWords(oldText, newText)
+
+		// both texts are rebuilt from the diff and only the longest common words are kept equal
+		oldWords, newWords := []string{}, []string{}
+		equalWords := 0
+		for _, diff := range diffs {
+			words := strings.Fields(diff.Text)
+			if diff.Operation != DiffInsert {
+				oldWords = append(oldWords, words...)
+			}
+			if diff.Operation != DiffDelete {
+				newWords = append(newWords, words...)
+			}
+			if diff.Operation == DiffEqual {
+				equalWords += len(words)
+			}
+		}
+		assert.Equal(t, strings.Fields(oldText), oldWords, fmt.Sprintf("%q %q", oldText, newText))
+		assert.Equal(t, strings.Fields(newText), newWords, fmt.Sprintf("%q %q", oldText, newText))
+		assert.Equal(t, lcsLength(strings.Fields(oldText), strings.Fields(newText)), equalWords, fmt.Sprintf("%q %q", oldText, newText))
+	}
+}
+
+func TestDiffWordsMemory(t *testing.T) {
+	// two long texts with nothing in common is the worst case
+	oldWords, newWords := []string{}, []string{}
+	for i := 0; i < 5000; i++ {
+		oldWords = append(oldWords, fmt.Sprintf("old%d", i))
+		newWords = append(newWords, fmt.Sprintf("new%d", i))
+	}
+	oldText, newText := strings.Join(oldWords, " "), strings.Join(newWords, " ")
+
+	before := runtime.MemStats{}
+	runtime.ReadMemStats(&before)
+	diffs := DiffWords(oldText, newText)
+	after := runtime.MemStats{}
+	runtime.ReadMemStats(&after)
+
+	assert.Len(t, diffs, 2)
+	assert.True(t, after.TotalAlloc-before.TotalAlloc < 32<<20, "diff allocated %d bytes", after.TotalAlloc-before.TotalAlloc)
+}
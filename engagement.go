@@ -0,0 +1,21 @@
+package chronicle
+
+var (
+	//StoryViewsEngagement provide a uniform way to use views engagement instead of literal string
+	StoryViewsEngagement = "views"
+	//StoryLikesEngagement provide a uniform way to use likes engagement instead of literal string
+	StoryLikesEngagement = "likes"
+	//StorySharesEngagement provide a uniform way to use shares engagement instead of literal string
+	StorySharesEngagement = "shares"
+)
+
+//StoryEngagement is counts of engagement of a story
+type StoryEngagement struct {
+	StoryID int
+	Likes   int
+	Shares  int
+	Views   int
+}
+
+//StoryEngagements short way to define array of story engagement
+type StoryEngagements []StoryEngagement
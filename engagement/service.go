@@ -0,0 +1,353 @@
+package engagement
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AdhityaRamadhanus/chronicle"
+	"github.com/AdhityaRamadhanus/chronicle/function"
+	"github.com/pkg/errors"
+)
+
+var (
+	//ErrUnknownEngagement sub-domain specific error
+	ErrUnknownEngagement = errors.New("Unknown Engagement")
+)
+
+const (
+	dirtyStoriesKey  = "chronicle:engagement:dirty-stories"
+	storyKeyPrefix   = "chronicle:engagement:stories"
+	flushKeyPrefix   = "chronicle:engagement:flush"
+	activeFlushesKey = "chronicle:engagement:active-flushes"
+)
+
+//Service provide an interface to engagement domain service
+type Service interface {
+	EngageStory(storyID int, kind string) error
+	ApplyPendingEngagements(stories chronicle.Stories) (chronicle.Stories, error)
+	FlushEngagements() (chronicle.StoryEngagements, error)
+	RecoverFlushes(abandonedAfter time.Duration) (chronicle.StoryEngagements, error)
+}
+
+/*
+NewService create engagement service that buffer increments in cache,
+FlushEngagements must be called periodically to write them to the story repository
+*/
+func NewService(storyRepository chronicle.StoryRepository, cacheService chronicle.CacheService) Service {
+	return &service{
+		storyRepository: storyRepository,
+		cacheService:    cacheService,
+	}
+}
+
+type service struct {
+	storyRepository chronicle.StoryRepository
+	cacheService    chronicle.CacheService
+}
+
+func (s *service) EngageStory(storyID int, kind string) (err error) {
+	defer func() {
+		if err != nil && err != ErrUnknownEngagement {
+			err = errors.Wrap(err, function.GetFunctionName(s.EngageStory))
+		}
+	}()
+
+	switch kind {
+	case chronicle.StoryViewsEngagement, chronicle.StoryLikesEngagement, chronicle.StorySharesEngagement:
+	default:
+		return ErrUnknownEngagement
+	}
+
+	if _, err := s.cacheService.HIncrBy(storyKey(storyID), kind, 1); err != nil {
+		return err
+	}
+
+	return s.cacheService.SAdd(dirtyStoriesKey, strconv.Itoa(storyID))
+}
+
+/*
+ApplyPendingEngagements add engagements not flushed yet, counters taken by a running or abandoned flush
+are pending too until they are written, every counter of stories is read in one batch
+*/
+func (s *service) ApplyPendingEngagements(stories chronicle.Stories) (engagedStories chronicle.Stories, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Wrap(err, function.GetFunctionName(s.ApplyPendingEngagements))
+		}
+	}()
+
+	flushKeys, err := s.cacheService.SMembers(activeFlushesKey)
+	if err != nil {
+		return stories, err
+	}
+
+	keys := []string{}
+	for _, story := range stories {
+		keys = append(keys, storyKey(story.ID))
+		for _, flushKey := range flushKeys {
+			keys = append(keys, flushStoryKey(flushKey, story.ID))
+		}
+	}
+
+	counts, err := s.cacheService.HGetAllBatch(keys...)
+	if err != nil {
+		return stories, err
+	}
+
+	engagedStories = chronicle.Stories{}
+	keysPerStory := len(flushKeys) + 1
+	for idx, story := range stories {
+		for _, storyCounts := range counts[idx*keysPerStory : (idx+1)*keysPerStory] {
+			pending := engagementFromCounts(story.ID, storyCounts)
+			story.Likes += pending.Likes
+			story.Shares += pending.Shares
+			story.Views += pending.Views
+		}
+		engagedStories = append(engagedStories, story)
+	}
+
+	return engagedStories, nil
+}
+
+/*
+FlushEngagements write pending engagements to the story repository in one batch,
+dirty stories and their counters are renamed to keys owned by this flush first,
+so concurrent flushers never see the same increments and engagements made meanwhile start fresh keys.
+The flush is registered as active before anything is renamed, so RecoverFlushes can finish it when this process dies
+*/
+func (s *service) FlushEngagements() (flushedEngagements chronicle.StoryEngagements, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Wrap(err, function.GetFunctionName(s.FlushEngagements))
+		}
+	}()
+
+	flushKey, err := newFlushKey(time.Now())
+	if err != nil {
+		return chronicle.StoryEngagements{}, err
+	}
+
+	if err := s.cacheService.SAdd(activeFlushesKey, flushKey); err != nil {
+		return chronicle.StoryEngagements{}, err
+	}
+
+	renamed, err := s.cacheService.Rename(dirtyStoriesKey, flushDirtyKey(flushKey))
+	if err != nil {
+		return chronicle.StoryEngagements{}, err
+	}
+
+	if !renamed {
+		return chronicle.StoryEngagements{}, s.cacheService.SRem(activeFlushesKey, flushKey)
+	}
+
+	storyIds, err := s.cacheService.SMembers(flushDirtyKey(flushKey))
+	if err != nil {
+		return chronicle.StoryEngagements{}, err
+	}
+
+	return s.flushStories(flushKey, storyIds, storyKey)
+}
+
+/*
+RecoverFlushes finish flushes registered longer than abandonedAfter ago, left behind by a process that died mid flush.
+Each is claimed by renaming its dirty stories to a new flush, so only one instance recover it.
+A process dying after writing but before deleting its counters get them written twice, rather than lost
+*/
+func (s *service) RecoverFlushes(abandonedAfter time.Duration) (recoveredEngagements chronicle.StoryEngagements, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Wrap(err, function.GetFunctionName(s.RecoverFlushes))
+		}
+	}()
+
+	flushKeys, err := s.cacheService.SMembers(activeFlushesKey)
+	if err != nil {
+		return chronicle.StoryEngagements{}, err
+	}
+
+	recoveredEngagements = chronicle.StoryEngagements{}
+	for _, abandonedKey := range flushKeys {
+		startedAt, ok := flushStartedAt(abandonedKey)
+		if ok && time.Since(startedAt) < abandonedAfter {
+			continue
+		}
+
+		flushKey, err := newFlushKey(time.Now())
+		if err != nil {
+			return recoveredEngagements, err
+		}
+
+		if err := s.cacheService.SAdd(activeFlushesKey, flushKey); err != nil {
+			return recoveredEngagements, err
+		}
+
+		renamed, err := s.cacheService.Rename(flushDirtyKey(abandonedKey), flushDirtyKey(flushKey))
+		if err != nil {
+			return recoveredEngagements, err
+		}
+
+		// already recovered elsewhere, or the abandoned flush never took the dirty stories
+		if !renamed {
+			if err := s.cacheService.SRem(activeFlushesKey, flushKey, abandonedKey); err != nil {
+				return recoveredEngagements, err
+			}
+			continue
+		}
+
+		storyIds, err := s.cacheService.SMembers(flushDirtyKey(flushKey))
+		if err != nil {
+			return recoveredEngagements, err
+		}
+
+		// counters the abandoned flush did not rename yet are still live, the next flush pick them up
+		if len(storyIds) > 0 {
+			if err := s.cacheService.SAdd(dirtyStoriesKey, storyIds...); err != nil {
+				return recoveredEngagements, err
+			}
+		}
+
+		flushedEngagements, err := s.flushStories(flushKey, storyIds, func(storyID int) string {
+			return flushStoryKey(abandonedKey, storyID)
+		})
+		if err != nil {
+			return recoveredEngagements, err
+		}
+
+		if err := s.cacheService.SRem(activeFlushesKey, abandonedKey); err != nil {
+			return recoveredEngagements, err
+		}
+
+		recoveredEngagements = append(recoveredEngagements, flushedEngagements...)
+	}
+
+	return recoveredEngagements, nil
+}
+
+/*
+flushStories move counters of stories from sourceKey to keys of flushKey, then write them in one batch.
+Counters are put back to the live ones when the write fail, other failures leave the flush to RecoverFlushes
+*/
+func (s *service) flushStories(flushKey string, storyIds []string, sourceKey func(storyID int) string) (flushedEngagements chronicle.StoryEngagements, err error) {
+	ids := []int{}
+	flushedKeys := []string{flushDirtyKey(flushKey)}
+	for _, storyId := range storyIds {
+		id, err := strconv.Atoi(storyId)
+		if err != nil {
+			continue
+		}
+
+		// counter may already be taken by another flush that saw the story dirty again
+		renamed, err := s.cacheService.Rename(sourceKey(id), flushStoryKey(flushKey, id))
+		if err != nil {
+			return chronicle.StoryEngagements{}, err
+		}
+
+		if renamed {
+			ids = append(ids, id)
+			flushedKeys = append(flushedKeys, flushStoryKey(flushKey, id))
+		}
+	}
+
+	counts, err := s.cacheService.HGetAllBatch(flushedKeys[1:]...)
+	if err != nil {
+		return chronicle.StoryEngagements{}, err
+	}
+
+	flushedEngagements = chronicle.StoryEngagements{}
+	for idx, id := range ids {
+		pending := engagementFromCounts(id, counts[idx])
+		if pending.Likes != 0 || pending.Shares != 0 || pending.Views != 0 {
+			flushedEngagements = append(flushedEngagements, pending)
+		}
+	}
+
+	if err := s.storyRepository.AddEngagements(flushedEngagements); err != nil {
+		s.restoreEngagements(flushedEngagements)
+		s.finishFlush(flushKey, flushedKeys)
+		return chronicle.StoryEngagements{}, err
+	}
+
+	if err := s.finishFlush(flushKey, flushedKeys); err != nil {
+		return flushedEngagements, err
+	}
+
+	return flushedEngagements, nil
+}
+
+// finishFlush delete counters of a flush before unregistering it, so they are never pending and written at once
+func (s *service) finishFlush(flushKey string, flushedKeys []string) error {
+	if err := s.cacheService.Del(flushedKeys...); err != nil {
+		return err
+	}
+
+	return s.cacheService.SRem(activeFlushesKey, flushKey)
+}
+
+// put engagements taken by a failed flush back to the live counters for the next flush
+func (s *service) restoreEngagements(engagements chronicle.StoryEngagements) {
+	for _, engagement := range engagements {
+		counts := map[string]int{
+			chronicle.StoryLikesEngagement:  engagement.Likes,
+			chronicle.StorySharesEngagement: engagement.Shares,
+			chronicle.StoryViewsEngagement:  engagement.Views,
+		}
+
+		for kind, count := range counts {
+			if count != 0 {
+				s.cacheService.HIncrBy(storyKey(engagement.StoryID), kind, int64(count))
+			}
+		}
+
+		s.cacheService.SAdd(dirtyStoriesKey, strconv.Itoa(engagement.StoryID))
+	}
+}
+
+func engagementFromCounts(storyID int, counts map[string]string) chronicle.StoryEngagement {
+	pending := chronicle.StoryEngagement{StoryID: storyID}
+	pending.Likes, _ = strconv.Atoi(counts[chronicle.StoryLikesEngagement])
+	pending.Shares, _ = strconv.Atoi(counts[chronicle.StorySharesEngagement])
+	pending.Views, _ = strconv.Atoi(counts[chronicle.StoryViewsEngagement])
+
+	return pending
+}
+
+// every flush get its own key prefix holding when it started, so instances flushing at the same time never share keys
+func newFlushKey(now time.Time) (string, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s:%d:%s", flushKeyPrefix, now.Unix(), hex.EncodeToString(id)), nil
+}
+
+// flushStartedAt read the start of a flush from its key, false for keys not made by newFlushKey
+func flushStartedAt(flushKey string) (time.Time, bool) {
+	parts := strings.Split(strings.TrimPrefix(flushKey, flushKeyPrefix+":"), ":")
+	if len(parts) != 2 {
+		return time.Time{}, false
+	}
+
+	startedAt, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return time.Unix(startedAt, 0), true
+}
+
+func flushDirtyKey(flushKey string) string {
+	return flushKey + ":dirty-stories"
+}
+
+func flushStoryKey(flushKey string, storyID int) string {
+	return fmt.Sprintf("%s:stories:%d", flushKey, storyID)
+}
+
+func storyKey(storyID int) string {
+	return fmt.Sprintf("%s:%d", storyKeyPrefix, storyID)
+}
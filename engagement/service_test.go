@@ -0,0 +1,327 @@
+package engagement_test
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AdhityaRamadhanus/chronicle"
+	"github.com/AdhityaRamadhanus/chronicle/engagement"
+	"github.com/stretchr/testify/assert"
+)
+
+// in memory cache, only hashes and sets are used by engagement service
+type fakeCacheService struct {
+	sync.Mutex
+	hashes map[string]map[string]string
+	sets   map[string]map[string]bool
+}
+
+func newFakeCacheService() *fakeCacheService {
+	return &fakeCacheService{
+		hashes: map[string]map[string]string{},
+		sets:   map[string]map[string]bool{},
+	}
+}
+
+func (c *fakeCacheService) Set(key string, value []byte) error { return nil }
+func (c *fakeCacheService) SetEx(key string, value []byte, expiration time.Duration) error {
+	return nil
+}
+func (c *fakeCacheService) Get(key string) ([]byte, error) { return nil, errors.New("cache miss") }
+func (c *fakeCacheService) Del(keys ...string) error {
+	c.Lock()
+	defer c.Unlock()
+	for _, key := range keys {
+		delete(c.hashes, key)
+		delete(c.sets, key)
+	}
+	return nil
+}
+
+func (c *fakeCacheService) Keys(pattern string) ([]string, error) { return []string{}, nil }
+
+func (c *fakeCacheService) HIncrBy(key string, field string, increment int64) (int64, error) {
+	c.Lock()
+	defer c.Unlock()
+	if c.hashes[key] == nil {
+		c.hashes[key] = map[string]string{}
+	}
+	value, _ := strconv.ParseInt(c.hashes[key][field], 10, 64)
+	value += increment
+	c.hashes[key][field] = strconv.FormatInt(value, 10)
+	return value, nil
+}
+
+func (c *fakeCacheService) HGetAll(key string) (map[string]string, error) {
+	c.Lock()
+	defer c.Unlock()
+	result := map[string]string{}
+	for field, value := range c.hashes[key] {
+		result[field] = value
+	}
+	return result, nil
+}
+
+func (c *fakeCacheService) HGetAllBatch(keys ...string) ([]map[string]string, error) {
+	results := []map[string]string{}
+	for _, key := range keys {
+		result, _ := c.HGetAll(key)
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (c *fakeCacheService) SAdd(key string, members ...string) error {
+	c.Lock()
+	defer c.Unlock()
+	if c.sets[key] == nil {
+		c.sets[key] = map[string]bool{}
+	}
+	for _, member := range members {
+		c.sets[key][member] = true
+	}
+	return nil
+}
+
+func (c *fakeCacheService) SRem(key string, members ...string) error {
+	c.Lock()
+	defer c.Unlock()
+	for _, member := range members {
+		delete(c.sets[key], member)
+	}
+	return nil
+}
+
+func (c *fakeCacheService) SMembers(key string) ([]string, error) {
+	c.Lock()
+	defer c.Unlock()
+	members := []string{}
+	for member := range c.sets[key] {
+		members = append(members, member)
+	}
+	return members, nil
+}
+
+func (c *fakeCacheService) Rename(key string, newKey string) (bool, error) {
+	c.Lock()
+	defer c.Unlock()
+	if hash, ok := c.hashes[key]; ok {
+		c.hashes[newKey] = hash
+		delete(c.hashes, key)
+		return true, nil
+	}
+	if set, ok := c.sets[key]; ok {
+		c.sets[newKey] = set
+		delete(c.sets, key)
+		return true, nil
+	}
+	return false, nil
+}
+
+// only AddEngagements is used by engagement service
+type fakeStoryRepository struct {
+	chronicle.StoryRepository
+	sync.Mutex
+	stories map[int]chronicle.Story
+	err     error
+	delay   time.Duration
+	// engagements below zero, written to correct counts flushed twice
+	corrections int
+}
+
+func (r *fakeStoryRepository) AddEngagements(engagements chronicle.StoryEngagements) error {
+	time.Sleep(r.delay)
+	r.Lock()
+	defer r.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	for _, engagement := range engagements {
+		if engagement.Likes < 0 || engagement.Shares < 0 || engagement.Views < 0 {
+			r.corrections++
+		}
+		story := r.stories[engagement.StoryID]
+		story.Likes += engagement.Likes
+		story.Shares += engagement.Shares
+		story.Views += engagement.Views
+		r.stories[engagement.StoryID] = story
+	}
+	return nil
+}
+
+func TestEngageStory(t *testing.T) {
+	engagementService := engagement.NewService(&fakeStoryRepository{}, newFakeCacheService())
+
+	testCases := []struct {
+		Kind          string
+		ExpectedError error
+	}{
+		{Kind: chronicle.StoryViewsEngagement},
+		{Kind: chronicle.StoryLikesEngagement},
+		{Kind: chronicle.StorySharesEngagement},
+		{Kind: "claps", ExpectedError: engagement.ErrUnknownEngagement},
+	}
+
+	for _, testCase := range testCases {
+		err := engagementService.EngageStory(1, testCase.Kind)
+		assert.Equal(t, err, testCase.ExpectedError)
+	}
+}
+
+func TestFlushEngagements(t *testing.T) {
+	storyRepository := &fakeStoryRepository{
+		stories: map[int]chronicle.Story{
+			1: chronicle.Story{ID: 1, Views: 10},
+			2: chronicle.Story{ID: 2},
+		},
+	}
+	engagementService := engagement.NewService(storyRepository, newFakeCacheService())
+
+	for i := 0; i < 3; i++ {
+		engagementService.EngageStory(1, chronicle.StoryViewsEngagement)
+	}
+	engagementService.EngageStory(1, chronicle.StoryLikesEngagement)
+	engagementService.EngageStory(2, chronicle.StorySharesEngagement)
+
+	// pending increments are visible before flush
+	stories, err := engagementService.ApplyPendingEngagements(chronicle.Stories{storyRepository.stories[1]})
+	assert.NoError(t, err)
+	assert.Equal(t, stories[0].Views, 13)
+	assert.Equal(t, stories[0].Likes, 1)
+
+	// failed flush keep increments pending
+	storyRepository.err = errors.New("database is down")
+	_, err = engagementService.FlushEngagements()
+	assert.Error(t, err)
+	assert.Equal(t, storyRepository.stories[1].Views, 10)
+
+	storyRepository.err = nil
+	flushedEngagements, err := engagementService.FlushEngagements()
+	assert.NoError(t, err)
+	assert.Equal(t, len(flushedEngagements), 2)
+	assert.Equal(t, storyRepository.stories[1].Views, 13)
+	assert.Equal(t, storyRepository.stories[1].Likes, 1)
+	assert.Equal(t, storyRepository.stories[2].Shares, 1)
+
+	// flushed increments are not counted twice
+	stories, err = engagementService.ApplyPendingEngagements(chronicle.Stories{storyRepository.stories[1]})
+	assert.NoError(t, err)
+	assert.Equal(t, stories[0].Views, 13)
+
+	flushedEngagements, err = engagementService.FlushEngagements()
+	assert.NoError(t, err)
+	assert.Equal(t, len(flushedEngagements), 0)
+}
+
+func TestFlushEngagementsConcurrently(t *testing.T) {
+	storyRepository := &fakeStoryRepository{
+		stories: map[int]chronicle.Story{
+			1: chronicle.Story{ID: 1},
+			2: chronicle.Story{ID: 2},
+		},
+		// slow writes let the other flusher run while a batch is in flight
+		delay: time.Millisecond,
+	}
+	cacheService := newFakeCacheService()
+	engagementServices := []engagement.Service{
+		engagement.NewService(storyRepository, cacheService),
+		engagement.NewService(storyRepository, cacheService),
+	}
+
+	engaged := sync.WaitGroup{}
+	flushed := sync.WaitGroup{}
+	done := make(chan struct{})
+	for _, engagementService := range engagementServices {
+		engaged.Add(1)
+		go func(engagementService engagement.Service) {
+			defer engaged.Done()
+			for i := 0; i < 100; i++ {
+				engagementService.EngageStory(1, chronicle.StoryViewsEngagement)
+				engagementService.EngageStory(2, chronicle.StoryLikesEngagement)
+				time.Sleep(100 * time.Microsecond)
+			}
+		}(engagementService)
+
+		flushed.Add(1)
+		go func(engagementService engagement.Service) {
+			defer flushed.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				_, err := engagementService.FlushEngagements()
+				assert.NoError(t, err)
+			}
+		}(engagementService)
+	}
+	engaged.Wait()
+	close(done)
+	flushed.Wait()
+
+	_, err := engagementServices[0].FlushEngagements()
+	assert.NoError(t, err)
+
+	// every increment is written exactly once whichever flusher picked it up
+	assert.Equal(t, storyRepository.corrections, 0)
+	assert.Equal(t, storyRepository.stories[1].Views, 200)
+	assert.Equal(t, storyRepository.stories[2].Likes, 200)
+
+	stories, err := engagementServices[1].ApplyPendingEngagements(chronicle.Stories{storyRepository.stories[1]})
+	assert.NoError(t, err)
+	assert.Equal(t, stories[0].Views, 200)
+}
+
+func TestRecoverFlushes(t *testing.T) {
+	storyRepository := &fakeStoryRepository{
+		stories: map[int]chronicle.Story{
+			1: chronicle.Story{ID: 1},
+			2: chronicle.Story{ID: 2},
+		},
+	}
+	cacheService := newFakeCacheService()
+	engagementService := engagement.NewService(storyRepository, cacheService)
+
+	// a process died an hour ago after taking the counter of story 1 but before taking the one of story 2,
+	// another flush started just now and is still running
+	abandonedKey := fmt.Sprintf("chronicle:engagement:flush:%d:dead", time.Now().Add(-time.Hour).Unix())
+	runningKey := fmt.Sprintf("chronicle:engagement:flush:%d:running", time.Now().Unix())
+	cacheService.SAdd("chronicle:engagement:active-flushes", abandonedKey, runningKey)
+	cacheService.SAdd(abandonedKey+":dirty-stories", "1", "2")
+	cacheService.HIncrBy(abandonedKey+":stories:1", chronicle.StoryViewsEngagement, 5)
+	cacheService.HIncrBy("chronicle:engagement:stories:2", chronicle.StoryLikesEngagement, 2)
+	cacheService.SAdd(runningKey+":dirty-stories", "1")
+	cacheService.HIncrBy(runningKey+":stories:1", chronicle.StoryViewsEngagement, 3)
+
+	// counters taken by flushes are still pending
+	stories, err := engagementService.ApplyPendingEngagements(chronicle.Stories{storyRepository.stories[1], storyRepository.stories[2]})
+	assert.NoError(t, err)
+	assert.Equal(t, stories[0].Views, 8)
+	assert.Equal(t, stories[1].Likes, 2)
+
+	recoveredEngagements, err := engagementService.RecoverFlushes(10 * time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, recoveredEngagements, chronicle.StoryEngagements{{StoryID: 1, Views: 5}})
+	assert.Equal(t, storyRepository.stories[1].Views, 5)
+
+	// the live counter the abandoned flush left behind is flushed next
+	_, err = engagementService.FlushEngagements()
+	assert.NoError(t, err)
+	assert.Equal(t, storyRepository.stories[2].Likes, 2)
+
+	// the running flush is left alone and recovering again find nothing
+	recoveredEngagements, err = engagementService.RecoverFlushes(10 * time.Minute)
+	assert.NoError(t, err)
+	assert.Empty(t, recoveredEngagements)
+	activeFlushes, _ := cacheService.SMembers("chronicle:engagement:active-flushes")
+	assert.Equal(t, activeFlushes, []string{runningKey})
+
+	stories, err = engagementService.ApplyPendingEngagements(chronicle.Stories{storyRepository.stories[1]})
+	assert.NoError(t, err)
+	assert.Equal(t, stories[0].Views, 8)
+}
@@ -0,0 +1,114 @@
+package feed
+
+import (
+	"encoding/xml"
+	"time"
+
+	chronicle "github.com/AdhityaRamadhanus/chronicle"
+)
+
+const atomNamespace = "http://www.w3.org/2005/Atom"
+
+//AtomContentType is the content type Atom documents are served with
+const AtomContentType = "application/atom+xml; charset=utf-8"
+
+//Atom is Atom document
+type Atom struct {
+	XMLName  xml.Name    `xml:"feed"`
+	Xmlns    string      `xml:"xmlns,attr"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Links    []AtomLink  `xml:"link"`
+	Updated  string      `xml:"updated"`
+	Entries  []AtomEntry `xml:"entry"`
+}
+
+//AtomEntry is a story in Atom document
+type AtomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Links      []AtomLink     `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Authors    []AtomPerson   `xml:"author"`
+	Categories []AtomCategory `xml:"category"`
+	Summary    string         `xml:"summary"`
+	Content    *AtomContent   `xml:"content"`
+}
+
+//AtomLink is link element of Atom, also used by RSS to point at itself
+type AtomLink struct {
+	Rel    string `xml:"rel,attr,omitempty"`
+	Href   string `xml:"href,attr"`
+	Type   string `xml:"type,attr,omitempty"`
+	Length int64  `xml:"length,attr,omitempty"`
+}
+
+//AtomPerson is author of an entry
+type AtomPerson struct {
+	Name string `xml:"name"`
+}
+
+//AtomCategory is a topic of an entry
+type AtomCategory struct {
+	Term  string `xml:"term,attr"`
+	Label string `xml:"label,attr,omitempty"`
+}
+
+//AtomContent is content of an entry, html content is escaped
+type AtomContent struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+//NewAtom build Atom document of stories, every media of a story is attached as an enclosure link
+func NewAtom(channel Channel, stories chronicle.Stories) Atom {
+	entries := []AtomEntry{}
+	for _, story := range stories {
+		entry := AtomEntry{
+			ID:        storyID(story),
+			Title:     story.Title,
+			Links:     []AtomLink{{Rel: "alternate", Href: channel.storyLink(story), Type: "text/html"}},
+			Published: published(story).UTC().Format(time.RFC3339),
+			Updated:   story.UpdatedAt.UTC().Format(time.RFC3339),
+			Summary:   story.Excerpt,
+		}
+
+		for _, name := range authors(story) {
+			entry.Authors = append(entry.Authors, AtomPerson{Name: name})
+		}
+
+		for _, topic := range story.Topics {
+			entry.Categories = append(entry.Categories, AtomCategory{Term: topic.Slug, Label: topic.Name})
+		}
+
+		for _, asset := range story.MediaAssets {
+			entry.Links = append(entry.Links, AtomLink{
+				Rel:    "enclosure",
+				Href:   channel.mediaLink(asset),
+				Type:   asset.MimeType,
+				Length: asset.Size,
+			})
+		}
+
+		if story.ContentHTML != "" {
+			entry.Content = &AtomContent{Type: "html", Value: story.ContentHTML}
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return Atom{
+		Xmlns:    atomNamespace,
+		ID:       channel.FeedURL,
+		Title:    channel.Title,
+		Subtitle: channel.Description,
+		Links: []AtomLink{
+			{Rel: "self", Href: channel.FeedURL, Type: "application/atom+xml"},
+			{Rel: "alternate", Href: channel.Link, Type: "text/html"},
+		},
+		Updated: updated(stories).UTC().Format(time.RFC3339),
+		Entries: entries,
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Package feed build RSS 2.0 and Atom documents out of stories,
+the documents are marshalled with encoding/xml
+*/
+package feed
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	chronicle "github.com/AdhityaRamadhanus/chronicle"
+)
+
+//Channel describe a feed, StoryURL and MediaURL are templates with {slug} and {id} replaced by those of a story and a media asset
+type Channel struct {
+	Title       string
+	Description string
+	// page the feed is about and the url of feed itself
+	Link    string
+	FeedURL string
+
+	StoryURL string
+	MediaURL string
+}
+
+func (c Channel) storyLink(story chronicle.Story) string {
+	return strings.Replace(c.StoryURL, "{slug}", story.Slug, -1)
+}
+
+func (c Channel) mediaLink(asset chronicle.MediaAsset) string {
+	return strings.Replace(c.MediaURL, "{id}", strconv.Itoa(asset.ID), -1)
+}
+
+// storyID identify a story for feed readers, unlike its link it survive a change of slug
+func storyID(story chronicle.Story) string {
+	return fmt.Sprintf("urn:chronicle:story:%d", story.ID)
+}
+
+// authors of story, its bylines in order or the author it was written by
+func authors(story chronicle.Story) []string {
+	if len(story.Bylines) == 0 {
+		return []string{story.Author}
+	}
+
+	names := []string{}
+	for _, byline := range story.Bylines {
+		names = append(names, byline.Name)
+	}
+	return names
+}
+
+// published is the time story was first published, stories published before it was recorded fall back to their creation
+func published(story chronicle.Story) time.Time {
+	if story.PublishedAt != nil {
+		return *story.PublishedAt
+	}
+	return story.CreatedAt
+}
+
+// updated is the time the newest story was updated, or now for a feed without stories
+func updated(stories chronicle.Stories) time.Time {
+	latest := time.Time{}
+	for _, story := range stories {
+		if story.UpdatedAt.After(latest) {
+			latest = story.UpdatedAt
+		}
+	}
+
+	if latest.IsZero() {
+		return time.Now()
+	}
+	return latest
+}
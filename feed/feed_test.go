@@ -0,0 +1,95 @@
+package feed_test
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/AdhityaRamadhanus/chronicle"
+	"github.com/AdhityaRamadhanus/chronicle/feed"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	publishedAt = time.Date(2018, 10, 25, 10, 0, 0, 0, time.UTC)
+
+	testChannel = feed.Channel{
+		Title:       "Chronicle",
+		Description: "Latest stories",
+		Link:        "https://example.com",
+		FeedURL:     "https://api.example.com/feeds/stories.rss",
+		StoryURL:    "https://example.com/stories/{slug}",
+		MediaURL:    "https://api.example.com/api/media/{id}/original",
+	}
+
+	testStories = chronicle.Stories{
+		{
+			ID:          7,
+			Title:       "Timnas & Jepang",
+			Slug:        "timnas-jepang",
+			Excerpt:     "Timnas gagal",
+			ContentHTML: "<p>Asa <b>Timnas</b> pupus</p>",
+			Author:      "Adhitya Ramadhanus",
+			Bylines: chronicle.Bylines{
+				{Contributor: chronicle.Contributor{Name: "Jane Doe"}},
+				{Contributor: chronicle.Contributor{Name: "John Doe"}},
+			},
+			Topics: chronicle.Topics{{Name: "Sepak Bola", Slug: "sepak-bola"}},
+			MediaAssets: chronicle.MediaAssets{
+				{ID: 3, MimeType: "image/jpeg", Size: 1024},
+				{ID: 4, MimeType: "audio/mpeg", Size: 2048},
+			},
+			PublishedAt: &publishedAt,
+			CreatedAt:   time.Date(2018, 10, 24, 10, 0, 0, 0, time.UTC),
+			UpdatedAt:   time.Date(2018, 10, 26, 10, 0, 0, 0, time.UTC),
+		},
+	}
+)
+
+func TestNewRSS(t *testing.T) {
+	rss := feed.NewRSS(testChannel, testStories)
+	document, err := xml.Marshal(rss)
+	assert.NoError(t, err)
+
+	expectedParts := []string{
+		`<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:dc="http://purl.org/dc/elements/1.1/">`,
+		`<atom:link rel="self" href="https://api.example.com/feeds/stories.rss" type="application/rss+xml"></atom:link>`,
+		`<lastBuildDate>Fri, 26 Oct 2018 10:00:00 +0000</lastBuildDate>`,
+		`<title>Timnas &amp; Jepang</title>`,
+		`<link>https://example.com/stories/timnas-jepang</link>`,
+		`<guid isPermaLink="false">urn:chronicle:story:7</guid>`,
+		`<content:encoded>&lt;p&gt;Asa &lt;b&gt;Timnas&lt;/b&gt; pupus&lt;/p&gt;</content:encoded>`,
+		`<dc:creator>Jane Doe</dc:creator><dc:creator>John Doe</dc:creator>`,
+		`<category>Sepak Bola</category>`,
+		`<pubDate>Thu, 25 Oct 2018 10:00:00 +0000</pubDate>`,
+		`<enclosure url="https://api.example.com/api/media/3/original" length="1024" type="image/jpeg"></enclosure>`,
+	}
+	for _, expectedPart := range expectedParts {
+		assert.Contains(t, string(document), expectedPart)
+	}
+
+	// a single enclosure per item
+	assert.NotContains(t, string(document), "media/4")
+}
+
+func TestNewAtom(t *testing.T) {
+	atom := feed.NewAtom(testChannel, testStories)
+	document, err := xml.Marshal(atom)
+	assert.NoError(t, err)
+
+	expectedParts := []string{
+		`<feed xmlns="http://www.w3.org/2005/Atom">`,
+		`<updated>2018-10-26T10:00:00Z</updated>`,
+		`<id>urn:chronicle:story:7</id>`,
+		`<link rel="alternate" href="https://example.com/stories/timnas-jepang" type="text/html"></link>`,
+		`<link rel="enclosure" href="https://api.example.com/api/media/3/original" type="image/jpeg" length="1024"></link>`,
+		`<link rel="enclosure" href="https://api.example.com/api/media/4/original" type="audio/mpeg" length="2048"></link>`,
+		`<published>2018-10-25T10:00:00Z</published>`,
+		`<author><name>Jane Doe</name></author><author><name>John Doe</name></author>`,
+		`<category term="sepak-bola" label="Sepak Bola"></category>`,
+		`<content type="html">&lt;p&gt;Asa &lt;b&gt;Timnas&lt;/b&gt; pupus&lt;/p&gt;</content>`,
+	}
+	for _, expectedPart := range expectedParts {
+		assert.Contains(t, string(document), expectedPart)
+	}
+}
@@ -0,0 +1,103 @@
+package feed
+
+import (
+	"encoding/xml"
+	"time"
+
+	chronicle "github.com/AdhityaRamadhanus/chronicle"
+)
+
+//RSSContentType is the content type RSS documents are served with
+const RSSContentType = "application/rss+xml; charset=utf-8"
+
+//RSS is RSS 2.0 document, with the content, dublin core and atom extensions
+type RSS struct {
+	XMLName          xml.Name   `xml:"rss"`
+	Version          string     `xml:"version,attr"`
+	AtomNamespace    string     `xml:"xmlns:atom,attr"`
+	ContentNamespace string     `xml:"xmlns:content,attr"`
+	DCNamespace      string     `xml:"xmlns:dc,attr"`
+	Channel          RSSChannel `xml:"channel"`
+}
+
+//RSSChannel is channel of RSS document
+type RSSChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	SelfLink      AtomLink  `xml:"atom:link"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []RSSItem `xml:"item"`
+}
+
+//RSSItem is a story in RSS document
+type RSSItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	GUID        RSSGUID       `xml:"guid"`
+	Description string        `xml:"description"`
+	Content     string        `xml:"content:encoded,omitempty"`
+	Creators    []string      `xml:"dc:creator"`
+	Categories  []string      `xml:"category"`
+	PubDate     string        `xml:"pubDate"`
+	Enclosure   *RSSEnclosure `xml:"enclosure"`
+}
+
+//RSSGUID identify an item, it is not a link unless IsPermaLink
+type RSSGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+//RSSEnclosure is a media file attached to an item
+type RSSEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int64  `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+//NewRSS build RSS document of stories, RSS allow a single enclosure so only the first media of a story is attached
+func NewRSS(channel Channel, stories chronicle.Stories) RSS {
+	items := []RSSItem{}
+	for _, story := range stories {
+		item := RSSItem{
+			Title:       story.Title,
+			Link:        channel.storyLink(story),
+			GUID:        RSSGUID{Value: storyID(story)},
+			Description: story.Excerpt,
+			Content:     story.ContentHTML,
+			Creators:    authors(story),
+			PubDate:     published(story).UTC().Format(time.RFC1123Z),
+		}
+
+		for _, topic := range story.Topics {
+			item.Categories = append(item.Categories, topic.Name)
+		}
+
+		if len(story.MediaAssets) > 0 {
+			asset := story.MediaAssets[0]
+			item.Enclosure = &RSSEnclosure{
+				URL:    channel.mediaLink(asset),
+				Length: asset.Size,
+				Type:   asset.MimeType,
+			}
+		}
+
+		items = append(items, item)
+	}
+
+	return RSS{
+		Version:          "2.0",
+		AtomNamespace:    atomNamespace,
+		ContentNamespace: "http://purl.org/rss/1.0/modules/content/",
+		DCNamespace:      "http://purl.org/dc/elements/1.1/",
+		Channel: RSSChannel{
+			Title:         channel.Title,
+			Link:          channel.Link,
+			Description:   channel.Description,
+			SelfLink:      AtomLink{Rel: "self", Href: channel.FeedURL, Type: "application/rss+xml"},
+			LastBuildDate: updated(stories).UTC().Format(time.RFC1123Z),
+			Items:         items,
+		},
+	}
+}
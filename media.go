@@ -0,0 +1,124 @@
+package chronicle
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+var (
+	//ErrUnknownMedia returned by repositories when an entity reference a media asset that does not exist
+	ErrUnknownMedia = errors.New("Unknown Media")
+	//ErrNoBlobFound returned by blob stores when there is no blob stored under a key
+	ErrNoBlobFound = errors.New("Cannot find Blob")
+)
+
+//MediaTypes are mime types accepted by the media library, mapped to the extension of stored blob
+var MediaTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"video/mp4":       ".mp4",
+	"audio/mpeg":      ".mp3",
+	"application/pdf": ".pdf",
+}
+
+var (
+	//RenditionCropMode fill the rendition box exactly, cutting the image around its focal point
+	RenditionCropMode = "crop"
+	//RenditionFitMode shrink the image to fit inside the rendition box, keeping all of it
+	RenditionFitMode = "fit"
+	//OriginalRendition is the name used to get the uploaded file itself
+	OriginalRendition = "original"
+)
+
+//RenditionSpec describe a resized copy generated for every uploaded image
+type RenditionSpec struct {
+	Name   string
+	Width  int
+	Height int
+	Mode   string
+}
+
+//RenditionSpecs short way to define array of rendition spec
+type RenditionSpecs []RenditionSpec
+
+//MediaRendition is a resized copy of an image stored next to the original
+type MediaRendition struct {
+	Name     string
+	Key      string
+	MimeType string
+	Width    int
+	Height   int
+}
+
+//MediaRenditions short way to define array of media rendition
+type MediaRenditions []MediaRendition
+
+//Value store renditions as a json array
+func (r MediaRenditions) Value() (driver.Value, error) {
+	if r == nil {
+		return "[]", nil
+	}
+
+	value, err := json.Marshal(r)
+	return string(value), err
+}
+
+//Scan read renditions from a json array
+func (r *MediaRenditions) Scan(src interface{}) error {
+	switch value := src.(type) {
+	case []byte:
+		return json.Unmarshal(value, r)
+	case string:
+		return json.Unmarshal([]byte(value), r)
+	case nil:
+		*r = MediaRenditions{}
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into MediaRenditions", src)
+	}
+}
+
+//MediaAsset is domain entity, an uploaded file and its metadata
+type MediaAsset struct {
+	ID       int
+	Key      string
+	Filename string
+	MimeType string
+	Size     int64
+	// dimensions are only known for images
+	Width  int
+	Height int
+	// focal point keep the subject of an image inside cropped renditions, 0..1 from top left
+	FocalX     float64
+	FocalY     float64
+	Renditions MediaRenditions
+	Caption    string
+	Credit     string
+	AltText    string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+//MediaAssets short way to define array of media asset
+type MediaAssets []MediaAsset
+
+//MediaAssetRepository provide an interface to get media asset entities
+type MediaAssetRepository interface {
+	Find(id int) (MediaAsset, error)
+	All(option PagingOptions) (assets MediaAssets, assetsCount int, err error)
+	Insert(asset MediaAsset) (createdAsset MediaAsset, err error)
+	Delete(id int) error
+}
+
+//BlobStore provide an interface to store the files behind media assets
+type BlobStore interface {
+	Put(key string, content io.Reader) error
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
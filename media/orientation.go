@@ -0,0 +1,166 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/draw"
+)
+
+// exif orientations run from 1 to 8, 1 is upright and 5 to 8 swap width and height
+const uprightOrientation = 1
+
+// jpeg markers and exif layout needed to find the orientation tag
+const (
+	jpegStartOfScan      = 0xda
+	jpegEndOfImage       = 0xd9
+	jpegApplicationExif  = 0xe1
+	exifIdentifierLength = 6
+	tiffHeaderLength     = 8
+	tiffIFDEntryLength   = 12
+	orientationTag       = 0x0112
+	exifShortType        = 3
+)
+
+/*
+jpegOrientation read the exif orientation of a jpeg, markers are walked until the image data start.
+Content without a valid orientation is upright
+*/
+func jpegOrientation(content []byte) int {
+	if len(content) < 4 || content[0] != 0xff || content[1] != 0xd8 {
+		return uprightOrientation
+	}
+
+	offset := 2
+	for offset+4 <= len(content) {
+		if content[offset] != 0xff {
+			return uprightOrientation
+		}
+
+		marker := content[offset+1]
+		switch {
+		case marker == 0xff:
+			// fill byte before a marker
+			offset++
+			continue
+		case marker == 0x01 || (marker >= 0xd0 && marker <= 0xd7):
+			// markers without a length
+			offset += 2
+			continue
+		case marker == jpegStartOfScan || marker == jpegEndOfImage:
+			return uprightOrientation
+		}
+
+		length := int(binary.BigEndian.Uint16(content[offset+2:]))
+		if length < 2 || offset+2+length > len(content) {
+			return uprightOrientation
+		}
+
+		segment := content[offset+4 : offset+2+length]
+		if marker == jpegApplicationExif && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return tiffOrientation(segment[exifIdentifierLength:])
+		}
+
+		offset += 2 + length
+	}
+
+	return uprightOrientation
+}
+
+// tiffOrientation find the orientation tag in the first IFD of an exif tiff structure
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < tiffHeaderLength {
+		return uprightOrientation
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return uprightOrientation
+	}
+
+	ifdOffset := int(order.Uint32(tiff[4:]))
+	if ifdOffset < tiffHeaderLength || ifdOffset+2 > len(tiff) {
+		return uprightOrientation
+	}
+
+	entries := int(order.Uint16(tiff[ifdOffset:]))
+	for i := 0; i < entries; i++ {
+		entry := ifdOffset + 2 + i*tiffIFDEntryLength
+		if entry+tiffIFDEntryLength > len(tiff) {
+			return uprightOrientation
+		}
+
+		if order.Uint16(tiff[entry:]) != orientationTag || order.Uint16(tiff[entry+2:]) != exifShortType {
+			continue
+		}
+
+		orientation := int(order.Uint16(tiff[entry+8:]))
+		if orientation < 1 || orientation > 8 {
+			return uprightOrientation
+		}
+		return orientation
+	}
+
+	return uprightOrientation
+}
+
+// swapsDimensions tell whether an orientation turn the image a quarter
+func swapsDimensions(orientation int) bool {
+	return orientation >= 5 && orientation <= 8
+}
+
+// orient turn src upright according to its exif orientation, so crops and focal points apply to what viewers see
+func orient(src image.Image, orientation int) image.Image {
+	if orientation <= uprightOrientation || orientation > 8 {
+		return src
+	}
+
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	source := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(source, source.Bounds(), src, bounds.Min, draw.Src)
+
+	dstWidth, dstHeight := width, height
+	if swapsDimensions(orientation) {
+		dstWidth, dstHeight = height, width
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				// mirrored horizontally
+				dx, dy = width-1-x, y
+			case 3:
+				// rotated 180
+				dx, dy = width-1-x, height-1-y
+			case 4:
+				// mirrored vertically
+				dx, dy = x, height-1-y
+			case 5:
+				// mirrored along the top left to bottom right diagonal
+				dx, dy = y, x
+			case 6:
+				// needs 90 clockwise
+				dx, dy = height-1-y, x
+			case 7:
+				// mirrored along the top right to bottom left diagonal
+				dx, dy = height-1-y, width-1-x
+			case 8:
+				// needs 90 counter clockwise
+				dx, dy = y, width-1-x
+			}
+
+			copy(dst.Pix[dst.PixOffset(dx, dy):dst.PixOffset(dx, dy)+4], source.Pix[source.PixOffset(x, y):source.PixOffset(x, y)+4])
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,151 @@
+package media
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"math"
+
+	"github.com/AdhityaRamadhanus/chronicle"
+)
+
+// images larger than this are stored without renditions instead of exhausting memory while decoding,
+// a decoded image and its working copies take about 4 bytes per pixel each
+const maxRenditionPixels = 24000000
+
+// uploads beyond this many wait for their renditions, so only a few decoded images are held in memory at once
+const maxConcurrentRenditions = 2
+
+var renditionSlots = make(chan struct{}, maxConcurrentRenditions)
+
+//DefaultRenditions are generated when no rendition is configured
+var DefaultRenditions = chronicle.RenditionSpecs{
+	{Name: "thumbnail", Width: 150, Height: 150, Mode: chronicle.RenditionCropMode},
+	{Name: "card", Width: 600, Height: 400, Mode: chronicle.RenditionCropMode},
+	{Name: "hero", Width: 1600, Height: 900, Mode: chronicle.RenditionFitMode},
+}
+
+/*
+RenderRendition resize src to the box of spec, crop mode cut the image around
+focal point (0..1 from top left) to fill the box, fit mode keep the whole image inside it.
+Images are never enlarged, a box larger than the image only shape the crop
+*/
+func RenderRendition(src image.Image, spec chronicle.RenditionSpec, focalX float64, focalY float64) image.Image {
+	bounds := src.Bounds()
+	sourceRect := bounds
+	width, height := bounds.Dx(), bounds.Dy()
+
+	// crop without a complete box fall back to fit
+	switch {
+	case spec.Mode == chronicle.RenditionCropMode && spec.Width > 0 && spec.Height > 0:
+		sourceRect = cropRect(bounds, spec, focalX, focalY)
+		scale := math.Min(1, float64(spec.Width)/float64(sourceRect.Dx()))
+		width = roundDimension(float64(sourceRect.Dx()) * scale)
+		height = roundDimension(float64(sourceRect.Dy()) * scale)
+	default:
+		scale := 1.0
+		if spec.Width > 0 {
+			scale = math.Min(scale, float64(spec.Width)/float64(bounds.Dx()))
+		}
+		if spec.Height > 0 {
+			scale = math.Min(scale, float64(spec.Height)/float64(bounds.Dy()))
+		}
+		width = roundDimension(float64(bounds.Dx()) * scale)
+		height = roundDimension(float64(bounds.Dy()) * scale)
+	}
+
+	return resample(src, sourceRect, width, height)
+}
+
+// cropRect is the largest rect with aspect ratio of spec, centered on focal point as far as bounds allow
+func cropRect(bounds image.Rectangle, spec chronicle.RenditionSpec, focalX float64, focalY float64) image.Rectangle {
+	aspect := float64(spec.Width) / float64(spec.Height)
+	cropWidth, cropHeight := bounds.Dx(), bounds.Dy()
+	if float64(cropWidth)/float64(cropHeight) > aspect {
+		cropWidth = roundDimension(float64(cropHeight) * aspect)
+	} else {
+		cropHeight = roundDimension(float64(cropWidth) / aspect)
+	}
+
+	x := clamp(int(math.Round(focalX*float64(bounds.Dx())))-cropWidth/2, 0, bounds.Dx()-cropWidth)
+	y := clamp(int(math.Round(focalY*float64(bounds.Dy())))-cropHeight/2, 0, bounds.Dy()-cropHeight)
+
+	min := bounds.Min.Add(image.Pt(x, y))
+	return image.Rectangle{Min: min, Max: min.Add(image.Pt(cropWidth, cropHeight))}
+}
+
+// resample shrink rect of src to width x height, every pixel is the average of the source pixels it cover
+func resample(src image.Image, rect image.Rectangle, width int, height int) *image.RGBA {
+	source := image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	draw.Draw(source, source.Bounds(), src, rect.Min, draw.Src)
+
+	if width == rect.Dx() && height == rect.Dy() {
+		return source
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0 := y * rect.Dy() / height
+		y1 := (y + 1) * rect.Dy() / height
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+
+		for x := 0; x < width; x++ {
+			x0 := x * rect.Dx() / width
+			x1 := (x + 1) * rect.Dx() / width
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+
+			var r, g, b, a, count int
+			for sy := y0; sy < y1; sy++ {
+				offset := source.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					r += int(source.Pix[offset])
+					g += int(source.Pix[offset+1])
+					b += int(source.Pix[offset+2])
+					a += int(source.Pix[offset+3])
+					offset += 4
+					count++
+				}
+			}
+
+			offset := dst.PixOffset(x, y)
+			dst.Pix[offset] = uint8(r / count)
+			dst.Pix[offset+1] = uint8(g / count)
+			dst.Pix[offset+2] = uint8(b / count)
+			dst.Pix[offset+3] = uint8(a / count)
+		}
+	}
+
+	return dst
+}
+
+// encodeRendition keep jpeg as jpeg, everything else is encoded losslessly as png
+func encodeRendition(img image.Image, mimeType string) (content []byte, renditionMimeType string, err error) {
+	buffer := bytes.Buffer{}
+	if mimeType == "image/jpeg" {
+		err = jpeg.Encode(&buffer, img, &jpeg.Options{Quality: 85})
+		return buffer.Bytes(), "image/jpeg", err
+	}
+
+	err = png.Encode(&buffer, img)
+	return buffer.Bytes(), "image/png", err
+}
+
+func roundDimension(value float64) int {
+	return int(math.Max(1, math.Round(value)))
+}
+
+func clamp(value int, min int, max int) int {
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
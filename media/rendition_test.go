@@ -0,0 +1,92 @@
+package media_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/AdhityaRamadhanus/chronicle"
+	"github.com/AdhityaRamadhanus/chronicle/media"
+	"github.com/stretchr/testify/assert"
+)
+
+// half white on the left and half black on the right
+func splitImage(width, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if x < width/2 {
+				img.Set(x, y, color.White)
+			} else {
+				img.Set(x, y, color.Black)
+			}
+		}
+	}
+	return img
+}
+
+func TestRenderRendition(t *testing.T) {
+	testCases := []struct {
+		Spec           chronicle.RenditionSpec
+		Width          int
+		Height         int
+		ExpectedWidth  int
+		ExpectedHeight int
+	}{
+		{
+			Spec:           chronicle.RenditionSpec{Width: 100, Height: 100, Mode: chronicle.RenditionCropMode},
+			Width:          400,
+			Height:         200,
+			ExpectedWidth:  100,
+			ExpectedHeight: 100,
+		},
+		{
+			Spec:           chronicle.RenditionSpec{Width: 100, Height: 100, Mode: chronicle.RenditionFitMode},
+			Width:          400,
+			Height:         200,
+			ExpectedWidth:  100,
+			ExpectedHeight: 50,
+		},
+		{
+			Spec:           chronicle.RenditionSpec{Width: 300, Height: 0, Mode: chronicle.RenditionFitMode},
+			Width:          600,
+			Height:         900,
+			ExpectedWidth:  300,
+			ExpectedHeight: 450,
+		},
+		{
+			// smaller image only take the aspect ratio of the box
+			Spec:           chronicle.RenditionSpec{Width: 600, Height: 400, Mode: chronicle.RenditionCropMode},
+			Width:          90,
+			Height:         90,
+			ExpectedWidth:  90,
+			ExpectedHeight: 60,
+		},
+		{
+			Spec:           chronicle.RenditionSpec{Width: 1600, Height: 900, Mode: chronicle.RenditionFitMode},
+			Width:          90,
+			Height:         90,
+			ExpectedWidth:  90,
+			ExpectedHeight: 90,
+		},
+	}
+
+	for _, testCase := range testCases {
+		rendition := media.RenderRendition(splitImage(testCase.Width, testCase.Height), testCase.Spec, 0.5, 0.5)
+		assert.Equal(t, testCase.ExpectedWidth, rendition.Bounds().Dx())
+		assert.Equal(t, testCase.ExpectedHeight, rendition.Bounds().Dy())
+	}
+}
+
+func TestRenderRenditionFocalPoint(t *testing.T) {
+	spec := chronicle.RenditionSpec{Width: 10, Height: 10, Mode: chronicle.RenditionCropMode}
+
+	// the square crop follow focal point to the white or black half
+	left := media.RenderRendition(splitImage(200, 100), spec, 0, 0.5)
+	r, g, b, _ := left.At(5, 5).RGBA()
+	assert.Equal(t, [3]uint32{0xffff, 0xffff, 0xffff}, [3]uint32{r, g, b})
+
+	right := media.RenderRendition(splitImage(200, 100), spec, 1, 0.5)
+	r, g, b, _ = right.At(5, 5).RGBA()
+	assert.Equal(t, [3]uint32{0, 0, 0}, [3]uint32{r, g, b})
+}
@@ -0,0 +1,269 @@
+package media
+
+import (
+	"bytes"
+	"database/sql"
+	"image"
+	// register decoders used to read image dimensions
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/AdhityaRamadhanus/chronicle"
+	"github.com/AdhityaRamadhanus/chronicle/function"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	//ErrNoMediaFound sub-domain specific error
+	ErrNoMediaFound = errors.New("Cannot find Media")
+	//ErrUnsupportedMediaType sub-domain specific error
+	ErrUnsupportedMediaType = errors.New("Unsupported Media Type")
+	//ErrNoRenditionFound sub-domain specific error
+	ErrNoRenditionFound = errors.New("Cannot find Media Rendition")
+)
+
+//Service provide an interface to media domain service
+type Service interface {
+	UploadMedia(asset chronicle.MediaAsset, content []byte) (createdAsset chronicle.MediaAsset, err error)
+	GetMediaAssets(option chronicle.PagingOptions) (chronicle.MediaAssets, int, error)
+	GetMediaAssetByID(id int) (chronicle.MediaAsset, error)
+	OpenMediaRendition(id int, rendition string) (chronicle.MediaRendition, io.ReadCloser, error)
+	DeleteMediaAssetByID(id int) error
+}
+
+/*
+NewService create media service storing files in blob store,
+every uploaded image is resized to renditions, DefaultRenditions are used when renditions is empty
+*/
+func NewService(mediaAssetRepository chronicle.MediaAssetRepository, blobStore chronicle.BlobStore, renditions chronicle.RenditionSpecs) Service {
+	if len(renditions) == 0 {
+		renditions = DefaultRenditions
+	}
+
+	return &service{
+		mediaAssetRepository: mediaAssetRepository,
+		blobStore:            blobStore,
+		renditions:           renditions,
+	}
+}
+
+type service struct {
+	mediaAssetRepository chronicle.MediaAssetRepository
+	blobStore            chronicle.BlobStore
+	renditions           chronicle.RenditionSpecs
+}
+
+func (s *service) UploadMedia(asset chronicle.MediaAsset, content []byte) (createdAsset chronicle.MediaAsset, err error) {
+	defer func() {
+		if err != nil && err != ErrUnsupportedMediaType {
+			err = errors.Wrap(err, function.GetFunctionName(s.UploadMedia))
+		}
+	}()
+
+	// the declared type of an upload is not trusted, sniff it from content
+	asset.MimeType = http.DetectContentType(content)
+	extension, ok := chronicle.MediaTypes[asset.MimeType]
+	if !ok {
+		return chronicle.MediaAsset{}, ErrUnsupportedMediaType
+	}
+
+	asset.Size = int64(len(content))
+	if config, _, err := image.DecodeConfig(bytes.NewReader(content)); err == nil {
+		asset.Width = config.Width
+		asset.Height = config.Height
+		// dimensions are the ones viewers see once exif orientation is applied
+		if asset.MimeType == "image/jpeg" && swapsDimensions(jpegOrientation(content)) {
+			asset.Width, asset.Height = asset.Height, asset.Width
+		}
+	}
+
+	asset.Key = path.Join(time.Now().Format("2006/01/02"), uuid.New().String()+extension)
+	if err := s.blobStore.Put(asset.Key, bytes.NewReader(content)); err != nil {
+		return chronicle.MediaAsset{}, err
+	}
+
+	asset.Renditions, err = s.storeRenditions(asset, content)
+	if err == nil {
+		createdAsset, err = s.mediaAssetRepository.Insert(asset)
+	}
+
+	if err != nil {
+		// do not leave blobs nothing refer to
+		s.deleteBlobs(asset)
+		return chronicle.MediaAsset{}, err
+	}
+
+	return createdAsset, nil
+}
+
+func (s *service) GetMediaAssets(option chronicle.PagingOptions) (assets chronicle.MediaAssets, assetsCount int, err error) {
+	defer func() {
+		if err != nil && err != ErrNoMediaFound {
+			err = errors.Wrap(err, function.GetFunctionName(s.GetMediaAssets))
+		}
+	}()
+
+	return s.mediaAssetRepository.All(option)
+}
+
+func (s *service) GetMediaAssetByID(id int) (asset chronicle.MediaAsset, err error) {
+	defer func() {
+		if err != nil && err != ErrNoMediaFound {
+			err = errors.Wrap(err, function.GetFunctionName(s.GetMediaAssetByID))
+		}
+	}()
+
+	asset, err = s.mediaAssetRepository.Find(id)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return asset, ErrNoMediaFound
+		default:
+			return asset, err
+		}
+	}
+
+	return asset, nil
+}
+
+func (s *service) OpenMediaRendition(id int, rendition string) (mediaRendition chronicle.MediaRendition, blob io.ReadCloser, err error) {
+	defer func() {
+		if err != nil && err != ErrNoMediaFound && err != ErrNoRenditionFound {
+			err = errors.Wrap(err, function.GetFunctionName(s.OpenMediaRendition))
+		}
+	}()
+
+	asset, err := s.GetMediaAssetByID(id)
+	if err != nil {
+		return chronicle.MediaRendition{}, nil, err
+	}
+
+	mediaRendition, ok := findRendition(asset, rendition)
+	if !ok {
+		return chronicle.MediaRendition{}, nil, ErrNoRenditionFound
+	}
+
+	blob, err = s.blobStore.Get(mediaRendition.Key)
+	if err != nil {
+		switch err {
+		case chronicle.ErrNoBlobFound:
+			return chronicle.MediaRendition{}, nil, ErrNoRenditionFound
+		default:
+			return chronicle.MediaRendition{}, nil, err
+		}
+	}
+
+	return mediaRendition, blob, nil
+}
+
+func (s *service) DeleteMediaAssetByID(id int) (err error) {
+	defer func() {
+		if err != nil && err != ErrNoMediaFound {
+			err = errors.Wrap(err, function.GetFunctionName(s.DeleteMediaAssetByID))
+		}
+	}()
+
+	asset, err := s.GetMediaAssetByID(id)
+	if err != nil {
+		return err
+	}
+
+	// stories referencing the asset lose the reference along with the row
+	if err := s.mediaAssetRepository.Delete(id); err != nil {
+		return err
+	}
+
+	s.deleteBlobs(asset)
+	return nil
+}
+
+// storeRenditions resize an image to every rendition spec, other media have no rendition.
+// At most maxConcurrentRenditions images are decoded at once, the others wait for a slot
+func (s *service) storeRenditions(asset chronicle.MediaAsset, content []byte) (renditions chronicle.MediaRenditions, err error) {
+	renditions = chronicle.MediaRenditions{}
+	if asset.Width == 0 || asset.Height == 0 || asset.Width*asset.Height > maxRenditionPixels {
+		return renditions, nil
+	}
+
+	renditionSlots <- struct{}{}
+	defer func() { <-renditionSlots }()
+
+	img, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		log.WithField("key", asset.Key).WithError(err).Warn("Failed to decode image, renditions are skipped")
+		return renditions, nil
+	}
+
+	// crops and focal points apply to the upright image, encoded renditions carry no exif
+	if asset.MimeType == "image/jpeg" {
+		img = orient(img, jpegOrientation(content))
+	}
+
+	keyPrefix := strings.TrimSuffix(asset.Key, path.Ext(asset.Key))
+	for _, spec := range s.renditions {
+		resized := RenderRendition(img, spec, asset.FocalX, asset.FocalY)
+		renditionContent, mimeType, err := encodeRendition(resized, asset.MimeType)
+		if err != nil {
+			return renditions, err
+		}
+
+		rendition := chronicle.MediaRendition{
+			Name:     spec.Name,
+			Key:      keyPrefix + "-" + spec.Name + chronicle.MediaTypes[mimeType],
+			MimeType: mimeType,
+			Width:    resized.Bounds().Dx(),
+			Height:   resized.Bounds().Dy(),
+		}
+
+		if err := s.blobStore.Put(rendition.Key, bytes.NewReader(renditionContent)); err != nil {
+			return renditions, err
+		}
+
+		renditions = append(renditions, rendition)
+	}
+
+	return renditions, nil
+}
+
+// deleteBlobs remove the original and renditions of asset, failures only leave unreachable blobs behind
+func (s *service) deleteBlobs(asset chronicle.MediaAsset) {
+	keys := []string{asset.Key}
+	for _, rendition := range asset.Renditions {
+		keys = append(keys, rendition.Key)
+	}
+
+	for _, key := range keys {
+		if err := s.blobStore.Delete(key); err != nil {
+			log.WithField("key", key).WithError(err).Error("Failed to delete blob")
+		}
+	}
+}
+
+// findRendition get rendition by name, the uploaded file is the original rendition
+func findRendition(asset chronicle.MediaAsset, name string) (chronicle.MediaRendition, bool) {
+	if name == chronicle.OriginalRendition {
+		return chronicle.MediaRendition{
+			Name:     chronicle.OriginalRendition,
+			Key:      asset.Key,
+			MimeType: asset.MimeType,
+			Width:    asset.Width,
+			Height:   asset.Height,
+		}, true
+	}
+
+	for _, rendition := range asset.Renditions {
+		if rendition.Name == name {
+			return rendition, true
+		}
+	}
+
+	return chronicle.MediaRendition{}, false
+}
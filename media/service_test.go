@@ -0,0 +1,217 @@
+package media_test
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/AdhityaRamadhanus/chronicle"
+	"github.com/AdhityaRamadhanus/chronicle/media"
+	"github.com/stretchr/testify/assert"
+)
+
+// in memory repository and blob store
+type fakeMediaAssetRepository struct {
+	assets    map[int]chronicle.MediaAsset
+	insertErr error
+}
+
+func (r *fakeMediaAssetRepository) Find(id int) (chronicle.MediaAsset, error) {
+	asset, ok := r.assets[id]
+	if !ok {
+		return chronicle.MediaAsset{}, sql.ErrNoRows
+	}
+	return asset, nil
+}
+
+func (r *fakeMediaAssetRepository) All(option chronicle.PagingOptions) (chronicle.MediaAssets, int, error) {
+	assets := chronicle.MediaAssets{}
+	for _, asset := range r.assets {
+		assets = append(assets, asset)
+	}
+	return assets, len(assets), nil
+}
+
+func (r *fakeMediaAssetRepository) Insert(asset chronicle.MediaAsset) (chronicle.MediaAsset, error) {
+	if r.insertErr != nil {
+		return chronicle.MediaAsset{}, r.insertErr
+	}
+	asset.ID = len(r.assets) + 1
+	r.assets[asset.ID] = asset
+	return asset, nil
+}
+
+func (r *fakeMediaAssetRepository) Delete(id int) error {
+	delete(r.assets, id)
+	return nil
+}
+
+type fakeBlobStore struct {
+	blobs map[string][]byte
+}
+
+func (b *fakeBlobStore) Put(key string, content io.Reader) error {
+	blob, err := ioutil.ReadAll(content)
+	b.blobs[key] = blob
+	return err
+}
+
+func (b *fakeBlobStore) Get(key string) (io.ReadCloser, error) {
+	blob, ok := b.blobs[key]
+	if !ok {
+		return nil, chronicle.ErrNoBlobFound
+	}
+	return ioutil.NopCloser(bytes.NewReader(blob)), nil
+}
+
+func (b *fakeBlobStore) Delete(key string) error {
+	delete(b.blobs, key)
+	return nil
+}
+
+func newFakes() (*fakeMediaAssetRepository, *fakeBlobStore) {
+	return &fakeMediaAssetRepository{assets: map[int]chronicle.MediaAsset{}}, &fakeBlobStore{blobs: map[string][]byte{}}
+}
+
+func pngContent(width, height int) []byte {
+	buffer := bytes.Buffer{}
+	png.Encode(&buffer, image.NewRGBA(image.Rect(0, 0, width, height)))
+	return buffer.Bytes()
+}
+
+// jpegContent encode img with an exif segment holding orientation right after the start of image
+func jpegContent(img image.Image, orientation uint16) []byte {
+	buffer := bytes.Buffer{}
+	jpeg.Encode(&buffer, img, &jpeg.Options{Quality: 100})
+	encoded := buffer.Bytes()
+
+	exif := bytes.Buffer{}
+	exif.WriteString("Exif\x00\x00MM\x00\x2a")
+	for _, value := range []interface{}{uint32(8), uint16(1), uint16(0x0112), uint16(3), uint32(1), orientation, uint16(0), uint32(0)} {
+		binary.Write(&exif, binary.BigEndian, value)
+	}
+
+	content := bytes.Buffer{}
+	content.Write(encoded[:2])
+	content.Write([]byte{0xff, 0xe1})
+	binary.Write(&content, binary.BigEndian, uint16(exif.Len()+2))
+	content.Write(exif.Bytes())
+	content.Write(encoded[2:])
+	return content.Bytes()
+}
+
+func TestUploadMedia(t *testing.T) {
+	mediaAssetRepository, blobStore := newFakes()
+	mediaService := media.NewService(mediaAssetRepository, blobStore, nil)
+
+	createdAsset, err := mediaService.UploadMedia(chronicle.MediaAsset{
+		Filename: "photo.png",
+		Caption:  "Photo",
+	}, pngContent(64, 32))
+	if err != nil {
+		t.Fatal("Failed to upload media", err)
+	}
+
+	assert.Equal(t, "image/png", createdAsset.MimeType)
+	assert.Equal(t, 64, createdAsset.Width)
+	assert.Equal(t, 32, createdAsset.Height)
+	assert.Equal(t, "Photo", createdAsset.Caption)
+	assert.Contains(t, blobStore.blobs, createdAsset.Key)
+
+	// default renditions never enlarge the image
+	expectedRenditions := map[string][2]int{
+		"thumbnail": {32, 32},
+		"card":      {48, 32},
+		"hero":      {64, 32},
+	}
+	assert.Len(t, createdAsset.Renditions, len(expectedRenditions))
+	for _, rendition := range createdAsset.Renditions {
+		assert.Equal(t, expectedRenditions[rendition.Name], [2]int{rendition.Width, rendition.Height}, rendition.Name)
+		assert.Contains(t, blobStore.blobs, rendition.Key)
+	}
+
+	foundRendition, blob, err := mediaService.OpenMediaRendition(createdAsset.ID, chronicle.OriginalRendition)
+	if assert.NoError(t, err) {
+		content, _ := ioutil.ReadAll(blob)
+		blob.Close()
+		assert.Equal(t, createdAsset.Key, foundRendition.Key)
+		assert.Equal(t, pngContent(64, 32), content)
+	}
+
+	foundRendition, blob, err = mediaService.OpenMediaRendition(createdAsset.ID, "thumbnail")
+	if assert.NoError(t, err) {
+		config, _, err := image.DecodeConfig(blob)
+		blob.Close()
+		assert.NoError(t, err)
+		assert.Equal(t, "image/png", foundRendition.MimeType)
+		assert.Equal(t, 32, config.Width)
+	}
+
+	_, _, err = mediaService.OpenMediaRendition(createdAsset.ID, "poster")
+	assert.Equal(t, media.ErrNoRenditionFound, err)
+
+	assert.NoError(t, mediaService.DeleteMediaAssetByID(createdAsset.ID))
+	assert.Empty(t, blobStore.blobs)
+
+	_, err = mediaService.GetMediaAssetByID(createdAsset.ID)
+	assert.Equal(t, media.ErrNoMediaFound, err)
+}
+
+func TestUploadMediaRejectUnsupportedType(t *testing.T) {
+	mediaAssetRepository, blobStore := newFakes()
+	mediaService := media.NewService(mediaAssetRepository, blobStore, nil)
+
+	// the declared filename does not matter, content is plain text
+	_, err := mediaService.UploadMedia(chronicle.MediaAsset{Filename: "photo.png"}, []byte("not an image"))
+	assert.Equal(t, media.ErrUnsupportedMediaType, err)
+	assert.Empty(t, blobStore.blobs)
+}
+
+func TestUploadMediaRemoveBlobWhenInsertFail(t *testing.T) {
+	mediaAssetRepository, blobStore := newFakes()
+	mediaAssetRepository.insertErr = errors.New("database is down")
+	mediaService := media.NewService(mediaAssetRepository, blobStore, nil)
+
+	_, err := mediaService.UploadMedia(chronicle.MediaAsset{Filename: "photo.png"}, pngContent(1, 1))
+	assert.Error(t, err)
+	assert.Empty(t, blobStore.blobs)
+}
+
+func TestUploadMediaApplyOrientation(t *testing.T) {
+	mediaAssetRepository, blobStore := newFakes()
+	mediaService := media.NewService(mediaAssetRepository, blobStore, chronicle.RenditionSpecs{
+		{Name: "hero", Width: 100, Height: 100, Mode: chronicle.RenditionFitMode},
+	})
+
+	// stored sideways, the white left half is the top once turned 90 clockwise
+	createdAsset, err := mediaService.UploadMedia(chronicle.MediaAsset{Filename: "photo.jpg"}, jpegContent(splitImage(40, 20), 6))
+	if err != nil {
+		t.Fatal("Failed to upload media", err)
+	}
+
+	assert.Equal(t, 20, createdAsset.Width)
+	assert.Equal(t, 40, createdAsset.Height)
+
+	_, blob, err := mediaService.OpenMediaRendition(createdAsset.ID, "hero")
+	if !assert.NoError(t, err) {
+		return
+	}
+	img, _, err := image.Decode(blob)
+	blob.Close()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, image.Rect(0, 0, 20, 40), img.Bounds())
+	top, _, _, _ := img.At(10, 5).RGBA()
+	bottom, _, _, _ := img.At(10, 35).RGBA()
+	assert.True(t, top > 0xc000, "top should be white")
+	assert.True(t, bottom < 0x4000, "bottom should be black")
+}
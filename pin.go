@@ -0,0 +1,71 @@
+package chronicle
+
+import "time"
+
+//StoryPin keep a story at a slot of a topic listing, or of the listing of all stories when TopicID is 0
+type StoryPin struct {
+	ID      int
+	StoryID int `db:"storyid"`
+	TopicID int `db:"topicid"`
+	// zero based index within the listing
+	Slot int
+	// nil pin the story until it is unpinned
+	ExpiresAt *time.Time `db:"expiresat"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+//StoryPins short way to define array of story pin
+type StoryPins []StoryPin
+
+//PinnedPage is a page of a listing with pinned stories at their slots
+type PinnedPage struct {
+	// pinned story ids by their index within the page
+	Pinned map[int]int
+	// the rest of the page is filled by unpinned stories of the listing from Offset, at most Limit of them
+	Offset int
+	Limit  int
+}
+
+/*
+Page lay pins, sorted by slot and all part of a listing of storiesCount stories, out on a page of that listing,
+pins whose slot is past the end of listing take the last slots instead
+*/
+func (pins StoryPins) Page(storiesCount int, option PagingOptions) PinnedPage {
+	// walking backward keep slots distinct while pulling those past the end back in
+	slots := make([]int, len(pins))
+	last := storiesCount
+	for idx := len(pins) - 1; idx >= 0; idx-- {
+		slot := pins[idx].Slot
+		if slot >= last {
+			slot = last - 1
+		}
+		slots[idx] = slot
+		last = slot
+	}
+
+	page := PinnedPage{
+		Pinned: map[int]int{},
+		Offset: option.Offset,
+		Limit:  option.Limit,
+	}
+	for idx, slot := range slots {
+		switch {
+		case slot < option.Offset:
+			page.Offset--
+		case slot < option.Offset+option.Limit:
+			page.Pinned[slot-option.Offset] = pins[idx].StoryID
+			page.Limit--
+		}
+	}
+
+	return page
+}
+
+//StoryPinRepository provide an interface to get story pin entities
+type StoryPinRepository interface {
+	Find(id int) (StoryPin, error)
+	FindByTopic(topic int) (StoryPins, error)
+	Insert(pin StoryPin) (createdPin StoryPin, err error)
+	Delete(id int) error
+}
@@ -0,0 +1,119 @@
+package pin
+
+import (
+	"database/sql"
+
+	"github.com/AdhityaRamadhanus/chronicle"
+	"github.com/AdhityaRamadhanus/chronicle/function"
+	"github.com/pkg/errors"
+)
+
+var (
+	//ErrNoPinFound sub-domain specific error
+	ErrNoPinFound = errors.New("Cannot find Pin")
+	//ErrUnknownStory sub-domain specific error
+	ErrUnknownStory = errors.New("Cannot find Story to pin")
+	//ErrUnknownTopic sub-domain specific error
+	ErrUnknownTopic = errors.New("Cannot find Topic to pin Story in")
+	//ErrInvalidPinSlot sub-domain specific error
+	ErrInvalidPinSlot = errors.New("Slot must not be negative")
+	//ErrPinSlotTaken sub-domain specific error
+	ErrPinSlotTaken = errors.New("Slot is taken by another pinned Story")
+)
+
+//Service provide an interface to pin domain service
+type Service interface {
+	GetPins(topic int) (chronicle.StoryPins, error)
+	PinStory(pin chronicle.StoryPin) (createdPin chronicle.StoryPin, err error)
+	UnpinStory(id int) error
+}
+
+func NewService(pinRepository chronicle.StoryPinRepository, storyRepository chronicle.StoryRepository, topicRepository chronicle.TopicRepository) Service {
+	return &service{
+		pinRepository:   pinRepository,
+		storyRepository: storyRepository,
+		topicRepository: topicRepository,
+	}
+}
+
+type service struct {
+	pinRepository   chronicle.StoryPinRepository
+	storyRepository chronicle.StoryRepository
+	topicRepository chronicle.TopicRepository
+}
+
+// GetPins list pins of topic that have not expired, topic 0 list pins of the listing of all stories
+func (s *service) GetPins(topic int) (pins chronicle.StoryPins, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Wrap(err, function.GetFunctionName(s.GetPins))
+		}
+	}()
+
+	return s.pinRepository.FindByTopic(topic)
+}
+
+// PinStory pin story at slot, a story pinned again in the same listing move to the new slot
+func (s *service) PinStory(pin chronicle.StoryPin) (createdPin chronicle.StoryPin, err error) {
+	defer func() {
+		if err != nil && err != ErrUnknownStory && err != ErrUnknownTopic && err != ErrInvalidPinSlot && err != ErrPinSlotTaken {
+			err = errors.Wrap(err, function.GetFunctionName(s.PinStory))
+		}
+	}()
+
+	if pin.Slot < 0 {
+		return chronicle.StoryPin{}, ErrInvalidPinSlot
+	}
+
+	if _, err := s.storyRepository.Find(pin.StoryID); err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return chronicle.StoryPin{}, ErrUnknownStory
+		default:
+			return chronicle.StoryPin{}, err
+		}
+	}
+
+	if pin.TopicID != 0 {
+		if _, err := s.topicRepository.Find(pin.TopicID); err != nil {
+			switch err {
+			case sql.ErrNoRows:
+				return chronicle.StoryPin{}, ErrUnknownTopic
+			default:
+				return chronicle.StoryPin{}, err
+			}
+		}
+	}
+
+	pins, err := s.pinRepository.FindByTopic(pin.TopicID)
+	if err != nil {
+		return chronicle.StoryPin{}, err
+	}
+
+	for _, existingPin := range pins {
+		if existingPin.Slot == pin.Slot && existingPin.StoryID != pin.StoryID {
+			return chronicle.StoryPin{}, ErrPinSlotTaken
+		}
+	}
+
+	return s.pinRepository.Insert(pin)
+}
+
+func (s *service) UnpinStory(id int) (err error) {
+	defer func() {
+		if err != nil && err != ErrNoPinFound {
+			err = errors.Wrap(err, function.GetFunctionName(s.UnpinStory))
+		}
+	}()
+
+	if _, err := s.pinRepository.Find(id); err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return ErrNoPinFound
+		default:
+			return err
+		}
+	}
+
+	return s.pinRepository.Delete(id)
+}
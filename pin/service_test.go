@@ -0,0 +1,201 @@
+package pin_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	"github.com/AdhityaRamadhanus/chronicle"
+	"github.com/AdhityaRamadhanus/chronicle/config"
+	"github.com/AdhityaRamadhanus/chronicle/pin"
+	"github.com/AdhityaRamadhanus/chronicle/storage/postgre"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	pinService      pin.Service
+	pinRepository   *postgre.StoryPinRepository
+	storyRepository *postgre.StoryRepository
+	topicRepository *postgre.TopicRepository
+
+	// specific test case var
+	topicId  int
+	storyIds []int
+	pinId    int
+)
+
+// make test kind of idempotent
+func setupDatabase(db *sqlx.DB) {
+	_, err := db.Query("DELETE FROM story_pins")
+	if err != nil {
+		log.Fatal("Failed to setup database ", errors.Wrap(err, "Failed in delete from story_pins"))
+	}
+
+	_, err = db.Query("DELETE FROM stories")
+	if err != nil {
+		log.Fatal("Failed to setup database ", errors.Wrap(err, "Failed in delete from stories"))
+	}
+
+	_, err = db.Query("DELETE FROM topics")
+	if err != nil {
+		log.Fatal("Failed to setup database ", errors.Wrap(err, "Failed in delete from topics"))
+	}
+}
+
+func TestMain(m *testing.M) {
+	log.SetLevel(log.WarnLevel)
+	if err := config.Init("testing", []string{"../config/testing"}); err != nil {
+		log.Fatal(err)
+	}
+
+	pgConnString := postgre.GetConnString()
+
+	db, err := sqlx.Open("postgres", pgConnString)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	setupDatabase(db)
+
+	// Repositories
+	pinRepository = postgre.NewStoryPinRepository(db, "story_pins")
+	storyRepository = postgre.NewStoryRepository(db, "stories")
+	topicRepository = postgre.NewTopicRepository(db, "topics")
+	pinService = pin.NewService(pinRepository, storyRepository, topicRepository)
+
+	code := m.Run()
+	os.Exit(code)
+}
+
+func listedStoryIds(stories chronicle.Stories) []int {
+	ids := []int{}
+	for _, story := range stories {
+		ids = append(ids, story.ID)
+	}
+	return ids
+}
+
+func TestPinStoryIntegration(t *testing.T) {
+	createdTopic, err := topicRepository.Insert(chronicle.Topic{
+		Name: "Pinned Topic",
+		Slug: "pinned-topic",
+	})
+	if err != nil {
+		t.Fatal("Failed to create topic", err)
+	}
+	topicId = createdTopic.ID
+
+	for idx := 0; idx < 4; idx++ {
+		createdStory, err := storyRepository.Insert(chronicle.Story{
+			Title:  fmt.Sprintf("Pinned Story %d", idx+1),
+			Slug:   fmt.Sprintf("pinned-story-%d", idx+1),
+			Media:  []byte("{}"),
+			Status: chronicle.StoryPublishStatus,
+			Topics: chronicle.Topics{{ID: topicId}},
+		})
+		if err != nil {
+			t.Fatal("Failed to create story", err)
+		}
+		storyIds = append(storyIds, createdStory.ID)
+	}
+
+	expiredAt := time.Now().UTC().Add(-time.Hour)
+	testCases := []struct {
+		Pin           chronicle.StoryPin
+		ExpectedError error
+	}{
+		{
+			Pin: chronicle.StoryPin{StoryID: storyIds[0], TopicID: topicId, Slot: 1},
+		},
+		{
+			// expired pins are ignored and their slots can be reused
+			Pin: chronicle.StoryPin{StoryID: storyIds[3], TopicID: topicId, Slot: 0, ExpiresAt: &expiredAt},
+		},
+		{
+			Pin: chronicle.StoryPin{StoryID: storyIds[2], TopicID: topicId, Slot: 0},
+		},
+		{
+			Pin:           chronicle.StoryPin{StoryID: storyIds[1], TopicID: topicId, Slot: 1},
+			ExpectedError: pin.ErrPinSlotTaken,
+		},
+		{
+			Pin:           chronicle.StoryPin{StoryID: storyIds[1], TopicID: topicId, Slot: -1},
+			ExpectedError: pin.ErrInvalidPinSlot,
+		},
+		{
+			Pin:           chronicle.StoryPin{StoryID: -1, TopicID: topicId, Slot: 2},
+			ExpectedError: pin.ErrUnknownStory,
+		},
+		{
+			Pin:           chronicle.StoryPin{StoryID: storyIds[1], TopicID: -1, Slot: 2},
+			ExpectedError: pin.ErrUnknownTopic,
+		},
+	}
+
+	for _, testCase := range testCases {
+		createdPin, err := pinService.PinStory(testCase.Pin)
+		if testCase.ExpectedError != nil {
+			assert.Equal(t, testCase.ExpectedError, err)
+			continue
+		}
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, testCase.Pin.Slot, createdPin.Slot)
+			pinId = createdPin.ID
+		}
+	}
+
+	pins, err := pinService.GetPins(topicId)
+	if assert.NoError(t, err) && assert.Len(t, pins, 2) {
+		assert.Equal(t, storyIds[2], pins[0].StoryID)
+		assert.Equal(t, storyIds[0], pins[1].StoryID)
+	}
+}
+
+func TestPinnedTopicListingIntegration(t *testing.T) {
+	// unpinned stories follow newest first: 4, 2
+	testCases := []struct {
+		Option        chronicle.PagingOptions
+		ExpectedOrder []int
+	}{
+		{
+			Option:        chronicle.PagingOptions{Limit: 10, Offset: 0, SortBy: "createdAt", Order: "desc"},
+			ExpectedOrder: []int{storyIds[2], storyIds[0], storyIds[3], storyIds[1]},
+		},
+		{
+			Option:        chronicle.PagingOptions{Limit: 2, Offset: 0, SortBy: "createdAt", Order: "desc"},
+			ExpectedOrder: []int{storyIds[2], storyIds[0]},
+		},
+		{
+			Option:        chronicle.PagingOptions{Limit: 2, Offset: 2, SortBy: "createdAt", Order: "desc"},
+			ExpectedOrder: []int{storyIds[3], storyIds[1]},
+		},
+	}
+
+	for _, testCase := range testCases {
+		stories, storiesCount, err := storyRepository.FindByTopicAndStatus(topicId, chronicle.StoryPublishStatus, false, testCase.Option)
+		if assert.NoError(t, err) {
+			assert.Equal(t, 4, storiesCount)
+			assert.Equal(t, testCase.ExpectedOrder, listedStoryIds(stories))
+		}
+	}
+}
+
+func TestUnpinStoryIntegration(t *testing.T) {
+	err := pinService.UnpinStory(pinId)
+	assert.NoError(t, err)
+
+	err = pinService.UnpinStory(pinId)
+	assert.Equal(t, pin.ErrNoPinFound, err)
+
+	pins, err := pinService.GetPins(topicId)
+	if assert.NoError(t, err) {
+		assert.Len(t, pins, 1)
+	}
+}
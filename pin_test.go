@@ -0,0 +1,49 @@
+package chronicle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStoryPinsPage(t *testing.T) {
+	testCases := []struct {
+		Pins         StoryPins
+		StoriesCount int
+		Option       PagingOptions
+		ExpectedPage PinnedPage
+	}{
+		{
+			// no pins
+			Pins:         StoryPins{},
+			StoriesCount: 10,
+			Option:       PagingOptions{Offset: 5, Limit: 5},
+			ExpectedPage: PinnedPage{Pinned: map[int]int{}, Offset: 5, Limit: 5},
+		},
+		{
+			Pins:         StoryPins{{StoryID: 7, Slot: 0}, {StoryID: 3, Slot: 2}},
+			StoriesCount: 10,
+			Option:       PagingOptions{Offset: 0, Limit: 5},
+			ExpectedPage: PinnedPage{Pinned: map[int]int{0: 7, 2: 3}, Offset: 0, Limit: 3},
+		},
+		{
+			// pins on earlier pages shift the unpinned stories back
+			Pins:         StoryPins{{StoryID: 7, Slot: 0}, {StoryID: 3, Slot: 6}},
+			StoriesCount: 10,
+			Option:       PagingOptions{Offset: 5, Limit: 5},
+			ExpectedPage: PinnedPage{Pinned: map[int]int{1: 3}, Offset: 4, Limit: 4},
+		},
+		{
+			// pins past the end of listing take the last slots
+			Pins:         StoryPins{{StoryID: 7, Slot: 8}, {StoryID: 3, Slot: 20}},
+			StoriesCount: 4,
+			Option:       PagingOptions{Offset: 0, Limit: 5},
+			ExpectedPage: PinnedPage{Pinned: map[int]int{2: 7, 3: 3}, Offset: 0, Limit: 3},
+		},
+	}
+
+	for _, testCase := range testCases {
+		page := testCase.Pins.Page(testCase.StoriesCount, testCase.Option)
+		assert.Equal(t, testCase.ExpectedPage, page)
+	}
+}
@@ -0,0 +1,26 @@
+package chronicle
+
+import (
+	"time"
+)
+
+//RelatedStoryWeights weigh the signals combined to rank stories related to a story, each signal is scored between 0 and 1
+type RelatedStoryWeights struct {
+	// shared topics, a topic few stories are filed under count more than a broad one
+	Topics float64
+	// words shared by titles and excerpts
+	Text float64
+	// newer stories first, halving every RecencyHalfLife
+	Recency         float64
+	RecencyHalfLife time.Duration
+}
+
+var (
+	//DefaultRelatedStoryWeights favour shared topics, then similar text, recency mostly break ties
+	DefaultRelatedStoryWeights = RelatedStoryWeights{
+		Topics:          3,
+		Text:            2,
+		Recency:         1,
+		RecencyHalfLife: 7 * 24 * time.Hour,
+	}
+)
@@ -0,0 +1,46 @@
+package chronicle
+
+import (
+	"encoding/json"
+	"time"
+)
+
+//StoryRevision is a snapshot of a story taken every time the story is written
+type StoryRevision struct {
+	ID       int
+	StoryID  int
+	Revision int
+	Media    json.RawMessage
+	Title    string
+	Slug     string
+	Excerpt  string
+	Content  string
+	Reporter string
+	Editor   string
+	Author   string
+	Status   string
+	// format Content is written in
+	ContentFormat string
+
+	CreatedAt time.Time
+}
+
+//StoryRevisions short way to define array of story revision
+type StoryRevisions []StoryRevision
+
+//StoryRevisionDiff hold word level differences of two revisions of a story
+type StoryRevisionDiff struct {
+	StoryID int
+	From    int
+	To      int
+	Title   WordDiffs
+	Excerpt WordDiffs
+	Content WordDiffs
+}
+
+//StoryRevisionRepository provide an interface to get story revision entities
+type StoryRevisionRepository interface {
+	Find(storyID int, revision int) (StoryRevision, error)
+	FindByStory(storyID int, option PagingOptions) (revisions StoryRevisions, revisionsCount int, err error)
+	Insert(story Story) (createdRevision StoryRevision, err error)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/AdhityaRamadhanus/chronicle/config"
+	"github.com/AdhityaRamadhanus/chronicle/storage/postgre"
+	"github.com/AdhityaRamadhanus/chronicle/transfer"
+	"github.com/jmoiron/sqlx"
+	"github.com/joho/godotenv"
+	_ "github.com/lib/pq"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	godotenv.Load()
+	config.Init(os.Getenv("ENV"), []string{})
+}
+
+func main() {
+	outputPath := flag.String("out", "", "file to write NDJSON records to, stdout when empty")
+	flag.Parse()
+
+	pgConnString := fmt.Sprintf(`
+		host=%s 
+		port=%d 
+		user=%s 
+		password=%s 
+		dbname=%s 
+		sslmode=%s`,
+		viper.GetString("database.host"),
+		viper.GetInt("database.port"),
+		viper.GetString("database.user"),
+		viper.GetString("database.password"),
+		viper.GetString("database.dbname"),
+		viper.GetString("database.sslmode"),
+	)
+
+	db, err := sqlx.Open("postgres", pgConnString)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var output io.Writer = os.Stdout
+	if *outputPath != "" {
+		file, err := os.Create(*outputPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+		output = file
+	}
+
+	// records go to stdout, logs go to stderr
+	buffered := bufio.NewWriter(output)
+	report, err := transfer.Export(
+		buffered,
+		postgre.NewTopicRepository(db, "topics"),
+		postgre.NewStoryRepository(db, "stories"),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := buffered.Flush(); err != nil {
+		log.Fatal(err)
+	}
+
+	log.WithFields(log.Fields{
+		"topics":       report.Topics,
+		"stories":      report.Stories,
+		"story_topics": report.StoryTopics,
+	}).Info("Exported")
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/AdhityaRamadhanus/chronicle/config"
+	"github.com/AdhityaRamadhanus/chronicle/storage/postgre"
+	"github.com/AdhityaRamadhanus/chronicle/transfer"
+	"github.com/jmoiron/sqlx"
+	"github.com/joho/godotenv"
+	_ "github.com/lib/pq"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	godotenv.Load()
+	config.Init(os.Getenv("ENV"), []string{})
+}
+
+func main() {
+	inputPath := flag.String("in", "", "file to read NDJSON records from, stdin when empty")
+	progressPath := flag.String("progress", "", "file keeping progress to resume an interrupted import")
+	dryRun := flag.Bool("dry-run", false, "report what would be imported without writing anything")
+	flag.Parse()
+
+	pgConnString := fmt.Sprintf(`
+		host=%s 
+		port=%d 
+		user=%s 
+		password=%s 
+		dbname=%s 
+		sslmode=%s`,
+		viper.GetString("database.host"),
+		viper.GetInt("database.port"),
+		viper.GetString("database.user"),
+		viper.GetString("database.password"),
+		viper.GetString("database.dbname"),
+		viper.GetString("database.sslmode"),
+	)
+
+	db, err := sqlx.Open("postgres", pgConnString)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var input io.Reader = os.Stdin
+	if *inputPath != "" {
+		file, err := os.Open(*inputPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+		input = file
+	}
+
+	report, err := transfer.Import(
+		bufio.NewReader(input),
+		postgre.NewTopicRepository(db, "topics"),
+		postgre.NewStoryRepository(db, "stories"),
+		transfer.ImportOptions{
+			DryRun:       *dryRun,
+			ProgressPath: *progressPath,
+		},
+	)
+
+	// what was done before a failure is reported too, the import can be resumed from its progress
+	for _, skipped := range report.Skipped {
+		log.Warn("Skipped ", skipped)
+	}
+
+	log.WithFields(log.Fields{
+		"dry_run":          *dryRun,
+		"resumed":          report.Resumed,
+		"topics_created":   report.TopicsCreated,
+		"topics_existing":  report.TopicsExisting,
+		"stories_created":  report.StoriesCreated,
+		"stories_existing": report.StoriesExisting,
+		"links_created":    report.LinksCreated,
+		"links_existing":   report.LinksExisting,
+		"skipped":          len(report.Skipped),
+	}).Info("Imported")
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/AdhityaRamadhanus/chronicle/config"
+	"github.com/AdhityaRamadhanus/chronicle/storage/postgre"
+	"github.com/AdhityaRamadhanus/chronicle/transfer"
+	"github.com/AdhityaRamadhanus/chronicle/wordpress"
+	"github.com/jmoiron/sqlx"
+	"github.com/joho/godotenv"
+	_ "github.com/lib/pq"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	godotenv.Load()
+	config.Init(os.Getenv("ENV"), []string{})
+}
+
+func main() {
+	inputPath := flag.String("in", "", "WXR file exported from WordPress")
+	progressPath := flag.String("progress", "", "file keeping progress to resume an interrupted import")
+	dryRun := flag.Bool("dry-run", false, "report what would be imported without writing anything")
+	flag.Parse()
+
+	if *inputPath == "" {
+		log.Fatal("-in is required")
+	}
+
+	file, err := os.Open(*inputPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	export, err := wordpress.Parse(bufio.NewReader(file))
+	file.Close()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// records go through the NDJSON import so ids are remapped and what exist by slug is reused
+	records, skipped := wordpress.Convert(export)
+	ndjson := &bytes.Buffer{}
+	encoder := json.NewEncoder(ndjson)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	pgConnString := fmt.Sprintf(`
+		host=%s 
+		port=%d 
+		user=%s 
+		password=%s 
+		dbname=%s 
+		sslmode=%s`,
+		viper.GetString("database.host"),
+		viper.GetInt("database.port"),
+		viper.GetString("database.user"),
+		viper.GetString("database.password"),
+		viper.GetString("database.dbname"),
+		viper.GetString("database.sslmode"),
+	)
+
+	db, err := sqlx.Open("postgres", pgConnString)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	report, err := transfer.Import(
+		ndjson,
+		postgre.NewTopicRepository(db, "topics"),
+		postgre.NewStoryRepository(db, "stories"),
+		transfer.ImportOptions{
+			DryRun:       *dryRun,
+			ProgressPath: *progressPath,
+		},
+	)
+
+	skipped = append(skipped, report.Skipped...)
+	for _, reason := range skipped {
+		log.Warn("Skipped ", reason)
+	}
+
+	log.WithFields(log.Fields{
+		"dry_run":          *dryRun,
+		"authors":          len(export.Authors),
+		"categories":       len(export.Categories),
+		"items":            len(export.Items),
+		"resumed":          report.Resumed,
+		"topics_created":   report.TopicsCreated,
+		"topics_existing":  report.TopicsExisting,
+		"stories_created":  report.StoriesCreated,
+		"stories_existing": report.StoriesExisting,
+		"links_created":    report.LinksCreated,
+		"links_existing":   report.LinksExisting,
+		"skipped":          len(skipped),
+	}).Info("Imported WordPress export")
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"github.com/AdhityaRamadhanus/chronicle"
+	"github.com/AdhityaRamadhanus/chronicle/content"
+	"github.com/jmoiron/sqlx"
+	log "github.com/sirupsen/logrus"
+)
+
+// renderContentBatchSize is how many stories are rendered per query
+const renderContentBatchSize = 500
+
+type unrenderedStory struct {
+	ID            int
+	ContentFormat string `db:"contentformat"`
+	Content       string
+}
+
+/*
+renderStoryContent fill contentHtml of stories written before content formats existed, their content is html
+that was never sanitized so it is sanitized along with contentHtml, stories are walked by id so it can be run again
+*/
+func renderStoryContent(db *sqlx.DB) error {
+	rendered := 0
+	lastID := 0
+	for {
+		stories := []unrenderedStory{}
+		query := `SELECT id, contentFormat, content FROM stories WHERE contentHtml='' AND id>$1 ORDER BY id LIMIT $2`
+		if err := db.Select(&stories, query, lastID, renderContentBatchSize); err != nil {
+			return err
+		}
+
+		if len(stories) == 0 {
+			break
+		}
+
+		for _, story := range stories {
+			lastID = story.ID
+
+			contentHTML, err := content.Render(story.ContentFormat, story.Content)
+			if err != nil {
+				log.WithField("story", story.ID).WithError(err).Warn("Content of story is not rendered")
+				continue
+			}
+
+			// html content is stored sanitized, like stories written through the api
+			storyContent := story.Content
+			if story.ContentFormat == "" || story.ContentFormat == chronicle.StoryContentHTML {
+				storyContent = contentHTML
+			}
+
+			if _, err := db.Exec(`UPDATE stories SET content=$1, contentHtml=$2 WHERE id=$3`, storyContent, contentHTML, story.ID); err != nil {
+				return err
+			}
+			rendered++
+		}
+	}
+
+	log.Info("Rendered content of ", rendered, " stories")
+	return nil
+}
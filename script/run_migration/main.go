@@ -74,4 +74,8 @@ func main() {
 			log.Fatal(err)
 		}
 	}
+
+	if err := renderStoryContent(db); err != nil {
+		log.Fatal(err)
+	}
 }
@@ -0,0 +1,39 @@
+package chronicle
+
+var (
+	//SearchLanguageIndonesian search stories using indonesian text configuration
+	SearchLanguageIndonesian = "id"
+	//SearchLanguageEnglish search stories using english text configuration
+	SearchLanguageEnglish = "en"
+)
+
+//StorySearchOptions struct used as parameter to search story by keyword, filtered by status and its topic
+type StorySearchOptions struct {
+	Query    string
+	Language string
+	Status   string
+	Topic    int
+}
+
+//StorySearchHit is a story matching a search with its rank and an html escaped snippet, matched words wrapped in <mark>
+type StorySearchHit struct {
+	Story
+	Rank    float64
+	Snippet string
+}
+
+//StorySearchHits short way to define array of story search hit
+type StorySearchHits []StorySearchHit
+
+//SearchFacet count search hits sharing the same value
+type SearchFacet struct {
+	Value string
+	Label string
+	Count int
+}
+
+//StorySearchFacets group search hits by status and by topic
+type StorySearchFacets struct {
+	Statuses []SearchFacet
+	Topics   []SearchFacet
+}
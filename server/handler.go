@@ -8,3 +8,9 @@ import (
 type Handler interface {
 	RegisterRoutes(router *mux.Router)
 }
+
+//RootHandler is a Handler with routes outside of /api too, for documents crawlers look for at the root of a site
+type RootHandler interface {
+	Handler
+	RegisterRootRoutes(router *mux.Router)
+}
@@ -0,0 +1,499 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/AdhityaRamadhanus/chronicle"
+	"github.com/AdhityaRamadhanus/chronicle/collection"
+	"github.com/AdhityaRamadhanus/chronicle/engagement"
+	"github.com/AdhityaRamadhanus/chronicle/server/internal/contextkey"
+	"github.com/AdhityaRamadhanus/chronicle/server/middlewares"
+	"github.com/AdhityaRamadhanus/chronicle/server/render"
+	"github.com/asaskevich/govalidator"
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+type CollectionHandler struct {
+	CollectionService collection.Service
+	EngagementService engagement.Service
+	CacheService      chronicle.CacheService
+}
+
+func (h CollectionHandler) RegisterRoutes(router *mux.Router) {
+	authMiddleware := middlewares.Authenticate
+	cacheMiddleware := middlewares.Cache(h.CacheService)
+
+	router.HandleFunc("/collections/", authMiddleware(cacheMiddleware("60s", h.getCollections))).Methods("GET")
+	router.HandleFunc("/collections/insert", authMiddleware(h.createCollection)).Methods("POST")
+
+	router.HandleFunc("/collections/{id:[0-9]+}", authMiddleware(cacheMiddleware("60s", h.getCollectionByID))).Methods("GET")
+	router.HandleFunc("/collections/{id:[0-9]+}/update", authMiddleware(h.updateCollection)).Methods("PATCH")
+	router.HandleFunc("/collections/{id:[0-9]+}/delete", authMiddleware(h.deleteCollectionByID)).Methods("DELETE")
+
+	router.HandleFunc("/collections/{id:[0-9]+}/stories/insert", authMiddleware(h.insertCollectionStory)).Methods("POST")
+	router.HandleFunc("/collections/{id:[0-9]+}/stories/reorder", authMiddleware(h.reorderCollectionStories)).Methods("PATCH")
+	router.HandleFunc("/collections/{id:[0-9]+}/stories/{storyId:[0-9]+}/delete", authMiddleware(h.removeCollectionStory)).Methods("DELETE")
+
+	router.HandleFunc("/collections/{slug}", authMiddleware(cacheMiddleware("60s", h.getCollectionBySlug))).Methods("GET")
+}
+
+func (h *CollectionHandler) getCollections(res http.ResponseWriter, req *http.Request) {
+	// Pagination
+	limit, _ := strconv.Atoi(req.URL.Query().Get("limit"))
+	if limit == 0 {
+		limit = 20
+	}
+	page, _ := strconv.Atoi(req.URL.Query().Get("page"))
+	if page <= 0 {
+		page = 1
+	}
+	sortby := req.URL.Query().Get("sort-by")
+	if sortby == "" {
+		sortby = "updatedAt"
+	}
+	order := req.URL.Query().Get("order")
+	if order == "" {
+		order = "desc"
+	}
+
+	getCollectionsRequest := struct {
+		Limit  int    `valid:"int"`
+		Page   int    `valid:"int"`
+		Order  string `valid:"in(asc|desc)"`
+		SortBy string `valid:"in(createdAt|updatedAt|title)"`
+	}{
+		Limit:  limit,
+		Page:   page,
+		SortBy: sortby,
+		Order:  order,
+	}
+
+	if ok, err := govalidator.ValidateStruct(getCollectionsRequest); !ok || err != nil {
+		RenderError(res, ErrInvalidRequest, err.Error())
+		return
+	}
+
+	collections, collectionsCount, err := h.CollectionService.GetCollections(chronicle.PagingOptions{
+		Limit:  limit,
+		Offset: (page - 1) * limit,
+		SortBy: sortby,
+		Order:  order,
+	})
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      getCollectionsRequest,
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Getting Collections")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	totalPage := int(math.Ceil(float64(collectionsCount) / float64(limit)))
+	render.Negotiate(res, req, http.StatusOK, map[string]interface{}{
+		"status":      http.StatusOK,
+		"collections": collections,
+		"pagination": map[string]interface{}{
+			"totalItems":   collectionsCount,
+			"page":         page,
+			"itemsPerPage": limit,
+			"totalPage":    totalPage,
+		},
+	}, collections)
+}
+
+func (h *CollectionHandler) createCollection(res http.ResponseWriter, req *http.Request) {
+	// Read Body, limit to 1 MB //
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, 1048576))
+	if err != nil {
+		RenderError(res, ErrFailedToReadBody)
+		return
+	}
+
+	createCollectionRequest := struct {
+		Title       string `json:"title" valid:"required"`
+		Description string `json:"description" valid:"-"`
+		StoryIDs    []int  `json:"stories" valid:"-"`
+	}{}
+
+	// Deserialize
+	if err := json.Unmarshal(body, &createCollectionRequest); err != nil {
+		RenderError(res, ErrFailedToUnmarshalJSON)
+		return
+	}
+
+	if err := req.Body.Close(); err != nil {
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	if ok, err := govalidator.ValidateStruct(createCollectionRequest); !ok || err != nil {
+		RenderError(res, ErrInvalidRequest, err.Error())
+		return
+	}
+
+	newCollectionStories := chronicle.Stories{}
+	for _, storyId := range createCollectionRequest.StoryIDs {
+		newCollectionStories = append(newCollectionStories, chronicle.Story{ID: storyId})
+	}
+
+	newCollection := chronicle.Collection{
+		Title:       createCollectionRequest.Title,
+		Slug:        slugify(createCollectionRequest.Title),
+		Description: createCollectionRequest.Description,
+		Stories:     newCollectionStories,
+	}
+
+	createdCollection, err := h.CollectionService.CreateCollection(newCollection)
+	if err != nil && err == collection.ErrUnknownStory {
+		RenderError(res, ErrInvalidRequest, "stories:", err.Error())
+		return
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      createCollectionRequest,
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Creating Collection")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	h.invalidateStoriesCache(req)
+	render.JSON(res, http.StatusCreated, map[string]interface{}{
+		"status":     http.StatusCreated,
+		"collection": createdCollection,
+	})
+}
+
+func (h *CollectionHandler) getCollectionByID(res http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	collectionId, _ := strconv.Atoi(params["id"])
+	foundCollection, err := h.CollectionService.GetCollectionByID(collectionId)
+
+	if err != nil && err == collection.ErrNoCollectionFound {
+		renderNoCollectionFound(res, err)
+		return
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      collectionId,
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Getting Collection By ID")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	foundCollection.Stories = applyPendingEngagements(h.EngagementService, req, foundCollection.Stories)
+	render.JSON(res, http.StatusOK, map[string]interface{}{
+		"status":     http.StatusOK,
+		"collection": foundCollection,
+	})
+}
+
+func (h *CollectionHandler) getCollectionBySlug(res http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	slug := params["slug"]
+	foundCollection, err := h.CollectionService.GetCollectionBySlug(slug)
+
+	if err != nil && err == collection.ErrNoCollectionFound {
+		renderNoCollectionFound(res, err)
+		return
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      slug,
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Getting Collection By Slug")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	foundCollection.Stories = applyPendingEngagements(h.EngagementService, req, foundCollection.Stories)
+	render.JSON(res, http.StatusOK, map[string]interface{}{
+		"status":     http.StatusOK,
+		"collection": foundCollection,
+	})
+}
+
+func (h *CollectionHandler) updateCollection(res http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	// Read Body, limit to 1 MB //
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, 1048576))
+	if err != nil {
+		RenderError(res, ErrFailedToReadBody)
+		return
+	}
+
+	// omitted fields are left untouched
+	updateCollectionRequest := struct {
+		Title       string  `json:"title" valid:"-"`
+		Description *string `json:"description" valid:"-"`
+	}{}
+
+	// Deserialize
+	if err := json.Unmarshal(body, &updateCollectionRequest); err != nil {
+		RenderError(res, ErrFailedToUnmarshalJSON)
+		return
+	}
+
+	if err := req.Body.Close(); err != nil {
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	if ok, err := govalidator.ValidateStruct(updateCollectionRequest); !ok || err != nil {
+		RenderError(res, ErrInvalidRequest, err.Error())
+		return
+	}
+
+	collectionId, _ := strconv.Atoi(params["id"])
+	foundCollection, err := h.CollectionService.GetCollectionByID(collectionId)
+
+	if err != nil && err == collection.ErrNoCollectionFound {
+		renderNoCollectionFound(res, err)
+		return
+	}
+
+	if updateCollectionRequest.Title != "" {
+		foundCollection.Title = updateCollectionRequest.Title
+		foundCollection.Slug = slugify(updateCollectionRequest.Title)
+	}
+
+	if updateCollectionRequest.Description != nil {
+		foundCollection.Description = *updateCollectionRequest.Description
+	}
+
+	updatedCollection, err := h.CollectionService.UpdateCollection(foundCollection)
+	if err != nil && err == collection.ErrNoCollectionFound {
+		renderNoCollectionFound(res, err)
+		return
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      updateCollectionRequest,
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Updating Collection")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	h.invalidateStoriesCache(req)
+	render.JSON(res, http.StatusOK, map[string]interface{}{
+		"status":     http.StatusOK,
+		"collection": updatedCollection,
+	})
+}
+
+func (h *CollectionHandler) deleteCollectionByID(res http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	collectionId, _ := strconv.Atoi(params["id"])
+	err := h.CollectionService.DeleteCollectionByID(collectionId)
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      collectionId,
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Delete Collection By ID")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	h.invalidateStoriesCache(req)
+	render.JSON(res, http.StatusOK, map[string]interface{}{
+		"status":  http.StatusOK,
+		"message": "Collection Deleted",
+	})
+}
+
+func (h *CollectionHandler) insertCollectionStory(res http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	// Read Body, limit to 1 MB //
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, 1048576))
+	if err != nil {
+		RenderError(res, ErrFailedToReadBody)
+		return
+	}
+
+	// omitted position append the story
+	insertCollectionStoryRequest := struct {
+		StoryID  int  `json:"storyId" valid:"required"`
+		Position *int `json:"position" valid:"-"`
+	}{}
+
+	// Deserialize
+	if err := json.Unmarshal(body, &insertCollectionStoryRequest); err != nil {
+		RenderError(res, ErrFailedToUnmarshalJSON)
+		return
+	}
+
+	if err := req.Body.Close(); err != nil {
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	if ok, err := govalidator.ValidateStruct(insertCollectionStoryRequest); !ok || err != nil {
+		RenderError(res, ErrInvalidRequest, err.Error())
+		return
+	}
+
+	position := -1
+	if insertCollectionStoryRequest.Position != nil {
+		position = *insertCollectionStoryRequest.Position
+	}
+
+	collectionId, _ := strconv.Atoi(params["id"])
+	updatedCollection, err := h.CollectionService.InsertCollectionStory(collectionId, insertCollectionStoryRequest.StoryID, position)
+	if err != nil && err == collection.ErrNoCollectionFound {
+		renderNoCollectionFound(res, err)
+		return
+	}
+
+	if err != nil && (err == collection.ErrUnknownStory || err == collection.ErrStoryAlreadyCollected) {
+		RenderError(res, ErrInvalidRequest, "storyId:", err.Error())
+		return
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      insertCollectionStoryRequest,
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Inserting Collection Story")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	h.invalidateStoriesCache(req)
+	render.JSON(res, http.StatusOK, map[string]interface{}{
+		"status":     http.StatusOK,
+		"collection": updatedCollection,
+	})
+}
+
+func (h *CollectionHandler) removeCollectionStory(res http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	collectionId, _ := strconv.Atoi(params["id"])
+	storyId, _ := strconv.Atoi(params["storyId"])
+	updatedCollection, err := h.CollectionService.RemoveCollectionStory(collectionId, storyId)
+
+	if err != nil && err == collection.ErrNoCollectionFound {
+		renderNoCollectionFound(res, err)
+		return
+	}
+
+	if err != nil && err == collection.ErrStoryNotCollected {
+		render.JSON(res, http.StatusNotFound, map[string]interface{}{
+			"status": http.StatusNotFound,
+			"error": map[string]interface{}{
+				"code":    "ErrStoryNotCollected",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      map[string]int{"id": collectionId, "storyId": storyId},
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Removing Collection Story")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	h.invalidateStoriesCache(req)
+	render.JSON(res, http.StatusOK, map[string]interface{}{
+		"status":     http.StatusOK,
+		"collection": updatedCollection,
+	})
+}
+
+func (h *CollectionHandler) reorderCollectionStories(res http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	// Read Body, limit to 1 MB //
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, 1048576))
+	if err != nil {
+		RenderError(res, ErrFailedToReadBody)
+		return
+	}
+
+	reorderCollectionStoriesRequest := struct {
+		StoryIDs []int `json:"stories" valid:"-"`
+	}{}
+
+	// Deserialize
+	if err := json.Unmarshal(body, &reorderCollectionStoriesRequest); err != nil {
+		RenderError(res, ErrFailedToUnmarshalJSON)
+		return
+	}
+
+	if err := req.Body.Close(); err != nil {
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	collectionId, _ := strconv.Atoi(params["id"])
+	updatedCollection, err := h.CollectionService.ReorderCollectionStories(collectionId, reorderCollectionStoriesRequest.StoryIDs)
+	if err != nil && err == collection.ErrNoCollectionFound {
+		renderNoCollectionFound(res, err)
+		return
+	}
+
+	if err != nil && err == collection.ErrInvalidCollectionOrder {
+		RenderError(res, ErrInvalidRequest, "stories:", err.Error())
+		return
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      reorderCollectionStoriesRequest,
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Reordering Collection Stories")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	h.invalidateStoriesCache(req)
+	render.JSON(res, http.StatusOK, map[string]interface{}{
+		"status":     http.StatusOK,
+		"collection": updatedCollection,
+	})
+}
+
+// invalidateStoriesCache drop cached collections and stories, stories embed their neighbours within collections
+func (h *CollectionHandler) invalidateStoriesCache(req *http.Request) {
+	if err := middlewares.InvalidateCache(h.CacheService, "/api/collections", "/api/stories"); err != nil {
+		log.WithFields(log.Fields{
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Warn("Error Handler Invalidating Collection Cache")
+	}
+}
+
+func renderNoCollectionFound(res http.ResponseWriter, err error) {
+	render.JSON(res, http.StatusNotFound, map[string]interface{}{
+		"status": http.StatusNotFound,
+		"error": map[string]interface{}{
+			"code":    "ErrNoCollectionFound",
+			"message": err.Error(),
+		},
+	})
+}
@@ -0,0 +1,391 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/AdhityaRamadhanus/chronicle"
+	"github.com/AdhityaRamadhanus/chronicle/contributor"
+	"github.com/AdhityaRamadhanus/chronicle/engagement"
+	"github.com/AdhityaRamadhanus/chronicle/server/internal/contextkey"
+	"github.com/AdhityaRamadhanus/chronicle/server/middlewares"
+	"github.com/AdhityaRamadhanus/chronicle/server/render"
+	"github.com/asaskevich/govalidator"
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+type ContributorHandler struct {
+	ContributorService contributor.Service
+	EngagementService  engagement.Service
+	CacheService       chronicle.CacheService
+}
+
+func (h ContributorHandler) RegisterRoutes(router *mux.Router) {
+	authMiddleware := middlewares.Authenticate
+	cacheMiddleware := middlewares.Cache(h.CacheService)
+
+	router.HandleFunc("/contributors/", authMiddleware(cacheMiddleware("60s", h.getContributors))).Methods("GET")
+	router.HandleFunc("/contributors/insert", authMiddleware(h.createContributor)).Methods("POST")
+
+	router.HandleFunc("/contributors/{id:[0-9]+}", authMiddleware(cacheMiddleware("60s", h.getContributorByID))).Methods("GET")
+	router.HandleFunc("/contributors/{id:[0-9]+}/update", authMiddleware(h.updateContributor)).Methods("PATCH")
+	router.HandleFunc("/contributors/{id:[0-9]+}/delete", authMiddleware(h.deleteContributorByID)).Methods("DELETE")
+
+	router.HandleFunc("/contributors/{slug}/stories", authMiddleware(cacheMiddleware("60s", h.getContributorStories))).Methods("GET")
+	router.HandleFunc("/contributors/{slug}", authMiddleware(cacheMiddleware("60s", h.getContributorBySlug))).Methods("GET")
+}
+
+func (h *ContributorHandler) getContributors(res http.ResponseWriter, req *http.Request) {
+	// Pagination
+	limit, _ := strconv.Atoi(req.URL.Query().Get("limit"))
+	if limit == 0 {
+		limit = 20
+	}
+	page, _ := strconv.Atoi(req.URL.Query().Get("page"))
+	if page <= 0 {
+		page = 1
+	}
+	sortby := req.URL.Query().Get("sort-by")
+	if sortby == "" {
+		sortby = "createdAt"
+	}
+	order := req.URL.Query().Get("order")
+	if order == "" {
+		order = "asc"
+	}
+
+	getContributorsRequest := struct {
+		Limit  int    `valid:"int"`
+		Page   int    `valid:"int"`
+		Order  string `valid:"in(asc|desc)"`
+		SortBy string `valid:"in(createdAt|updatedAt|name)"`
+	}{
+		Limit:  limit,
+		Page:   page,
+		SortBy: sortby,
+		Order:  order,
+	}
+
+	if ok, err := govalidator.ValidateStruct(getContributorsRequest); !ok || err != nil {
+		RenderError(res, ErrInvalidRequest, err.Error())
+		return
+	}
+
+	contributors, contributorsCount, err := h.ContributorService.GetContributors(chronicle.PagingOptions{
+		Limit:  limit,
+		Offset: (page - 1) * limit,
+		SortBy: sortby,
+		Order:  order,
+	})
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      getContributorsRequest,
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Getting Contributors")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	totalPage := int(math.Ceil(float64(contributorsCount) / float64(limit)))
+	render.Negotiate(res, req, http.StatusOK, map[string]interface{}{
+		"status":       http.StatusOK,
+		"contributors": contributors,
+		"pagination": map[string]interface{}{
+			"totalItems":   contributorsCount,
+			"page":         page,
+			"itemsPerPage": limit,
+			"totalPage":    totalPage,
+		},
+	}, contributors)
+}
+
+func (h *ContributorHandler) createContributor(res http.ResponseWriter, req *http.Request) {
+	// Read Body, limit to 1 MB //
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, 1048576))
+	if err != nil {
+		RenderError(res, ErrFailedToReadBody)
+		return
+	}
+
+	createContributorRequest := struct {
+		Name  string `json:"name" valid:"required"`
+		Bio   string `json:"bio" valid:"-"`
+		Photo string `json:"photo" valid:"url,optional"`
+	}{}
+
+	// Deserialize
+	if err := json.Unmarshal(body, &createContributorRequest); err != nil {
+		RenderError(res, ErrFailedToUnmarshalJSON)
+		return
+	}
+
+	if err := req.Body.Close(); err != nil {
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	if ok, err := govalidator.ValidateStruct(createContributorRequest); !ok || err != nil {
+		RenderError(res, ErrInvalidRequest, err.Error())
+		return
+	}
+
+	newContributor := chronicle.Contributor{
+		Name:  createContributorRequest.Name,
+		Slug:  slugify(createContributorRequest.Name),
+		Bio:   createContributorRequest.Bio,
+		Photo: createContributorRequest.Photo,
+	}
+
+	createdContributor, err := h.ContributorService.CreateContributor(newContributor)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      createContributorRequest,
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Creating Contributor")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	render.JSON(res, http.StatusCreated, map[string]interface{}{
+		"status":      http.StatusCreated,
+		"contributor": createdContributor,
+	})
+}
+
+func (h *ContributorHandler) getContributorByID(res http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	contributorId, _ := strconv.Atoi(params["id"])
+	foundContributor, err := h.ContributorService.GetContributorByID(contributorId)
+
+	if err != nil && err == contributor.ErrNoContributorFound {
+		renderNoContributorFound(res, err)
+		return
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      contributorId,
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Getting Contributor By ID")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	render.JSON(res, http.StatusOK, map[string]interface{}{
+		"status":      http.StatusOK,
+		"contributor": foundContributor,
+	})
+}
+
+func (h *ContributorHandler) updateContributor(res http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	// Read Body, limit to 1 MB //
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, 1048576))
+	if err != nil {
+		RenderError(res, ErrFailedToReadBody)
+		return
+	}
+
+	// omitted fields are left untouched
+	updateContributorRequest := struct {
+		Name  string  `json:"name" valid:"-"`
+		Bio   *string `json:"bio" valid:"-"`
+		Photo *string `json:"photo" valid:"url,optional"`
+	}{}
+
+	// Deserialize
+	if err := json.Unmarshal(body, &updateContributorRequest); err != nil {
+		RenderError(res, ErrFailedToUnmarshalJSON)
+		return
+	}
+
+	if err := req.Body.Close(); err != nil {
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	if ok, err := govalidator.ValidateStruct(updateContributorRequest); !ok || err != nil {
+		RenderError(res, ErrInvalidRequest, err.Error())
+		return
+	}
+
+	contributorId, _ := strconv.Atoi(params["id"])
+	foundContributor, err := h.ContributorService.GetContributorByID(contributorId)
+
+	if err != nil && err == contributor.ErrNoContributorFound {
+		renderNoContributorFound(res, err)
+		return
+	}
+
+	if updateContributorRequest.Name != "" {
+		foundContributor.Name = updateContributorRequest.Name
+		foundContributor.Slug = slugify(updateContributorRequest.Name)
+	}
+
+	if updateContributorRequest.Bio != nil {
+		foundContributor.Bio = *updateContributorRequest.Bio
+	}
+
+	if updateContributorRequest.Photo != nil {
+		foundContributor.Photo = *updateContributorRequest.Photo
+	}
+
+	updatedContributor, err := h.ContributorService.UpdateContributor(foundContributor)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      updateContributorRequest,
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Updating Contributor")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	render.JSON(res, http.StatusOK, map[string]interface{}{
+		"status":      http.StatusOK,
+		"contributor": updatedContributor,
+	})
+}
+
+func (h *ContributorHandler) deleteContributorByID(res http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	contributorId, _ := strconv.Atoi(params["id"])
+	err := h.ContributorService.DeleteContributorByID(contributorId)
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      contributorId,
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Delete Contributor By ID")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	render.JSON(res, http.StatusOK, map[string]interface{}{
+		"status":  http.StatusOK,
+		"message": "Contributor Deleted",
+	})
+}
+
+func (h *ContributorHandler) getContributorBySlug(res http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	slug := params["slug"]
+	foundContributor, err := h.ContributorService.GetContributorBySlug(slug)
+
+	if err != nil && err == contributor.ErrNoContributorFound {
+		renderNoContributorFound(res, err)
+		return
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      slug,
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Getting Contributor By Slug")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	render.JSON(res, http.StatusOK, map[string]interface{}{
+		"status":      http.StatusOK,
+		"contributor": foundContributor,
+	})
+}
+
+func (h *ContributorHandler) getContributorStories(res http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	slug := params["slug"]
+
+	// Pagination
+	limit, _ := strconv.Atoi(req.URL.Query().Get("limit"))
+	if limit == 0 {
+		limit = 20
+	}
+	page, _ := strconv.Atoi(req.URL.Query().Get("page"))
+	if page <= 0 {
+		page = 1
+	}
+	sortby := req.URL.Query().Get("sort-by")
+	if sortby == "" {
+		sortby = "updatedAt"
+	}
+	order := req.URL.Query().Get("order")
+	if order == "" {
+		order = "desc"
+	}
+
+	getContributorStoriesRequest := struct {
+		Slug   string
+		Limit  int    `valid:"int"`
+		Page   int    `valid:"int"`
+		Order  string `valid:"in(asc|desc)"`
+		SortBy string `valid:"in(createdAt|updatedAt)"`
+	}{
+		Slug:   slug,
+		Limit:  limit,
+		Page:   page,
+		SortBy: sortby,
+		Order:  order,
+	}
+
+	if ok, err := govalidator.ValidateStruct(getContributorStoriesRequest); !ok || err != nil {
+		RenderError(res, ErrInvalidRequest, err.Error())
+		return
+	}
+
+	stories, storiesCount, err := h.ContributorService.GetContributorStories(slug, chronicle.PagingOptions{
+		Limit:  limit,
+		Offset: (page - 1) * limit,
+		SortBy: sortby,
+		Order:  order,
+	})
+
+	if err != nil && err == contributor.ErrNoContributorFound {
+		renderNoContributorFound(res, err)
+		return
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      getContributorStoriesRequest,
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Getting Contributor Stories")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	totalPage := int(math.Ceil(float64(storiesCount) / float64(limit)))
+	stories = applyPendingEngagements(h.EngagementService, req, stories)
+	render.Negotiate(res, req, http.StatusOK, map[string]interface{}{
+		"status":  http.StatusOK,
+		"stories": stories,
+		"pagination": map[string]interface{}{
+			"totalItems":   storiesCount,
+			"page":         page,
+			"itemsPerPage": limit,
+			"totalPage":    totalPage,
+		},
+	}, stories)
+}
+
+func renderNoContributorFound(res http.ResponseWriter, err error) {
+	render.JSON(res, http.StatusNotFound, map[string]interface{}{
+		"status": http.StatusNotFound,
+		"error": map[string]interface{}{
+			"code":    "ErrNoContributorFound",
+			"message": err.Error(),
+		},
+	})
+}
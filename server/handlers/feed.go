@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/AdhityaRamadhanus/chronicle"
+	"github.com/AdhityaRamadhanus/chronicle/feed"
+	"github.com/AdhityaRamadhanus/chronicle/server/middlewares"
+	"github.com/AdhityaRamadhanus/chronicle/server/render"
+	"github.com/AdhityaRamadhanus/chronicle/story"
+	topic "github.com/AdhityaRamadhanus/chronicle/topic"
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+type FeedHandler struct {
+	StoryService story.Service
+	TopicService topic.Service
+	CacheService chronicle.CacheService
+}
+
+//RegisterRoutes feeds have no routes under /api
+func (h FeedHandler) RegisterRoutes(router *mux.Router) {}
+
+//RegisterRootRoutes feed readers and aggregators look for feeds at the root and cannot send bearer token
+func (h FeedHandler) RegisterRootRoutes(router *mux.Router) {
+	cacheMiddleware := middlewares.Cache(h.CacheService)
+
+	router.HandleFunc("/feeds/stories.{format:rss|atom}", cacheMiddleware("300s", h.getStoriesFeed)).Methods("GET")
+	router.HandleFunc("/feeds/topics/{slug}.{format:rss|atom}", cacheMiddleware("300s", h.getTopicFeed)).Methods("GET")
+}
+
+func (h *FeedHandler) getStoriesFeed(res http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	limit := parseFeedLimit(req)
+
+	stories, err := h.StoryService.GetFeedStories(0, limit)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      params,
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Getting Stories Feed")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	channel := feedChannel(req)
+	renderFeed(res, params["format"], channel, stories)
+}
+
+func (h *FeedHandler) getTopicFeed(res http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	slug := params["slug"]
+	limit := parseFeedLimit(req)
+
+	foundTopic, err := h.TopicService.GetTopicBySlug(slug)
+
+	// renamed topic keep answering its former slugs
+	if err != nil && err == topic.ErrNoTopicFound {
+		movedTopic, movedErr := h.TopicService.GetTopicByFormerSlug(slug)
+		if movedErr == nil {
+			extension := "." + params["format"]
+			RenderMovedPermanently(res, req, slug+extension, movedTopic.Slug+extension)
+			return
+		}
+		if movedErr != topic.ErrNoTopicFound {
+			err = movedErr
+		}
+	}
+
+	if err != nil && err == topic.ErrNoTopicFound {
+		render.JSON(res, http.StatusNotFound, map[string]interface{}{
+			"status": http.StatusNotFound,
+			"error": map[string]interface{}{
+				"code":    "ErrNoTopicFound",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      params,
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Getting Topic Feed")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	stories, err := h.StoryService.GetFeedStories(foundTopic.ID, limit)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      params,
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Getting Topic Feed")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	channel := feedChannel(req)
+	channel.Title += " - " + foundTopic.Name
+	channel.Link = strings.Replace(viper.GetString("site.topic_url"), "{slug}", foundTopic.Slug, -1)
+	renderFeed(res, params["format"], channel, stories)
+}
+
+// parseFeedLimit read limit of feed items, feeds are kept small whatever is asked
+func parseFeedLimit(req *http.Request) int {
+	limit, _ := strconv.Atoi(req.URL.Query().Get("limit"))
+	if limit <= 0 || limit > 100 {
+		return 20
+	}
+	return limit
+}
+
+// feedChannel describe the feed requested by req from feed config, links are absolute as feeds are read out of the site
+func feedChannel(req *http.Request) feed.Channel {
+	apiURL := strings.TrimSuffix(viper.GetString("site.api_url"), "/")
+	return feed.Channel{
+		Title:       viper.GetString("feed.title"),
+		Description: viper.GetString("feed.description"),
+		Link:        viper.GetString("site.url"),
+		FeedURL:     apiURL + req.URL.Path,
+		StoryURL:    viper.GetString("site.story_url"),
+		MediaURL:    apiURL + "/api/media/{id}/original",
+	}
+}
+
+func renderFeed(res http.ResponseWriter, format string, channel feed.Channel, stories chronicle.Stories) {
+	if format == "atom" {
+		render.XML(res, http.StatusOK, feed.AtomContentType, feed.NewAtom(channel, stories))
+		return
+	}
+
+	render.XML(res, http.StatusOK, feed.RSSContentType, feed.NewRSS(channel, stories))
+}
@@ -0,0 +1,305 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/AdhityaRamadhanus/chronicle"
+	"github.com/AdhityaRamadhanus/chronicle/media"
+	"github.com/AdhityaRamadhanus/chronicle/server/internal/contextkey"
+	"github.com/AdhityaRamadhanus/chronicle/server/middlewares"
+	"github.com/AdhityaRamadhanus/chronicle/server/render"
+	"github.com/asaskevich/govalidator"
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+type MediaHandler struct {
+	MediaService media.Service
+	CacheService chronicle.CacheService
+}
+
+func (h MediaHandler) RegisterRoutes(router *mux.Router) {
+	authMiddleware := middlewares.Authenticate
+	cacheMiddleware := middlewares.Cache(h.CacheService)
+
+	router.HandleFunc("/media/", authMiddleware(cacheMiddleware("60s", h.getMediaAssets))).Methods("GET")
+	router.HandleFunc("/media/upload", authMiddleware(h.uploadMedia)).Methods("POST")
+
+	router.HandleFunc("/media/{id:[0-9]+}", authMiddleware(cacheMiddleware("60s", h.getMediaAssetByID))).Methods("GET")
+	router.HandleFunc("/media/{id:[0-9]+}/delete", authMiddleware(h.deleteMediaAssetByID)).Methods("DELETE")
+
+	// files are embedded by browsers which cannot send bearer token
+	router.HandleFunc("/media/{id:[0-9]+}/{rendition}", h.getMediaRendition).Methods("GET")
+}
+
+func (h *MediaHandler) getMediaAssets(res http.ResponseWriter, req *http.Request) {
+	// Pagination
+	limit, _ := strconv.Atoi(req.URL.Query().Get("limit"))
+	if limit == 0 {
+		limit = 20
+	}
+	page, _ := strconv.Atoi(req.URL.Query().Get("page"))
+	if page <= 0 {
+		page = 1
+	}
+	sortby := req.URL.Query().Get("sort-by")
+	if sortby == "" {
+		sortby = "createdAt"
+	}
+	order := req.URL.Query().Get("order")
+	if order == "" {
+		order = "desc"
+	}
+
+	getMediaAssetsRequest := struct {
+		Limit  int    `valid:"int"`
+		Page   int    `valid:"int"`
+		Order  string `valid:"in(asc|desc)"`
+		SortBy string `valid:"in(createdAt|updatedAt|size)"`
+	}{
+		Limit:  limit,
+		Page:   page,
+		SortBy: sortby,
+		Order:  order,
+	}
+
+	if ok, err := govalidator.ValidateStruct(getMediaAssetsRequest); !ok || err != nil {
+		RenderError(res, ErrInvalidRequest, err.Error())
+		return
+	}
+
+	assets, assetsCount, err := h.MediaService.GetMediaAssets(chronicle.PagingOptions{
+		Limit:  limit,
+		Offset: (page - 1) * limit,
+		SortBy: sortby,
+		Order:  order,
+	})
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      getMediaAssetsRequest,
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Getting Media Assets")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	totalPage := int(math.Ceil(float64(assetsCount) / float64(limit)))
+	render.Negotiate(res, req, http.StatusOK, map[string]interface{}{
+		"status": http.StatusOK,
+		"media":  assets,
+		"pagination": map[string]interface{}{
+			"totalItems":   assetsCount,
+			"page":         page,
+			"itemsPerPage": limit,
+			"totalPage":    totalPage,
+		},
+	}, assets)
+}
+
+func (h *MediaHandler) uploadMedia(res http.ResponseWriter, req *http.Request) {
+	maxUploadSize := viper.GetInt64("media.max_upload_size")
+	if maxUploadSize <= 0 {
+		maxUploadSize = 10485760
+	}
+
+	// Read multipart body, limit to max upload size //
+	req.Body = http.MaxBytesReader(res, req.Body, maxUploadSize)
+	if err := req.ParseMultipartForm(maxUploadSize); err != nil {
+		RenderError(res, ErrInvalidRequest, "file:", err.Error())
+		return
+	}
+	defer req.MultipartForm.RemoveAll()
+
+	file, fileHeader, err := req.FormFile("file")
+	if err != nil {
+		RenderError(res, ErrInvalidRequest, "file:", err.Error())
+		return
+	}
+	defer file.Close()
+
+	content, err := ioutil.ReadAll(io.LimitReader(file, maxUploadSize))
+	if err != nil {
+		RenderError(res, ErrFailedToReadBody)
+		return
+	}
+
+	uploadMediaRequest := struct {
+		Filename string `valid:"required"`
+		Caption  string `valid:"-"`
+		Credit   string `valid:"-"`
+		AltText  string `valid:"-"`
+		FocalX   string `valid:"float,optional"`
+		FocalY   string `valid:"float,optional"`
+	}{
+		Filename: fileHeader.Filename,
+		Caption:  req.FormValue("caption"),
+		Credit:   req.FormValue("credit"),
+		AltText:  req.FormValue("altText"),
+		FocalX:   req.FormValue("focalX"),
+		FocalY:   req.FormValue("focalY"),
+	}
+
+	if ok, err := govalidator.ValidateStruct(uploadMediaRequest); !ok || err != nil {
+		RenderError(res, ErrInvalidRequest, err.Error())
+		return
+	}
+
+	// focal point default to the center of image
+	focalX, err := parseFocalPoint(uploadMediaRequest.FocalX)
+	if err != nil {
+		RenderError(res, ErrInvalidRequest, "focalX:", err.Error())
+		return
+	}
+
+	focalY, err := parseFocalPoint(uploadMediaRequest.FocalY)
+	if err != nil {
+		RenderError(res, ErrInvalidRequest, "focalY:", err.Error())
+		return
+	}
+
+	newAsset := chronicle.MediaAsset{
+		Filename: uploadMediaRequest.Filename,
+		Caption:  uploadMediaRequest.Caption,
+		Credit:   uploadMediaRequest.Credit,
+		AltText:  uploadMediaRequest.AltText,
+		FocalX:   focalX,
+		FocalY:   focalY,
+	}
+
+	createdAsset, err := h.MediaService.UploadMedia(newAsset, content)
+	if err != nil && err == media.ErrUnsupportedMediaType {
+		render.JSON(res, http.StatusUnsupportedMediaType, map[string]interface{}{
+			"status": http.StatusUnsupportedMediaType,
+			"error": map[string]interface{}{
+				"code":    "ErrUnsupportedMediaType",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      uploadMediaRequest,
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Uploading Media")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	render.JSON(res, http.StatusCreated, map[string]interface{}{
+		"status": http.StatusCreated,
+		"media":  createdAsset,
+	})
+}
+
+func (h *MediaHandler) getMediaAssetByID(res http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	assetId, _ := strconv.Atoi(params["id"])
+	foundAsset, err := h.MediaService.GetMediaAssetByID(assetId)
+
+	if err != nil && err == media.ErrNoMediaFound {
+		renderNoMediaFound(res, err)
+		return
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      assetId,
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Getting Media By ID")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	render.JSON(res, http.StatusOK, map[string]interface{}{
+		"status": http.StatusOK,
+		"media":  foundAsset,
+	})
+}
+
+func (h *MediaHandler) getMediaRendition(res http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	assetId, _ := strconv.Atoi(params["id"])
+	foundRendition, blob, err := h.MediaService.OpenMediaRendition(assetId, params["rendition"])
+
+	if err != nil && (err == media.ErrNoMediaFound || err == media.ErrNoRenditionFound) {
+		renderNoMediaFound(res, err)
+		return
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      params,
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Getting Media Rendition")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+	defer blob.Close()
+
+	// a stored blob is never rewritten, its key change on every upload
+	res.Header().Set("Content-Type", foundRendition.MimeType)
+	res.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	res.WriteHeader(http.StatusOK)
+	io.Copy(res, blob)
+}
+
+func (h *MediaHandler) deleteMediaAssetByID(res http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	assetId, _ := strconv.Atoi(params["id"])
+	err := h.MediaService.DeleteMediaAssetByID(assetId)
+
+	if err != nil && err == media.ErrNoMediaFound {
+		renderNoMediaFound(res, err)
+		return
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      assetId,
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Delete Media By ID")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	render.JSON(res, http.StatusOK, map[string]interface{}{
+		"status":  http.StatusOK,
+		"message": "Media Deleted",
+	})
+}
+
+func parseFocalPoint(value string) (float64, error) {
+	if value == "" {
+		return 0.5, nil
+	}
+
+	focalPoint, err := strconv.ParseFloat(value, 64)
+	if err != nil || focalPoint < 0 || focalPoint > 1 {
+		return 0, errors.New("must be a number between 0 and 1")
+	}
+
+	return focalPoint, nil
+}
+
+func renderNoMediaFound(res http.ResponseWriter, err error) {
+	render.JSON(res, http.StatusNotFound, map[string]interface{}{
+		"status": http.StatusNotFound,
+		"error": map[string]interface{}{
+			"code":    "ErrNoMediaFound",
+			"message": err.Error(),
+		},
+	})
+}
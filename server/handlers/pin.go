@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/AdhityaRamadhanus/chronicle"
+	"github.com/AdhityaRamadhanus/chronicle/pin"
+	"github.com/AdhityaRamadhanus/chronicle/server/internal/contextkey"
+	"github.com/AdhityaRamadhanus/chronicle/server/middlewares"
+	"github.com/AdhityaRamadhanus/chronicle/server/render"
+	"github.com/asaskevich/govalidator"
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+type PinHandler struct {
+	PinService   pin.Service
+	CacheService chronicle.CacheService
+}
+
+func (h PinHandler) RegisterRoutes(router *mux.Router) {
+	authMiddleware := middlewares.Authenticate
+
+	router.HandleFunc("/pins/", authMiddleware(h.getPins)).Methods("GET")
+	router.HandleFunc("/pins/insert", authMiddleware(h.pinStory)).Methods("POST")
+	router.HandleFunc("/pins/{id:[0-9]+}/delete", authMiddleware(h.unpinStory)).Methods("DELETE")
+}
+
+func (h *PinHandler) getPins(res http.ResponseWriter, req *http.Request) {
+	// topic 0 or omitted list pins of the listing of all stories
+	topic := req.URL.Query().Get("topic")
+	if topic == "" {
+		topic = "0"
+	}
+
+	getPinsRequest := struct {
+		Topic string `valid:"int"`
+	}{
+		Topic: topic,
+	}
+
+	if ok, err := govalidator.ValidateStruct(getPinsRequest); !ok || err != nil {
+		RenderError(res, ErrInvalidRequest, err.Error())
+		return
+	}
+
+	topicId, _ := strconv.Atoi(topic)
+	pins, err := h.PinService.GetPins(topicId)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      getPinsRequest,
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Getting Pins")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	render.Negotiate(res, req, http.StatusOK, map[string]interface{}{
+		"status": http.StatusOK,
+		"pins":   pins,
+	}, pins)
+}
+
+func (h *PinHandler) pinStory(res http.ResponseWriter, req *http.Request) {
+	// Read Body, limit to 1 MB //
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, 1048576))
+	if err != nil {
+		RenderError(res, ErrFailedToReadBody)
+		return
+	}
+
+	// topicId 0 or omitted pin the story to the listing of all stories
+	pinStoryRequest := struct {
+		StoryID   int     `json:"storyId" valid:"required"`
+		TopicID   int     `json:"topicId" valid:"-"`
+		Slot      int     `json:"slot" valid:"-"`
+		ExpiresAt *string `json:"expiresAt" valid:"-"`
+	}{}
+
+	// Deserialize
+	if err := json.Unmarshal(body, &pinStoryRequest); err != nil {
+		RenderError(res, ErrFailedToUnmarshalJSON)
+		return
+	}
+
+	if err := req.Body.Close(); err != nil {
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	if ok, err := govalidator.ValidateStruct(pinStoryRequest); !ok || err != nil {
+		RenderError(res, ErrInvalidRequest, err.Error())
+		return
+	}
+
+	expiresAt, err := parseSchedule(pinStoryRequest.ExpiresAt, nil)
+	if err != nil {
+		RenderError(res, ErrInvalidRequest, "expiresAt:", err.Error())
+		return
+	}
+
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		RenderError(res, ErrInvalidRequest, "expiresAt: must be in the future")
+		return
+	}
+
+	createdPin, err := h.PinService.PinStory(chronicle.StoryPin{
+		StoryID:   pinStoryRequest.StoryID,
+		TopicID:   pinStoryRequest.TopicID,
+		Slot:      pinStoryRequest.Slot,
+		ExpiresAt: expiresAt,
+	})
+
+	if err != nil && err == pin.ErrUnknownStory {
+		RenderError(res, ErrInvalidRequest, "storyId:", err.Error())
+		return
+	}
+
+	if err != nil && err == pin.ErrUnknownTopic {
+		RenderError(res, ErrInvalidRequest, "topicId:", err.Error())
+		return
+	}
+
+	if err != nil && (err == pin.ErrInvalidPinSlot || err == pin.ErrPinSlotTaken) {
+		RenderError(res, ErrInvalidRequest, "slot:", err.Error())
+		return
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      pinStoryRequest,
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Pinning Story")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	h.invalidateStoriesCache(req)
+	render.JSON(res, http.StatusCreated, map[string]interface{}{
+		"status": http.StatusCreated,
+		"pin":    createdPin,
+	})
+}
+
+func (h *PinHandler) unpinStory(res http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	pinId, _ := strconv.Atoi(params["id"])
+	err := h.PinService.UnpinStory(pinId)
+
+	if err != nil && err == pin.ErrNoPinFound {
+		render.JSON(res, http.StatusNotFound, map[string]interface{}{
+			"status": http.StatusNotFound,
+			"error": map[string]interface{}{
+				"code":    "ErrNoPinFound",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      pinId,
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Unpinning Story")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	h.invalidateStoriesCache(req)
+	render.JSON(res, http.StatusOK, map[string]interface{}{
+		"status":  http.StatusOK,
+		"message": "Story Unpinned",
+	})
+}
+
+// invalidateStoriesCache drop cached story listings so pins take effect right away, an expiring pin still linger until the cache expire
+func (h *PinHandler) invalidateStoriesCache(req *http.Request) {
+	if err := middlewares.InvalidateCache(h.CacheService, "/api/stories", "/api/topics"); err != nil {
+		log.WithFields(log.Fields{
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Warn("Error Handler Invalidating Pin Cache")
+	}
+}
@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/AdhityaRamadhanus/chronicle/server/render"
+)
+
+//RenderMovedPermanently point client requesting a former slug to the url of the current one
+func RenderMovedPermanently(res http.ResponseWriter, req *http.Request, formerSlug string, currentSlug string) error {
+	location := strings.TrimSuffix(req.URL.Path, formerSlug) + currentSlug
+	if req.URL.RawQuery != "" {
+		location += "?" + req.URL.RawQuery
+	}
+
+	res.Header().Set("Location", location)
+	return render.JSON(res, http.StatusMovedPermanently, map[string]interface{}{
+		"status":   http.StatusMovedPermanently,
+		"location": location,
+	})
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/AdhityaRamadhanus/chronicle"
+	"github.com/AdhityaRamadhanus/chronicle/server/middlewares"
+	"github.com/AdhityaRamadhanus/chronicle/server/render"
+	"github.com/AdhityaRamadhanus/chronicle/sitemap"
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+type SitemapHandler struct {
+	SitemapService sitemap.Service
+	CacheService   chronicle.CacheService
+}
+
+//RegisterRoutes sitemaps have no routes under /api
+func (h SitemapHandler) RegisterRoutes(router *mux.Router) {}
+
+//RegisterRootRoutes crawlers look for sitemaps at the root and cannot send bearer token
+func (h SitemapHandler) RegisterRootRoutes(router *mux.Router) {
+	cacheMiddleware := middlewares.Cache(h.CacheService)
+
+	router.HandleFunc("/sitemap.xml", cacheMiddleware("3600s", h.getSitemapIndex)).Methods("GET")
+	router.HandleFunc("/sitemap-news.xml", cacheMiddleware("300s", h.getNewsSitemap)).Methods("GET")
+	router.HandleFunc("/sitemap-stories-{page:[0-9]+}.xml", cacheMiddleware("3600s", h.getStoriesSitemap)).Methods("GET")
+	router.HandleFunc("/sitemap-topics-{page:[0-9]+}.xml", cacheMiddleware("3600s", h.getTopicsSitemap)).Methods("GET")
+}
+
+func (h *SitemapHandler) getSitemapIndex(res http.ResponseWriter, req *http.Request) {
+	storyPages, topicPages, err := h.SitemapService.GetIndex()
+	if err != nil {
+		log.WithFields(log.Fields{
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Getting Sitemap Index")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	render.XML(res, http.StatusOK, sitemap.ContentType, sitemap.NewIndex(sitemapSite(), storyPages, topicPages))
+}
+
+func (h *SitemapHandler) getStoriesSitemap(res http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	page, _ := strconv.Atoi(params["page"])
+	stories, err := h.SitemapService.GetStoriesPage(page)
+
+	if err != nil && err == sitemap.ErrNoSitemapPageFound {
+		renderNoSitemapPageFound(res, err)
+		return
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      params,
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Getting Stories Sitemap")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	render.XML(res, http.StatusOK, sitemap.ContentType, sitemap.NewStoriesURLSet(sitemapSite(), stories))
+}
+
+func (h *SitemapHandler) getTopicsSitemap(res http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	page, _ := strconv.Atoi(params["page"])
+	topics, err := h.SitemapService.GetTopicsPage(page)
+
+	if err != nil && err == sitemap.ErrNoSitemapPageFound {
+		renderNoSitemapPageFound(res, err)
+		return
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      params,
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Getting Topics Sitemap")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	render.XML(res, http.StatusOK, sitemap.ContentType, sitemap.NewTopicsURLSet(sitemapSite(), topics))
+}
+
+func (h *SitemapHandler) getNewsSitemap(res http.ResponseWriter, req *http.Request) {
+	stories, err := h.SitemapService.GetNewsStories(time.Now())
+	if err != nil {
+		log.WithFields(log.Fields{
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Getting News Sitemap")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	render.XML(res, http.StatusOK, sitemap.ContentType, sitemap.NewNewsURLSet(sitemapSite(), stories))
+}
+
+func sitemapSite() sitemap.Site {
+	return sitemap.Site{
+		Name:     viper.GetString("site.name"),
+		Language: viper.GetString("site.language"),
+		StoryURL: viper.GetString("site.story_url"),
+		TopicURL: viper.GetString("site.topic_url"),
+		APIURL:   viper.GetString("site.api_url"),
+	}
+}
+
+func renderNoSitemapPageFound(res http.ResponseWriter, err error) {
+	render.JSON(res, http.StatusNotFound, map[string]interface{}{
+		"status": http.StatusNotFound,
+		"error": map[string]interface{}{
+			"code":    "ErrNoSitemapPageFound",
+			"message": err.Error(),
+		},
+	})
+}
@@ -0,0 +1,14 @@
+package handlers
+
+import (
+	"github.com/AdhityaRamadhanus/chronicle"
+	"github.com/spf13/viper"
+)
+
+// slugify create slug of text using the slug settings of config
+func slugify(text string) string {
+	return chronicle.SlugifyWithOptions(text, chronicle.SlugOptions{
+		MaxLength: viper.GetInt("slug.max_length"),
+		StopWords: viper.GetStringSlice("slug.stop_words"),
+	})
+}
@@ -1,14 +1,22 @@
 package handlers
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"math"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/AdhityaRamadhanus/chronicle"
+	"github.com/AdhityaRamadhanus/chronicle/content"
+	"github.com/AdhityaRamadhanus/chronicle/engagement"
 	"github.com/AdhityaRamadhanus/chronicle/server/internal/contextkey"
 	"github.com/AdhityaRamadhanus/chronicle/server/middlewares"
 	"github.com/AdhityaRamadhanus/chronicle/server/render"
@@ -19,8 +27,9 @@ import (
 )
 
 type StoryHandler struct {
-	StoryService story.Service
-	CacheService chronicle.CacheService
+	StoryService      story.Service
+	EngagementService engagement.Service
+	CacheService      chronicle.CacheService
 }
 
 func (h StoryHandler) RegisterRoutes(router *mux.Router) {
@@ -29,10 +38,26 @@ func (h StoryHandler) RegisterRoutes(router *mux.Router) {
 
 	router.HandleFunc("/stories/", authMiddleware(cacheMiddleware("60s", h.getStories))).Methods("GET")
 	router.HandleFunc("/stories/insert", authMiddleware(h.createStory)).Methods("POST")
+	router.HandleFunc("/stories/scheduled", authMiddleware(h.getScheduledStories)).Methods("GET")
+	router.HandleFunc("/stories/search", authMiddleware(cacheMiddleware("60s", h.searchStories))).Methods("GET")
+	router.HandleFunc("/stories/trending", authMiddleware(cacheMiddleware("60s", h.getTrendingStories))).Methods("GET")
 
 	router.HandleFunc("/stories/{id:[0-9]+}", authMiddleware(cacheMiddleware("60s", h.getStoryByID))).Methods("GET")
 	router.HandleFunc("/stories/{id:[0-9]+}/update", authMiddleware(h.updateStory)).Methods("PATCH")
 	router.HandleFunc("/stories/{id:[0-9]+}/delete", authMiddleware(h.deleteStoryByID)).Methods("DELETE")
+	router.HandleFunc("/stories/{id:[0-9]+}/related", authMiddleware(cacheMiddleware("300s", h.getRelatedStories))).Methods("GET")
+
+	// cached responses carry counts of when they were cached, live counts are never cached
+	router.HandleFunc("/stories/{id:[0-9]+}/engagements", authMiddleware(h.getStoryEngagements)).Methods("GET")
+	router.HandleFunc("/stories/{id:[0-9]+}/{engagement:views|likes|shares}", authMiddleware(h.engageStory)).Methods("POST")
+
+	router.HandleFunc("/stories/{id:[0-9]+}/transitions", authMiddleware(h.getStoryTransitions)).Methods("GET")
+	router.HandleFunc("/stories/{id:[0-9]+}/transitions/{name}", authMiddleware(h.transitionStory)).Methods("POST")
+
+	router.HandleFunc("/stories/{id:[0-9]+}/revisions", authMiddleware(h.getStoryRevisions)).Methods("GET")
+	router.HandleFunc("/stories/{id:[0-9]+}/revisions/diff", authMiddleware(h.diffStoryRevisions)).Methods("GET")
+	router.HandleFunc("/stories/{id:[0-9]+}/revisions/{revision:[0-9]+}", authMiddleware(h.getStoryRevision)).Methods("GET")
+	router.HandleFunc("/stories/{id:[0-9]+}/revisions/{revision:[0-9]+}/restore", authMiddleware(h.restoreStoryRevision)).Methods("POST")
 
 	router.HandleFunc("/stories/{slug}", authMiddleware(cacheMiddleware("60s", h.getStoryBySlug))).Methods("GET")
 }
@@ -59,14 +84,17 @@ func (h *StoryHandler) getStories(res http.ResponseWriter, req *http.Request) {
 	// filter
 	status := req.URL.Query().Get("status")
 	topic := req.URL.Query().Get("topic")
+	includeDescendants := req.URL.Query().Get("include-descendants")
 
 	getStoriesRequest := struct {
 		Limit  int    `valid:"int"`
 		Page   int    `valid:"int"`
 		Order  string `valid:"in(asc|desc)"`
 		SortBy string `valid:"in(createdAt|updatedAt)"`
-		Status string `valid:"in(Draft|Deleted|Publish)"`
+		Status string `valid:"in(Draft|InReview|Approved|Publish|Archived|Deleted)"`
 		Topic  string `valid:"int"`
+
+		IncludeDescendants string `valid:"in(true|false)"`
 	}{
 		Limit:  limit,
 		Page:   page,
@@ -74,6 +102,8 @@ func (h *StoryHandler) getStories(res http.ResponseWriter, req *http.Request) {
 		Order:  order,
 		Status: status,
 		Topic:  topic,
+
+		IncludeDescendants: includeDescendants,
 	}
 
 	if ok, err := govalidator.ValidateStruct(getStoriesRequest); !ok || err != nil {
@@ -85,6 +115,8 @@ func (h *StoryHandler) getStories(res http.ResponseWriter, req *http.Request) {
 		chronicle.StoryFilterOptions{
 			Status: status,
 			Topic:  topic,
+
+			IncludeDescendants: includeDescendants == "true",
 		},
 		chronicle.PagingOptions{
 			Limit:  limit,
@@ -105,8 +137,10 @@ func (h *StoryHandler) getStories(res http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	stories = h.applyPendingEngagements(req, stories)
+
 	totalPage := int(math.Ceil(float64(storiesCount) / float64(limit)))
-	render.JSON(res, http.StatusOK, map[string]interface{}{
+	render.Negotiate(res, req, http.StatusOK, map[string]interface{}{
 		"status":  http.StatusOK,
 		"stories": stories,
 		"pagination": map[string]interface{}{
@@ -115,7 +149,158 @@ func (h *StoryHandler) getStories(res http.ResponseWriter, req *http.Request) {
 			"itemsPerPage": limit,
 			"totalPage":    totalPage,
 		},
+	}, stories)
+}
+
+func (h *StoryHandler) getTrendingStories(res http.ResponseWriter, req *http.Request) {
+	// Pagination
+	limit, _ := strconv.Atoi(req.URL.Query().Get("limit"))
+	if limit == 0 {
+		limit = 20
+	}
+	page, _ := strconv.Atoi(req.URL.Query().Get("page"))
+	if page <= 0 {
+		page = 1
+	}
+
+	// filter
+	topic := req.URL.Query().Get("topic")
+	window := req.URL.Query().Get("window")
+	if window == "" {
+		window = chronicle.DefaultTrendingWindow
+	}
+
+	getTrendingStoriesRequest := struct {
+		Limit  int    `valid:"int"`
+		Page   int    `valid:"int"`
+		Topic  string `valid:"int"`
+		Window string `valid:"in(6h|24h|7d)"`
+	}{
+		Limit:  limit,
+		Page:   page,
+		Topic:  topic,
+		Window: window,
+	}
+
+	if ok, err := govalidator.ValidateStruct(getTrendingStoriesRequest); !ok || err != nil {
+		RenderError(res, ErrInvalidRequest, err.Error())
+		return
+	}
+
+	topicId, _ := strconv.Atoi(topic)
+	stories, storiesCount, err := h.StoryService.GetTrendingStories(topicId, window, chronicle.PagingOptions{
+		Limit:  limit,
+		Offset: (page - 1) * limit,
 	})
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      getTrendingStoriesRequest,
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Getting Trending Stories")
+
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	stories = h.applyPendingEngagements(req, stories)
+
+	totalPage := int(math.Ceil(float64(storiesCount) / float64(limit)))
+	render.Negotiate(res, req, http.StatusOK, map[string]interface{}{
+		"status":  http.StatusOK,
+		"stories": stories,
+		"window":  window,
+		"pagination": map[string]interface{}{
+			"totalItems":   storiesCount,
+			"page":         page,
+			"itemsPerPage": limit,
+			"totalPage":    totalPage,
+		},
+	}, stories)
+}
+
+func (h *StoryHandler) searchStories(res http.ResponseWriter, req *http.Request) {
+	// Pagination
+	limit, _ := strconv.Atoi(req.URL.Query().Get("limit"))
+	if limit == 0 {
+		limit = 20
+	}
+	page, _ := strconv.Atoi(req.URL.Query().Get("page"))
+	if page <= 0 {
+		page = 1
+	}
+
+	// search
+	query := req.URL.Query().Get("q")
+	language := req.URL.Query().Get("lang")
+	if language == "" {
+		language = chronicle.SearchLanguageIndonesian
+	}
+
+	// filter
+	status := req.URL.Query().Get("status")
+	topic := req.URL.Query().Get("topic")
+
+	searchStoriesRequest := struct {
+		Limit    int    `valid:"int"`
+		Page     int    `valid:"int"`
+		Query    string `valid:"required"`
+		Language string `valid:"in(id|en)"`
+		Status   string `valid:"in(Draft|InReview|Approved|Publish|Archived|Deleted)"`
+		Topic    string `valid:"int"`
+	}{
+		Limit:    limit,
+		Page:     page,
+		Query:    query,
+		Language: language,
+		Status:   status,
+		Topic:    topic,
+	}
+
+	if ok, err := govalidator.ValidateStruct(searchStoriesRequest); !ok || err != nil {
+		RenderError(res, ErrInvalidRequest, err.Error())
+		return
+	}
+
+	topicId, _ := strconv.Atoi(topic)
+	hits, hitsCount, facets, err := h.StoryService.SearchStories(
+		chronicle.StorySearchOptions{
+			Query:    query,
+			Language: language,
+			Status:   status,
+			Topic:    topicId,
+		},
+		chronicle.PagingOptions{
+			Limit:  limit,
+			Offset: (page - 1) * limit,
+		},
+	)
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      searchStoriesRequest,
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Searching Stories")
+
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	totalPage := int(math.Ceil(float64(hitsCount) / float64(limit)))
+	hits = h.applyPendingEngagementsToHits(req, hits)
+	render.Negotiate(res, req, http.StatusOK, map[string]interface{}{
+		"status":  http.StatusOK,
+		"stories": hits,
+		"facets":  facets,
+		"pagination": map[string]interface{}{
+			"totalItems":   hitsCount,
+			"page":         page,
+			"itemsPerPage": limit,
+			"totalPage":    totalPage,
+		},
+	}, hits)
 }
 
 func (h *StoryHandler) createStory(res http.ResponseWriter, req *http.Request) {
@@ -128,6 +313,10 @@ func (h *StoryHandler) createStory(res http.ResponseWriter, req *http.Request) {
 
 	createStoryRequest := struct {
 		TopicIDs []int           `json:"topics" valid:"-"`
+		Bylines  []bylineRequest `json:"bylines" valid:"-"`
+		MediaIDs []int           `json:"mediaIds" valid:"-"`
+		Tags     []string        `json:"tags" valid:"-"`
+		// deprecated, free form media kept for stories written before mediaIds, see validateLegacyMedia
 		Media    json.RawMessage `json:"media" valid:"-"`
 		Title    string          `json:"title" valid:"required"`
 		Excerpt  string          `json:"excerpt" valid:"required"`
@@ -135,6 +324,12 @@ func (h *StoryHandler) createStory(res http.ResponseWriter, req *http.Request) {
 		Reporter string          `json:"reporter" valid:"required"`
 		Editor   string          `json:"editor" valid:"required"`
 		Author   string          `json:"author" valid:"required"`
+
+		// html when empty
+		ContentFormat string `json:"contentFormat" valid:"in(html|markdown|text)"`
+
+		PublishAt   *string `json:"publishAt" valid:"-"`
+		UnpublishAt *string `json:"unpublishAt" valid:"-"`
 	}{}
 
 	// Deserialize
@@ -153,25 +348,91 @@ func (h *StoryHandler) createStory(res http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	publishAt, err := parseSchedule(createStoryRequest.PublishAt, nil)
+	if err != nil {
+		RenderError(res, ErrInvalidRequest, "publishAt:", err.Error())
+		return
+	}
+
+	unpublishAt, err := parseSchedule(createStoryRequest.UnpublishAt, nil)
+	if err != nil {
+		RenderError(res, ErrInvalidRequest, "unpublishAt:", err.Error())
+		return
+	}
+
+	if err := validateSchedule(publishAt, unpublishAt, time.Now()); err != nil {
+		RenderError(res, ErrInvalidRequest, err.Error())
+		return
+	}
+
+	mediaWarning, err := validateLegacyMedia(createStoryRequest.Media)
+	if err != nil {
+		RenderError(res, ErrInvalidRequest, "media:", err.Error())
+		return
+	}
+	if mediaWarning != "" {
+		res.Header().Set("Warning", mediaWarning)
+	}
+
 	newStoryTopics := chronicle.Topics{}
 	for _, topicId := range createStoryRequest.TopicIDs {
 		newStoryTopics = append(newStoryTopics, chronicle.Topic{ID: topicId})
 	}
 
+	newStoryBylines, err := parseBylines(createStoryRequest.Bylines)
+	if err != nil {
+		RenderError(res, ErrInvalidRequest, "bylines:", err.Error())
+		return
+	}
+
+	newStoryTags, err := tagsFromNames(createStoryRequest.Tags)
+	if err != nil {
+		RenderError(res, ErrInvalidRequest, "tags:", err.Error())
+		return
+	}
+
 	newStory := chronicle.Story{
-		Topics:   newStoryTopics,
-		Media:    createStoryRequest.Media,
-		Title:    createStoryRequest.Title,
-		Slug:     chronicle.Slugify(createStoryRequest.Title),
-		Content:  createStoryRequest.Content,
-		Excerpt:  createStoryRequest.Excerpt,
-		Reporter: createStoryRequest.Reporter,
-		Editor:   createStoryRequest.Editor,
-		Author:   createStoryRequest.Author,
-		Status:   chronicle.StoryDraftStatus,
+		Topics:      newStoryTopics,
+		Bylines:     newStoryBylines,
+		Tags:        newStoryTags,
+		MediaAssets: mediaAssetsFromIDs(createStoryRequest.MediaIDs),
+		Media:       createStoryRequest.Media,
+		Title:       createStoryRequest.Title,
+		Slug:        slugify(createStoryRequest.Title),
+		Content:     createStoryRequest.Content,
+		Excerpt:     createStoryRequest.Excerpt,
+		Reporter:    createStoryRequest.Reporter,
+		Editor:      createStoryRequest.Editor,
+		Author:      createStoryRequest.Author,
+		Status:      chronicle.StoryDraftStatus,
+
+		ContentFormat: createStoryRequest.ContentFormat,
+
+		PublishAt:   publishAt,
+		UnpublishAt: unpublishAt,
 	}
 
 	createdStory, err := h.StoryService.CreateStory(newStory)
+	if err != nil && err == story.ErrUnknownTopic {
+		RenderError(res, ErrInvalidRequest, "topics:", err.Error())
+		return
+	}
+
+	if err != nil && err == story.ErrUnknownContributor {
+		RenderError(res, ErrInvalidRequest, "bylines:", err.Error())
+		return
+	}
+
+	if err != nil && err == story.ErrUnknownMedia {
+		RenderError(res, ErrInvalidRequest, "mediaIds:", err.Error())
+		return
+	}
+
+	if err != nil && err == story.ErrUnknownContentFormat {
+		RenderError(res, ErrInvalidRequest, "contentFormat:", err.Error())
+		return
+	}
+
 	if err != nil {
 		log.WithFields(log.Fields{
 			"request":      createStoryRequest,
@@ -183,6 +444,8 @@ func (h *StoryHandler) createStory(res http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	h.invalidateStoriesCache(req)
+
 	render.JSON(res, http.StatusCreated, map[string]interface{}{
 		"status": http.StatusCreated,
 		"story":  createdStory,
@@ -199,14 +462,31 @@ func (h *StoryHandler) updateStory(res http.ResponseWriter, req *http.Request) {
 	}
 
 	updateStoryRequest := struct {
-		Status   string          `json:"status" valid:"in(Draft|Deleted|Publish)"`
+		// deprecated, free form media kept for stories written before mediaIds, see validateLegacyMedia
 		Media    json.RawMessage `json:"media" valid:"-"`
+		Status   string          `json:"status" valid:"-"`
 		Title    string          `json:"title"`
 		Excerpt  string          `json:"excerpt"`
 		Content  string          `json:"content"`
 		Reporter string          `json:"reporter"`
 		Editor   string          `json:"editor"`
 		Author   string          `json:"author"`
+
+		// content is read in the current format of story unless given
+		ContentFormat *string `json:"contentFormat" valid:"in(html|markdown|text)"`
+
+		PublishAt   *string `json:"publishAt" valid:"-"`
+		UnpublishAt *string `json:"unpublishAt" valid:"-"`
+
+		// topics replace the whole set, addTopics and removeTopics are applied after it
+		TopicIDs       *[]int `json:"topics" valid:"-"`
+		AddTopicIDs    []int  `json:"addTopics" valid:"-"`
+		RemoveTopicIDs []int  `json:"removeTopics" valid:"-"`
+
+		// bylines, tags and media replace the whole set in the given order
+		Bylines  *[]bylineRequest `json:"bylines" valid:"-"`
+		Tags     *[]string        `json:"tags" valid:"-"`
+		MediaIDs *[]int           `json:"mediaIds" valid:"-"`
 	}{}
 
 	// Deserialize
@@ -225,6 +505,20 @@ func (h *StoryHandler) updateStory(res http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	if updateStoryRequest.Status != "" {
+		RenderError(res, ErrInvalidRequest, "status: can only be changed through /stories/{id}/transitions/{name}")
+		return
+	}
+
+	mediaWarning, err := validateLegacyMedia(updateStoryRequest.Media)
+	if err != nil {
+		RenderError(res, ErrInvalidRequest, "media:", err.Error())
+		return
+	}
+	if mediaWarning != "" {
+		res.Header().Set("Warning", mediaWarning)
+	}
+
 	storyId, _ := strconv.Atoi(params["id"])
 	foundStory, err := h.StoryService.GetStoryByID(storyId)
 
@@ -245,7 +539,7 @@ func (h *StoryHandler) updateStory(res http.ResponseWriter, req *http.Request) {
 
 	if updateStoryRequest.Title != "" {
 		foundStory.Title = updateStoryRequest.Title
-		foundStory.Slug = chronicle.Slugify(updateStoryRequest.Title)
+		foundStory.Slug = slugify(updateStoryRequest.Title)
 	}
 
 	if updateStoryRequest.Excerpt != "" {
@@ -256,6 +550,10 @@ func (h *StoryHandler) updateStory(res http.ResponseWriter, req *http.Request) {
 		foundStory.Content = updateStoryRequest.Content
 	}
 
+	if updateStoryRequest.ContentFormat != nil {
+		foundStory.ContentFormat = *updateStoryRequest.ContentFormat
+	}
+
 	if updateStoryRequest.Reporter != "" {
 		foundStory.Reporter = updateStoryRequest.Reporter
 	}
@@ -268,11 +566,76 @@ func (h *StoryHandler) updateStory(res http.ResponseWriter, req *http.Request) {
 		foundStory.Author = updateStoryRequest.Author
 	}
 
-	if updateStoryRequest.Status != "" {
-		foundStory.Status = updateStoryRequest.Status
+	if updateStoryRequest.TopicIDs != nil || len(updateStoryRequest.AddTopicIDs) > 0 || len(updateStoryRequest.RemoveTopicIDs) > 0 {
+		foundStory.Topics = mergeStoryTopics(
+			foundStory.Topics,
+			updateStoryRequest.TopicIDs,
+			updateStoryRequest.AddTopicIDs,
+			updateStoryRequest.RemoveTopicIDs,
+		)
+	}
+
+	if updateStoryRequest.Bylines != nil {
+		foundStory.Bylines, err = parseBylines(*updateStoryRequest.Bylines)
+		if err != nil {
+			RenderError(res, ErrInvalidRequest, "bylines:", err.Error())
+			return
+		}
+	}
+
+	if updateStoryRequest.Tags != nil {
+		foundStory.Tags, err = tagsFromNames(*updateStoryRequest.Tags)
+		if err != nil {
+			RenderError(res, ErrInvalidRequest, "tags:", err.Error())
+			return
+		}
+	}
+
+	if updateStoryRequest.MediaIDs != nil {
+		foundStory.MediaAssets = mediaAssetsFromIDs(*updateStoryRequest.MediaIDs)
+	}
+
+	// empty string clear the schedule
+	if updateStoryRequest.PublishAt != nil || updateStoryRequest.UnpublishAt != nil {
+		foundStory.PublishAt, err = parseSchedule(updateStoryRequest.PublishAt, foundStory.PublishAt)
+		if err != nil {
+			RenderError(res, ErrInvalidRequest, "publishAt:", err.Error())
+			return
+		}
+
+		foundStory.UnpublishAt, err = parseSchedule(updateStoryRequest.UnpublishAt, foundStory.UnpublishAt)
+		if err != nil {
+			RenderError(res, ErrInvalidRequest, "unpublishAt:", err.Error())
+			return
+		}
+
+		if err := validateSchedule(foundStory.PublishAt, foundStory.UnpublishAt, time.Now()); err != nil {
+			RenderError(res, ErrInvalidRequest, err.Error())
+			return
+		}
 	}
 
 	updatedStory, err := h.StoryService.UpdateStory(foundStory)
+	if err != nil && err == story.ErrUnknownTopic {
+		RenderError(res, ErrInvalidRequest, "topics:", err.Error())
+		return
+	}
+
+	if err != nil && err == story.ErrUnknownContributor {
+		RenderError(res, ErrInvalidRequest, "bylines:", err.Error())
+		return
+	}
+
+	if err != nil && err == story.ErrUnknownMedia {
+		RenderError(res, ErrInvalidRequest, "mediaIds:", err.Error())
+		return
+	}
+
+	if err != nil && err == story.ErrUnknownContentFormat {
+		RenderError(res, ErrInvalidRequest, "contentFormat:", err.Error())
+		return
+	}
+
 	if err != nil {
 		log.WithFields(log.Fields{
 			"request":      updateStoryRequest,
@@ -283,6 +646,8 @@ func (h *StoryHandler) updateStory(res http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	h.invalidateStoriesCache(req)
+
 	render.JSON(res, http.StatusOK, map[string]interface{}{
 		"status": http.StatusOK,
 		"story":  updatedStory,
@@ -292,6 +657,18 @@ func (h *StoryHandler) updateStory(res http.ResponseWriter, req *http.Request) {
 func (h *StoryHandler) getStoryByID(res http.ResponseWriter, req *http.Request) {
 	params := mux.Vars(req)
 	storyId, _ := strconv.Atoi(params["id"])
+
+	getStoryRequest := struct {
+		Content string `valid:"in(html|markdown|text)"`
+	}{
+		Content: req.URL.Query().Get("content"),
+	}
+
+	if ok, err := govalidator.ValidateStruct(getStoryRequest); !ok || err != nil {
+		RenderError(res, ErrInvalidRequest, err.Error())
+		return
+	}
+
 	foundStory, err := h.StoryService.GetStoryByID(storyId)
 
 	if err != nil && err == story.ErrNoStoryFound {
@@ -315,12 +692,65 @@ func (h *StoryHandler) getStoryByID(res http.ResponseWriter, req *http.Request)
 		return
 	}
 
+	foundStory = h.applyPendingEngagements(req, chronicle.Stories{foundStory})[0]
+	foundStory = storyContentAs(foundStory, getStoryRequest.Content)
+
 	render.JSON(res, http.StatusOK, map[string]interface{}{
 		"status": http.StatusOK,
 		"story":  foundStory,
 	})
 }
 
+func (h *StoryHandler) getRelatedStories(res http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	storyId, _ := strconv.Atoi(params["id"])
+	limit, _ := strconv.Atoi(req.URL.Query().Get("limit"))
+	if limit == 0 {
+		limit = 5
+	}
+
+	getRelatedStoriesRequest := struct {
+		ID    int
+		Limit int `valid:"int"`
+	}{
+		ID:    storyId,
+		Limit: limit,
+	}
+
+	if ok, err := govalidator.ValidateStruct(getRelatedStoriesRequest); !ok || err != nil {
+		RenderError(res, ErrInvalidRequest, err.Error())
+		return
+	}
+
+	relatedStories, err := h.StoryService.GetRelatedStories(storyId, limit)
+	if err != nil && err == story.ErrNoStoryFound {
+		render.JSON(res, http.StatusNotFound, map[string]interface{}{
+			"status": http.StatusNotFound,
+			"error": map[string]interface{}{
+				"code":    "ErrNoStoryFound",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      getRelatedStoriesRequest,
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Getting Related Stories")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	relatedStories = h.applyPendingEngagements(req, relatedStories)
+	render.Negotiate(res, req, http.StatusOK, map[string]interface{}{
+		"status":  http.StatusOK,
+		"stories": relatedStories,
+	}, relatedStories)
+}
+
 func (h *StoryHandler) deleteStoryByID(res http.ResponseWriter, req *http.Request) {
 	params := mux.Vars(req)
 	storyId, _ := strconv.Atoi(params["id"])
@@ -336,6 +766,8 @@ func (h *StoryHandler) deleteStoryByID(res http.ResponseWriter, req *http.Reques
 		return
 	}
 
+	h.invalidateStoriesCache(req)
+
 	render.JSON(res, http.StatusOK, map[string]interface{}{
 		"status":  http.StatusOK,
 		"message": "Story Deleted",
@@ -345,8 +777,32 @@ func (h *StoryHandler) deleteStoryByID(res http.ResponseWriter, req *http.Reques
 func (h *StoryHandler) getStoryBySlug(res http.ResponseWriter, req *http.Request) {
 	params := mux.Vars(req)
 	slug := params["slug"]
+
+	getStoryRequest := struct {
+		Content string `valid:"in(html|markdown|text)"`
+	}{
+		Content: req.URL.Query().Get("content"),
+	}
+
+	if ok, err := govalidator.ValidateStruct(getStoryRequest); !ok || err != nil {
+		RenderError(res, ErrInvalidRequest, err.Error())
+		return
+	}
+
 	foundStory, err := h.StoryService.GetStoryBySlug(slug)
 
+	// retitled story keep answering its former slugs
+	if err != nil && err == story.ErrNoStoryFound {
+		movedStory, movedErr := h.StoryService.GetStoryByFormerSlug(slug)
+		if movedErr == nil {
+			RenderMovedPermanently(res, req, slug, movedStory.Slug)
+			return
+		}
+		if movedErr != story.ErrNoStoryFound {
+			err = movedErr
+		}
+	}
+
 	if err != nil && err == story.ErrNoStoryFound {
 		render.JSON(res, http.StatusNotFound, map[string]interface{}{
 			"status": http.StatusNotFound,
@@ -368,8 +824,685 @@ func (h *StoryHandler) getStoryBySlug(res http.ResponseWriter, req *http.Request
 		return
 	}
 
+	foundStory = h.applyPendingEngagements(req, chronicle.Stories{foundStory})[0]
+	foundStory = storyContentAs(foundStory, getStoryRequest.Content)
+
 	render.JSON(res, http.StatusOK, map[string]interface{}{
 		"status": http.StatusOK,
 		"story":  foundStory,
 	})
 }
+
+func (h *StoryHandler) getStoryRevisions(res http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	storyId, _ := strconv.Atoi(params["id"])
+
+	// Pagination
+	limit, _ := strconv.Atoi(req.URL.Query().Get("limit"))
+	if limit == 0 {
+		limit = 20
+	}
+	page, _ := strconv.Atoi(req.URL.Query().Get("page"))
+	if page <= 0 {
+		page = 1
+	}
+	order := req.URL.Query().Get("order")
+	if order == "" {
+		order = "desc"
+	}
+
+	getStoryRevisionsRequest := struct {
+		Limit int    `valid:"int"`
+		Page  int    `valid:"int"`
+		Order string `valid:"in(asc|desc)"`
+	}{
+		Limit: limit,
+		Page:  page,
+		Order: order,
+	}
+
+	if ok, err := govalidator.ValidateStruct(getStoryRevisionsRequest); !ok || err != nil {
+		RenderError(res, ErrInvalidRequest, err.Error())
+		return
+	}
+
+	revisions, revisionsCount, err := h.StoryService.GetStoryRevisions(storyId, chronicle.PagingOptions{
+		Limit:  limit,
+		Offset: (page - 1) * limit,
+		Order:  order,
+	})
+
+	if err != nil && err == story.ErrNoStoryFound {
+		render.JSON(res, http.StatusNotFound, map[string]interface{}{
+			"status": http.StatusNotFound,
+			"error": map[string]interface{}{
+				"code":    "ErrNoStoryFound",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      getStoryRevisionsRequest,
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Getting Story Revisions")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	totalPage := int(math.Ceil(float64(revisionsCount) / float64(limit)))
+	render.Negotiate(res, req, http.StatusOK, map[string]interface{}{
+		"status":    http.StatusOK,
+		"revisions": revisions,
+		"pagination": map[string]interface{}{
+			"totalItems":   revisionsCount,
+			"page":         page,
+			"itemsPerPage": limit,
+			"totalPage":    totalPage,
+		},
+	}, revisions)
+}
+
+func (h *StoryHandler) getStoryRevision(res http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	storyId, _ := strconv.Atoi(params["id"])
+	revision, _ := strconv.Atoi(params["revision"])
+	foundRevision, err := h.StoryService.GetStoryRevision(storyId, revision)
+
+	if err != nil && err == story.ErrNoStoryRevisionFound {
+		render.JSON(res, http.StatusNotFound, map[string]interface{}{
+			"status": http.StatusNotFound,
+			"error": map[string]interface{}{
+				"code":    "ErrNoStoryRevisionFound",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      params,
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Get Story Revision")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	render.JSON(res, http.StatusOK, map[string]interface{}{
+		"status":   http.StatusOK,
+		"revision": foundRevision,
+	})
+}
+
+func (h *StoryHandler) diffStoryRevisions(res http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	storyId, _ := strconv.Atoi(params["id"])
+
+	diffStoryRevisionsRequest := struct {
+		From string `valid:"int, required"`
+		To   string `valid:"int, required"`
+	}{
+		From: req.URL.Query().Get("from"),
+		To:   req.URL.Query().Get("to"),
+	}
+
+	if ok, err := govalidator.ValidateStruct(diffStoryRevisionsRequest); !ok || err != nil {
+		RenderError(res, ErrInvalidRequest, err.Error())
+		return
+	}
+
+	from, _ := strconv.Atoi(diffStoryRevisionsRequest.From)
+	to, _ := strconv.Atoi(diffStoryRevisionsRequest.To)
+	diff, err := h.StoryService.DiffStoryRevisions(storyId, from, to)
+
+	if err != nil && err == story.ErrNoStoryRevisionFound {
+		render.JSON(res, http.StatusNotFound, map[string]interface{}{
+			"status": http.StatusNotFound,
+			"error": map[string]interface{}{
+				"code":    "ErrNoStoryRevisionFound",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      diffStoryRevisionsRequest,
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Diff Story Revisions")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	render.JSON(res, http.StatusOK, map[string]interface{}{
+		"status": http.StatusOK,
+		"diff":   diff,
+	})
+}
+
+func (h *StoryHandler) restoreStoryRevision(res http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	storyId, _ := strconv.Atoi(params["id"])
+	revision, _ := strconv.Atoi(params["revision"])
+	restoredStory, err := h.StoryService.RestoreStoryRevision(storyId, revision)
+
+	if err != nil && err == story.ErrNoStoryFound {
+		render.JSON(res, http.StatusNotFound, map[string]interface{}{
+			"status": http.StatusNotFound,
+			"error": map[string]interface{}{
+				"code":    "ErrNoStoryFound",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	if err != nil && err == story.ErrNoStoryRevisionFound {
+		render.JSON(res, http.StatusNotFound, map[string]interface{}{
+			"status": http.StatusNotFound,
+			"error": map[string]interface{}{
+				"code":    "ErrNoStoryRevisionFound",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      params,
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Restore Story Revision")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	h.invalidateStoriesCache(req)
+
+	render.JSON(res, http.StatusOK, map[string]interface{}{
+		"status": http.StatusOK,
+		"story":  restoredStory,
+	})
+}
+
+func (h *StoryHandler) getScheduledStories(res http.ResponseWriter, req *http.Request) {
+	// Pagination
+	limit, _ := strconv.Atoi(req.URL.Query().Get("limit"))
+	if limit == 0 {
+		limit = 20
+	}
+	page, _ := strconv.Atoi(req.URL.Query().Get("page"))
+	if page <= 0 {
+		page = 1
+	}
+
+	getScheduledStoriesRequest := struct {
+		Limit int `valid:"int"`
+		Page  int `valid:"int"`
+	}{
+		Limit: limit,
+		Page:  page,
+	}
+
+	if ok, err := govalidator.ValidateStruct(getScheduledStoriesRequest); !ok || err != nil {
+		RenderError(res, ErrInvalidRequest, err.Error())
+		return
+	}
+
+	stories, storiesCount, err := h.StoryService.GetScheduledStories(chronicle.PagingOptions{
+		Limit:  limit,
+		Offset: (page - 1) * limit,
+	})
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      getScheduledStoriesRequest,
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Getting Scheduled Stories")
+
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	totalPage := int(math.Ceil(float64(storiesCount) / float64(limit)))
+	render.Negotiate(res, req, http.StatusOK, map[string]interface{}{
+		"status":  http.StatusOK,
+		"stories": stories,
+		"pagination": map[string]interface{}{
+			"totalItems":   storiesCount,
+			"page":         page,
+			"itemsPerPage": limit,
+			"totalPage":    totalPage,
+		},
+	}, stories)
+}
+
+// bylineRequest is a contributor credited on a story with their role
+type bylineRequest struct {
+	ContributorID int    `json:"contributorId"`
+	Role          string `json:"role"`
+}
+
+// parseBylines keep the order of requests as position of bylines
+func parseBylines(requests []bylineRequest) (chronicle.Bylines, error) {
+	bylines := chronicle.Bylines{}
+	for _, request := range requests {
+		switch request.Role {
+		case chronicle.ContributorAuthorRole, chronicle.ContributorReporterRole, chronicle.ContributorEditorRole, chronicle.ContributorPhotographerRole:
+		default:
+			return nil, fmt.Errorf("unknown role %q", request.Role)
+		}
+
+		bylines = append(bylines, chronicle.Byline{
+			Contributor: chronicle.Contributor{ID: request.ContributorID},
+			Role:        request.Role,
+		})
+	}
+
+	return bylines, nil
+}
+
+/*
+validateLegacyMedia accept the deprecated free form media only as a json object or null, the returned warning is meant for the Warning header.
+It stay for stories imported from WordPress, whose attachments are kept as urls because the importer does not download files,
+new stories reference uploaded media assets through mediaIds
+*/
+func validateLegacyMedia(media json.RawMessage) (warning string, err error) {
+	trimmed := bytes.TrimSpace(media)
+	if len(trimmed) == 0 {
+		return "", nil
+	}
+
+	if !bytes.Equal(trimmed, []byte("null")) && trimmed[0] != '{' {
+		return "", errors.New("must be an object, reference media assets through mediaIds instead")
+	}
+
+	return `299 - "media is deprecated, reference media assets through mediaIds"`, nil
+}
+
+// mediaAssetsFromIDs keep the order of ids as position of media assets
+func mediaAssetsFromIDs(mediaIDs []int) chronicle.MediaAssets {
+	assets := chronicle.MediaAssets{}
+	for _, mediaID := range mediaIDs {
+		assets = append(assets, chronicle.MediaAsset{ID: mediaID})
+	}
+
+	return assets
+}
+
+// tagsFromNames slug tag names as typed, tags without any letter or digit are dropped
+func tagsFromNames(names []string) (chronicle.Tags, error) {
+	tags := chronicle.Tags{}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if utf8.RuneCountInString(name) > chronicle.SlugColumnLength {
+			return nil, fmt.Errorf("%q is longer than %d characters", name, chronicle.SlugColumnLength)
+		}
+
+		slug := chronicle.Slugify(name)
+		if slug == "" {
+			continue
+		}
+
+		tags = append(tags, chronicle.Tag{Name: name, Slug: slug})
+	}
+
+	return tags, nil
+}
+
+//mergeStoryTopics apply replaced, added and removed topic ids to current topics of a story
+func mergeStoryTopics(current chronicle.Topics, replaceIDs *[]int, addIDs []int, removeIDs []int) chronicle.Topics {
+	topicIDs := []int{}
+	if replaceIDs != nil {
+		topicIDs = append(topicIDs, (*replaceIDs)...)
+	} else {
+		for _, topic := range current {
+			topicIDs = append(topicIDs, topic.ID)
+		}
+	}
+	topicIDs = append(topicIDs, addIDs...)
+
+	removed := map[int]bool{}
+	for _, topicID := range removeIDs {
+		removed[topicID] = true
+	}
+
+	topics := chronicle.Topics{}
+	for _, topicID := range topicIDs {
+		if !removed[topicID] {
+			// mark as seen so duplicates are skipped
+			removed[topicID] = true
+			topics = append(topics, chronicle.Topic{ID: topicID})
+		}
+	}
+
+	return topics
+}
+
+//parseSchedule parse RFC3339 schedule of request, nil keep current schedule and empty string clear it
+func parseSchedule(value *string, current *time.Time) (*time.Time, error) {
+	if value == nil {
+		return current, nil
+	}
+
+	if *value == "" {
+		return nil, nil
+	}
+
+	schedule, err := time.Parse(time.RFC3339, *value)
+	if err != nil {
+		return nil, err
+	}
+
+	// normalized to UTC, the instant is kept whatever offset it was given with
+	schedule = schedule.UTC()
+	return &schedule, nil
+}
+
+func validateSchedule(publishAt *time.Time, unpublishAt *time.Time, now time.Time) error {
+	if publishAt != nil && !publishAt.After(now) {
+		return errors.New("publishAt: must be in the future")
+	}
+
+	if unpublishAt != nil && !unpublishAt.After(now) {
+		return errors.New("unpublishAt: must be in the future")
+	}
+
+	if publishAt != nil && unpublishAt != nil && !unpublishAt.After(*publishAt) {
+		return errors.New("unpublishAt: must be after publishAt")
+	}
+
+	return nil
+}
+
+func (h *StoryHandler) transitionStory(res http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	storyId, _ := strconv.Atoi(params["id"])
+	clientID := req.Context().Value(contextkey.ClientID).(string)
+	transitionedStory, err := h.StoryService.TransitionStory(storyId, params["name"], clientID)
+
+	if err != nil && err == story.ErrNoStoryFound {
+		render.JSON(res, http.StatusNotFound, map[string]interface{}{
+			"status": http.StatusNotFound,
+			"error": map[string]interface{}{
+				"code":    "ErrNoStoryFound",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	if err != nil && err == story.ErrUnknownTransition {
+		render.JSON(res, 422, map[string]interface{}{
+			"status": 422,
+			"error": map[string]interface{}{
+				"code":    "ErrUnknownTransition",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	if _, illegal := err.(story.IllegalTransitionError); illegal {
+		render.JSON(res, http.StatusConflict, map[string]interface{}{
+			"status": http.StatusConflict,
+			"error": map[string]interface{}{
+				"code":    "ErrIllegalTransition",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      params,
+			"client":       clientID,
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Transition Story")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	h.invalidateStoriesCache(req)
+
+	render.JSON(res, http.StatusOK, map[string]interface{}{
+		"status": http.StatusOK,
+		"story":  transitionedStory,
+	})
+}
+
+func (h *StoryHandler) getStoryTransitions(res http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	storyId, _ := strconv.Atoi(params["id"])
+
+	// Pagination
+	limit, _ := strconv.Atoi(req.URL.Query().Get("limit"))
+	if limit == 0 {
+		limit = 20
+	}
+	page, _ := strconv.Atoi(req.URL.Query().Get("page"))
+	if page <= 0 {
+		page = 1
+	}
+	order := req.URL.Query().Get("order")
+	if order == "" {
+		order = "desc"
+	}
+
+	getStoryTransitionsRequest := struct {
+		Limit int    `valid:"int"`
+		Page  int    `valid:"int"`
+		Order string `valid:"in(asc|desc)"`
+	}{
+		Limit: limit,
+		Page:  page,
+		Order: order,
+	}
+
+	if ok, err := govalidator.ValidateStruct(getStoryTransitionsRequest); !ok || err != nil {
+		RenderError(res, ErrInvalidRequest, err.Error())
+		return
+	}
+
+	transitions, transitionsCount, err := h.StoryService.GetStoryTransitions(storyId, chronicle.PagingOptions{
+		Limit:  limit,
+		Offset: (page - 1) * limit,
+		Order:  order,
+	})
+
+	if err != nil && err == story.ErrNoStoryFound {
+		render.JSON(res, http.StatusNotFound, map[string]interface{}{
+			"status": http.StatusNotFound,
+			"error": map[string]interface{}{
+				"code":    "ErrNoStoryFound",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      getStoryTransitionsRequest,
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Getting Story Transitions")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	totalPage := int(math.Ceil(float64(transitionsCount) / float64(limit)))
+	render.Negotiate(res, req, http.StatusOK, map[string]interface{}{
+		"status":      http.StatusOK,
+		"transitions": transitions,
+		"pagination": map[string]interface{}{
+			"totalItems":   transitionsCount,
+			"page":         page,
+			"itemsPerPage": limit,
+			"totalPage":    totalPage,
+		},
+	}, transitions)
+}
+
+// getStoryEngagements serve stored counts with the ones not flushed yet, uncached unlike the story itself
+func (h *StoryHandler) getStoryEngagements(res http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	storyId, _ := strconv.Atoi(params["id"])
+
+	foundStory, err := h.StoryService.GetStoryByID(storyId)
+	if err != nil && err == story.ErrNoStoryFound {
+		render.JSON(res, http.StatusNotFound, map[string]interface{}{
+			"status": http.StatusNotFound,
+			"error": map[string]interface{}{
+				"code":    "ErrNoStoryFound",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      storyId,
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Get Story Engagements")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	foundStory = h.applyPendingEngagements(req, chronicle.Stories{foundStory})[0]
+
+	render.JSON(res, http.StatusOK, map[string]interface{}{
+		"status": http.StatusOK,
+		"engagement": chronicle.StoryEngagement{
+			StoryID: foundStory.ID,
+			Likes:   foundStory.Likes,
+			Shares:  foundStory.Shares,
+			Views:   foundStory.Views,
+		},
+	})
+}
+
+func (h *StoryHandler) engageStory(res http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	storyId, _ := strconv.Atoi(params["id"])
+	kind := params["engagement"]
+
+	foundStory, err := h.StoryService.GetStoryByID(storyId)
+	if err != nil && err == story.ErrNoStoryFound {
+		render.JSON(res, http.StatusNotFound, map[string]interface{}{
+			"status": http.StatusNotFound,
+			"error": map[string]interface{}{
+				"code":    "ErrNoStoryFound",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	if err == nil {
+		err = h.EngagementService.EngageStory(foundStory.ID, kind)
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      storyId,
+			"engagement":   kind,
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Engaging Story")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	foundStory = h.applyPendingEngagements(req, chronicle.Stories{foundStory})[0]
+
+	render.JSON(res, http.StatusOK, map[string]interface{}{
+		"status": http.StatusOK,
+		"engagement": chronicle.StoryEngagement{
+			StoryID: foundStory.ID,
+			Likes:   foundStory.Likes,
+			Shares:  foundStory.Shares,
+			Views:   foundStory.Views,
+		},
+	})
+}
+
+/*
+storyContentAs replace Content of story with its representation in format, an empty format keep the stored source.
+Html is valid markdown, it is served as markdown of stories not written in markdown
+*/
+func storyContentAs(foundStory chronicle.Story, format string) chronicle.Story {
+	switch format {
+	case chronicle.StoryContentHTML:
+		foundStory.Content = foundStory.ContentHTML
+	case chronicle.StoryContentText:
+		foundStory.Content = content.HTMLToText(foundStory.ContentHTML)
+	case chronicle.StoryContentMarkdown:
+		if foundStory.ContentFormat != chronicle.StoryContentMarkdown {
+			foundStory.Content = foundStory.ContentHTML
+		}
+	default:
+		return foundStory
+	}
+
+	foundStory.ContentFormat = format
+	return foundStory
+}
+
+// applyPendingEngagementsToHits add engagements not flushed yet to the stories of search hits
+func (h *StoryHandler) applyPendingEngagementsToHits(req *http.Request, hits chronicle.StorySearchHits) chronicle.StorySearchHits {
+	stories := chronicle.Stories{}
+	for _, hit := range hits {
+		stories = append(stories, hit.Story)
+	}
+
+	stories = h.applyPendingEngagements(req, stories)
+	for idx := range hits {
+		hits[idx].Story = stories[idx]
+	}
+	return hits
+}
+
+// invalidateStoriesCache drop cached responses built from stories so a write is seen right away
+func (h *StoryHandler) invalidateStoriesCache(req *http.Request) {
+	if err := middlewares.InvalidateStoriesCache(h.CacheService); err != nil {
+		log.WithFields(log.Fields{
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Warn("Error Handler Invalidating Stories Cache")
+	}
+}
+
+// applyPendingEngagements add engagements not flushed yet, stored counts are served when cache is unreachable
+func (h *StoryHandler) applyPendingEngagements(req *http.Request, stories chronicle.Stories) chronicle.Stories {
+	return applyPendingEngagements(h.EngagementService, req, stories)
+}
+
+/*
+applyPendingEngagements add engagements not flushed yet to stories of any handler, stored counts are served when cache is unreachable.
+Cached responses keep the counts of when they were cached, /stories/{id}/engagements serve live ones
+*/
+func applyPendingEngagements(engagementService engagement.Service, req *http.Request, stories chronicle.Stories) chronicle.Stories {
+	engagedStories, err := engagementService.ApplyPendingEngagements(stories)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Warn("Error Handler Applying Pending Engagements")
+		return stories
+	}
+
+	return engagedStories
+}
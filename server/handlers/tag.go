@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/AdhityaRamadhanus/chronicle"
+	"github.com/AdhityaRamadhanus/chronicle/engagement"
+	"github.com/AdhityaRamadhanus/chronicle/server/internal/contextkey"
+	"github.com/AdhityaRamadhanus/chronicle/server/middlewares"
+	"github.com/AdhityaRamadhanus/chronicle/server/render"
+	"github.com/AdhityaRamadhanus/chronicle/tag"
+	"github.com/asaskevich/govalidator"
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+type TagHandler struct {
+	TagService        tag.Service
+	EngagementService engagement.Service
+	CacheService      chronicle.CacheService
+}
+
+func (h TagHandler) RegisterRoutes(router *mux.Router) {
+	authMiddleware := middlewares.Authenticate
+	cacheMiddleware := middlewares.Cache(h.CacheService)
+
+	router.HandleFunc("/tags/autocomplete", authMiddleware(cacheMiddleware("60s", h.autocompleteTags))).Methods("GET")
+	router.HandleFunc("/tags/counts", authMiddleware(cacheMiddleware("60s", h.getTagCounts))).Methods("GET")
+
+	router.HandleFunc("/tags/{slug}/stories", authMiddleware(cacheMiddleware("60s", h.getTagStories))).Methods("GET")
+	router.HandleFunc("/tags/{slug}", authMiddleware(cacheMiddleware("60s", h.getTagBySlug))).Methods("GET")
+}
+
+func (h *TagHandler) autocompleteTags(res http.ResponseWriter, req *http.Request) {
+	limit, _ := strconv.Atoi(req.URL.Query().Get("limit"))
+	if limit == 0 {
+		limit = 10
+	}
+	prefix := req.URL.Query().Get("prefix")
+
+	autocompleteTagsRequest := struct {
+		Prefix string `valid:"required"`
+		Limit  int    `valid:"int"`
+	}{
+		Prefix: prefix,
+		Limit:  limit,
+	}
+
+	if ok, err := govalidator.ValidateStruct(autocompleteTagsRequest); !ok || err != nil {
+		RenderError(res, ErrInvalidRequest, err.Error())
+		return
+	}
+
+	tags, err := h.TagService.AutocompleteTags(prefix, limit)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      autocompleteTagsRequest,
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Autocompleting Tags")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	render.Negotiate(res, req, http.StatusOK, map[string]interface{}{
+		"status": http.StatusOK,
+		"tags":   tags,
+	}, tags)
+}
+
+func (h *TagHandler) getTagCounts(res http.ResponseWriter, req *http.Request) {
+	limit, _ := strconv.Atoi(req.URL.Query().Get("limit"))
+	if limit == 0 {
+		limit = 20
+	}
+	window := req.URL.Query().Get("window")
+	if window == "" {
+		window = chronicle.DefaultTagCountWindow
+	}
+
+	getTagCountsRequest := struct {
+		Limit  int    `valid:"int"`
+		Window string `valid:"in(24h|7d|30d)"`
+	}{
+		Limit:  limit,
+		Window: window,
+	}
+
+	if ok, err := govalidator.ValidateStruct(getTagCountsRequest); !ok || err != nil {
+		RenderError(res, ErrInvalidRequest, err.Error())
+		return
+	}
+
+	tagCounts, err := h.TagService.GetTagCounts(window, limit)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      getTagCountsRequest,
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Getting Tag Counts")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	render.Negotiate(res, req, http.StatusOK, map[string]interface{}{
+		"status": http.StatusOK,
+		"tags":   tagCounts,
+		"window": window,
+	}, tagCounts)
+}
+
+func (h *TagHandler) getTagBySlug(res http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	slug := params["slug"]
+	foundTag, err := h.TagService.GetTagBySlug(slug)
+
+	if err != nil && err == tag.ErrNoTagFound {
+		renderNoTagFound(res, err)
+		return
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      slug,
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Getting Tag By Slug")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	render.JSON(res, http.StatusOK, map[string]interface{}{
+		"status": http.StatusOK,
+		"tag":    foundTag,
+	})
+}
+
+func (h *TagHandler) getTagStories(res http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	slug := params["slug"]
+
+	// Pagination
+	limit, _ := strconv.Atoi(req.URL.Query().Get("limit"))
+	if limit == 0 {
+		limit = 20
+	}
+	page, _ := strconv.Atoi(req.URL.Query().Get("page"))
+	if page <= 0 {
+		page = 1
+	}
+	sortby := req.URL.Query().Get("sort-by")
+	if sortby == "" {
+		sortby = "updatedAt"
+	}
+	order := req.URL.Query().Get("order")
+	if order == "" {
+		order = "desc"
+	}
+
+	getTagStoriesRequest := struct {
+		Slug   string
+		Limit  int    `valid:"int"`
+		Page   int    `valid:"int"`
+		Order  string `valid:"in(asc|desc)"`
+		SortBy string `valid:"in(createdAt|updatedAt)"`
+	}{
+		Slug:   slug,
+		Limit:  limit,
+		Page:   page,
+		SortBy: sortby,
+		Order:  order,
+	}
+
+	if ok, err := govalidator.ValidateStruct(getTagStoriesRequest); !ok || err != nil {
+		RenderError(res, ErrInvalidRequest, err.Error())
+		return
+	}
+
+	stories, storiesCount, err := h.TagService.GetTagStories(slug, chronicle.PagingOptions{
+		Limit:  limit,
+		Offset: (page - 1) * limit,
+		SortBy: sortby,
+		Order:  order,
+	})
+
+	if err != nil && err == tag.ErrNoTagFound {
+		renderNoTagFound(res, err)
+		return
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      getTagStoriesRequest,
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Getting Tag Stories")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	totalPage := int(math.Ceil(float64(storiesCount) / float64(limit)))
+	stories = applyPendingEngagements(h.EngagementService, req, stories)
+	render.Negotiate(res, req, http.StatusOK, map[string]interface{}{
+		"status":  http.StatusOK,
+		"stories": stories,
+		"pagination": map[string]interface{}{
+			"totalItems":   storiesCount,
+			"page":         page,
+			"itemsPerPage": limit,
+			"totalPage":    totalPage,
+		},
+	}, stories)
+}
+
+func renderNoTagFound(res http.ResponseWriter, err error) {
+	render.JSON(res, http.StatusNotFound, map[string]interface{}{
+		"status": http.StatusNotFound,
+		"error": map[string]interface{}{
+			"code":    "ErrNoTagFound",
+			"message": err.Error(),
+		},
+	})
+}
@@ -34,6 +34,8 @@ func (h TopicHandler) RegisterRoutes(router *mux.Router) {
 	router.HandleFunc("/topics/{id:[0-9]+}", authMiddleware(cacheMiddleware("60s", h.getTopicByID))).Methods("GET")
 	router.HandleFunc("/topics/{id:[0-9]+}/update", authMiddleware(h.updateTopic)).Methods("PATCH")
 	router.HandleFunc("/topics/{id:[0-9]+}/delete", authMiddleware(h.deleteTopicByID)).Methods("DELETE")
+	router.HandleFunc("/topics/{id:[0-9]+}/children", authMiddleware(cacheMiddleware("60s", h.getTopicChildren))).Methods("GET")
+	router.HandleFunc("/topics/{id:[0-9]+}/merge-into/{targetId:[0-9]+}", authMiddleware(h.mergeTopic)).Methods("POST")
 
 	router.HandleFunc("/topics/{slug}", authMiddleware(cacheMiddleware("60s", h.getTopicBySlug))).Methods("GET")
 }
@@ -86,7 +88,7 @@ func (h *TopicHandler) getTopics(res http.ResponseWriter, req *http.Request) {
 	}
 
 	totalPage := int(math.Ceil(float64(topicsCount) / float64(limit)))
-	render.JSON(res, http.StatusOK, map[string]interface{}{
+	render.Negotiate(res, req, http.StatusOK, map[string]interface{}{
 		"status": http.StatusOK,
 		"topics": topics,
 		"pagination": map[string]interface{}{
@@ -95,7 +97,7 @@ func (h *TopicHandler) getTopics(res http.ResponseWriter, req *http.Request) {
 			"itemsPerPage": limit,
 			"totalPage":    totalPage,
 		},
-	})
+	}, topics)
 }
 
 func (h *TopicHandler) createTopic(res http.ResponseWriter, req *http.Request) {
@@ -107,7 +109,8 @@ func (h *TopicHandler) createTopic(res http.ResponseWriter, req *http.Request) {
 	}
 
 	createTopicRequest := struct {
-		Name string `json:"name" valid:"required"`
+		Name     string `json:"name" valid:"required"`
+		ParentID *int   `json:"parentId" valid:"-"`
 	}{}
 
 	// Deserialize
@@ -127,11 +130,17 @@ func (h *TopicHandler) createTopic(res http.ResponseWriter, req *http.Request) {
 	}
 
 	newTopic := chronicle.Topic{
-		Name: createTopicRequest.Name,
-		Slug: chronicle.Slugify(createTopicRequest.Name),
+		ParentID: parentTopicID(createTopicRequest.ParentID),
+		Name:     createTopicRequest.Name,
+		Slug:     slugify(createTopicRequest.Name),
 	}
 
 	createdTopic, err := h.TopicService.CreateTopic(newTopic)
+	if err != nil && (err == topic.ErrUnknownParentTopic || err == topic.ErrTopicCycle) {
+		RenderError(res, ErrInvalidRequest, "parentId:", err.Error())
+		return
+	}
+
 	if err != nil {
 		log.WithFields(log.Fields{
 			"request":      createTopicRequest,
@@ -191,6 +200,8 @@ func (h *TopicHandler) updateTopic(res http.ResponseWriter, req *http.Request) {
 
 	updateTopicRequest := struct {
 		Name string `json:"name" valid:"required"`
+		// omitted parent is left untouched, zero move the topic to the root
+		ParentID *int `json:"parentId" valid:"-"`
 	}{}
 
 	// Deserialize
@@ -224,9 +235,18 @@ func (h *TopicHandler) updateTopic(res http.ResponseWriter, req *http.Request) {
 	}
 
 	oldTopic.Name = updateTopicRequest.Name
-	oldTopic.Slug = chronicle.Slugify(updateTopicRequest.Name)
+	oldTopic.Slug = slugify(updateTopicRequest.Name)
+
+	if updateTopicRequest.ParentID != nil {
+		oldTopic.ParentID = parentTopicID(updateTopicRequest.ParentID)
+	}
 
 	updatedTopic, err := h.TopicService.UpdateTopic(oldTopic)
+	if err != nil && (err == topic.ErrUnknownParentTopic || err == topic.ErrTopicCycle) {
+		RenderError(res, ErrInvalidRequest, "parentId:", err.Error())
+		return
+	}
+
 	if err != nil {
 		log.WithFields(log.Fields{
 			"request":      updateTopicRequest,
@@ -264,11 +284,70 @@ func (h *TopicHandler) deleteTopicByID(res http.ResponseWriter, req *http.Reques
 	})
 }
 
+func (h *TopicHandler) mergeTopic(res http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	topicId, _ := strconv.Atoi(params["id"])
+	targetTopicId, _ := strconv.Atoi(params["targetId"])
+	mergedTopic, err := h.TopicService.MergeTopic(topicId, targetTopicId)
+
+	if err != nil && err == topic.ErrNoTopicFound {
+		render.JSON(res, http.StatusNotFound, map[string]interface{}{
+			"status": http.StatusNotFound,
+			"error": map[string]interface{}{
+				"code":    "ErrNoTopicFound",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	if err != nil && err == topic.ErrInvalidMergeTarget {
+		RenderError(res, ErrInvalidRequest, "targetId:", err.Error())
+		return
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      map[string]int{"id": topicId, "targetId": targetTopicId},
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Merging Topic")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	// stories embed their topics, so story listings are as stale as topic listings
+	if err := middlewares.InvalidateCache(h.CacheService, "/api/topics", "/api/stories", "/api/contributors"); err != nil {
+		log.WithFields(log.Fields{
+			"request":      map[string]int{"id": topicId, "targetId": targetTopicId},
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Warn("Error Handler Invalidating Cache After Merging Topic")
+	}
+
+	render.JSON(res, http.StatusOK, map[string]interface{}{
+		"status": http.StatusOK,
+		"topic":  mergedTopic,
+	})
+}
+
 func (h *TopicHandler) getTopicBySlug(res http.ResponseWriter, req *http.Request) {
 	params := mux.Vars(req)
 	slug, _ := params["slug"]
 	foundTopic, err := h.TopicService.GetTopicBySlug(slug)
 
+	// renamed topic keep answering its former slugs
+	if err != nil && err == topic.ErrNoTopicFound {
+		movedTopic, movedErr := h.TopicService.GetTopicByFormerSlug(slug)
+		if movedErr == nil {
+			RenderMovedPermanently(res, req, slug, movedTopic.Slug)
+			return
+		}
+		if movedErr != topic.ErrNoTopicFound {
+			err = movedErr
+		}
+	}
+
 	if err != nil && err == topic.ErrNoTopicFound {
 		render.JSON(res, http.StatusNotFound, map[string]interface{}{
 			"status": http.StatusNotFound,
@@ -295,3 +374,92 @@ func (h *TopicHandler) getTopicBySlug(res http.ResponseWriter, req *http.Request
 		"topic":  foundTopic,
 	})
 }
+
+func (h *TopicHandler) getTopicChildren(res http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+	topicId, _ := strconv.Atoi(params["id"])
+
+	// Pagination
+	limit, _ := strconv.Atoi(req.URL.Query().Get("limit"))
+	if limit == 0 {
+		limit = 20
+	}
+	page, _ := strconv.Atoi(req.URL.Query().Get("page"))
+	if page <= 0 {
+		page = 1
+	}
+	sortby := req.URL.Query().Get("sort-by")
+	if sortby == "" {
+		sortby = "name"
+	}
+	order := req.URL.Query().Get("order")
+	if order == "" {
+		order = "asc"
+	}
+
+	getTopicChildrenRequest := struct {
+		Limit  int    `valid:"int"`
+		Page   int    `valid:"int"`
+		Order  string `valid:"in(asc|desc)"`
+		SortBy string `valid:"in(createdAt|updatedAt|name)"`
+	}{
+		Limit:  limit,
+		Page:   page,
+		SortBy: sortby,
+		Order:  order,
+	}
+
+	if ok, err := govalidator.ValidateStruct(getTopicChildrenRequest); !ok || err != nil {
+		RenderError(res, ErrInvalidRequest, err.Error())
+		return
+	}
+
+	topics, topicsCount, err := h.TopicService.GetTopicChildren(topicId, chronicle.PagingOptions{
+		Limit:  limit,
+		Offset: (page - 1) * limit,
+		SortBy: sortby,
+		Order:  order,
+	})
+
+	if err != nil && err == topic.ErrNoTopicFound {
+		render.JSON(res, http.StatusNotFound, map[string]interface{}{
+			"status": http.StatusNotFound,
+			"error": map[string]interface{}{
+				"code":    "ErrNoTopicFound",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"request":      getTopicChildrenRequest,
+			"client":       req.Context().Value(contextkey.ClientID).(string),
+			"x-request-id": req.Header.Get("X-Request-ID"),
+		}).WithError(err).Error("Error Handler Getting Topic Children")
+		RenderError(res, ErrSomethingWrong)
+		return
+	}
+
+	totalPage := int(math.Ceil(float64(topicsCount) / float64(limit)))
+	render.Negotiate(res, req, http.StatusOK, map[string]interface{}{
+		"status": http.StatusOK,
+		"topics": topics,
+		"pagination": map[string]interface{}{
+			"totalItems":   topicsCount,
+			"page":         page,
+			"itemsPerPage": limit,
+			"totalPage":    totalPage,
+		},
+	}, topics)
+}
+
+// parentTopicID treat zero as no parent
+func parentTopicID(parentID *int) *int {
+	if parentID == nil || *parentID == 0 {
+		return nil
+	}
+
+	return parentID
+}
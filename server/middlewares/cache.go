@@ -1,21 +1,30 @@
 package middlewares
 
 import (
+	"bytes"
 	"fmt"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/AdhityaRamadhanus/chronicle"
+	"github.com/AdhityaRamadhanus/chronicle/server/render"
 	"github.com/spf13/viper"
 )
 
+// content type is stored in front of the cached body, separated by a NUL which neither content types nor json contain
+const cachedContentTypeSeparator = "\x00"
+
 type cachedResponseWriter struct {
 	http.ResponseWriter
 	status       int
+	body         bytes.Buffer
 	CacheService chronicle.CacheService
 	Key          string
 	Exp          time.Duration
+	// format the key was built for and the format the handler negotiated, empty when it did not negotiate
+	Format         string
+	renderedFormat string
 }
 
 func newcachedResponseWriter(res http.ResponseWriter) *cachedResponseWriter {
@@ -27,18 +36,53 @@ func (crw *cachedResponseWriter) Status() int {
 }
 
 func (crw *cachedResponseWriter) Write(resBody []byte) (n int, err error) {
+	if crw.status == 0 {
+		crw.WriteHeader(http.StatusOK)
+	}
+
 	if crw.Status() == 200 {
-		crw.CacheService.SetEx(crw.Key, resBody, crw.Exp)
+		crw.body.Write(resBody)
 	}
 
 	return crw.ResponseWriter.Write(resBody)
 }
 
+func (crw *cachedResponseWriter) RecordFormat(format string) {
+	crw.renderedFormat = format
+}
+
 func (crw *cachedResponseWriter) WriteHeader(code int) {
 	crw.ResponseWriter.WriteHeader(code)
 	crw.status = code
 }
 
+/*
+store cache the whole body once the handler is done, handlers may write it in several parts.
+A handler offering fewer formats may negotiate another format than the key was built for, that body is not cached
+*/
+func (crw *cachedResponseWriter) store() {
+	if crw.Status() != 200 {
+		return
+	}
+
+	if crw.renderedFormat != "" && crw.renderedFormat != crw.Format {
+		return
+	}
+
+	contentType := crw.Header().Get("Content-Type")
+	crw.CacheService.SetEx(crw.Key, append([]byte(contentType+cachedContentTypeSeparator), crw.body.Bytes()...), crw.Exp)
+}
+
+// splitCachedResponse get content type and body of cached response, responses cached without content type are json
+func splitCachedResponse(cached []byte) (contentType string, body []byte) {
+	separator := bytes.Index(cached, []byte(cachedContentTypeSeparator))
+	if separator < 0 {
+		return "application/json; charset=utf-8", cached
+	}
+
+	return string(cached[:separator]), cached[separator+1:]
+}
+
 func buildCacheKeyFromURI(req *http.Request) string {
 	cacheKeyParts := []string{
 		"chronicle",
@@ -59,6 +103,16 @@ func buildCacheKeyFromURI(req *http.Request) string {
 		//filter
 		"status",
 		"topic",
+		"include-descendants",
+		//search
+		"q",
+		"lang",
+		//trending and tag counts
+		"window",
+		//tag autocomplete
+		"prefix",
+		//story content representation
+		"content",
 	}
 
 	querystring := req.URL.Query()
@@ -67,9 +121,59 @@ func buildCacheKeyFromURI(req *http.Request) string {
 		cacheKeyParts = append(cacheKeyParts, keyPart)
 	}
 
+	// the same uri is rendered differently per negotiated format, responses that are not negotiated are always json
+	cacheKeyParts = append(cacheKeyParts, fmt.Sprintf("format=%s", cacheFormat(req)))
+
 	return strings.Join(cacheKeyParts, ":")
 }
 
+// format req is cached under, negotiated against every format so it matches what handlers offering all of them pick
+func cacheFormat(req *http.Request) string {
+	format, _ := render.NegotiateFormat(req, render.Formats)
+	return format
+}
+
+//InvalidateCache drop cached responses of every request whose path start with one of paths
+func InvalidateCache(cacheService chronicle.CacheService, paths ...string) error {
+	for _, path := range paths {
+		cacheKeyParts := []string{
+			"chronicle",
+			"http-cache",
+		}
+		cacheKeyParts = append(cacheKeyParts, strings.Split(path, "/")...)
+
+		keys, err := cacheService.Keys(strings.Join(cacheKeyParts, ":") + "*")
+		if err != nil {
+			return err
+		}
+
+		if len(keys) == 0 {
+			continue
+		}
+
+		if err := cacheService.Del(keys...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//StoryCachePaths are paths of cached responses built from stories, they are dropped whenever a story is written
+var StoryCachePaths = []string{
+	"/api/stories",
+	"/api/tags",
+	"/api/contributors",
+	"/api/collections",
+	"/api/feeds",
+	"/sitemap",
+}
+
+//InvalidateStoriesCache drop cached responses built from stories
+func InvalidateStoriesCache(cacheService chronicle.CacheService) error {
+	return InvalidateCache(cacheService, StoryCachePaths...)
+}
+
 //Cache http request
 func Cache(cacheService chronicle.CacheService) func(string, http.HandlerFunc) http.HandlerFunc {
 	return func(duration string, next http.HandlerFunc) http.HandlerFunc {
@@ -79,21 +183,25 @@ func Cache(cacheService chronicle.CacheService) func(string, http.HandlerFunc) h
 				return
 			}
 
-			existingCache, err := cacheService.Get(req.RequestURI)
+			cacheKey := buildCacheKeyFromURI(req)
+			existingCache, err := cacheService.Get(cacheKey)
 			if err != nil {
 				crw := newcachedResponseWriter(res)
 				crw.CacheService = cacheService
-				crw.Key = buildCacheKeyFromURI(req)
+				crw.Key = cacheKey
+				crw.Format = cacheFormat(req)
 				crw.Exp, _ = time.ParseDuration(duration)
 
 				next(crw, req)
+				crw.store()
 				return
 			}
 
-			//only cache json
-			res.Header().Set("Content-Type", "application/json; charset=utf-8")
+			contentType, body := splitCachedResponse(existingCache)
+			res.Header().Set("Content-Type", contentType)
+			res.Header().Add("Vary", "Accept")
 			res.WriteHeader(http.StatusOK)
-			res.Write(existingCache)
+			res.Write(body)
 			return
 		})
 	}
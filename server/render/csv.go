@@ -0,0 +1,184 @@
+package render
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+//CSV render list, a slice of structs, as csv with a header row, a slice of anything else is rendered in a single Value column
+func CSV(res http.ResponseWriter, code int, list interface{}) error {
+	rows, err := CSVRows(list)
+	if err != nil {
+		return err
+	}
+
+	res.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	res.WriteHeader(code)
+
+	writer := csv.NewWriter(res)
+	writer.WriteAll(rows)
+	return writer.Error()
+}
+
+/*
+CSVRows turn list into csv rows, columns are the exported fields of its element named as they are in json,
+embedded structs are flattened and nested lists and structs are written as json
+*/
+func CSVRows(list interface{}) ([][]string, error) {
+	value := reflect.ValueOf(list)
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return nil, fmt.Errorf("render: csv needs a list, got %T", list)
+	}
+
+	elemType := value.Type().Elem()
+	for elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+
+	if elemType.Kind() != reflect.Struct || elemType == timeType {
+		rows := [][]string{{"Value"}}
+		for idx := 0; idx < value.Len(); idx++ {
+			cell, err := csvCell(value.Index(idx))
+			if err != nil {
+				return nil, err
+			}
+			rows = append(rows, []string{cell})
+		}
+		return rows, nil
+	}
+
+	columns := csvColumns(elemType, nil)
+	header := make([]string, len(columns))
+	for idx, column := range columns {
+		header[idx] = column.name
+	}
+
+	rows := [][]string{header}
+	for idx := 0; idx < value.Len(); idx++ {
+		elem := reflect.Indirect(value.Index(idx))
+		row := make([]string, len(columns))
+		for columnIdx, column := range columns {
+			if !elem.IsValid() {
+				continue
+			}
+
+			field, ok := fieldByIndex(elem, column.index)
+			if !ok {
+				continue
+			}
+
+			cell, err := csvCell(field)
+			if err != nil {
+				return nil, err
+			}
+			row[columnIdx] = cell
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+type csvColumn struct {
+	name  string
+	index []int
+}
+
+// csvColumns list the fields of t the way encoding/json would, embedded structs without a json name are flattened
+func csvColumns(t reflect.Type, parentIndex []int) []csvColumn {
+	columns := []csvColumn{}
+	for idx := 0; idx < t.NumField(); idx++ {
+		field := t.Field(idx)
+		index := append(append([]int{}, parentIndex...), idx)
+
+		name := field.Name
+		if tag := strings.Split(field.Tag.Get("json"), ",")[0]; tag == "-" {
+			continue
+		} else if tag != "" {
+			name = tag
+		}
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && fieldType.Kind() == reflect.Struct && field.Tag.Get("json") == "" {
+			columns = append(columns, csvColumns(fieldType, index)...)
+			continue
+		}
+
+		if field.PkgPath != "" {
+			continue
+		}
+		columns = append(columns, csvColumn{name: name, index: index})
+	}
+	return columns
+}
+
+// fieldByIndex is reflect.Value.FieldByIndex that is false instead of panicking at a nil embedded pointer
+func fieldByIndex(value reflect.Value, index []int) (reflect.Value, bool) {
+	for _, idx := range index {
+		if value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				return reflect.Value{}, false
+			}
+			value = value.Elem()
+		}
+		value = value.Field(idx)
+	}
+	return value, true
+}
+
+func csvCell(value reflect.Value) (string, error) {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return "", nil
+		}
+		value = value.Elem()
+	}
+
+	switch {
+	case value.Type() == timeType:
+		return value.Interface().(time.Time).Format(time.RFC3339Nano), nil
+	case value.Type() == rawMessageType:
+		return string(value.Bytes()), nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		return escapeFormula(value.String()), nil
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return fmt.Sprint(value.Interface()), nil
+	}
+
+	if (value.Kind() == reflect.Slice || value.Kind() == reflect.Map) && value.IsNil() {
+		return "", nil
+	}
+
+	encoded, err := json.Marshal(value.Interface())
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// spreadsheets evaluate cells starting with these as formulas, text cells starting with one are prefixed with a quote
+const formulaPrefixes = "=+-@\t\r"
+
+func escapeFormula(cell string) string {
+	if cell != "" && strings.IndexByte(formulaPrefixes, cell[0]) >= 0 {
+		return "'" + cell
+	}
+	return cell
+}
@@ -0,0 +1,60 @@
+package render_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/AdhityaRamadhanus/chronicle"
+	"github.com/AdhityaRamadhanus/chronicle/server/render"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSVRows(t *testing.T) {
+	createdAt := time.Date(2018, 10, 25, 10, 0, 0, 0, time.UTC)
+	stories := chronicle.Stories{
+		{
+			ID:        7,
+			Media:     json.RawMessage(`{"cover":"a.jpg"}`),
+			Title:     "Timnas \"Garuda\"",
+			Topics:    chronicle.Topics{{ID: 2, Name: "Sepak Bola"}},
+			PublishAt: &createdAt,
+			CreatedAt: createdAt,
+		},
+	}
+
+	rows, err := render.CSVRows(stories)
+	assert.NoError(t, err)
+	assert.Len(t, rows, 2)
+
+	row := map[string]string{}
+	for idx, column := range rows[0] {
+		row[column] = rows[1][idx]
+	}
+	assert.Equal(t, "7", row["ID"])
+	assert.Equal(t, `{"cover":"a.jpg"}`, row["Media"])
+	assert.Equal(t, "Timnas \"Garuda\"", row["Title"])
+	assert.Contains(t, row["Topics"], `"Name":"Sepak Bola"`)
+	assert.Equal(t, "", row["Tags"])
+	assert.Equal(t, "2018-10-25T10:00:00Z", row["PublishAt"])
+	assert.Equal(t, "", row["UnpublishAt"])
+
+	hits := chronicle.StorySearchHits{{Story: stories[0], Rank: 0.5, Snippet: "Timnas"}}
+	rows, err = render.CSVRows(hits)
+	assert.NoError(t, err)
+	assert.Equal(t, "ID", rows[0][0])
+	assert.Equal(t, []string{"Rank", "Snippet"}, rows[0][len(rows[0])-2:])
+	assert.Equal(t, []string{"0.5", "Timnas"}, rows[1][len(rows[1])-2:])
+
+	rows, err = render.CSVRows([]string{"a", "b"})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"Value"}, {"a"}, {"b"}}, rows)
+
+	// text that spreadsheets would evaluate as a formula is quoted, numbers are kept as they are
+	rows, err = render.CSVRows([]interface{}{"=HYPERLINK(\"http://evil\")", "+1", "-1", "@SUM(A1)", "\tcmd", -1, "a=b"})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"Value"}, {"'=HYPERLINK(\"http://evil\")"}, {"'+1"}, {"'-1"}, {"'@SUM(A1)"}, {"'\tcmd"}, {"-1"}, {"a=b"}}, rows)
+
+	_, err = render.CSVRows(stories[0])
+	assert.Error(t, err)
+}
@@ -0,0 +1,134 @@
+package render
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+)
+
+//MsgPack render body as MessagePack, body is encoded as it would be as json so field names and omitted fields are the same
+func MsgPack(res http.ResponseWriter, code int, body interface{}) error {
+	encoded, err := EncodeMsgPack(body)
+	if err != nil {
+		return err
+	}
+
+	res.Header().Set("Content-Type", "application/msgpack")
+	res.WriteHeader(code)
+	_, err = res.Write(encoded)
+	return err
+}
+
+//EncodeMsgPack encode value as MessagePack through its json representation, map keys are sorted
+func EncodeMsgPack(value interface{}) ([]byte, error) {
+	jsonValue, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var generic interface{}
+	decoder := json.NewDecoder(bytes.NewReader(jsonValue))
+	decoder.UseNumber()
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	writeMsgPack(buf, generic)
+	return buf.Bytes(), nil
+}
+
+// writeMsgPack write a value decoded from json, which is nil, bool, json.Number, string, []interface{} or map[string]interface{}
+func writeMsgPack(buf *bytes.Buffer, value interface{}) {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteByte(0xc0)
+	case bool:
+		if v {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			writeMsgPackInt(buf, i)
+			return
+		}
+		f, _ := v.Float64()
+		buf.WriteByte(0xcb)
+		binary.Write(buf, binary.BigEndian, math.Float64bits(f))
+	case string:
+		writeMsgPackString(buf, v)
+	case []interface{}:
+		writeMsgPackLength(buf, len(v), 0x90, 15, 0xdc, 0xdd)
+		for _, elem := range v {
+			writeMsgPack(buf, elem)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		writeMsgPackLength(buf, len(v), 0x80, 15, 0xde, 0xdf)
+		for _, key := range keys {
+			writeMsgPackString(buf, key)
+			writeMsgPack(buf, v[key])
+		}
+	}
+}
+
+func writeMsgPackInt(buf *bytes.Buffer, i int64) {
+	switch {
+	case i >= 0 && i <= 127:
+		buf.WriteByte(byte(i))
+	case i < 0 && i >= -32:
+		buf.WriteByte(byte(int8(i)))
+	case i >= math.MinInt8 && i <= math.MaxInt8:
+		buf.WriteByte(0xd0)
+		buf.WriteByte(byte(int8(i)))
+	case i >= math.MinInt16 && i <= math.MaxInt16:
+		buf.WriteByte(0xd1)
+		binary.Write(buf, binary.BigEndian, int16(i))
+	case i >= math.MinInt32 && i <= math.MaxInt32:
+		buf.WriteByte(0xd2)
+		binary.Write(buf, binary.BigEndian, int32(i))
+	default:
+		buf.WriteByte(0xd3)
+		binary.Write(buf, binary.BigEndian, i)
+	}
+}
+
+func writeMsgPackString(buf *bytes.Buffer, s string) {
+	if len(s) <= 31 {
+		buf.WriteByte(0xa0 | byte(len(s)))
+	} else if len(s) <= math.MaxUint8 {
+		buf.WriteByte(0xd9)
+		buf.WriteByte(byte(len(s)))
+	} else if len(s) <= math.MaxUint16 {
+		buf.WriteByte(0xda)
+		binary.Write(buf, binary.BigEndian, uint16(len(s)))
+	} else {
+		buf.WriteByte(0xdb)
+		binary.Write(buf, binary.BigEndian, uint32(len(s)))
+	}
+	buf.WriteString(s)
+}
+
+// writeMsgPackLength write the header of an array or a map, fix formats hold up to fixMax elements in their first byte
+func writeMsgPackLength(buf *bytes.Buffer, length int, fixPrefix byte, fixMax int, prefix16 byte, prefix32 byte) {
+	switch {
+	case length <= fixMax:
+		buf.WriteByte(fixPrefix | byte(length))
+	case length <= math.MaxUint16:
+		buf.WriteByte(prefix16)
+		binary.Write(buf, binary.BigEndian, uint16(length))
+	default:
+		buf.WriteByte(prefix32)
+		binary.Write(buf, binary.BigEndian, uint32(length))
+	}
+}
@@ -0,0 +1,51 @@
+package render_test
+
+import (
+	"testing"
+
+	"github.com/AdhityaRamadhanus/chronicle/server/render"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeMsgPack(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Value    interface{}
+		Expected []byte
+	}{
+		{Name: "nil", Value: nil, Expected: []byte{0xc0}},
+		{Name: "bool", Value: true, Expected: []byte{0xc3}},
+		{Name: "positive fixint", Value: 7, Expected: []byte{0x07}},
+		{Name: "negative fixint", Value: -3, Expected: []byte{0xfd}},
+		{Name: "int16", Value: 300, Expected: []byte{0xd1, 0x01, 0x2c}},
+		{Name: "int32", Value: -70000, Expected: []byte{0xd2, 0xff, 0xfe, 0xee, 0x90}},
+		{Name: "float", Value: 0.5, Expected: []byte{0xcb, 0x3f, 0xe0, 0, 0, 0, 0, 0, 0}},
+		{Name: "fixstr", Value: "abc", Expected: []byte{0xa3, 'a', 'b', 'c'}},
+		{Name: "array", Value: []int{1, 2}, Expected: []byte{0x92, 0x01, 0x02}},
+		{
+			Name:     "map sorted by key",
+			Value:    map[string]interface{}{"b": 1, "a": "x"},
+			Expected: []byte{0x82, 0xa1, 'a', 0xa1, 'x', 0xa1, 'b', 0x01},
+		},
+		{
+			Name:     "struct as json",
+			Value:    struct{ ID int }{ID: 2},
+			Expected: []byte{0x81, 0xa2, 'I', 'D', 0x02},
+		},
+	}
+
+	for _, testCase := range testCases {
+		encoded, err := render.EncodeMsgPack(testCase.Value)
+		assert.NoError(t, err, testCase.Name)
+		assert.Equal(t, testCase.Expected, encoded, testCase.Name)
+	}
+
+	longString := make([]byte, 40)
+	for idx := range longString {
+		longString[idx] = 'a'
+	}
+	encoded, err := render.EncodeMsgPack(string(longString))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0xd9, 40}, encoded[:2])
+	assert.Len(t, encoded, 42)
+}
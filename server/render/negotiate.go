@@ -0,0 +1,153 @@
+package render
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var (
+	//JSONFormat render body as json
+	JSONFormat = "json"
+	//MsgPackFormat render body as MessagePack
+	MsgPackFormat = "msgpack"
+	//CSVFormat render the listed entities of body as csv rows
+	CSVFormat = "csv"
+
+	//Formats are every format Negotiate can render, by preference when a client accept several equally
+	Formats = []string{JSONFormat, MsgPackFormat, CSVFormat}
+)
+
+// media types clients ask for each format with
+var formatMediaTypes = map[string][]string{
+	JSONFormat:    {"application/json"},
+	MsgPackFormat: {"application/msgpack", "application/x-msgpack"},
+	CSVFormat:     {"text/csv"},
+}
+
+type acceptedRange struct {
+	mediaType string
+	quality   float64
+}
+
+// parseAccept read the media ranges of an Accept header, a range without quality has quality 1
+func parseAccept(accept string) []acceptedRange {
+	ranges := []acceptedRange{}
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+		if mediaType == "" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		ranges = append(ranges, acceptedRange{mediaType: mediaType, quality: quality})
+	}
+	return ranges
+}
+
+// specificity of the range for mediaType, an exact match beat type/* which beat */*, 0 when it does not match
+func (accepted acceptedRange) specificity(mediaType string) int {
+	switch {
+	case accepted.mediaType == mediaType:
+		return 3
+	case accepted.mediaType == "*/*":
+		return 1
+	case strings.HasSuffix(accepted.mediaType, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(accepted.mediaType, "*")):
+		return 2
+	default:
+		return 0
+	}
+}
+
+// qualityOf mediaType is that of the most specific range matching it, -1 when none match
+func qualityOf(ranges []acceptedRange, mediaType string) float64 {
+	quality, bestSpecificity := -1.0, 0
+	for _, accepted := range ranges {
+		if specificity := accepted.specificity(mediaType); specificity > bestSpecificity {
+			quality, bestSpecificity = accepted.quality, specificity
+		}
+	}
+	return quality
+}
+
+/*
+NegotiateFormat pick one of offered formats for req, the format query string override Accept header
+and a request without either get the first offered format. It is false when req accept none of them
+*/
+func NegotiateFormat(req *http.Request, offered []string) (string, bool) {
+	if format := req.URL.Query().Get("format"); format != "" {
+		for _, offeredFormat := range offered {
+			if offeredFormat == format {
+				return format, true
+			}
+		}
+		return "", false
+	}
+
+	accept := req.Header.Get("Accept")
+	if strings.TrimSpace(accept) == "" {
+		return offered[0], true
+	}
+
+	ranges := parseAccept(accept)
+	bestFormat, bestQuality := "", 0.0
+	for _, format := range offered {
+		for _, mediaType := range formatMediaTypes[format] {
+			if quality := qualityOf(ranges, mediaType); quality > bestQuality {
+				bestFormat, bestQuality = format, quality
+			}
+		}
+	}
+
+	return bestFormat, bestFormat != ""
+}
+
+//FormatRecorder is implemented by response writers that need to know the format Negotiate picked, like response caches
+type FormatRecorder interface {
+	RecordFormat(format string)
+}
+
+/*
+Negotiate render body in the format negotiated for req, list is what body list and is rendered as csv rows,
+csv is not offered when list is nil. A request accepting none of the offered formats get 406
+*/
+func Negotiate(res http.ResponseWriter, req *http.Request, code int, body interface{}, list interface{}) error {
+	offered := []string{JSONFormat, MsgPackFormat}
+	if list != nil {
+		offered = append(offered, CSVFormat)
+	}
+
+	res.Header().Add("Vary", "Accept")
+	format, ok := NegotiateFormat(req, offered)
+	if !ok {
+		return JSON(res, http.StatusNotAcceptable, map[string]interface{}{
+			"status": http.StatusNotAcceptable,
+			"error": map[string]interface{}{
+				"code":    "ErrNotAcceptable",
+				"message": "Response can only be rendered as " + strings.Join(offered, ", "),
+			},
+		})
+	}
+
+	if recorder, ok := res.(FormatRecorder); ok {
+		recorder.RecordFormat(format)
+	}
+
+	switch format {
+	case MsgPackFormat:
+		return MsgPack(res, code, body)
+	case CSVFormat:
+		return CSV(res, code, list)
+	default:
+		return JSON(res, code, body)
+	}
+}
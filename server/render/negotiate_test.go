@@ -0,0 +1,117 @@
+package render_test
+
+import (
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AdhityaRamadhanus/chronicle"
+	"github.com/AdhityaRamadhanus/chronicle/server/render"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiateFormat(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		URL      string
+		Accept   string
+		Offered  []string
+		Expected string
+		OK       bool
+	}{
+		{Name: "no accept", URL: "/stories", Offered: render.Formats, Expected: render.JSONFormat, OK: true},
+		{Name: "any", URL: "/stories", Accept: "*/*", Offered: render.Formats, Expected: render.JSONFormat, OK: true},
+		{Name: "csv", URL: "/stories", Accept: "text/csv", Offered: render.Formats, Expected: render.CSVFormat, OK: true},
+		{Name: "legacy msgpack", URL: "/stories", Accept: "application/x-msgpack", Offered: render.Formats, Expected: render.MsgPackFormat, OK: true},
+		{Name: "quality", URL: "/stories", Accept: "application/json;q=0.5, text/csv", Offered: render.Formats, Expected: render.CSVFormat, OK: true},
+		{Name: "specific range win", URL: "/stories", Accept: "text/*;q=0.9, text/csv;q=0, application/json;q=0.1", Offered: render.Formats, Expected: render.JSONFormat, OK: true},
+		{Name: "browser", URL: "/stories", Accept: "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", Offered: render.Formats, Expected: render.JSONFormat, OK: true},
+		{Name: "override", URL: "/stories?format=msgpack", Accept: "text/csv", Offered: render.Formats, Expected: render.MsgPackFormat, OK: true},
+		{Name: "unsupported override", URL: "/stories?format=xml", Offered: render.Formats, OK: false},
+		{Name: "unsupported", URL: "/stories", Accept: "application/xml", Offered: render.Formats, OK: false},
+		{Name: "not offered", URL: "/stories", Accept: "text/csv", Offered: []string{render.JSONFormat, render.MsgPackFormat}, OK: false},
+	}
+
+	for _, testCase := range testCases {
+		req := httptest.NewRequest("GET", testCase.URL, nil)
+		if testCase.Accept != "" {
+			req.Header.Set("Accept", testCase.Accept)
+		}
+
+		format, ok := render.NegotiateFormat(req, testCase.Offered)
+		assert.Equal(t, testCase.OK, ok, testCase.Name)
+		assert.Equal(t, testCase.Expected, format, testCase.Name)
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	createdAt := time.Date(2018, 10, 25, 10, 0, 0, 0, time.UTC)
+	tagCounts := chronicle.TagCounts{
+		{Tag: chronicle.Tag{ID: 1, Name: "Sepak Bola, Liga", Slug: "sepak-bola-liga", CreatedAt: createdAt, UpdatedAt: createdAt}, Count: 3},
+	}
+	body := map[string]interface{}{
+		"status": http.StatusOK,
+		"tags":   tagCounts,
+	}
+
+	testCases := []struct {
+		Name                string
+		Accept              string
+		List                interface{}
+		ExpectedStatus      int
+		ExpectedContentType string
+	}{
+		{Name: "json", Accept: "application/json", List: tagCounts, ExpectedStatus: http.StatusOK, ExpectedContentType: "application/json; charset=utf-8"},
+		{Name: "msgpack", Accept: "application/msgpack", List: tagCounts, ExpectedStatus: http.StatusOK, ExpectedContentType: "application/msgpack"},
+		{Name: "csv", Accept: "text/csv", List: tagCounts, ExpectedStatus: http.StatusOK, ExpectedContentType: "text/csv; charset=utf-8"},
+		{Name: "csv without list", Accept: "text/csv", ExpectedStatus: http.StatusNotAcceptable, ExpectedContentType: "application/json; charset=utf-8"},
+		{Name: "unsupported", Accept: "application/xml", List: tagCounts, ExpectedStatus: http.StatusNotAcceptable, ExpectedContentType: "application/json; charset=utf-8"},
+	}
+
+	for _, testCase := range testCases {
+		req := httptest.NewRequest("GET", "/tags/counts", nil)
+		req.Header.Set("Accept", testCase.Accept)
+		res := httptest.NewRecorder()
+
+		err := render.Negotiate(res, req, http.StatusOK, body, testCase.List)
+		assert.NoError(t, err, testCase.Name)
+		assert.Equal(t, testCase.ExpectedStatus, res.Code, testCase.Name)
+		assert.Equal(t, testCase.ExpectedContentType, res.Header().Get("Content-Type"), testCase.Name)
+		assert.Equal(t, "Accept", res.Header().Get("Vary"), testCase.Name)
+	}
+
+	req := httptest.NewRequest("GET", "/tags/counts?format=csv", nil)
+	res := httptest.NewRecorder()
+	render.Negotiate(res, req, http.StatusOK, body, tagCounts)
+
+	rows, err := csv.NewReader(strings.NewReader(res.Body.String())).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"ID", "Name", "Slug", "CreatedAt", "UpdatedAt", "Count"},
+		{"1", "Sepak Bola, Liga", "sepak-bola-liga", "2018-10-25T10:00:00Z", "2018-10-25T10:00:00Z", "3"},
+	}, rows)
+}
+
+type formatRecorder struct {
+	*httptest.ResponseRecorder
+	format string
+}
+
+func (recorder *formatRecorder) RecordFormat(format string) {
+	recorder.format = format
+}
+
+func TestNegotiateRecordFormat(t *testing.T) {
+	// csv is preferred but not offered without a list, the format actually rendered is recorded
+	req := httptest.NewRequest("GET", "/stories/1", nil)
+	req.Header.Set("Accept", "text/csv, application/msgpack;q=0.5")
+	res := &formatRecorder{ResponseRecorder: httptest.NewRecorder()}
+
+	err := render.Negotiate(res, req, http.StatusOK, map[string]interface{}{"status": http.StatusOK}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, render.MsgPackFormat, res.format)
+	assert.Equal(t, "application/msgpack", res.Header().Get("Content-Type"))
+}
@@ -0,0 +1,16 @@
+package render
+
+import (
+	"encoding/xml"
+	"net/http"
+)
+
+//XML write body as an xml document served with contentType
+func XML(res http.ResponseWriter, code int, contentType string, body interface{}) error {
+	res.Header().Set("Content-Type", contentType)
+	res.WriteHeader(code)
+	if _, err := res.Write([]byte(xml.Header)); err != nil {
+		return err
+	}
+	return xml.NewEncoder(res).Encode(body)
+}
@@ -18,17 +18,20 @@ type Server struct {
 
 //NewServer create Server from Handler
 func NewServer(Handlers []Handler) *Server {
-	router := mux.NewRouter().
-		StrictSlash(true).
+	rootRouter := mux.NewRouter().StrictSlash(true)
+	router := rootRouter.
 		PathPrefix("/api").
 		Subrouter()
 
 	for _, handler := range Handlers {
 		handler.RegisterRoutes(router)
+		if rootHandler, ok := handler.(RootHandler); ok {
+			rootHandler.RegisterRootRoutes(rootRouter)
+		}
 	}
 
 	return &Server{
-		Router: router,
+		Router: rootRouter,
 		Port:   os.Getenv("PORT"),
 	}
 }
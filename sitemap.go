@@ -0,0 +1,31 @@
+package chronicle
+
+import "time"
+
+var (
+	//SitemapPageSize is the most urls a sitemap may list
+	SitemapPageSize = 50000
+	//NewsSitemapSize is the most urls a news sitemap may list
+	NewsSitemapSize = 1000
+	//NewsSitemapWindow is how long a published story is listed in news sitemap
+	NewsSitemapWindow = 48 * time.Hour
+)
+
+//SitemapPage is a chunk of a sitemap, numbered from 1
+type SitemapPage struct {
+	Page int
+	// latest update of the entries listed in the page
+	UpdatedAt time.Time `db:"updatedat"`
+}
+
+//SitemapPages short way to define array of sitemap page
+type SitemapPages []SitemapPage
+
+//SitemapRepository provide an interface to get entities listed in sitemaps, stories are only listed once published
+type SitemapRepository interface {
+	StoryPages(pageSize int) (SitemapPages, error)
+	FindStories(page int, pageSize int) (Stories, error)
+	TopicPages(pageSize int) (SitemapPages, error)
+	FindTopics(page int, pageSize int) (Topics, error)
+	FindNewsStories(since time.Time, limit int) (Stories, error)
+}
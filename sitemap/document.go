@@ -0,0 +1,139 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AdhityaRamadhanus/chronicle"
+)
+
+const (
+	sitemapNamespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+	newsNamespace    = "http://www.google.com/schemas/sitemap-news/0.9"
+)
+
+//ContentType is the content type sitemaps are served with
+const ContentType = "application/xml; charset=utf-8"
+
+//Site describe where sitemaps list urls of, StoryURL and TopicURL are templates with {slug} replaced, sitemaps themselves are served from APIURL
+type Site struct {
+	Name     string
+	Language string
+	StoryURL string
+	TopicURL string
+	APIURL   string
+}
+
+//Index is sitemap index document
+type Index struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	Xmlns    string       `xml:"xmlns,attr"`
+	Sitemaps []IndexEntry `xml:"sitemap"`
+}
+
+//IndexEntry is a sitemap listed in sitemap index
+type IndexEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod"`
+}
+
+//URLSet is sitemap document, news sitemaps add the news namespace
+type URLSet struct {
+	XMLName   xml.Name `xml:"urlset"`
+	Xmlns     string   `xml:"xmlns,attr"`
+	NewsXmlns string   `xml:"xmlns:news,attr,omitempty"`
+	URLs      []URL    `xml:"url"`
+}
+
+//URL is an url listed in sitemap
+type URL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod"`
+	News    *News  `xml:"news:news"`
+}
+
+//News describe a story in news sitemap
+type News struct {
+	Publication     NewsPublication `xml:"news:publication"`
+	PublicationDate string          `xml:"news:publication_date"`
+	Title           string          `xml:"news:title"`
+}
+
+//NewsPublication is the publication a story in news sitemap belong to
+type NewsPublication struct {
+	Name     string `xml:"news:name"`
+	Language string `xml:"news:language"`
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func (s Site) sitemapURL(name string, page int) string {
+	return fmt.Sprintf("%s/sitemap-%s-%d.xml", strings.TrimSuffix(s.APIURL, "/"), name, page)
+}
+
+//NewIndex build sitemap index listing every page of stories and topics sitemaps
+func NewIndex(site Site, storyPages chronicle.SitemapPages, topicPages chronicle.SitemapPages) Index {
+	entries := []IndexEntry{}
+	for _, page := range storyPages {
+		entries = append(entries, IndexEntry{Loc: site.sitemapURL("stories", page.Page), LastMod: formatTime(page.UpdatedAt)})
+	}
+
+	for _, page := range topicPages {
+		entries = append(entries, IndexEntry{Loc: site.sitemapURL("topics", page.Page), LastMod: formatTime(page.UpdatedAt)})
+	}
+
+	return Index{Xmlns: sitemapNamespace, Sitemaps: entries}
+}
+
+//NewStoriesURLSet build sitemap of stories
+func NewStoriesURLSet(site Site, stories chronicle.Stories) URLSet {
+	urls := []URL{}
+	for _, story := range stories {
+		urls = append(urls, URL{
+			Loc:     strings.Replace(site.StoryURL, "{slug}", story.Slug, -1),
+			LastMod: formatTime(story.UpdatedAt),
+		})
+	}
+
+	return URLSet{Xmlns: sitemapNamespace, URLs: urls}
+}
+
+//NewTopicsURLSet build sitemap of topics
+func NewTopicsURLSet(site Site, topics chronicle.Topics) URLSet {
+	urls := []URL{}
+	for _, topic := range topics {
+		urls = append(urls, URL{
+			Loc:     strings.Replace(site.TopicURL, "{slug}", topic.Slug, -1),
+			LastMod: formatTime(topic.UpdatedAt),
+		})
+	}
+
+	return URLSet{Xmlns: sitemapNamespace, URLs: urls}
+}
+
+//NewNewsURLSet build news sitemap of stories, they are dated by the time they were first published
+func NewNewsURLSet(site Site, stories chronicle.Stories) URLSet {
+	urls := []URL{}
+	for _, story := range stories {
+		publishedAt := story.CreatedAt
+		if story.PublishedAt != nil {
+			publishedAt = *story.PublishedAt
+		}
+
+		urls = append(urls, URL{
+			Loc:     strings.Replace(site.StoryURL, "{slug}", story.Slug, -1),
+			LastMod: formatTime(story.UpdatedAt),
+			News: &News{
+				Publication:     NewsPublication{Name: site.Name, Language: site.Language},
+				PublicationDate: formatTime(publishedAt),
+				Title:           story.Title,
+			},
+		})
+	}
+
+	return URLSet{Xmlns: sitemapNamespace, NewsXmlns: newsNamespace, URLs: urls}
+}
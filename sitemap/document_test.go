@@ -0,0 +1,64 @@
+package sitemap_test
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/AdhityaRamadhanus/chronicle"
+	"github.com/AdhityaRamadhanus/chronicle/sitemap"
+	"github.com/stretchr/testify/assert"
+)
+
+var testSite = sitemap.Site{
+	Name:     "Chronicle",
+	Language: "id",
+	StoryURL: "https://example.com/stories/{slug}",
+	TopicURL: "https://example.com/topics/{slug}",
+	APIURL:   "https://api.example.com/",
+}
+
+func TestNewIndex(t *testing.T) {
+	updatedAt := time.Date(2018, 10, 26, 10, 0, 0, 0, time.UTC)
+	index := sitemap.NewIndex(
+		testSite,
+		chronicle.SitemapPages{{Page: 1, UpdatedAt: updatedAt}, {Page: 2, UpdatedAt: updatedAt}},
+		chronicle.SitemapPages{{Page: 1, UpdatedAt: updatedAt}},
+	)
+	document, err := xml.Marshal(index)
+	assert.NoError(t, err)
+
+	assert.Equal(
+		t,
+		`<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`+
+			`<sitemap><loc>https://api.example.com/sitemap-stories-1.xml</loc><lastmod>2018-10-26T10:00:00Z</lastmod></sitemap>`+
+			`<sitemap><loc>https://api.example.com/sitemap-stories-2.xml</loc><lastmod>2018-10-26T10:00:00Z</lastmod></sitemap>`+
+			`<sitemap><loc>https://api.example.com/sitemap-topics-1.xml</loc><lastmod>2018-10-26T10:00:00Z</lastmod></sitemap>`+
+			`</sitemapindex>`,
+		string(document),
+	)
+}
+
+func TestNewNewsURLSet(t *testing.T) {
+	publishedAt := time.Date(2018, 10, 25, 17, 0, 0, 0, time.FixedZone("WIB", 7*60*60))
+	urlSet := sitemap.NewNewsURLSet(testSite, chronicle.Stories{
+		{
+			Title:       "Timnas & Jepang",
+			Slug:        "timnas-jepang",
+			PublishedAt: &publishedAt,
+			UpdatedAt:   time.Date(2018, 10, 26, 10, 0, 0, 0, time.UTC),
+		},
+	})
+	document, err := xml.Marshal(urlSet)
+	assert.NoError(t, err)
+
+	assert.Equal(
+		t,
+		`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9" xmlns:news="http://www.google.com/schemas/sitemap-news/0.9">`+
+			`<url><loc>https://example.com/stories/timnas-jepang</loc><lastmod>2018-10-26T10:00:00Z</lastmod>`+
+			`<news:news><news:publication><news:name>Chronicle</news:name><news:language>id</news:language></news:publication>`+
+			`<news:publication_date>2018-10-25T10:00:00Z</news:publication_date><news:title>Timnas &amp; Jepang</news:title></news:news>`+
+			`</url></urlset>`,
+		string(document),
+	)
+}
@@ -0,0 +1,113 @@
+package sitemap
+
+import (
+	"time"
+
+	"github.com/AdhityaRamadhanus/chronicle"
+	"github.com/AdhityaRamadhanus/chronicle/function"
+	"github.com/pkg/errors"
+)
+
+var (
+	//ErrNoSitemapPageFound sub-domain specific error
+	ErrNoSitemapPageFound = errors.New("Cannot find Sitemap Page")
+)
+
+//Service provide an interface to sitemap domain service
+type Service interface {
+	GetIndex() (storyPages chronicle.SitemapPages, topicPages chronicle.SitemapPages, err error)
+	GetStoriesPage(page int) (chronicle.Stories, error)
+	GetTopicsPage(page int) (chronicle.Topics, error)
+	GetNewsStories(now time.Time) (chronicle.Stories, error)
+}
+
+func NewService(sitemapRepository chronicle.SitemapRepository) Service {
+	return &service{
+		sitemapRepository: sitemapRepository,
+	}
+}
+
+type service struct {
+	sitemapRepository chronicle.SitemapRepository
+}
+
+// GetIndex list the pages of stories and topics sitemaps
+func (s *service) GetIndex() (storyPages chronicle.SitemapPages, topicPages chronicle.SitemapPages, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Wrap(err, function.GetFunctionName(s.GetIndex))
+		}
+	}()
+
+	storyPages, err = s.sitemapRepository.StoryPages(chronicle.SitemapPageSize)
+	if err != nil {
+		return chronicle.SitemapPages{}, chronicle.SitemapPages{}, err
+	}
+
+	topicPages, err = s.sitemapRepository.TopicPages(chronicle.SitemapPageSize)
+	if err != nil {
+		return chronicle.SitemapPages{}, chronicle.SitemapPages{}, err
+	}
+
+	return storyPages, topicPages, nil
+}
+
+// GetStoriesPage list published stories of page, a page past the last one is not found
+func (s *service) GetStoriesPage(page int) (stories chronicle.Stories, err error) {
+	defer func() {
+		if err != nil && err != ErrNoSitemapPageFound {
+			err = errors.Wrap(err, function.GetFunctionName(s.GetStoriesPage))
+		}
+	}()
+
+	if page < 1 {
+		return chronicle.Stories{}, ErrNoSitemapPageFound
+	}
+
+	stories, err = s.sitemapRepository.FindStories(page, chronicle.SitemapPageSize)
+	if err != nil {
+		return chronicle.Stories{}, err
+	}
+
+	// the first page is listed even when there is nothing published yet
+	if len(stories) == 0 && page > 1 {
+		return chronicle.Stories{}, ErrNoSitemapPageFound
+	}
+
+	return stories, nil
+}
+
+// GetTopicsPage list topics of page, a page past the last one is not found
+func (s *service) GetTopicsPage(page int) (topics chronicle.Topics, err error) {
+	defer func() {
+		if err != nil && err != ErrNoSitemapPageFound {
+			err = errors.Wrap(err, function.GetFunctionName(s.GetTopicsPage))
+		}
+	}()
+
+	if page < 1 {
+		return chronicle.Topics{}, ErrNoSitemapPageFound
+	}
+
+	topics, err = s.sitemapRepository.FindTopics(page, chronicle.SitemapPageSize)
+	if err != nil {
+		return chronicle.Topics{}, err
+	}
+
+	if len(topics) == 0 && page > 1 {
+		return chronicle.Topics{}, ErrNoSitemapPageFound
+	}
+
+	return topics, nil
+}
+
+// GetNewsStories list stories published within NewsSitemapWindow before now
+func (s *service) GetNewsStories(now time.Time) (stories chronicle.Stories, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Wrap(err, function.GetFunctionName(s.GetNewsStories))
+		}
+	}()
+
+	return s.sitemapRepository.FindNewsStories(now.UTC().Add(-chronicle.NewsSitemapWindow), chronicle.NewsSitemapSize)
+}
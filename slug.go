@@ -1,21 +1,212 @@
 package chronicle
 
 import (
-	"regexp"
+	"fmt"
 	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/unicode/norm"
+)
+
+var (
+	//ErrSlugConflict returned by repositories when a concurrent write took the slug after it was checked
+	ErrSlugConflict = errors.New("Slug Conflict")
 )
 
+//SlugAttempts is how many times RetrySlugConflict run a write
+const SlugAttempts = 5
+
+//SlugColumnLength is the length of slug columns, in characters
+const SlugColumnLength = 255
+
+//MaxSlugLength is the longest slug SlugifyWithOptions return, room is left for the -N suffix of UniqueSlug
+const MaxSlugLength = SlugColumnLength - slugSuffixLength
+
+// a dash and up to 9 digits
+const slugSuffixLength = 10
+
+//SlugOptions tune slug created by SlugifyWithOptions
+type SlugOptions struct {
+	// MaxLength cut the slug on a word boundary, zero or anything above MaxSlugLength mean MaxSlugLength
+	MaxLength int
+	// StopWords are left out of the slug unless every word is a stop word
+	StopWords []string
+}
+
+// letters that do not decompose into ascii base letter and combining marks
+var transliterations = map[rune]string{
+	// latin
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'đ': "d", 'ð': "d", 'þ': "th",
+	'ł': "l", 'ı': "i", 'ħ': "h", 'ŋ': "n", 'ŧ': "t", 'ſ': "s",
+	// cyrillic
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "",
+	'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya", 'є': "ye", 'і': "i",
+	'ї': "yi", 'ґ': "g", 'ў': "u", 'ј': "j", 'љ': "lj", 'њ': "nj", 'ћ': "c",
+	'ђ': "dj", 'џ': "dz",
+	// greek
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i",
+	'θ': "th", 'ι': "i", 'κ': "k", 'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x",
+	'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t", 'υ': "y",
+	'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o",
+}
+
 //Slugify return a human readable slug of a text
 func Slugify(text string) string {
-	wordsLowerCased := []string{}
-	for _, word := range strings.Split(text, " ") {
-		regex, _ := regexp.Compile("[a-zA-Z0-9]+")
-		matchedString := regex.FindString(word)
+	return SlugifyWithOptions(text, SlugOptions{})
+}
+
+//SlugifyWithOptions return a human readable slug of a text, shortened and without stop words
+func SlugifyWithOptions(text string, options SlugOptions) string {
+	maxLength := options.MaxLength
+	if maxLength <= 0 || maxLength > MaxSlugLength {
+		maxLength = MaxSlugLength
+	}
+
+	words := slugWords(text)
+
+	if len(options.StopWords) > 0 {
+		stopWords := map[string]bool{}
+		for _, stopWord := range options.StopWords {
+			stopWords[strings.ToLower(stopWord)] = true
+		}
+
+		keptWords := []string{}
+		for _, word := range words {
+			if !stopWords[word] {
+				keptWords = append(keptWords, word)
+			}
+		}
+
+		if len(keptWords) > 0 {
+			words = keptWords
+		}
+	}
+
+	slug := ""
+	for _, word := range words {
+		candidate := word
+		if slug != "" {
+			candidate = slug + "-" + word
+		}
+
+		if len([]rune(candidate)) > maxLength {
+			break
+		}
+		slug = candidate
+	}
+
+	// a single word longer than the limit is cut as is
+	if slug == "" && len(words) > 0 {
+		slug = strings.Trim(string([]rune(words[0])[:maxLength]), "-")
+	}
+
+	return slug
+}
+
+/*
+UniqueSlug return slug, or slug suffixed with -2, -3 and so on when taken report it is already used.
+Slugs not made by SlugifyWithOptions are cut before suffixing so the suffix still fit in SlugColumnLength
+*/
+func UniqueSlug(slug string, taken func(candidate string) (bool, error)) (string, error) {
+	base := slug
+	if runes := []rune(base); len(runes) > MaxSlugLength {
+		base = strings.TrimRight(string(runes[:MaxSlugLength]), "-")
+	}
+
+	candidate := slug
+	for suffix := 2; ; suffix++ {
+		isTaken, err := taken(candidate)
+		if err != nil {
+			return "", err
+		}
+
+		if !isTaken {
+			return candidate, nil
+		}
+
+		candidate = fmt.Sprintf("%s-%d", base, suffix)
+	}
+}
+
+/*
+RetrySlugConflict run write again while it fail with ErrSlugConflict, write must resolve its slug again
+so the slug taken meanwhile is seen and the next suffix is picked
+*/
+func RetrySlugConflict(write func() error) (err error) {
+	for attempt := 0; attempt < SlugAttempts; attempt++ {
+		if err = write(); errors.Cause(err) != ErrSlugConflict {
+			return err
+		}
+	}
+
+	return err
+}
+
+/*
+slugWords split text into lower cased transliterated words,
+hyphenated words are kept whole and apostrophes are dropped
+*/
+func slugWords(text string) []string {
+	words := []string{}
+	parts := []string{}
+	part := strings.Builder{}
+
+	flushPart := func() {
+		if part.Len() > 0 {
+			parts = append(parts, part.String())
+			part.Reset()
+		}
+	}
+	flushWord := func() {
+		flushPart()
+		if len(parts) > 0 {
+			words = append(words, strings.Join(parts, "-"))
+			parts = []string{}
+		}
+	}
+
+	for _, r := range norm.NFC.String(text) {
+		r = unicode.ToLower(r)
+		switch {
+		case r == '\'' || r == '’' || r == 'ʼ' || r == '`':
+			continue
+		case r == '-' || r == '‐' || r == '‑':
+			flushPart()
+		case unicode.Is(unicode.Mn, r):
+			continue
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			part.WriteString(transliterate(r))
+		default:
+			flushWord()
+		}
+	}
+	flushWord()
+
+	return words
+}
+
+// transliterate return ascii spelling of r when known, otherwise r without its combining marks
+func transliterate(r rune) string {
+	if letters, ok := transliterations[r]; ok {
+		return letters
+	}
+
+	letters := strings.Builder{}
+	for _, decomposed := range norm.NFD.String(string(r)) {
+		if unicode.Is(unicode.Mn, decomposed) {
+			continue
+		}
 
-		if len(matchedString) > 0 {
-			wordsLowerCased = append(wordsLowerCased, strings.ToLower(matchedString))
+		if transliterated, ok := transliterations[decomposed]; ok {
+			letters.WriteString(transliterated)
+		} else {
+			letters.WriteRune(decomposed)
 		}
 	}
 
-	return strings.Join(wordsLowerCased, "-")
+	return letters.String()
 }
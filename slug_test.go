@@ -1,8 +1,10 @@
 package chronicle
 
 import (
+	"strings"
 	"testing"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -31,6 +33,43 @@ func TestSlugify(t *testing.T) {
 			"Widodo Beri Apresiasi, pada Pemain Bali United Usai Imbangi Persib",
 			"widodo-beri-apresiasi-pada-pemain-bali-united-usai-imbangi-persib",
 		},
+		{
+			"Café São Paulo",
+			"cafe-sao-paulo",
+		},
+		{
+			// decomposed input
+			"Cafe\u0301 Sa\u0303o Paulo",
+			"cafe-sao-paulo",
+		},
+		{
+			"Don't Stop Believin’",
+			"dont-stop-believin",
+		},
+		{
+			"State-of-the-art e-KTP",
+			"state-of-the-art-e-ktp",
+		},
+		{
+			"Straße Łódź Ærø",
+			"strasse-lodz-aero",
+		},
+		{
+			"Путин и Зеленский",
+			"putin-i-zelenskiy",
+		},
+		{
+			"Αθήνα 2004",
+			"athina-2004",
+		},
+		{
+			"東京 2020",
+			"東京-2020",
+		},
+		{
+			"Rupiah -- Melemah!",
+			"rupiah-melemah",
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -38,3 +77,162 @@ func TestSlugify(t *testing.T) {
 		assert.Equal(t, slug, testCase.ExpectedSlug, "Incorrect Slug")
 	}
 }
+
+func TestSlugifyWithOptions(t *testing.T) {
+	testCases := []struct {
+		Text         string
+		Options      SlugOptions
+		ExpectedSlug string
+	}{
+		{
+			"Widodo Beri Apresiasi pada Pemain Bali United",
+			SlugOptions{MaxLength: 30},
+			"widodo-beri-apresiasi-pada",
+		},
+		{
+			"Widodo Beri Apresiasi pada Pemain Bali United",
+			SlugOptions{MaxLength: 26},
+			"widodo-beri-apresiasi-pada",
+		},
+		{
+			"Widodo Beri Apresiasi pada Pemain Bali United",
+			SlugOptions{MaxLength: 25},
+			"widodo-beri-apresiasi",
+		},
+		{
+			"State-of-the-art Stadium",
+			SlugOptions{MaxLength: 20},
+			"state-of-the-art",
+		},
+		{
+			"Pneumonoultramicroscopicsilicovolcanoconiosis Outbreak",
+			SlugOptions{MaxLength: 10},
+			"pneumonoul",
+		},
+		{
+			"Jokowi dan Prabowo di Debat Pertama",
+			SlugOptions{StopWords: []string{"dan", "di", "yang"}},
+			"jokowi-prabowo-debat-pertama",
+		},
+		{
+			"The State of the Union",
+			SlugOptions{StopWords: []string{"the", "of"}},
+			"state-union",
+		},
+		{
+			"State-of-the-art",
+			SlugOptions{StopWords: []string{"the", "of"}},
+			"state-of-the-art",
+		},
+		{
+			// every word is a stop word
+			"Di Dan",
+			SlugOptions{StopWords: []string{"dan", "di"}},
+			"di-dan",
+		},
+		{
+			"Jokowi dan Prabowo di Debat Pertama",
+			SlugOptions{MaxLength: 20, StopWords: []string{"dan", "di"}},
+			"jokowi-prabowo-debat",
+		},
+	}
+
+	for _, testCase := range testCases {
+		slug := SlugifyWithOptions(testCase.Text, testCase.Options)
+		assert.Equal(t, slug, testCase.ExpectedSlug, "Incorrect Slug")
+	}
+
+	// transliteration lengthen text, slugs stay within the column with room for a suffix whatever the options
+	longText := strings.Repeat("щука ", 80)
+	for _, options := range []SlugOptions{{}, {MaxLength: 1000}} {
+		slug := SlugifyWithOptions(longText, options)
+		assert.True(t, len([]rune(slug)) <= MaxSlugLength, "slug of %d characters", len([]rune(slug)))
+		assert.True(t, strings.HasPrefix(slug, "shchuka-shchuka"))
+	}
+}
+
+func TestUniqueSlug(t *testing.T) {
+	testCases := []struct {
+		Slug         string
+		TakenSlugs   []string
+		ExpectedSlug string
+	}{
+		{
+			"pemilu-2019",
+			[]string{},
+			"pemilu-2019",
+		},
+		{
+			"pemilu-2019",
+			[]string{"pemilu-2019"},
+			"pemilu-2019-2",
+		},
+		{
+			"pemilu-2019",
+			[]string{"pemilu-2019", "pemilu-2019-2", "pemilu-2019-3"},
+			"pemilu-2019-4",
+		},
+		{
+			"pemilu-2019",
+			[]string{"pemilu-2019-2"},
+			"pemilu-2019",
+		},
+		{
+			// slugs too long for a suffix are cut before suffixing
+			strings.Repeat("a", SlugColumnLength),
+			[]string{strings.Repeat("a", SlugColumnLength)},
+			strings.Repeat("a", MaxSlugLength) + "-2",
+		},
+	}
+
+	for _, testCase := range testCases {
+		slug, err := UniqueSlug(testCase.Slug, func(candidate string) (bool, error) {
+			for _, takenSlug := range testCase.TakenSlugs {
+				if takenSlug == candidate {
+					return true, nil
+				}
+			}
+			return false, nil
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, slug, testCase.ExpectedSlug, "Incorrect Slug")
+	}
+}
+
+func TestRetrySlugConflict(t *testing.T) {
+	// a concurrent write take the slug between the check and the write of the first attempt
+	takenSlugs := map[string]bool{"pemilu-2019": true}
+	attempts := 0
+	written := ""
+	err := RetrySlugConflict(func() error {
+		attempts++
+		slug, err := UniqueSlug("pemilu-2019", func(candidate string) (bool, error) {
+			return takenSlugs[candidate], nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if attempts == 1 {
+			takenSlugs[slug] = true
+			return errors.Wrap(ErrSlugConflict, "Insert")
+		}
+
+		written = slug
+		return nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, attempts, 2)
+	assert.Equal(t, written, "pemilu-2019-3")
+
+	// conflicts are not retried forever
+	attempts = 0
+	err = RetrySlugConflict(func() error {
+		attempts++
+		return ErrSlugConflict
+	})
+	assert.Equal(t, err, ErrSlugConflict)
+	assert.Equal(t, attempts, SlugAttempts)
+}
@@ -0,0 +1,118 @@
+package filesystem
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	chronicle "github.com/AdhityaRamadhanus/chronicle"
+	function "github.com/AdhityaRamadhanus/chronicle/function"
+)
+
+var (
+	//ErrInvalidKey returned when a key would resolve outside of the blob store directory
+	ErrInvalidKey = errors.New("Invalid Blob Key")
+)
+
+//BlobStore implements chronicle.BlobStore interface on local filesystem
+type BlobStore struct {
+	directory string
+}
+
+//NewBlobStore construct a new BlobStore keeping blobs under directory
+func NewBlobStore(directory string) *BlobStore {
+	return &BlobStore{
+		directory: directory,
+	}
+}
+
+//Put write content to key, replacing any blob already stored there
+func (b BlobStore) Put(key string, content io.Reader) (err error) {
+	defer func() {
+		if err != nil && err != ErrInvalidKey {
+			err = errors.Wrap(err, function.GetFunctionName(b.Put))
+		}
+	}()
+
+	path, err := b.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	// write next to the destination then rename, readers never see a partial blob
+	tempFile, err := ioutil.TempFile(filepath.Dir(path), ".upload-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFile.Name())
+
+	if _, err := io.Copy(tempFile, content); err != nil {
+		tempFile.Close()
+		return err
+	}
+
+	if err := tempFile.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tempFile.Name(), path)
+}
+
+//Get open blob stored under key, caller must close it
+func (b BlobStore) Get(key string) (blob io.ReadCloser, err error) {
+	defer func() {
+		if err != nil && err != ErrInvalidKey && err != chronicle.ErrNoBlobFound {
+			err = errors.Wrap(err, function.GetFunctionName(b.Get))
+		}
+	}()
+
+	path, err := b.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, chronicle.ErrNoBlobFound
+	}
+
+	return file, err
+}
+
+//Delete remove blob stored under key, deleting a missing blob is not an error
+func (b BlobStore) Delete(key string) (err error) {
+	defer func() {
+		if err != nil && err != ErrInvalidKey {
+			err = errors.Wrap(err, function.GetFunctionName(b.Delete))
+		}
+	}()
+
+	path, err := b.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	return err
+}
+
+func (b BlobStore) path(key string) (string, error) {
+	cleanKey := filepath.Clean("/" + filepath.FromSlash(key))
+	if cleanKey == string(filepath.Separator) || strings.Contains(key, "\x00") {
+		return "", ErrInvalidKey
+	}
+
+	return filepath.Join(b.directory, cleanKey), nil
+}
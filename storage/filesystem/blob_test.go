@@ -0,0 +1,47 @@
+package filesystem_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AdhityaRamadhanus/chronicle"
+	"github.com/AdhityaRamadhanus/chronicle/storage/filesystem"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlobStore(t *testing.T) {
+	directory, err := ioutil.TempDir("", "chronicle-blobs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(directory)
+
+	blobStore := filesystem.NewBlobStore(directory)
+
+	err = blobStore.Put("2019/01/02/photo.jpg", strings.NewReader("photo"))
+	assert.NoError(t, err)
+
+	blob, err := blobStore.Get("2019/01/02/photo.jpg")
+	if assert.NoError(t, err) {
+		content, _ := ioutil.ReadAll(blob)
+		blob.Close()
+		assert.Equal(t, "photo", string(content))
+	}
+
+	// keys cannot escape the directory
+	err = blobStore.Put("../../escaped.jpg", strings.NewReader("escaped"))
+	assert.NoError(t, err)
+	_, err = os.Stat(filepath.Join(directory, "escaped.jpg"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, blobStore.Delete("2019/01/02/photo.jpg"))
+	assert.NoError(t, blobStore.Delete("2019/01/02/photo.jpg"), "Deleting missing blob should not fail")
+
+	_, err = blobStore.Get("2019/01/02/photo.jpg")
+	assert.Equal(t, chronicle.ErrNoBlobFound, err)
+
+	assert.Equal(t, filesystem.ErrInvalidKey, blobStore.Put("", strings.NewReader("")))
+}
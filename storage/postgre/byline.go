@@ -0,0 +1,181 @@
+package postgre
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	chronicle "github.com/AdhityaRamadhanus/chronicle"
+	function "github.com/AdhityaRamadhanus/chronicle/function"
+)
+
+//FindByContributor find stories credited to contributor x in any role
+func (s StoryRepository) FindByContributor(contributor int, option chronicle.PagingOptions) (stories chronicle.Stories, storiesCount int, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.FindByContributor))
+		}
+	}()
+
+	whereStatement := "WHERE EXISTS (SELECT 1 FROM story_contributors WHERE story_contributors.storyId=stories.id AND story_contributors.contributorId=$1)"
+
+	stories = chronicle.Stories{}
+	selectQuery := fmt.Sprintf(
+		`SELECT
+			stories.id,
+			stories.title,
+			stories.slug,
+			stories.excerpt,
+			stories.author,
+			stories.status,
+			stories.media,
+			stories.likes,
+			stories.shares,
+			stories.views,
+			stories.publishat,
+			stories.unpublishat,
+			stories.publishedat,
+			stories.createdat,
+			stories.updatedat
+		FROM stories
+		%s
+		ORDER BY %s %s
+		LIMIT %d
+		OFFSET %d`,
+		whereStatement,
+		option.SortBy,
+		option.Order,
+		option.Limit,
+		option.Offset,
+	)
+
+	err = s.db.Select(&stories, selectQuery, contributor)
+	if err != nil {
+		return chronicle.Stories{}, 0, err
+	}
+
+	if len(stories) == 0 {
+		return chronicle.Stories{}, 0, nil
+	}
+
+	countQuery := fmt.Sprintf(`SELECT count(*) FROM stories %s`, whereStatement)
+	row := s.db.QueryRow(countQuery, contributor)
+	row.Scan(&storiesCount)
+
+	if err := s.fillStoryAssociations(&stories); err != nil {
+		return chronicle.Stories{}, 0, err
+	}
+
+	return stories, storiesCount, nil
+}
+
+func (s StoryRepository) setBylinesForStory(tx dbExecutor, storyId int, bylines chronicle.Bylines) (err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.setBylinesForStory))
+		}
+	}()
+
+	// position follow the order of bylines, a contributor is credited once per role
+	contributorIds := []int64{}
+	roles := []string{}
+	positions := []int64{}
+	seenBylines := map[string]bool{}
+	seenContributorIds := map[int]bool{}
+	for _, byline := range bylines {
+		key := fmt.Sprintf("%d:%s", byline.ID, byline.Role)
+		if seenBylines[key] {
+			continue
+		}
+		seenBylines[key] = true
+		seenContributorIds[byline.ID] = true
+
+		contributorIds = append(contributorIds, int64(byline.ID))
+		roles = append(roles, byline.Role)
+		positions = append(positions, int64(len(positions)))
+	}
+
+	// validate first instead of leaking foreign key violation
+	if len(contributorIds) > 0 {
+		var foundContributorsCount int
+		err = tx.Get(&foundContributorsCount, `SELECT count(*) FROM contributors WHERE id = ANY($1)`, pq.Array(contributorIds))
+		if err != nil {
+			return err
+		}
+
+		if foundContributorsCount != len(seenContributorIds) {
+			return chronicle.ErrUnknownContributor
+		}
+	}
+
+	if _, err = tx.Exec(`DELETE FROM story_contributors WHERE storyId=$1`, storyId); err != nil {
+		return err
+	}
+
+	if len(contributorIds) == 0 {
+		return nil
+	}
+
+	insertQuery := `INSERT INTO story_contributors (
+			storyId,
+			contributorId,
+			role,
+			position,
+			createdAt
+		)
+		SELECT $1, bylines.contributorId, bylines.role, bylines.position, now()
+		FROM unnest($2::int[], $3::varchar[], $4::int[]) AS bylines(contributorId, role, position)`
+	_, err = tx.Exec(insertQuery, storyId, pq.Array(contributorIds), pq.Array(roles), pq.Array(positions))
+	return err
+}
+
+func (s StoryRepository) getBylinesForStories(stories *chronicle.Stories) (err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.getBylinesForStories))
+		}
+	}()
+
+	storyIds := []int64{}
+	storyBylines := map[int]chronicle.Bylines{}
+	for _, story := range *stories {
+		storyIds = append(storyIds, int64(story.ID))
+		storyBylines[story.ID] = chronicle.Bylines{}
+	}
+
+	bylineQuery := `SELECT
+			story_contributors.storyId,
+			contributors.id,
+			contributors.name,
+			contributors.slug,
+			contributors.bio,
+			contributors.photo,
+			contributors.createdat,
+			contributors.updatedat,
+			story_contributors.role,
+			story_contributors.position
+		FROM story_contributors
+		INNER JOIN contributors ON (story_contributors.contributorId = contributors.id)
+		WHERE story_contributors.storyId = ANY($1)
+		ORDER BY story_contributors.storyId, story_contributors.position`
+
+	rows := []struct {
+		StoryID int `db:"storyid"`
+		chronicle.Byline
+	}{}
+	if err := s.db.Select(&rows, bylineQuery, pq.Array(storyIds)); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		storyBylines[row.StoryID] = append(storyBylines[row.StoryID], row.Byline)
+	}
+
+	for idx, story := range *stories {
+		(*stories)[idx].Bylines = storyBylines[story.ID]
+	}
+
+	return nil
+}
@@ -0,0 +1,415 @@
+package postgre
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	chronicle "github.com/AdhityaRamadhanus/chronicle"
+	function "github.com/AdhityaRamadhanus/chronicle/function"
+)
+
+// compactCollectionQuery renumber stories of a collection from zero, closing gaps left by removed or deleted stories
+const compactCollectionQuery = `UPDATE collection_stories SET position=ordered.position
+	FROM (
+		SELECT storyId, row_number() OVER (ORDER BY position) - 1 AS position
+		FROM collection_stories
+		WHERE collectionId=$1
+	) ordered
+	WHERE collection_stories.collectionId=$1 AND collection_stories.storyId=ordered.storyId`
+
+/*
+CollectionRepository is implementation of CollectionRepository interface
+of chronicle domain using postgre
+*/
+type CollectionRepository struct {
+	db dbExecutor
+}
+
+//NewCollectionRepository is constructor to create collection repository
+func NewCollectionRepository(conn *sqlx.DB, tableName string) *CollectionRepository {
+	return &CollectionRepository{
+		db: conn,
+	}
+}
+
+//Find find collection by id
+func (s CollectionRepository) Find(id int) (collection chronicle.Collection, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.Find))
+		}
+	}()
+
+	collection = chronicle.Collection{}
+	query := `SELECT
+							id,
+							title,
+							slug,
+							description,
+							createdAt,
+							updatedAt
+						FROM collections
+						WHERE id=$1`
+
+	err = s.db.Get(&collection, query, id)
+	if err != nil {
+		return chronicle.Collection{}, err
+	}
+
+	collection.Stories, err = s.getStoriesForCollection(collection.ID)
+	return collection, err
+}
+
+//FindBySlug find collection by slug
+func (s CollectionRepository) FindBySlug(slug string) (collection chronicle.Collection, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.FindBySlug))
+		}
+	}()
+
+	collection = chronicle.Collection{}
+	query := `SELECT
+							id,
+							title,
+							slug,
+							description,
+							createdAt,
+							updatedAt
+						FROM collections
+						WHERE slug=$1`
+
+	err = s.db.Get(&collection, query, slug)
+	if err != nil {
+		return chronicle.Collection{}, err
+	}
+
+	collection.Stories, err = s.getStoriesForCollection(collection.ID)
+	return collection, err
+}
+
+//IsSlugTaken check whether slug is used by a collection other than exceptID
+func (s CollectionRepository) IsSlugTaken(slug string, exceptID int) (taken bool, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Wrap(err, function.GetFunctionName(s.IsSlugTaken))
+		}
+	}()
+
+	query := `SELECT EXISTS (SELECT 1 FROM collections WHERE slug=$1 AND id<>$2)`
+
+	err = s.db.Get(&taken, query, slug, exceptID)
+	return taken, err
+}
+
+//All get all collection, without their stories
+func (s CollectionRepository) All(option chronicle.PagingOptions) (collections chronicle.Collections, collectionsCount int, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.All))
+		}
+	}()
+
+	collections = chronicle.Collections{}
+	selectQuery := fmt.Sprintf(
+		`SELECT
+			id,
+			title,
+			slug,
+			description,
+			createdAt,
+			updatedAt
+		FROM collections
+		ORDER BY %s %s
+		LIMIT %d
+		OFFSET %d`,
+		option.SortBy,
+		option.Order,
+		option.Limit,
+		option.Offset,
+	)
+
+	err = s.db.Select(&collections, selectQuery)
+	if err != nil {
+		return chronicle.Collections{}, 0, err
+	}
+
+	countQuery := `SELECT count(*) FROM collections`
+	row := s.db.QueryRow(countQuery)
+	err = row.Scan(&collectionsCount)
+	return collections, collectionsCount, err
+}
+
+//Insert insert collection along with its stories in the given order
+func (s CollectionRepository) Insert(collection chronicle.Collection) (createdCollection chronicle.Collection, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.Insert))
+		}
+	}()
+
+	query := `INSERT INTO collections (
+							title,
+							slug,
+							description,
+							createdAt,
+							updatedAt
+						) VALUES (
+							:title,
+							:slug,
+							:description,
+							now(),
+							now()
+						) RETURNING id`
+
+	err = runInTx(s.db, func(tx dbExecutor) error {
+		rows, err := tx.NamedQuery(query, collection)
+		if isUniqueViolation(err, "collections_unique_slug") {
+			return chronicle.ErrSlugConflict
+		}
+		if err != nil {
+			return err
+		}
+
+		if rows.Next() {
+			rows.Scan(&collection.ID)
+		}
+		rows.Close()
+
+		storyIds := []int{}
+		for _, story := range collection.Stories {
+			storyIds = append(storyIds, story.ID)
+		}
+
+		return s.setStoriesForCollection(tx, collection.ID, storyIds)
+	})
+	if err != nil {
+		return chronicle.Collection{}, err
+	}
+
+	return s.Find(collection.ID)
+}
+
+//Update update title, slug and description of collection, use InsertStory, RemoveStory and ReorderStories to change its stories
+func (s CollectionRepository) Update(collection chronicle.Collection) (updatedCollection chronicle.Collection, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.Update))
+		}
+	}()
+
+	query := `UPDATE collections SET (
+							title,
+							slug,
+							description,
+							updatedAt
+						) = (
+							:title,
+							:slug,
+							:description,
+							now()
+						) WHERE id=:id`
+
+	_, err = s.db.NamedExec(query, collection)
+	if isUniqueViolation(err, "collections_unique_slug") {
+		return chronicle.Collection{}, chronicle.ErrSlugConflict
+	}
+	if err != nil {
+		return chronicle.Collection{}, err
+	}
+
+	return s.Find(collection.ID)
+}
+
+//Delete delete collection by id, its stories are left untouched
+func (s CollectionRepository) Delete(id int) (err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.Delete))
+		}
+	}()
+
+	_, err = s.db.Exec(`DELETE FROM collections WHERE id=$1`, id)
+	return err
+}
+
+//InsertStory put story at position of collection x, stories from that position onward move one place down
+func (s CollectionRepository) InsertStory(id int, storyID int, position int) (err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.InsertStory))
+		}
+	}()
+
+	shiftQuery := `UPDATE collection_stories SET position=position + 1 WHERE collectionId=$1 AND position >= $2`
+
+	// position past the end append the story
+	insertQuery := `INSERT INTO collection_stories (
+							collectionId,
+							storyId,
+							position,
+							createdAt
+						)
+						SELECT $1, $2, LEAST($3, count(*)), now() FROM collection_stories WHERE collectionId=$1`
+
+	return runInTx(s.db, func(tx dbExecutor) error {
+		if err := s.validateStories(tx, []int{storyID}); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(compactCollectionQuery, id); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(shiftQuery, id, position); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec(insertQuery, id, storyID, position)
+		return err
+	})
+}
+
+//RemoveStory take story out of collection x, stories after it move one place up
+func (s CollectionRepository) RemoveStory(id int, storyID int) (err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.RemoveStory))
+		}
+	}()
+
+	return runInTx(s.db, func(tx dbExecutor) error {
+		if _, err := tx.Exec(`DELETE FROM collection_stories WHERE collectionId=$1 AND storyId=$2`, id, storyID); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec(compactCollectionQuery, id)
+		return err
+	})
+}
+
+//ReorderStories place stories of collection x in the order of storyIDs, which must list every story of the collection
+func (s CollectionRepository) ReorderStories(id int, storyIDs []int) (err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.ReorderStories))
+		}
+	}()
+
+	return runInTx(s.db, func(tx dbExecutor) error {
+		return s.setStoriesForCollection(tx, id, storyIDs)
+	})
+}
+
+// setStoriesForCollection replace stories of collection, position follow the order of storyIds
+func (s CollectionRepository) setStoriesForCollection(tx dbExecutor, collectionId int, storyIds []int) (err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.setStoriesForCollection))
+		}
+	}()
+
+	// a story is collected once, at its first position
+	uniqueStoryIds := []int64{}
+	positions := []int64{}
+	seenStoryIds := map[int]bool{}
+	for _, storyId := range storyIds {
+		if seenStoryIds[storyId] {
+			continue
+		}
+		seenStoryIds[storyId] = true
+
+		uniqueStoryIds = append(uniqueStoryIds, int64(storyId))
+		positions = append(positions, int64(len(positions)))
+	}
+
+	storyIdsToValidate := []int{}
+	for storyId := range seenStoryIds {
+		storyIdsToValidate = append(storyIdsToValidate, storyId)
+	}
+	if err := s.validateStories(tx, storyIdsToValidate); err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(`DELETE FROM collection_stories WHERE collectionId=$1 AND NOT (storyId = ANY($2))`, collectionId, pq.Array(uniqueStoryIds)); err != nil {
+		return err
+	}
+
+	if len(uniqueStoryIds) == 0 {
+		return nil
+	}
+
+	// stories already collected keep the time they were added
+	insertQuery := `INSERT INTO collection_stories (
+			collectionId,
+			storyId,
+			position,
+			createdAt
+		)
+		SELECT $1, input.storyId, input.position, now()
+		FROM unnest($2::int[], $3::int[]) AS input(storyId, position)
+		ON CONFLICT (collectionId, storyId) DO UPDATE SET position=EXCLUDED.position`
+	_, err = tx.Exec(insertQuery, collectionId, pq.Array(uniqueStoryIds), pq.Array(positions))
+	return err
+}
+
+// validateStories return ErrUnknownStory instead of leaking foreign key violation
+func (s CollectionRepository) validateStories(tx dbExecutor, storyIds []int) (err error) {
+	if len(storyIds) == 0 {
+		return nil
+	}
+
+	ids := []int64{}
+	for _, storyId := range storyIds {
+		ids = append(ids, int64(storyId))
+	}
+
+	var foundStoriesCount int
+	err = tx.Get(&foundStoriesCount, `SELECT count(*) FROM stories WHERE id = ANY($1)`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+
+	if foundStoriesCount != len(ids) {
+		return chronicle.ErrUnknownStory
+	}
+
+	return nil
+}
+
+func (s CollectionRepository) getStoriesForCollection(collectionId int) (stories chronicle.Stories, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.getStoriesForCollection))
+		}
+	}()
+
+	storyQuery := `SELECT
+									stories.id,
+									stories.title,
+									stories.slug,
+									stories.excerpt,
+									stories.author,
+									stories.status,
+									stories.media,
+									stories.likes,
+									stories.shares,
+									stories.views,
+									stories.publishat,
+									stories.unpublishat,
+									stories.publishedat,
+									stories.createdat,
+									stories.updatedat
+								FROM collection_stories
+								INNER JOIN stories ON (collection_stories.storyId = stories.id)
+								WHERE collection_stories.collectionId=$1
+								ORDER BY collection_stories.position`
+
+	stories = chronicle.Stories{}
+	err = s.db.Select(&stories, storyQuery, collectionId)
+	return stories, err
+}
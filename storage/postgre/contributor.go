@@ -0,0 +1,215 @@
+package postgre
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	chronicle "github.com/AdhityaRamadhanus/chronicle"
+	function "github.com/AdhityaRamadhanus/chronicle/function"
+)
+
+/*
+ContributorRepository is implementation of ContributorRepository interface
+of chronicle domain using postgre
+*/
+type ContributorRepository struct {
+	db dbExecutor
+}
+
+//NewContributorRepository is constructor to create contributor repository
+func NewContributorRepository(conn *sqlx.DB, tableName string) *ContributorRepository {
+	return &ContributorRepository{
+		db: conn,
+	}
+}
+
+//Find find contributor by id
+func (s ContributorRepository) Find(id int) (contributor chronicle.Contributor, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.Find))
+		}
+	}()
+
+	contributor = chronicle.Contributor{}
+	query := `SELECT
+							id,
+							name,
+							slug,
+							bio,
+							photo,
+							createdAt,
+							updatedAt
+						FROM contributors
+						WHERE id=$1`
+
+	err = s.db.Get(&contributor, query, id)
+	return contributor, err
+}
+
+//FindBySlug find contributor by slug
+func (s ContributorRepository) FindBySlug(slug string) (contributor chronicle.Contributor, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.FindBySlug))
+		}
+	}()
+
+	contributor = chronicle.Contributor{}
+	query := `SELECT
+							id,
+							name,
+							slug,
+							bio,
+							photo,
+							createdAt,
+							updatedAt
+						FROM contributors
+						WHERE slug=$1`
+
+	err = s.db.Get(&contributor, query, slug)
+	return contributor, err
+}
+
+//IsSlugTaken check whether slug is used by a contributor other than exceptID
+func (s ContributorRepository) IsSlugTaken(slug string, exceptID int) (taken bool, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Wrap(err, function.GetFunctionName(s.IsSlugTaken))
+		}
+	}()
+
+	query := `SELECT EXISTS (SELECT 1 FROM contributors WHERE slug=$1 AND id<>$2)`
+
+	err = s.db.Get(&taken, query, slug, exceptID)
+	return taken, err
+}
+
+//Delete delete contributor by id
+func (s ContributorRepository) Delete(id int) (err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.Delete))
+		}
+	}()
+
+	query := `DELETE FROM contributors where id=$1`
+
+	deleteStatement, err := s.db.Prepare(query)
+	if err != nil {
+		return err
+	}
+	defer deleteStatement.Close()
+	_, err = deleteStatement.Exec(id)
+	return err
+}
+
+//All get all contributor
+func (s ContributorRepository) All(option chronicle.PagingOptions) (contributors chronicle.Contributors, contributorsCount int, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.All))
+		}
+	}()
+
+	contributors = chronicle.Contributors{}
+	selectQuery := fmt.Sprintf(
+		`SELECT
+			id,
+			name,
+			slug,
+			bio,
+			photo,
+			createdAt,
+			updatedAt
+		FROM contributors
+		ORDER BY %s %s
+		LIMIT %d
+		OFFSET %d`,
+		option.SortBy,
+		option.Order,
+		option.Limit,
+		option.Offset,
+	)
+
+	err = s.db.Select(&contributors, selectQuery)
+	if err != nil {
+		return chronicle.Contributors{}, 0, err
+	}
+
+	countQuery := `SELECT count(*) FROM contributors`
+	row := s.db.QueryRow(countQuery)
+	err = row.Scan(&contributorsCount)
+
+	return contributors, contributorsCount, err
+}
+
+//Insert insert contributor to datastore
+func (s ContributorRepository) Insert(contributor chronicle.Contributor) (createdContributor chronicle.Contributor, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.Insert))
+		}
+	}()
+
+	query := `INSERT INTO contributors (
+							name,
+							slug,
+							bio,
+							photo,
+							createdAt,
+							updatedAt
+						) VALUES (
+							:name,
+							:slug,
+							:bio,
+							:photo,
+							now(),
+							now()
+						) RETURNING id`
+
+	rows, err := s.db.NamedQuery(query, contributor)
+	if err != nil {
+		return chronicle.Contributor{}, err
+	}
+
+	if rows.Next() {
+		rows.Scan(&contributor.ID)
+	}
+	rows.Close()
+
+	return s.Find(contributor.ID)
+}
+
+//Update update contributor
+func (s ContributorRepository) Update(contributor chronicle.Contributor) (updatedContributor chronicle.Contributor, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.Update))
+		}
+	}()
+
+	query := `UPDATE contributors SET (
+							name,
+							slug,
+							bio,
+							photo,
+							updatedAt
+						) = (
+							:name,
+							:slug,
+							:bio,
+							:photo,
+							now()
+						) WHERE id=:id`
+
+	_, err = s.db.NamedExec(query, contributor)
+	if err != nil {
+		return chronicle.Contributor{}, err
+	}
+
+	return s.Find(contributor.ID)
+}
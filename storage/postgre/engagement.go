@@ -0,0 +1,67 @@
+package postgre
+
+import (
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	chronicle "github.com/AdhityaRamadhanus/chronicle"
+	function "github.com/AdhityaRamadhanus/chronicle/function"
+)
+
+//AddEngagements add engagement counts to their stories in a single batch, unknown stories are ignored
+func (s StoryRepository) AddEngagements(engagements chronicle.StoryEngagements) (err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Wrap(err, function.GetFunctionName(s.AddEngagements))
+		}
+	}()
+
+	if len(engagements) == 0 {
+		return nil
+	}
+
+	storyIds := []int64{}
+	likes := []int64{}
+	shares := []int64{}
+	views := []int64{}
+	for _, engagement := range engagements {
+		storyIds = append(storyIds, int64(engagement.StoryID))
+		likes = append(likes, int64(engagement.Likes))
+		shares = append(shares, int64(engagement.Shares))
+		views = append(views, int64(engagement.Views))
+	}
+
+	// engagement is not an edit, updatedAt is left untouched
+	query := `UPDATE stories SET
+							likes = stories.likes + engagements.likes,
+							shares = stories.shares + engagements.shares,
+							views = stories.views + engagements.views
+						FROM unnest($1::int[], $2::int[], $3::int[], $4::int[]) AS engagements(storyId, likes, shares, views)
+						WHERE stories.id = engagements.storyId`
+
+	// hourly buckets keep when engagements happened, trending rank recent engagements higher
+	bucketQuery := `INSERT INTO story_engagements (
+							storyId,
+							bucket,
+							likes,
+							shares,
+							views
+						)
+						SELECT engagements.storyId, date_trunc('hour', now()), engagements.likes, engagements.shares, engagements.views
+						FROM unnest($1::int[], $2::int[], $3::int[], $4::int[]) AS engagements(storyId, likes, shares, views)
+						INNER JOIN stories ON (stories.id = engagements.storyId)
+						ON CONFLICT (storyId, bucket) DO UPDATE SET
+							likes = story_engagements.likes + EXCLUDED.likes,
+							shares = story_engagements.shares + EXCLUDED.shares,
+							views = story_engagements.views + EXCLUDED.views`
+
+	return runInTx(s.db, func(tx dbExecutor) error {
+		queryArgs := []interface{}{pq.Array(storyIds), pq.Array(likes), pq.Array(shares), pq.Array(views)}
+		if _, err := tx.Exec(query, queryArgs...); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec(bucketQuery, queryArgs...)
+		return err
+	})
+}
@@ -0,0 +1,68 @@
+package postgre
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+
+	chronicle "github.com/AdhityaRamadhanus/chronicle"
+	function "github.com/AdhityaRamadhanus/chronicle/function"
+)
+
+/*
+FindForFeed find the latest published stories along with their rendered content,
+of topic x and all its subtopics or of every topic when x is 0
+*/
+func (s StoryRepository) FindForFeed(topic int, limit int) (stories chronicle.Stories, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.FindForFeed))
+		}
+	}()
+
+	// pins are left out, feed readers order items by date anyway
+	selectQuery := `SELECT
+						stories.id,
+						stories.title,
+						stories.slug,
+						stories.excerpt,
+						stories.contentHtml,
+						stories.author,
+						stories.status,
+						stories.media,
+						stories.publishat,
+						stories.unpublishat,
+						stories.publishedat,
+						stories.createdat,
+						stories.updatedat
+					FROM stories
+					WHERE stories.status=$1 AND ($2=0 OR EXISTS (
+						SELECT 1 FROM topic_stories
+						WHERE topic_stories.storyId=stories.id AND topic_stories.topicId IN (
+							WITH RECURSIVE descendants AS (
+								SELECT id FROM topics WHERE id=$2
+								UNION
+								SELECT topics.id FROM topics INNER JOIN descendants ON (topics.parentId = descendants.id)
+							)
+							SELECT id FROM descendants
+						)
+					))
+					ORDER BY stories.publishedAt DESC NULLS LAST, stories.createdAt DESC
+					LIMIT $3`
+
+	stories = chronicle.Stories{}
+	err = s.db.Select(&stories, selectQuery, chronicle.StoryPublishStatus, topic, limit)
+	if err != nil {
+		return chronicle.Stories{}, err
+	}
+
+	if len(stories) == 0 {
+		return chronicle.Stories{}, nil
+	}
+
+	if err := s.fillStoryAssociations(&stories); err != nil {
+		return chronicle.Stories{}, err
+	}
+
+	return stories, nil
+}
@@ -0,0 +1,179 @@
+package postgre
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	chronicle "github.com/AdhityaRamadhanus/chronicle"
+	function "github.com/AdhityaRamadhanus/chronicle/function"
+)
+
+/*
+MediaAssetRepository is implementation of MediaAssetRepository interface
+of chronicle domain using postgre
+*/
+type MediaAssetRepository struct {
+	db dbExecutor
+}
+
+//NewMediaAssetRepository is constructor to create media asset repository
+func NewMediaAssetRepository(conn *sqlx.DB, tableName string) *MediaAssetRepository {
+	return &MediaAssetRepository{
+		db: conn,
+	}
+}
+
+//Find find media asset by id
+func (s MediaAssetRepository) Find(id int) (asset chronicle.MediaAsset, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.Find))
+		}
+	}()
+
+	asset = chronicle.MediaAsset{}
+	query := `SELECT
+							id,
+							key,
+							filename,
+							mimeType,
+							size,
+							width,
+							height,
+							focalX,
+							focalY,
+							renditions,
+							caption,
+							credit,
+							altText,
+							createdAt,
+							updatedAt
+						FROM media_assets
+						WHERE id=$1`
+
+	err = s.db.Get(&asset, query, id)
+	return asset, err
+}
+
+//Delete delete media asset by id
+func (s MediaAssetRepository) Delete(id int) (err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.Delete))
+		}
+	}()
+
+	query := `DELETE FROM media_assets where id=$1`
+
+	deleteStatement, err := s.db.Prepare(query)
+	if err != nil {
+		return err
+	}
+	defer deleteStatement.Close()
+	_, err = deleteStatement.Exec(id)
+	return err
+}
+
+//All get all media asset
+func (s MediaAssetRepository) All(option chronicle.PagingOptions) (assets chronicle.MediaAssets, assetsCount int, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.All))
+		}
+	}()
+
+	assets = chronicle.MediaAssets{}
+	selectQuery := fmt.Sprintf(
+		`SELECT
+			id,
+			key,
+			filename,
+			mimeType,
+			size,
+			width,
+			height,
+			focalX,
+			focalY,
+			renditions,
+			caption,
+			credit,
+			altText,
+			createdAt,
+			updatedAt
+		FROM media_assets
+		ORDER BY %s %s
+		LIMIT %d
+		OFFSET %d`,
+		option.SortBy,
+		option.Order,
+		option.Limit,
+		option.Offset,
+	)
+
+	err = s.db.Select(&assets, selectQuery)
+	if err != nil {
+		return chronicle.MediaAssets{}, 0, err
+	}
+
+	countQuery := `SELECT count(*) FROM media_assets`
+	row := s.db.QueryRow(countQuery)
+	err = row.Scan(&assetsCount)
+
+	return assets, assetsCount, err
+}
+
+//Insert insert media asset to datastore
+func (s MediaAssetRepository) Insert(asset chronicle.MediaAsset) (createdAsset chronicle.MediaAsset, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.Insert))
+		}
+	}()
+
+	query := `INSERT INTO media_assets (
+							key,
+							filename,
+							mimeType,
+							size,
+							width,
+							height,
+							focalX,
+							focalY,
+							renditions,
+							caption,
+							credit,
+							altText,
+							createdAt,
+							updatedAt
+						) VALUES (
+							:key,
+							:filename,
+							:mimetype,
+							:size,
+							:width,
+							:height,
+							:focalx,
+							:focaly,
+							:renditions,
+							:caption,
+							:credit,
+							:alttext,
+							now(),
+							now()
+						) RETURNING id`
+
+	rows, err := s.db.NamedQuery(query, asset)
+	if err != nil {
+		return chronicle.MediaAsset{}, err
+	}
+
+	if rows.Next() {
+		rows.Scan(&asset.ID)
+	}
+	rows.Close()
+
+	return s.Find(asset.ID)
+}
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS story_revisions (
+  id serial PRIMARY KEY,
+  storyId int REFERENCES stories(id) ON DELETE CASCADE,
+  revision int NOT NULL,
+  title varchar(255) NOT NULL,
+  slug varchar(255) NOT NULL,
+  excerpt varchar(255) NOT NULL,
+	content text NOT NULL,
+	reporter  varchar(25),
+	editor  varchar(25),
+	author  varchar(25),
+	media json,
+	status      VARCHAR(20) NOT NULL,
+  createdAt TIMESTAMP,
+
+	CONSTRAINT story_revisions_unique_revision UNIQUE (storyId, revision)
+);
@@ -0,0 +1,5 @@
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS publishAt TIMESTAMP;
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS unpublishAt TIMESTAMP;
+
+CREATE INDEX index_stories_on_publishAt ON public.stories USING btree (publishAt) WHERE publishAt IS NOT NULL;
+CREATE INDEX index_stories_on_unpublishAt ON public.stories USING btree (unpublishAt) WHERE unpublishAt IS NOT NULL;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS story_transitions (
+  id serial PRIMARY KEY,
+  storyId int REFERENCES stories(id) ON DELETE CASCADE,
+  transition varchar(50) NOT NULL,
+  fromStatus VARCHAR(20) NOT NULL,
+  toStatus VARCHAR(20) NOT NULL,
+  clientId varchar(255) NOT NULL,
+  createdAt TIMESTAMP
+);
+
+CREATE INDEX index_story_transitions_on_storyId ON public.story_transitions USING btree (storyId);
@@ -0,0 +1,28 @@
+-- one weighted vector per supported text search configuration, title > excerpt > content
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS searchEnglish tsvector;
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS searchIndonesian tsvector;
+
+CREATE OR REPLACE FUNCTION stories_search_vector_update() RETURNS trigger AS $$
+BEGIN
+  NEW.searchEnglish :=
+    setweight(to_tsvector('english', coalesce(NEW.title, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(NEW.excerpt, '')), 'B') ||
+    setweight(to_tsvector('english', coalesce(NEW.content, '')), 'C');
+  NEW.searchIndonesian :=
+    setweight(to_tsvector('indonesian', coalesce(NEW.title, '')), 'A') ||
+    setweight(to_tsvector('indonesian', coalesce(NEW.excerpt, '')), 'B') ||
+    setweight(to_tsvector('indonesian', coalesce(NEW.content, '')), 'C');
+  RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS stories_search_vector_update ON stories;
+CREATE TRIGGER stories_search_vector_update
+  BEFORE INSERT OR UPDATE OF title, excerpt, content ON stories
+  FOR EACH ROW EXECUTE PROCEDURE stories_search_vector_update();
+
+-- backfill existing stories through the trigger
+UPDATE stories SET title = title;
+
+CREATE INDEX index_stories_on_searchEnglish ON public.stories USING gin (searchEnglish);
+CREATE INDEX index_stories_on_searchIndonesian ON public.stories USING gin (searchIndonesian);
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS story_slugs (
+  slug varchar(255) NOT NULL,
+  storyId int REFERENCES stories(id) ON DELETE CASCADE,
+  createdAt TIMESTAMP,
+
+  CONSTRAINT story_slugs_pkey PRIMARY KEY (slug)
+);
+
+CREATE INDEX index_story_slugs_on_storyId ON public.story_slugs USING btree (storyId);
+
+CREATE TABLE IF NOT EXISTS topic_slugs (
+  slug varchar(255) NOT NULL,
+  topicId int REFERENCES topics(id) ON DELETE CASCADE,
+  createdAt TIMESTAMP,
+
+  CONSTRAINT topic_slugs_pkey PRIMARY KEY (slug)
+);
+
+CREATE INDEX index_topic_slugs_on_topicId ON public.topic_slugs USING btree (topicId);
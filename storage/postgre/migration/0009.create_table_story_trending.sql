@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS story_engagements (
+  storyId int REFERENCES stories(id) ON DELETE CASCADE,
+  bucket TIMESTAMP NOT NULL,
+  likes int DEFAULT 0,
+  shares int DEFAULT 0,
+  views int DEFAULT 0,
+
+  CONSTRAINT story_engagements_pkey PRIMARY KEY (storyId, bucket)
+);
+
+CREATE INDEX index_story_engagements_on_bucket ON public.story_engagements USING btree (bucket);
+
+CREATE TABLE IF NOT EXISTS story_trending (
+  storyId int REFERENCES stories(id) ON DELETE CASCADE,
+  period varchar(10) NOT NULL,
+  score double precision NOT NULL,
+  computedAt TIMESTAMP,
+
+  CONSTRAINT story_trending_pkey PRIMARY KEY (period, storyId)
+);
+
+CREATE INDEX index_story_trending_on_period_and_score ON public.story_trending USING btree (period, score DESC);
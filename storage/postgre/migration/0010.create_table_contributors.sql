@@ -0,0 +1,48 @@
+CREATE TABLE IF NOT EXISTS contributors (
+  id serial PRIMARY KEY,
+  name varchar(255) NOT NULL,
+  slug varchar(255) NOT NULL,
+  bio text NOT NULL DEFAULT '',
+  photo varchar(255) NOT NULL DEFAULT '',
+  createdAt TIMESTAMP,
+  updatedAt TIMESTAMP,
+
+  CONSTRAINT contributors_unique_slug UNIQUE (slug)
+);
+
+CREATE TABLE IF NOT EXISTS story_contributors (
+  storyId int REFERENCES stories(id) ON DELETE CASCADE,
+  contributorId int REFERENCES contributors(id) ON DELETE CASCADE,
+  role varchar(25) NOT NULL,
+  position int NOT NULL DEFAULT 0,
+  createdAt TIMESTAMP,
+
+  CONSTRAINT story_contributors_pkey PRIMARY KEY (storyId, contributorId, role)
+);
+
+CREATE INDEX IF NOT EXISTS index_story_contributors_on_contributorId ON public.story_contributors USING btree (contributorId);
+
+-- every spelling of reporter, editor and author sharing a slug become one contributor
+INSERT INTO contributors (name, slug, bio, photo, createdAt, updatedAt)
+SELECT DISTINCT ON (names.slug) names.name, names.slug, '', '', now(), now()
+FROM (
+  SELECT
+    trim(name) AS name,
+    trim(both '-' FROM regexp_replace(lower(trim(name)), '[^a-z0-9]+', '-', 'g')) AS slug
+  FROM stories, unnest(ARRAY[stories.author, stories.reporter, stories.editor]) AS name
+  WHERE name IS NOT NULL
+) names
+WHERE names.slug <> ''
+ORDER BY names.slug, names.name
+ON CONFLICT (slug) DO NOTHING;
+
+INSERT INTO story_contributors (storyId, contributorId, role, position, createdAt)
+SELECT stories.id, contributors.id, bylines.role, bylines.position, now()
+FROM stories
+CROSS JOIN LATERAL (
+  VALUES ('author', stories.author, 0), ('reporter', stories.reporter, 1), ('editor', stories.editor, 2)
+) AS bylines(role, name, position)
+INNER JOIN contributors ON (
+  contributors.slug = trim(both '-' FROM regexp_replace(lower(trim(bylines.name)), '[^a-z0-9]+', '-', 'g'))
+)
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS media_assets (
+  id serial PRIMARY KEY,
+  key varchar(255) NOT NULL,
+  filename varchar(255) NOT NULL DEFAULT '',
+  mimeType varchar(100) NOT NULL,
+  size bigint NOT NULL DEFAULT 0,
+  width int NOT NULL DEFAULT 0,
+  height int NOT NULL DEFAULT 0,
+  caption text NOT NULL DEFAULT '',
+  credit varchar(255) NOT NULL DEFAULT '',
+  altText text NOT NULL DEFAULT '',
+  createdAt TIMESTAMP,
+  updatedAt TIMESTAMP,
+
+  CONSTRAINT media_assets_unique_key UNIQUE (key)
+);
+
+CREATE TABLE IF NOT EXISTS story_media (
+  storyId int REFERENCES stories(id) ON DELETE CASCADE,
+  mediaId int REFERENCES media_assets(id) ON DELETE CASCADE,
+  position int NOT NULL DEFAULT 0,
+  createdAt TIMESTAMP,
+
+  CONSTRAINT story_media_pkey PRIMARY KEY (storyId, mediaId)
+);
+
+CREATE INDEX IF NOT EXISTS index_story_media_on_mediaId ON public.story_media USING btree (mediaId);
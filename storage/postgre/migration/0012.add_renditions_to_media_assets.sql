@@ -0,0 +1,5 @@
+ALTER TABLE media_assets ADD COLUMN IF NOT EXISTS focalX float8 NOT NULL DEFAULT 0.5;
+ALTER TABLE media_assets ADD COLUMN IF NOT EXISTS focalY float8 NOT NULL DEFAULT 0.5;
+
+-- resized copies stored next to the original, listed in order of their spec
+ALTER TABLE media_assets ADD COLUMN IF NOT EXISTS renditions jsonb NOT NULL DEFAULT '[]';
@@ -0,0 +1,4 @@
+-- children of a deleted topic are moved to its parent by the repository, SET NULL is the last resort
+ALTER TABLE topics ADD COLUMN IF NOT EXISTS parentId int REFERENCES topics(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS index_topics_on_parentId ON public.topics USING btree (parentId);
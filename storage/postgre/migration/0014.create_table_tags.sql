@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS tags (
+  id serial PRIMARY KEY,
+  name varchar(255) NOT NULL,
+  slug varchar(255) NOT NULL,
+  createdAt TIMESTAMP,
+  updatedAt TIMESTAMP,
+
+  CONSTRAINT tags_unique_slug UNIQUE (slug)
+);
+
+-- autocomplete match slug prefix with LIKE
+CREATE INDEX IF NOT EXISTS index_tags_on_slug_pattern ON public.tags USING btree (slug varchar_pattern_ops);
+
+CREATE TABLE IF NOT EXISTS story_tags (
+  storyId int REFERENCES stories(id) ON DELETE CASCADE,
+  tagId int REFERENCES tags(id) ON DELETE CASCADE,
+  position int NOT NULL DEFAULT 0,
+  createdAt TIMESTAMP,
+
+  CONSTRAINT story_tags_pkey PRIMARY KEY (storyId, tagId)
+);
+
+CREATE INDEX IF NOT EXISTS index_story_tags_on_tagId_and_createdAt ON public.story_tags USING btree (tagId, createdAt);
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS collections (
+  id serial PRIMARY KEY,
+  title varchar(255) NOT NULL,
+  slug varchar(255) NOT NULL,
+  description text NOT NULL DEFAULT '',
+  createdAt TIMESTAMP,
+  updatedAt TIMESTAMP,
+
+  CONSTRAINT collections_unique_slug UNIQUE (slug)
+);
+
+-- positions are shifted a row at a time, uniqueness is only checked at commit
+CREATE TABLE IF NOT EXISTS collection_stories (
+  collectionId int REFERENCES collections(id) ON DELETE CASCADE,
+  storyId int REFERENCES stories(id) ON DELETE CASCADE,
+  position int NOT NULL,
+  createdAt TIMESTAMP,
+
+  CONSTRAINT collection_stories_pkey PRIMARY KEY (collectionId, storyId),
+  CONSTRAINT collection_stories_unique_position UNIQUE (collectionId, position) DEFERRABLE INITIALLY DEFERRED
+);
+
+CREATE INDEX IF NOT EXISTS index_collection_stories_on_storyId ON public.collection_stories USING btree (storyId);
@@ -0,0 +1,13 @@
+-- topicId NULL pin the story to the listing of all stories
+CREATE TABLE IF NOT EXISTS story_pins (
+  id serial PRIMARY KEY,
+  storyId int NOT NULL REFERENCES stories(id) ON DELETE CASCADE,
+  topicId int REFERENCES topics(id) ON DELETE CASCADE,
+  slot int NOT NULL,
+  expiresAt TIMESTAMP,
+  createdAt TIMESTAMP,
+  updatedAt TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS index_story_pins_on_topicId_and_storyId ON public.story_pins USING btree (COALESCE(topicId, 0), storyId);
+CREATE UNIQUE INDEX IF NOT EXISTS index_story_pins_on_topicId_and_slot ON public.story_pins USING btree (COALESCE(topicId, 0), slot);
@@ -0,0 +1,5 @@
+-- existing content was written as html, script/run_migration render it into contentHtml through the sanitizer
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS contentFormat varchar(20) NOT NULL DEFAULT 'html';
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS contentHtml text NOT NULL DEFAULT '';
+
+ALTER TABLE story_revisions ADD COLUMN IF NOT EXISTS contentFormat varchar(20) NOT NULL DEFAULT 'html';
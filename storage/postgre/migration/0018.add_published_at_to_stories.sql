@@ -0,0 +1,9 @@
+-- stories published before are dated by their first transition to publish, or by their last update
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS publishedAt TIMESTAMP;
+
+UPDATE stories SET publishedAt=COALESCE(
+  (SELECT min(story_transitions.createdAt) FROM story_transitions WHERE story_transitions.storyId=stories.id AND story_transitions.toStatus='Publish'),
+  stories.updatedAt
+) WHERE stories.status='Publish' AND stories.publishedAt IS NULL;
+
+CREATE INDEX IF NOT EXISTS index_stories_on_publishedAt ON public.stories USING btree (publishedAt) WHERE publishedAt IS NOT NULL;
@@ -0,0 +1,33 @@
+-- timestamps become timestamptz so rows written in the session time zone and ones written in UTC compare as instants.
+-- Columns filled by now() were written in the session time zone, the ones the application fill with go times hold UTC.
+-- Converted columns are skipped, so running it again change nothing
+DO $$
+DECLARE
+  timestampColumn record;
+  zone text;
+BEGIN
+  FOR timestampColumn IN
+    SELECT table_name, column_name
+    FROM information_schema.columns
+    WHERE table_schema='public' AND data_type='timestamp without time zone'
+  LOOP
+    zone := current_setting('TimeZone');
+    IF (timestampColumn.table_name, timestampColumn.column_name) IN (
+      ('stories', 'publishat'),
+      ('stories', 'unpublishat'),
+      ('story_trending', 'computedat'),
+      ('story_pins', 'expiresat')
+    ) THEN
+      zone := 'UTC';
+    END IF;
+
+    EXECUTE format(
+      'ALTER TABLE %I ALTER COLUMN %I TYPE timestamptz USING %I AT TIME ZONE %L',
+      timestampColumn.table_name,
+      timestampColumn.column_name,
+      timestampColumn.column_name,
+      zone
+    );
+  END LOOP;
+END
+$$;
@@ -0,0 +1,143 @@
+package postgre
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	chronicle "github.com/AdhityaRamadhanus/chronicle"
+	function "github.com/AdhityaRamadhanus/chronicle/function"
+)
+
+// activePinCondition match pins that have not expired
+const activePinCondition = "(story_pins.expiresAt IS NULL OR story_pins.expiresAt > now())"
+
+/*
+StoryPinRepository is implementation of StoryPinRepository interface
+of chronicle domain using postgre
+*/
+type StoryPinRepository struct {
+	db dbExecutor
+}
+
+//NewStoryPinRepository is constructor to create story pin repository
+func NewStoryPinRepository(conn *sqlx.DB, tableName string) *StoryPinRepository {
+	return &StoryPinRepository{
+		db: conn,
+	}
+}
+
+//Find find pin by id
+func (s StoryPinRepository) Find(id int) (pin chronicle.StoryPin, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.Find))
+		}
+	}()
+
+	pin = chronicle.StoryPin{}
+	query := `SELECT
+							id,
+							storyId,
+							COALESCE(topicId, 0) AS topicId,
+							slot,
+							expiresAt,
+							createdAt,
+							updatedAt
+						FROM story_pins
+						WHERE id=$1`
+
+	err = s.db.Get(&pin, query, id)
+	return pin, err
+}
+
+//FindByTopic find pins of topic x that have not expired by slot, topic 0 find pins of the listing of all stories
+func (s StoryPinRepository) FindByTopic(topic int) (pins chronicle.StoryPins, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.FindByTopic))
+		}
+	}()
+
+	query := `SELECT
+							id,
+							storyId,
+							COALESCE(topicId, 0) AS topicId,
+							slot,
+							expiresAt,
+							createdAt,
+							updatedAt
+						FROM story_pins
+						WHERE COALESCE(topicId, 0)=$1 AND ` + activePinCondition + `
+						ORDER BY slot`
+
+	pins = chronicle.StoryPins{}
+	err = s.db.Select(&pins, query, topic)
+	return pins, err
+}
+
+/*
+Insert pin story at slot, pinning a story that is already pinned in the same listing move it to the new slot,
+expired pins of the listing are cleared first so their slots can be reused
+*/
+func (s StoryPinRepository) Insert(pin chronicle.StoryPin) (createdPin chronicle.StoryPin, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.Insert))
+		}
+	}()
+
+	clearExpiredQuery := `DELETE FROM story_pins WHERE COALESCE(topicId, 0)=$1 AND NOT ` + activePinCondition
+
+	query := `INSERT INTO story_pins (
+							storyId,
+							topicId,
+							slot,
+							expiresAt,
+							createdAt,
+							updatedAt
+						) VALUES (
+							$1,
+							NULLIF($2, 0),
+							$3,
+							$4,
+							now(),
+							now()
+						)
+						ON CONFLICT (COALESCE(topicId, 0), storyId) DO UPDATE SET (
+							slot,
+							expiresAt,
+							updatedAt
+						) = (
+							EXCLUDED.slot,
+							EXCLUDED.expiresAt,
+							now()
+						)
+						RETURNING id`
+
+	err = runInTx(s.db, func(tx dbExecutor) error {
+		if _, err := tx.Exec(clearExpiredQuery, pin.TopicID); err != nil {
+			return err
+		}
+
+		return tx.Get(&pin.ID, query, pin.StoryID, pin.TopicID, pin.Slot, pin.ExpiresAt)
+	})
+	if err != nil {
+		return chronicle.StoryPin{}, err
+	}
+
+	return s.Find(pin.ID)
+}
+
+//Delete delete pin by id
+func (s StoryPinRepository) Delete(id int) (err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.Delete))
+		}
+	}()
+
+	_, err = s.db.Exec(`DELETE FROM story_pins WHERE id=$1`, id)
+	return err
+}
@@ -0,0 +1,108 @@
+package postgre
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	chronicle "github.com/AdhityaRamadhanus/chronicle"
+	function "github.com/AdhityaRamadhanus/chronicle/function"
+)
+
+/*
+FindRelated find published stories related to story x, ranked by weighted shared topics,
+words shared by titles and excerpts and recency, see RelatedStoryWeights
+*/
+func (s StoryRepository) FindRelated(id int, weights chronicle.RelatedStoryWeights, limit int) (stories chronicle.Stories, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.FindRelated))
+		}
+	}()
+
+	configuration := searchConfigurations[chronicle.SearchLanguageIndonesian]
+
+	// title and excerpt lexemes of story x are OR-ed into a query, quoted as they are already normalized
+	// a topic weigh 1 / ln(2 + its stories), the topic score is the share of those weights a story has in common with x
+	// ts_rank normalization 32 and the halving recency keep the other scores between 0 and 1 too
+	selectQuery := fmt.Sprintf(
+		`WITH source_query AS (
+			SELECT COALESCE(
+				(SELECT string_agg(quote_literal(lexeme), ' | ') FROM unnest(tsvector_to_array(ts_filter(%[1]s, '{a,b}'))) AS lexeme),
+				''
+			)::tsquery AS query
+			FROM stories
+			WHERE stories.id=$1
+		),
+		source_topics AS (
+			SELECT
+				topic_stories.topicId,
+				1 / ln(2 + (SELECT count(*) FROM topic_stories others WHERE others.topicId=topic_stories.topicId)::float8) AS weight
+			FROM topic_stories
+			WHERE topic_stories.storyId=$1
+		),
+		topic_scores AS (
+			SELECT
+				topic_stories.storyId,
+				sum(source_topics.weight) / (SELECT sum(weight) FROM source_topics) AS score
+			FROM topic_stories
+			INNER JOIN source_topics ON (topic_stories.topicId = source_topics.topicId)
+			WHERE topic_stories.storyId<>$1
+			GROUP BY topic_stories.storyId
+		)
+		SELECT
+			stories.id,
+			stories.title,
+			stories.slug,
+			stories.excerpt,
+			stories.author,
+			stories.status,
+			stories.media,
+			stories.likes,
+			stories.shares,
+			stories.views,
+			stories.publishat,
+			stories.unpublishat,
+			stories.publishedat,
+			stories.createdat,
+			stories.updatedat
+		FROM stories
+		CROSS JOIN source_query
+		LEFT JOIN topic_scores ON (topic_scores.storyId = stories.id)
+		WHERE stories.id<>$1 AND stories.status=$2
+		ORDER BY (
+			$3::float8 * COALESCE(topic_scores.score, 0) +
+			$4::float8 * ts_rank('{0, 0, 0.4, 1}', %[1]s, source_query.query, 32) +
+			$5::float8 * power(0.5, extract(epoch FROM now() - stories.createdAt) / $6::float8)
+		) DESC, stories.createdAt DESC
+		LIMIT $7`,
+		configuration.column,
+	)
+
+	stories = chronicle.Stories{}
+	err = s.db.Select(
+		&stories,
+		selectQuery,
+		id,
+		chronicle.StoryPublishStatus,
+		weights.Topics,
+		weights.Text,
+		weights.Recency,
+		weights.RecencyHalfLife.Seconds(),
+		limit,
+	)
+	if err != nil {
+		return chronicle.Stories{}, err
+	}
+
+	if len(stories) == 0 {
+		return chronicle.Stories{}, nil
+	}
+
+	if err := s.fillStoryAssociations(&stories); err != nil {
+		return chronicle.Stories{}, err
+	}
+
+	return stories, nil
+}
@@ -0,0 +1,166 @@
+package postgre
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	chronicle "github.com/AdhityaRamadhanus/chronicle"
+	function "github.com/AdhityaRamadhanus/chronicle/function"
+)
+
+/*
+StoryRevisionRepository is implementation of StoryRevisionRepository interface
+of chronicle domain using postgre
+*/
+type StoryRevisionRepository struct {
+	db dbExecutor
+}
+
+//NewStoryRevisionRepository is constructor to create story revision repository
+func NewStoryRevisionRepository(conn *sqlx.DB, tableName string) *StoryRevisionRepository {
+	return &StoryRevisionRepository{
+		db: conn,
+	}
+}
+
+//Find find revision x of story y
+func (s StoryRevisionRepository) Find(storyID int, revision int) (storyRevision chronicle.StoryRevision, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.Find))
+		}
+	}()
+
+	storyRevision = chronicle.StoryRevision{}
+	query := `SELECT
+							id,
+							storyId,
+							revision,
+							title,
+							slug,
+							excerpt,
+							content,
+							contentFormat,
+							reporter,
+							editor,
+							author,
+							status,
+							media,
+							createdAt
+						FROM story_revisions
+						WHERE storyId=$1 AND revision=$2`
+
+	err = s.db.Get(&storyRevision, query, storyID, revision)
+	return storyRevision, err
+}
+
+//FindByStory find all revisions of story x
+func (s StoryRevisionRepository) FindByStory(storyID int, option chronicle.PagingOptions) (storyRevisions chronicle.StoryRevisions, revisionsCount int, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.FindByStory))
+		}
+	}()
+
+	// revisions are listed without content, use Find to get the full snapshot
+	storyRevisions = chronicle.StoryRevisions{}
+	selectQuery := fmt.Sprintf(
+		`SELECT
+			id,
+			storyId,
+			revision,
+			title,
+			slug,
+			excerpt,
+			reporter,
+			editor,
+			author,
+			status,
+			media,
+			createdAt
+		FROM story_revisions
+		WHERE storyId=$1
+		ORDER BY revision %s
+		LIMIT %d
+		OFFSET %d`,
+		option.Order,
+		option.Limit,
+		option.Offset,
+	)
+
+	err = s.db.Select(&storyRevisions, selectQuery, storyID)
+	if err != nil {
+		return chronicle.StoryRevisions{}, 0, err
+	}
+
+	countQuery := `SELECT count(*) FROM story_revisions WHERE storyId=$1`
+	row := s.db.QueryRow(countQuery, storyID)
+	err = row.Scan(&revisionsCount)
+
+	return storyRevisions, revisionsCount, err
+}
+
+//Insert snapshot story as its next revision
+func (s StoryRevisionRepository) Insert(story chronicle.Story) (createdRevision chronicle.StoryRevision, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.Insert))
+		}
+	}()
+
+	query := `INSERT INTO story_revisions (
+							storyId,
+							revision,
+							title,
+							slug,
+							excerpt,
+							content,
+							contentFormat,
+							reporter,
+							editor,
+							author,
+							status,
+							media,
+							createdAt
+						) VALUES (
+							:id,
+							(SELECT COALESCE(MAX(revision), 0) + 1 FROM story_revisions WHERE storyId=:id),
+							:title,
+							:slug,
+							:excerpt,
+							:content,
+							:contentformat,
+							:reporter,
+							:editor,
+							:author,
+							:status,
+							:media,
+							now()
+						) RETURNING revision`
+
+	// the story row is locked so concurrent writes of the same story number their revisions one after the other
+	var revision int
+	err = runInTx(s.db, func(tx dbExecutor) error {
+		if _, err := tx.Exec(`SELECT id FROM stories WHERE id=$1 FOR UPDATE`, story.ID); err != nil {
+			return err
+		}
+
+		rows, err := tx.NamedQuery(query, story)
+		if err != nil {
+			return err
+		}
+
+		if rows.Next() {
+			rows.Scan(&revision)
+		}
+		return rows.Close()
+	})
+	if err != nil {
+		return chronicle.StoryRevision{}, err
+	}
+
+	return s.Find(story.ID, revision)
+}
@@ -0,0 +1,229 @@
+package postgre
+
+import (
+	"database/sql"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	chronicle "github.com/AdhityaRamadhanus/chronicle"
+	content "github.com/AdhityaRamadhanus/chronicle/content"
+	function "github.com/AdhityaRamadhanus/chronicle/function"
+)
+
+type searchConfiguration struct {
+	name   string
+	column string
+}
+
+// control characters marking highlighted words, they cannot appear in escaped text and are swapped for <mark> after escaping
+const (
+	snippetStartSel = "\x01"
+	snippetStopSel  = "\x02"
+)
+
+// text search configuration and the weighted vector column maintained for it
+var searchConfigurations = map[string]searchConfiguration{
+	chronicle.SearchLanguageIndonesian: {name: "indonesian", column: "stories.searchIndonesian"},
+	chronicle.SearchLanguageEnglish:    {name: "english", column: "stories.searchEnglish"},
+}
+
+//Search find stories matching keywords ordered by rank, along with status and topic facets
+func (s StoryRepository) Search(filter chronicle.StorySearchOptions, option chronicle.PagingOptions) (hits chronicle.StorySearchHits, hitsCount int, facets chronicle.StorySearchFacets, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.Search))
+		}
+	}()
+
+	configuration, ok := searchConfigurations[filter.Language]
+	if !ok {
+		configuration = searchConfigurations[chronicle.SearchLanguageIndonesian]
+	}
+
+	facets = chronicle.StorySearchFacets{
+		Statuses: []chronicle.SearchFacet{},
+		Topics:   []chronicle.SearchFacet{},
+	}
+
+	whereStatement, queryArgs := searchWhereStatement(configuration, filter, true, true)
+
+	// rank and paginate first so snippets are only highlighted for the returned page
+	hits = chronicle.StorySearchHits{}
+	selectQuery := fmt.Sprintf(
+		`SELECT
+			hits.id,
+			hits.title,
+			hits.slug,
+			hits.excerpt,
+			hits.author,
+			hits.status,
+			hits.media,
+			hits.likes,
+			hits.shares,
+			hits.views,
+			hits.publishAt,
+			hits.unpublishAt,
+			hits.publishedAt,
+			hits.createdAt,
+			hits.updatedAt,
+			hits.contentHtml,
+			hits.rank
+		FROM (
+			SELECT
+				stories.*,
+				ts_rank(%[2]s, websearch_to_tsquery('%[1]s', $1)) AS rank
+			FROM stories
+			%[3]s
+			ORDER BY rank DESC, stories.updatedAt DESC
+			LIMIT %[4]d
+			OFFSET %[5]d
+		) hits
+		ORDER BY hits.rank DESC, hits.updatedAt DESC`,
+		configuration.name,
+		configuration.column,
+		whereStatement,
+		option.Limit,
+		option.Offset,
+	)
+
+	err = s.db.Select(&hits, selectQuery, queryArgs...)
+	if err != nil {
+		return chronicle.StorySearchHits{}, 0, facets, err
+	}
+
+	countQuery := fmt.Sprintf(`SELECT count(*) FROM stories %s`, whereStatement)
+	row := s.db.QueryRow(countQuery, queryArgs...)
+	row.Scan(&hitsCount)
+
+	// each facet ignore its own filter so clients can switch between values
+	statusWhereStatement, statusQueryArgs := searchWhereStatement(configuration, filter, false, true)
+	statusFacetQuery := fmt.Sprintf(
+		`SELECT
+			stories.status AS value,
+			stories.status AS label,
+			count(*) AS count
+		FROM stories
+		%s
+		GROUP BY stories.status
+		ORDER BY count DESC`,
+		statusWhereStatement,
+	)
+
+	err = s.db.Select(&facets.Statuses, statusFacetQuery, statusQueryArgs...)
+	if err != nil {
+		return chronicle.StorySearchHits{}, 0, facets, err
+	}
+
+	topicWhereStatement, topicQueryArgs := searchWhereStatement(configuration, filter, true, false)
+	topicFacetQuery := fmt.Sprintf(
+		`SELECT
+			topics.id::text AS value,
+			topics.name AS label,
+			count(*) AS count
+		FROM stories
+		INNER JOIN topic_stories ON (topic_stories.storyId = stories.id)
+		INNER JOIN topics ON (topic_stories.topicId = topics.id)
+		%s
+		GROUP BY topics.id, topics.name
+		ORDER BY count DESC
+		LIMIT 20`,
+		topicWhereStatement,
+	)
+
+	err = s.db.Select(&facets.Topics, topicFacetQuery, topicQueryArgs...)
+	if err != nil {
+		return chronicle.StorySearchHits{}, 0, facets, err
+	}
+
+	if len(hits) == 0 {
+		return chronicle.StorySearchHits{}, hitsCount, facets, nil
+	}
+
+	if err := s.highlightSnippets(configuration, filter.Query, hits); err != nil {
+		return chronicle.StorySearchHits{}, 0, facets, err
+	}
+
+	stories := chronicle.Stories{}
+	for _, hit := range hits {
+		stories = append(stories, hit.Story)
+	}
+
+	if err := s.fillStoryAssociations(&stories); err != nil {
+		return chronicle.StorySearchHits{}, 0, facets, err
+	}
+
+	for idx := range hits {
+		hits[idx].Story = stories[idx]
+	}
+
+	return hits, hitsCount, facets, nil
+}
+
+// internal function
+// snippets are highlighted over plain text and escaped afterwards, so markup stored in a story never reaches the snippet
+func (s StoryRepository) highlightSnippets(configuration searchConfiguration, query string, hits chronicle.StorySearchHits) error {
+	ids := []int64{}
+	documents := []string{}
+	for _, hit := range hits {
+		document := hit.Title + " " + hit.Excerpt + " " + content.HTMLToText(hit.ContentHTML)
+		document = strings.NewReplacer(snippetStartSel, "", snippetStopSel, "").Replace(document)
+		ids = append(ids, int64(hit.ID))
+		documents = append(documents, document)
+	}
+
+	headlines := []struct {
+		ID      int
+		Snippet string
+	}{}
+	headlineQuery := fmt.Sprintf(
+		`SELECT
+			documents.id,
+			ts_headline('%[1]s', documents.body, websearch_to_tsquery('%[1]s', $1), $4) AS snippet
+		FROM unnest($2::int[], $3::text[]) AS documents(id, body)`,
+		configuration.name,
+	)
+
+	options := fmt.Sprintf("StartSel=%s, StopSel=%s, MaxFragments=2, MaxWords=30, MinWords=10", snippetStartSel, snippetStopSel)
+	if err := s.db.Select(&headlines, headlineQuery, query, pq.Array(ids), pq.Array(documents), options); err != nil {
+		return err
+	}
+
+	snippets := map[int]string{}
+	markReplacer := strings.NewReplacer(snippetStartSel, "<mark>", snippetStopSel, "</mark>")
+	for _, headline := range headlines {
+		snippets[headline.ID] = markReplacer.Replace(html.EscapeString(headline.Snippet))
+	}
+
+	for idx := range hits {
+		hits[idx].Snippet = snippets[hits[idx].ID]
+		hits[idx].ContentHTML = ""
+	}
+
+	return nil
+}
+
+func searchWhereStatement(configuration searchConfiguration, filter chronicle.StorySearchOptions, withStatus bool, withTopic bool) (string, []interface{}) {
+	queryArgs := []interface{}{filter.Query}
+	conditions := []string{
+		fmt.Sprintf("%s @@ websearch_to_tsquery('%s', $1)", configuration.column, configuration.name),
+	}
+
+	if withStatus && filter.Status != "" {
+		queryArgs = append(queryArgs, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("stories.status=$%d", len(queryArgs)))
+	}
+
+	if withTopic && filter.Topic != 0 {
+		queryArgs = append(queryArgs, filter.Topic)
+		conditions = append(
+			conditions,
+			fmt.Sprintf("EXISTS (SELECT 1 FROM topic_stories WHERE topic_stories.storyId=stories.id AND topic_stories.topicId=$%d)", len(queryArgs)),
+		)
+	}
+
+	return "WHERE " + strings.Join(conditions, " AND "), queryArgs
+}
@@ -0,0 +1,145 @@
+package postgre
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	chronicle "github.com/AdhityaRamadhanus/chronicle"
+	function "github.com/AdhityaRamadhanus/chronicle/function"
+)
+
+/*
+SitemapRepository is implementation of SitemapRepository interface
+of chronicle domain using postgre
+*/
+type SitemapRepository struct {
+	db dbExecutor
+}
+
+//NewSitemapRepository is constructor to create sitemap repository
+func NewSitemapRepository(conn *sqlx.DB, tableName string) *SitemapRepository {
+	return &SitemapRepository{
+		db: conn,
+	}
+}
+
+//StoryPages split published stories by id into pages of pageSize
+func (s SitemapRepository) StoryPages(pageSize int) (pages chronicle.SitemapPages, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.StoryPages))
+		}
+	}()
+
+	query := `SELECT page, max(updatedAt) AS updatedAt
+				FROM (
+					SELECT (row_number() OVER (ORDER BY id) - 1) / $2 + 1 AS page, updatedAt
+					FROM stories
+					WHERE status=$1
+				) numbered_stories
+				GROUP BY page
+				ORDER BY page`
+
+	pages = chronicle.SitemapPages{}
+	err = s.db.Select(&pages, query, chronicle.StoryPublishStatus, pageSize)
+	return pages, err
+}
+
+//FindStories find published stories of page x, ordered by id as StoryPages split them
+func (s SitemapRepository) FindStories(page int, pageSize int) (stories chronicle.Stories, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.FindStories))
+		}
+	}()
+
+	query := `SELECT
+							id,
+							title,
+							slug,
+							publishedAt,
+							createdAt,
+							updatedAt
+						FROM stories
+						WHERE status=$1
+						ORDER BY id
+						LIMIT $2
+						OFFSET $3`
+
+	stories = chronicle.Stories{}
+	err = s.db.Select(&stories, query, chronicle.StoryPublishStatus, pageSize, (page-1)*pageSize)
+	return stories, err
+}
+
+//TopicPages split topics by id into pages of pageSize
+func (s SitemapRepository) TopicPages(pageSize int) (pages chronicle.SitemapPages, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.TopicPages))
+		}
+	}()
+
+	query := `SELECT page, max(updatedAt) AS updatedAt
+				FROM (
+					SELECT (row_number() OVER (ORDER BY id) - 1) / $1 + 1 AS page, updatedAt
+					FROM topics
+				) numbered_topics
+				GROUP BY page
+				ORDER BY page`
+
+	pages = chronicle.SitemapPages{}
+	err = s.db.Select(&pages, query, pageSize)
+	return pages, err
+}
+
+//FindTopics find topics of page x, ordered by id as TopicPages split them
+func (s SitemapRepository) FindTopics(page int, pageSize int) (topics chronicle.Topics, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.FindTopics))
+		}
+	}()
+
+	query := `SELECT
+							id,
+							name,
+							slug,
+							createdAt,
+							updatedAt
+						FROM topics
+						ORDER BY id
+						LIMIT $1
+						OFFSET $2`
+
+	topics = chronicle.Topics{}
+	err = s.db.Select(&topics, query, pageSize, (page-1)*pageSize)
+	return topics, err
+}
+
+//FindNewsStories find stories first published since x, latest first
+func (s SitemapRepository) FindNewsStories(since time.Time, limit int) (stories chronicle.Stories, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.FindNewsStories))
+		}
+	}()
+
+	query := `SELECT
+							id,
+							title,
+							slug,
+							publishedAt,
+							createdAt,
+							updatedAt
+						FROM stories
+						WHERE status=$1 AND publishedAt >= $2
+						ORDER BY publishedAt DESC
+						LIMIT $3`
+
+	stories = chronicle.Stories{}
+	err = s.db.Select(&stories, query, chronicle.StoryPublishStatus, since, limit)
+	return stories, err
+}
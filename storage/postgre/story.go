@@ -4,8 +4,10 @@ import (
 	"database/sql"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 
 	chronicle "github.com/AdhityaRamadhanus/chronicle"
@@ -17,7 +19,7 @@ StoryRepository is implementation of StoryRepository interface
 of chronicle domain using postgre
 */
 type StoryRepository struct {
-	db *sqlx.DB
+	db dbExecutor
 }
 
 //NewStoryRepository is constructor to create story repository
@@ -42,6 +44,8 @@ func (s StoryRepository) Find(id int) (story chronicle.Story, err error) {
 							slug, 
 							excerpt, 
 							content,
+							contentFormat,
+							contentHtml,
 							reporter,
 							editor,
 							author,
@@ -50,6 +54,9 @@ func (s StoryRepository) Find(id int) (story chronicle.Story, err error) {
 							likes,
 							shares,
 							views,
+							publishAt,
+							unpublishAt,
+							publishedAt,
 							createdAt, 
 							updatedAt
 						FROM stories 
@@ -60,14 +67,12 @@ func (s StoryRepository) Find(id int) (story chronicle.Story, err error) {
 		return chronicle.Story{}, err
 	}
 
-	// fill Topics
-
-	story.Topics, err = s.getTopicsForStory(story.ID)
-	if err != nil {
+	stories := chronicle.Stories{story}
+	if err := s.fillStoryAssociations(&stories); err != nil {
 		return chronicle.Story{}, err
 	}
 
-	return story, nil
+	return stories[0], nil
 }
 
 //FindBySlug find story by slug
@@ -85,6 +90,8 @@ func (s StoryRepository) FindBySlug(slug string) (story chronicle.Story, err err
 							slug, 
 							excerpt, 
 							content,
+							contentFormat,
+							contentHtml,
 							reporter,
 							editor,
 							author,
@@ -93,6 +100,9 @@ func (s StoryRepository) FindBySlug(slug string) (story chronicle.Story, err err
 							likes,
 							shares,
 							views,
+							publishAt,
+							unpublishAt,
+							publishedAt,
 							createdAt, 
 							updatedAt
 						FROM stories 
@@ -103,13 +113,45 @@ func (s StoryRepository) FindBySlug(slug string) (story chronicle.Story, err err
 		return chronicle.Story{}, err
 	}
 
-	// fill s.getTopicsForStory(story.ID)
-	story.Topics, err = s.getTopicsForStory(story.ID)
+	stories := chronicle.Stories{story}
+	if err := s.fillStoryAssociations(&stories); err != nil {
+		return chronicle.Story{}, err
+	}
+
+	return stories[0], nil
+}
+
+//FindByFormerSlug find story that used to be reachable by slug
+func (s StoryRepository) FindByFormerSlug(slug string) (story chronicle.Story, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.FindByFormerSlug))
+		}
+	}()
+
+	var storyId int
+	err = s.db.Get(&storyId, `SELECT storyId FROM story_slugs WHERE slug=$1`, slug)
 	if err != nil {
 		return chronicle.Story{}, err
 	}
 
-	return story, err
+	return s.Find(storyId)
+}
+
+//IsSlugTaken check whether slug is used, now or formerly, by a story other than exceptID
+func (s StoryRepository) IsSlugTaken(slug string, exceptID int) (taken bool, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Wrap(err, function.GetFunctionName(s.IsSlugTaken))
+		}
+	}()
+
+	query := `SELECT
+							EXISTS (SELECT 1 FROM stories WHERE slug=$1 AND id<>$2) OR
+							EXISTS (SELECT 1 FROM story_slugs WHERE slug=$1 AND storyId<>$2)`
+
+	err = s.db.Get(&taken, query, slug, exceptID)
+	return taken, err
 }
 
 //Delete delete story by id
@@ -132,7 +174,7 @@ func (s StoryRepository) Delete(id int) (err error) {
 	return err
 }
 
-//FindByStatus find all story with status x
+//FindByStatus find all story with status x, stories pinned to the listing of all stories are placed at their slots
 func (s StoryRepository) FindByStatus(status string, option chronicle.PagingOptions) (stories chronicle.Stories, storiesCount int, err error) {
 	defer func() {
 		if err != nil && err != sql.ErrNoRows {
@@ -140,6 +182,18 @@ func (s StoryRepository) FindByStatus(status string, option chronicle.PagingOpti
 		}
 	}()
 
+	// count topics for pagination
+	countQuery := `SELECT count(*) FROM stories WHERE status=$1`
+	row := s.db.QueryRow(countQuery, status)
+	row.Scan(&storiesCount)
+
+	// pins of the listing of all stories keep their slots, the rest follow in order
+	pins, err := s.getPinsForListing("story_pins.topicId IS NULL", "WHERE stories.status=$1", status)
+	if err != nil {
+		return chronicle.Stories{}, 0, err
+	}
+	page := pins.Page(storiesCount, option)
+
 	stories = chronicle.Stories{}
 	selectQuery := fmt.Sprintf(
 		`SELECT
@@ -153,43 +207,45 @@ func (s StoryRepository) FindByStatus(status string, option chronicle.PagingOpti
 			likes,
 			shares,
 			views,
+			publishAt,
+			unpublishAt,
+			publishedAt,
 			createdAt, 
 			updatedAt
 		FROM stories
-		WHERE status=$1
+		WHERE status=$1 AND NOT (id = ANY($2))
 		ORDER BY %s %s 
 		LIMIT %d 
 		OFFSET %d`,
 		option.SortBy,
 		option.Order,
-		option.Limit,
-		option.Offset,
+		page.Limit,
+		page.Offset,
 	)
 
-	err = s.db.Select(&stories, selectQuery, status)
+	err = s.db.Select(&stories, selectQuery, status, pq.Array(pinnedStoryIds(pins)))
 	if err != nil {
 		return chronicle.Stories{}, 0, err
 	}
 
-	// count topics for pagination
-	countQuery := `SELECT count(*) FROM stories WHERE status=$1`
-	row := s.db.QueryRow(countQuery, status)
-	row.Scan(&storiesCount)
+	stories, err = s.placePinnedStories(page, stories, option.Limit)
+	if err != nil {
+		return chronicle.Stories{}, 0, err
+	}
 
 	if len(stories) == 0 {
 		return chronicle.Stories{}, storiesCount, nil
 	}
 
-	// fill Topics
-	if err := s.getTopicsForStories(&stories); err != nil {
+	if err := s.fillStoryAssociations(&stories); err != nil {
 		return chronicle.Stories{}, 0, err
 	}
 
 	return stories, storiesCount, nil
 }
 
-//FindByTopicAndStatus find all story with topic x and status y
-func (s StoryRepository) FindByTopicAndStatus(topic int, status string, option chronicle.PagingOptions) (stories chronicle.Stories, storiesCount int, err error) {
+//FindByTopicAndStatus find all story with topic x and status y, optionally with stories of all subtopics of x, stories pinned to x are placed at their slots
+func (s StoryRepository) FindByTopicAndStatus(topic int, status string, includeDescendants bool, option chronicle.PagingOptions) (stories chronicle.Stories, storiesCount int, err error) {
 	defer func() {
 		if err != nil && err != sql.ErrNoRows {
 			err = errors.Wrap(err, function.GetFunctionName(s.FindByTopicAndStatus))
@@ -198,12 +254,37 @@ func (s StoryRepository) FindByTopicAndStatus(topic int, status string, option c
 
 	// building where statement and query arguments
 	queryArgs := []interface{}{topic}
-	whereStatement := "WHERE topic_stories.topicId=$1"
+	topicCondition := "topic_stories.topicId=$1"
+	if includeDescendants {
+		// UNION stop at topics already visited should the tree ever loop
+		topicCondition = `topic_stories.topicId IN (
+			WITH RECURSIVE descendants AS (
+				SELECT id FROM topics WHERE id=$1
+				UNION
+				SELECT topics.id FROM topics INNER JOIN descendants ON (topics.parentId = descendants.id)
+			)
+			SELECT id FROM descendants
+		)`
+	}
+
+	// a story filed under several topics of the subtree is listed once
+	whereStatement := fmt.Sprintf("WHERE EXISTS (SELECT 1 FROM topic_stories WHERE topic_stories.storyId=stories.id AND %s)", topicCondition)
 	if status != "" {
-		whereStatement += "AND stories.status=$2"
+		whereStatement += " AND stories.status=$2"
 		queryArgs = append(queryArgs, status)
 	}
 
+	countQuery := fmt.Sprintf(`SELECT count(*) FROM stories %s`, whereStatement)
+	row := s.db.QueryRow(countQuery, queryArgs...)
+	row.Scan(&storiesCount)
+
+	// pins of topic x keep their slots, the rest follow in order
+	pins, err := s.getPinsForListing("story_pins.topicId=$1", whereStatement, queryArgs...)
+	if err != nil {
+		return chronicle.Stories{}, 0, err
+	}
+	page := pins.Page(storiesCount, option)
+
 	stories = chronicle.Stories{}
 	selectQuery := fmt.Sprintf(
 		`SELECT
@@ -217,22 +298,30 @@ func (s StoryRepository) FindByTopicAndStatus(topic int, status string, option c
 			stories.likes,
 			stories.shares,
 			stories.views,
+			stories.publishat,
+			stories.unpublishat,
+			stories.publishedat,
 			stories.createdat,
 			stories.updatedat
-		FROM topic_stories
-		INNER JOIN stories ON (topic_stories.storyId = stories.id) 
-		%s
+		FROM stories
+		%s AND NOT (stories.id = ANY($%d))
 		ORDER BY %s %s 
 		LIMIT %d 
 		OFFSET %d`,
 		whereStatement,
+		len(queryArgs)+1,
 		option.SortBy,
 		option.Order,
-		option.Limit,
-		option.Offset,
+		page.Limit,
+		page.Offset,
 	)
 
-	err = s.db.Select(&stories, selectQuery, queryArgs...)
+	err = s.db.Select(&stories, selectQuery, append(queryArgs, pq.Array(pinnedStoryIds(pins)))...)
+	if err != nil {
+		return chronicle.Stories{}, 0, err
+	}
+
+	stories, err = s.placePinnedStories(page, stories, option.Limit)
 	if err != nil {
 		return chronicle.Stories{}, 0, err
 	}
@@ -241,26 +330,14 @@ func (s StoryRepository) FindByTopicAndStatus(topic int, status string, option c
 		return chronicle.Stories{}, 0, nil
 	}
 
-	countQuery := fmt.Sprintf(
-		`SELECT count(distinct storyId) 
-		FROM topic_stories 
-		INNER JOIN stories ON (topic_stories.storyId = stories.id)  
-		%s`,
-		whereStatement,
-	)
-
-	row := s.db.QueryRow(countQuery, queryArgs...)
-	row.Scan(&storiesCount)
-
-	// fill Topics
-	if err := s.getTopicsForStories(&stories); err != nil {
+	if err := s.fillStoryAssociations(&stories); err != nil {
 		return chronicle.Stories{}, 0, err
 	}
 
 	return stories, storiesCount, nil
 }
 
-//All get all stories
+//All get all stories, stories pinned to the listing of all stories are placed at their slots
 func (s StoryRepository) All(option chronicle.PagingOptions) (stories chronicle.Stories, storiesCount int, err error) {
 	defer func() {
 		if err != nil && err != sql.ErrNoRows {
@@ -268,6 +345,17 @@ func (s StoryRepository) All(option chronicle.PagingOptions) (stories chronicle.
 		}
 	}()
 
+	countQuery := `SELECT count(*) FROM stories`
+	row := s.db.QueryRow(countQuery)
+	row.Scan(&storiesCount)
+
+	// pins of the listing of all stories keep their slots, the rest follow in order
+	pins, err := s.getPinsForListing("story_pins.topicId IS NULL", "WHERE TRUE")
+	if err != nil {
+		return chronicle.Stories{}, 0, err
+	}
+	page := pins.Page(storiesCount, option)
+
 	stories = chronicle.Stories{}
 	selectQuery := fmt.Sprintf(
 		`SELECT
@@ -281,33 +369,37 @@ func (s StoryRepository) All(option chronicle.PagingOptions) (stories chronicle.
 			likes,
 			shares,
 			views,
+			publishAt,
+			unpublishAt,
+			publishedAt,
 			createdAt, 
 			updatedAt
 		FROM stories 
+		WHERE NOT (id = ANY($1))
 		ORDER BY %s %s 
 		LIMIT %d 
 		OFFSET %d`,
 		option.SortBy,
 		option.Order,
-		option.Limit,
-		option.Offset,
+		page.Limit,
+		page.Offset,
 	)
 
-	err = s.db.Select(&stories, selectQuery)
+	err = s.db.Select(&stories, selectQuery, pq.Array(pinnedStoryIds(pins)))
 	if err != nil {
 		return chronicle.Stories{}, 0, err
 	}
 
-	countQuery := `SELECT count(*) FROM stories`
-	row := s.db.QueryRow(countQuery)
-	row.Scan(&storiesCount)
+	stories, err = s.placePinnedStories(page, stories, option.Limit)
+	if err != nil {
+		return chronicle.Stories{}, 0, err
+	}
 
 	if len(stories) == 0 {
 		return chronicle.Stories{}, storiesCount, nil
 	}
 
-	// fill Topics
-	if err := s.getTopicsForStories(&stories); err != nil {
+	if err := s.fillStoryAssociations(&stories); err != nil {
 		return chronicle.Stories{}, 0, err
 	}
 
@@ -327,11 +419,16 @@ func (s StoryRepository) Insert(story chronicle.Story) (createdStory chronicle.S
 							slug, 
 							excerpt, 
 							content,
+							contentFormat,
+							contentHtml,
 							reporter,
 							editor,
 							author,
 							status,
 							media, 
+							publishAt,
+							unpublishAt,
+							publishedAt,
 							createdAt, 
 							updatedAt
 						) VALUES (
@@ -339,32 +436,57 @@ func (s StoryRepository) Insert(story chronicle.Story) (createdStory chronicle.S
 							:slug, 
 							:excerpt, 
 							:content,
+							:contentformat,
+							:contenthtml,
 							:reporter,
 							:editor,
 							:author,
 							:status,
 							:media, 
+							:publishat,
+							:unpublishat,
+							:publishedat,
 							now(), 
 							now()
 						) RETURNING id`
 
-	rows, err := s.db.NamedQuery(query, story)
-	if err != nil {
-		return chronicle.Story{}, err
-	}
+	// story and its topics are written together, an outer unit of work own the transaction when there is one
+	err = runInTx(s.db, func(tx dbExecutor) error {
+		rows, err := tx.NamedQuery(query, story)
+		if isUniqueViolation(err, "stories_unique_slug") {
+			return chronicle.ErrSlugConflict
+		}
+		if err != nil {
+			return err
+		}
 
-	if rows.Next() {
-		rows.Scan(&story.ID)
-	}
+		if rows.Next() {
+			rows.Scan(&story.ID)
+		}
+		rows.Close()
+
+		if err := s.setTopicsForStory(tx, story.ID, story.Topics); err != nil {
+			return err
+		}
+
+		if err := s.setTagsForStory(tx, story.ID, story.Tags); err != nil {
+			return err
+		}
+
+		if err := s.setBylinesForStory(tx, story.ID, story.Bylines); err != nil {
+			return err
+		}
 
-	if err := s.setTopicsForStory(story.ID, story.Topics); err != nil {
+		return s.setMediaForStory(tx, story.ID, story.MediaAssets)
+	})
+	if err != nil {
 		return chronicle.Story{}, err
 	}
 
 	return s.Find(story.ID)
 }
 
-//Update update story
+//Update update story and replace its topics, status is left untouched use UpdateStatus to change it
 func (s StoryRepository) Update(story chronicle.Story) (createdStory chronicle.Story, err error) {
 	defer func() {
 		if err != nil && err != sql.ErrNoRows {
@@ -377,26 +499,83 @@ func (s StoryRepository) Update(story chronicle.Story) (createdStory chronicle.S
 							slug, 
 							excerpt, 
 							content,
+							contentFormat,
+							contentHtml,
 							reporter,
 							editor,
 							author,
-							status,
 							media, 
+							publishAt,
+							unpublishAt,
 							updatedAt
 						) = (
 							:title, 
 							:slug, 
 							:excerpt, 
 							:content,
+							:contentformat,
+							:contenthtml,
 							:reporter,
 							:editor,
 							:author,
-							:status,
 							:media, 
+							:publishat,
+							:unpublishat,
 							now()
 						) WHERE id=:id`
 
-	_, err = s.db.NamedQuery(query, story)
+	// the replaced slug is kept so old urls can be redirected
+	historyQuery := `INSERT INTO story_slugs (
+							slug,
+							storyId,
+							createdAt
+						)
+						SELECT slug, id, now() FROM stories WHERE id=$1 AND slug<>$2
+						ON CONFLICT (slug) DO UPDATE SET storyId=EXCLUDED.storyId, createdAt=EXCLUDED.createdAt`
+
+	err = runInTx(s.db, func(tx dbExecutor) error {
+		if _, err := tx.Exec(historyQuery, story.ID, story.Slug); err != nil {
+			return err
+		}
+
+		_, err := tx.NamedExec(query, story)
+		if isUniqueViolation(err, "stories_unique_slug") {
+			return chronicle.ErrSlugConflict
+		}
+		if err != nil {
+			return err
+		}
+
+		// a slug taken back is current again
+		if _, err := tx.Exec(`DELETE FROM story_slugs WHERE slug=$1`, story.Slug); err != nil {
+			return err
+		}
+
+		// nil topics, tags, bylines or media assets leave those of the story untouched
+		if story.Topics != nil {
+			if err := s.setTopicsForStory(tx, story.ID, story.Topics); err != nil {
+				return err
+			}
+		}
+
+		if story.Tags != nil {
+			if err := s.setTagsForStory(tx, story.ID, story.Tags); err != nil {
+				return err
+			}
+		}
+
+		if story.Bylines != nil {
+			if err := s.setBylinesForStory(tx, story.ID, story.Bylines); err != nil {
+				return err
+			}
+		}
+
+		if story.MediaAssets == nil {
+			return nil
+		}
+
+		return s.setMediaForStory(tx, story.ID, story.MediaAssets)
+	})
 	if err != nil {
 		return chronicle.Story{}, err
 	}
@@ -404,62 +583,252 @@ func (s StoryRepository) Update(story chronicle.Story) (createdStory chronicle.S
 	return s.Find(story.ID)
 }
 
+/*
+UpdateStatus change status of story x from y to z,
+return sql.ErrNoRows when the story is no longer in status y
+*/
+func (s StoryRepository) UpdateStatus(id int, fromStatus string, toStatus string) (updatedStory chronicle.Story, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.UpdateStatus))
+		}
+	}()
+
+	// publishedAt keep the first time a story was published
+	query := `UPDATE stories SET (
+							status,
+							publishedAt,
+							updatedAt
+						) = (
+							$1,
+							CASE WHEN $1=$4 THEN COALESCE(publishedAt, now()) ELSE publishedAt END,
+							now()
+						) WHERE id=$2 AND status=$3`
+
+	result, err := s.db.Exec(query, toStatus, id, fromStatus, chronicle.StoryPublishStatus)
+	if err != nil {
+		return chronicle.Story{}, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return chronicle.Story{}, err
+	}
+
+	if affected == 0 {
+		return chronicle.Story{}, sql.ErrNoRows
+	}
+
+	return s.Find(id)
+}
+
+/*
+FindScheduled find approved stories waiting to be published and published ones waiting to be unpublished, nearest schedule first,
+the same stories PublishDue and UnpublishDue act on
+*/
+func (s StoryRepository) FindScheduled(option chronicle.PagingOptions) (stories chronicle.Stories, storiesCount int, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.FindScheduled))
+		}
+	}()
+
+	whereStatement := `WHERE (status=$1 AND publishAt IS NOT NULL) OR (status=$2 AND unpublishAt IS NOT NULL)`
+	queryArgs := []interface{}{chronicle.StoryApprovedStatus, chronicle.StoryPublishStatus}
+
+	stories = chronicle.Stories{}
+	selectQuery := fmt.Sprintf(
+		`SELECT
+			id,
+			title, 
+			slug, 
+			excerpt,
+			author,
+			status,
+			media, 
+			likes,
+			shares,
+			views,
+			publishAt,
+			unpublishAt,
+			publishedAt,
+			createdAt, 
+			updatedAt
+		FROM stories
+		%s
+		ORDER BY COALESCE(publishAt, unpublishAt) ASC
+		LIMIT %d 
+		OFFSET %d`,
+		whereStatement,
+		option.Limit,
+		option.Offset,
+	)
+
+	err = s.db.Select(&stories, selectQuery, queryArgs...)
+	if err != nil {
+		return chronicle.Stories{}, 0, err
+	}
+
+	countQuery := fmt.Sprintf(`SELECT count(*) FROM stories %s`, whereStatement)
+	row := s.db.QueryRow(countQuery, queryArgs...)
+	row.Scan(&storiesCount)
+
+	if len(stories) == 0 {
+		return chronicle.Stories{}, storiesCount, nil
+	}
+
+	if err := s.fillStoryAssociations(&stories); err != nil {
+		return chronicle.Stories{}, 0, err
+	}
+
+	return stories, storiesCount, nil
+}
+
+/*
+PublishDue publish every approved story with publishAt before now and return them,
+the update is a single statement so concurrent callers never publish the same story twice
+*/
+func (s StoryRepository) PublishDue(now time.Time) (stories chronicle.Stories, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.PublishDue))
+		}
+	}()
+
+	query := `UPDATE stories SET (
+							status,
+							publishAt,
+							publishedAt,
+							updatedAt
+						) = (
+							$1,
+							NULL,
+							COALESCE(publishedAt, now()),
+							now()
+						) WHERE status=$2 AND publishAt <= $3
+						RETURNING id`
+
+	return s.updateAndFind(query, chronicle.StoryPublishStatus, chronicle.StoryApprovedStatus, now)
+}
+
+/*
+UnpublishDue move every published story with unpublishAt before now back to draft and return them,
+the update is a single statement so concurrent callers never unpublish the same story twice
+*/
+func (s StoryRepository) UnpublishDue(now time.Time) (stories chronicle.Stories, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.UnpublishDue))
+		}
+	}()
+
+	query := `UPDATE stories SET (
+							status,
+							unpublishAt,
+							updatedAt
+						) = (
+							$1,
+							NULL,
+							now()
+						) WHERE status=$2 AND unpublishAt <= $3
+						RETURNING id`
+
+	return s.updateAndFind(query, chronicle.StoryDraftStatus, chronicle.StoryPublishStatus, now)
+}
+
 // internal function
-func (s StoryRepository) setTopicsForStory(storyId int, topics chronicle.Topics) (err error) {
+func (s StoryRepository) updateAndFind(query string, queryArgs ...interface{}) (stories chronicle.Stories, err error) {
 	defer func() {
 		if err != nil && err != sql.ErrNoRows {
-			err = errors.Wrap(err, function.GetFunctionName(s.setTopicsForStory))
+			err = errors.Wrap(err, function.GetFunctionName(s.updateAndFind))
 		}
 	}()
 
-	if len(topics) == 0 {
-		return nil
+	storyIds := []int{}
+	if err := s.db.Select(&storyIds, query, queryArgs...); err != nil {
+		return chronicle.Stories{}, err
+	}
+
+	stories = chronicle.Stories{}
+	for _, storyId := range storyIds {
+		story, err := s.Find(storyId)
+		if err != nil {
+			return chronicle.Stories{}, err
+		}
+		stories = append(stories, story)
 	}
 
-	multipleValueStatement := []string{}
+	return stories, nil
+}
+
+func (s StoryRepository) setTopicsForStory(tx dbExecutor, storyId int, topics chronicle.Topics) (err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.setTopicsForStory))
+		}
+	}()
+
+	topicIds := []int64{}
+	seenTopicIds := map[int]bool{}
 	for _, topic := range topics {
-		multipleValueStatement = append(
-			multipleValueStatement,
-			fmt.Sprintf("(%d, %d, now(), now())", storyId, topic.ID),
-		)
+		if !seenTopicIds[topic.ID] {
+			seenTopicIds[topic.ID] = true
+			topicIds = append(topicIds, int64(topic.ID))
+		}
 	}
-	// insert topics to junction table
-	topicQuery := fmt.Sprintf(
-		`INSERT INTO topic_stories (
+
+	// validate first instead of leaking foreign key violation
+	if len(topicIds) > 0 {
+		var foundTopicsCount int
+		err = tx.Get(&foundTopicsCount, `SELECT count(*) FROM topics WHERE id = ANY($1)`, pq.Array(topicIds))
+		if err != nil {
+			return err
+		}
+
+		if foundTopicsCount != len(topicIds) {
+			return chronicle.ErrUnknownTopic
+		}
+	}
+
+	deleteQuery := `DELETE FROM topic_stories WHERE storyId=$1 AND NOT (topicId = ANY($2))`
+	if _, err = tx.Exec(deleteQuery, storyId, pq.Array(topicIds)); err != nil {
+		return err
+	}
+
+	if len(topicIds) == 0 {
+		return nil
+	}
+
+	// insert topics to junction table, existing pairs are kept as is
+	insertQuery := `INSERT INTO topic_stories (
 			storyId,
 			topicId,
 			createdAt,
 			updatedAt
 		)
-		VALUES %s`,
-		strings.Join(multipleValueStatement, ","),
-	)
-	// no transactions
-	_, err = s.db.Exec(topicQuery)
+		SELECT $1, topicId, now(), now() FROM unnest($2::int[]) AS topicId
+		ON CONFLICT DO NOTHING`
+	_, err = tx.Exec(insertQuery, storyId, pq.Array(topicIds))
 	return err
 }
 
-func (s StoryRepository) getTopicsForStory(storyId int) (topics chronicle.Topics, err error) {
-	defer func() {
-		if err != nil && err != sql.ErrNoRows {
-			err = errors.Wrap(err, function.GetFunctionName(s.getTopicsForStory))
+// fillStoryAssociations fill Topics, Tags, Bylines, MediaAssets and Collections of every story
+func (s StoryRepository) fillStoryAssociations(stories *chronicle.Stories) error {
+	fillers := []func(stories *chronicle.Stories) error{
+		s.getTopicsForStories,
+		s.getTagsForStories,
+		s.getBylinesForStories,
+		s.getMediaForStories,
+		s.getCollectionsForStories,
+	}
+
+	for _, fill := range fillers {
+		if err := fill(stories); err != nil {
+			return err
 		}
-	}()
+	}
 
-	// fill Topics
-	topicQuery := `SELECT
-									topics.id,
-									topics.name, 
-									topics.slug, 
-									topics.createdat, 
-									topics.updatedat
-								FROM topic_stories
-								INNER JOIN topics ON (topic_stories.topicid = topics.id)
-								WHERE topic_stories.storyid=$1`
-
-	topics = chronicle.Topics{}
-	err = s.db.Select(&topics, topicQuery, storyId)
-	return topics, err
+	return nil
 }
 
 func (s StoryRepository) getTopicsForStories(stories *chronicle.Stories) (err error) {
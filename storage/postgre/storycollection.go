@@ -0,0 +1,111 @@
+package postgre
+
+import (
+	"database/sql"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	chronicle "github.com/AdhityaRamadhanus/chronicle"
+	function "github.com/AdhityaRamadhanus/chronicle/function"
+)
+
+// getCollectionsForStories fill collections of every story along with the published stories right before and after it there
+func (s StoryRepository) getCollectionsForStories(stories *chronicle.Stories) (err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.getCollectionsForStories))
+		}
+	}()
+
+	storyIds := []int64{}
+	storyCollections := map[int]chronicle.StoryCollections{}
+	for _, story := range *stories {
+		storyIds = append(storyIds, int64(story.ID))
+		storyCollections[story.ID] = chronicle.StoryCollections{}
+	}
+
+	// neighbours are the closest published members, drafts in between are skipped
+	collectionQuery := `SELECT
+			members.storyId,
+			collections.id,
+			collections.title,
+			collections.slug,
+			members.position,
+			previous.id AS previousId,
+			previous.title AS previousTitle,
+			previous.slug AS previousSlug,
+			next.id AS nextId,
+			next.title AS nextTitle,
+			next.slug AS nextSlug
+		FROM collection_stories members
+		INNER JOIN collections ON (members.collectionId = collections.id)
+		LEFT JOIN LATERAL (
+			SELECT stories.id, stories.title, stories.slug
+			FROM collection_stories
+			INNER JOIN stories ON (collection_stories.storyId = stories.id)
+			WHERE collection_stories.collectionId = members.collectionId AND collection_stories.position < members.position AND stories.status = $2
+			ORDER BY collection_stories.position DESC
+			LIMIT 1
+		) previous ON true
+		LEFT JOIN LATERAL (
+			SELECT stories.id, stories.title, stories.slug
+			FROM collection_stories
+			INNER JOIN stories ON (collection_stories.storyId = stories.id)
+			WHERE collection_stories.collectionId = members.collectionId AND collection_stories.position > members.position AND stories.status = $2
+			ORDER BY collection_stories.position ASC
+			LIMIT 1
+		) next ON true
+		WHERE members.storyId = ANY($1)
+		ORDER BY members.storyId, collections.title`
+
+	rows := []struct {
+		StoryID       int            `db:"storyid"`
+		ID            int            `db:"id"`
+		Title         string         `db:"title"`
+		Slug          string         `db:"slug"`
+		Position      int            `db:"position"`
+		PreviousID    sql.NullInt64  `db:"previousid"`
+		PreviousTitle sql.NullString `db:"previoustitle"`
+		PreviousSlug  sql.NullString `db:"previousslug"`
+		NextID        sql.NullInt64  `db:"nextid"`
+		NextTitle     sql.NullString `db:"nexttitle"`
+		NextSlug      sql.NullString `db:"nextslug"`
+	}{}
+	if err := s.db.Select(&rows, collectionQuery, pq.Array(storyIds), chronicle.StoryPublishStatus); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		collection := chronicle.StoryCollection{
+			ID:       row.ID,
+			Title:    row.Title,
+			Slug:     row.Slug,
+			Position: row.Position,
+		}
+
+		if row.PreviousID.Valid {
+			collection.Previous = &chronicle.StoryLink{
+				ID:    int(row.PreviousID.Int64),
+				Title: row.PreviousTitle.String,
+				Slug:  row.PreviousSlug.String,
+			}
+		}
+
+		if row.NextID.Valid {
+			collection.Next = &chronicle.StoryLink{
+				ID:    int(row.NextID.Int64),
+				Title: row.NextTitle.String,
+				Slug:  row.NextSlug.String,
+			}
+		}
+
+		storyCollections[row.StoryID] = append(storyCollections[row.StoryID], collection)
+	}
+
+	for idx, story := range *stories {
+		(*stories)[idx].Collections = storyCollections[story.ID]
+	}
+
+	return nil
+}
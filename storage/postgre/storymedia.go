@@ -0,0 +1,120 @@
+package postgre
+
+import (
+	"database/sql"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	chronicle "github.com/AdhityaRamadhanus/chronicle"
+	function "github.com/AdhityaRamadhanus/chronicle/function"
+)
+
+func (s StoryRepository) setMediaForStory(tx dbExecutor, storyId int, assets chronicle.MediaAssets) (err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.setMediaForStory))
+		}
+	}()
+
+	// position follow the order of assets, an asset is referenced once
+	mediaIds := []int64{}
+	positions := []int64{}
+	seenMediaIds := map[int]bool{}
+	for _, asset := range assets {
+		if seenMediaIds[asset.ID] {
+			continue
+		}
+		seenMediaIds[asset.ID] = true
+
+		mediaIds = append(mediaIds, int64(asset.ID))
+		positions = append(positions, int64(len(positions)))
+	}
+
+	// validate first instead of leaking foreign key violation
+	if len(mediaIds) > 0 {
+		var foundMediaCount int
+		err = tx.Get(&foundMediaCount, `SELECT count(*) FROM media_assets WHERE id = ANY($1)`, pq.Array(mediaIds))
+		if err != nil {
+			return err
+		}
+
+		if foundMediaCount != len(mediaIds) {
+			return chronicle.ErrUnknownMedia
+		}
+	}
+
+	if _, err = tx.Exec(`DELETE FROM story_media WHERE storyId=$1`, storyId); err != nil {
+		return err
+	}
+
+	if len(mediaIds) == 0 {
+		return nil
+	}
+
+	insertQuery := `INSERT INTO story_media (
+			storyId,
+			mediaId,
+			position,
+			createdAt
+		)
+		SELECT $1, assets.mediaId, assets.position, now()
+		FROM unnest($2::int[], $3::int[]) AS assets(mediaId, position)`
+	_, err = tx.Exec(insertQuery, storyId, pq.Array(mediaIds), pq.Array(positions))
+	return err
+}
+
+func (s StoryRepository) getMediaForStories(stories *chronicle.Stories) (err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.getMediaForStories))
+		}
+	}()
+
+	storyIds := []int64{}
+	storyMedia := map[int]chronicle.MediaAssets{}
+	for _, story := range *stories {
+		storyIds = append(storyIds, int64(story.ID))
+		storyMedia[story.ID] = chronicle.MediaAssets{}
+	}
+
+	mediaQuery := `SELECT
+			story_media.storyId,
+			media_assets.id,
+			media_assets.key,
+			media_assets.filename,
+			media_assets.mimetype,
+			media_assets.size,
+			media_assets.width,
+			media_assets.height,
+			media_assets.focalx,
+			media_assets.focaly,
+			media_assets.renditions,
+			media_assets.caption,
+			media_assets.credit,
+			media_assets.alttext,
+			media_assets.createdat,
+			media_assets.updatedat
+		FROM story_media
+		INNER JOIN media_assets ON (story_media.mediaId = media_assets.id)
+		WHERE story_media.storyId = ANY($1)
+		ORDER BY story_media.storyId, story_media.position`
+
+	rows := []struct {
+		StoryID int `db:"storyid"`
+		chronicle.MediaAsset
+	}{}
+	if err := s.db.Select(&rows, mediaQuery, pq.Array(storyIds)); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		storyMedia[row.StoryID] = append(storyMedia[row.StoryID], row.MediaAsset)
+	}
+
+	for idx, story := range *stories {
+		(*stories)[idx].MediaAssets = storyMedia[story.ID]
+	}
+
+	return nil
+}
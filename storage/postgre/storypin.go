@@ -0,0 +1,120 @@
+package postgre
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	chronicle "github.com/AdhityaRamadhanus/chronicle"
+	function "github.com/AdhityaRamadhanus/chronicle/function"
+)
+
+/*
+getPinsForListing find pins of a listing that have not expired by slot, only stories matching whereStatement
+of the listing are considered so the pins never add stories to it
+*/
+func (s StoryRepository) getPinsForListing(pinCondition string, whereStatement string, queryArgs ...interface{}) (pins chronicle.StoryPins, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.getPinsForListing))
+		}
+	}()
+
+	pinQuery := fmt.Sprintf(
+		`SELECT
+			story_pins.id,
+			story_pins.storyId,
+			COALESCE(story_pins.topicId, 0) AS topicId,
+			story_pins.slot,
+			story_pins.expiresAt,
+			story_pins.createdAt,
+			story_pins.updatedAt
+		FROM story_pins
+		INNER JOIN stories ON (story_pins.storyId = stories.id)
+		%s AND %s AND %s
+		ORDER BY story_pins.slot`,
+		whereStatement,
+		pinCondition,
+		activePinCondition,
+	)
+
+	pins = chronicle.StoryPins{}
+	err = s.db.Select(&pins, pinQuery, queryArgs...)
+	return pins, err
+}
+
+//placePinnedStories merge pinned stories of page between unpinned stories, which are in listing order
+func (s StoryRepository) placePinnedStories(page chronicle.PinnedPage, stories chronicle.Stories, limit int) (placedStories chronicle.Stories, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.placePinnedStories))
+		}
+	}()
+
+	if len(page.Pinned) == 0 {
+		return stories, nil
+	}
+
+	storyIds := []int64{}
+	for _, storyId := range page.Pinned {
+		storyIds = append(storyIds, int64(storyId))
+	}
+
+	pinnedQuery := `SELECT
+			id,
+			title,
+			slug,
+			excerpt,
+			author,
+			status,
+			media,
+			likes,
+			shares,
+			views,
+			publishAt,
+			unpublishAt,
+			publishedAt,
+			createdAt,
+			updatedAt
+		FROM stories
+		WHERE id = ANY($1)`
+
+	pinnedStories := chronicle.Stories{}
+	if err := s.db.Select(&pinnedStories, pinnedQuery, pq.Array(storyIds)); err != nil {
+		return chronicle.Stories{}, err
+	}
+
+	pinnedStoriesById := map[int]chronicle.Story{}
+	for _, story := range pinnedStories {
+		pinnedStoriesById[story.ID] = story
+	}
+
+	placedStories = chronicle.Stories{}
+	for idx := 0; idx < limit; idx++ {
+		if storyId, ok := page.Pinned[idx]; ok {
+			// a pinned story deleted in the meantime leave its slot to the next story
+			if story, found := pinnedStoriesById[storyId]; found {
+				placedStories = append(placedStories, story)
+				continue
+			}
+		}
+
+		if len(stories) > 0 {
+			placedStories = append(placedStories, stories[0])
+			stories = stories[1:]
+		}
+	}
+
+	return placedStories, nil
+}
+
+//pinnedStoryIds list story ids of pins for excluding them from the unpinned stories of a listing
+func pinnedStoryIds(pins chronicle.StoryPins) []int64 {
+	storyIds := []int64{}
+	for _, pin := range pins {
+		storyIds = append(storyIds, int64(pin.StoryID))
+	}
+	return storyIds
+}
@@ -0,0 +1,181 @@
+package postgre
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	chronicle "github.com/AdhityaRamadhanus/chronicle"
+	function "github.com/AdhityaRamadhanus/chronicle/function"
+)
+
+//FindByTag find published stories tagged with tag x
+func (s StoryRepository) FindByTag(tag int, option chronicle.PagingOptions) (stories chronicle.Stories, storiesCount int, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.FindByTag))
+		}
+	}()
+
+	whereStatement := "WHERE stories.status=$2 AND EXISTS (SELECT 1 FROM story_tags WHERE story_tags.storyId=stories.id AND story_tags.tagId=$1)"
+
+	stories = chronicle.Stories{}
+	selectQuery := fmt.Sprintf(
+		`SELECT
+			stories.id,
+			stories.title,
+			stories.slug,
+			stories.excerpt,
+			stories.author,
+			stories.status,
+			stories.media,
+			stories.likes,
+			stories.shares,
+			stories.views,
+			stories.publishat,
+			stories.unpublishat,
+			stories.publishedat,
+			stories.createdat,
+			stories.updatedat
+		FROM stories
+		%s
+		ORDER BY %s %s
+		LIMIT %d
+		OFFSET %d`,
+		whereStatement,
+		option.SortBy,
+		option.Order,
+		option.Limit,
+		option.Offset,
+	)
+
+	err = s.db.Select(&stories, selectQuery, tag, chronicle.StoryPublishStatus)
+	if err != nil {
+		return chronicle.Stories{}, 0, err
+	}
+
+	if len(stories) == 0 {
+		return chronicle.Stories{}, 0, nil
+	}
+
+	countQuery := fmt.Sprintf(`SELECT count(*) FROM stories %s`, whereStatement)
+	row := s.db.QueryRow(countQuery, tag, chronicle.StoryPublishStatus)
+	row.Scan(&storiesCount)
+
+	if err := s.fillStoryAssociations(&stories); err != nil {
+		return chronicle.Stories{}, 0, err
+	}
+
+	return stories, storiesCount, nil
+}
+
+/*
+setTagsForStory create tags that do not exist yet and tag the story with them,
+tags are matched by slug and a tag kept on the story keep the time it was added
+*/
+func (s StoryRepository) setTagsForStory(tx dbExecutor, storyId int, tags chronicle.Tags) (err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.setTagsForStory))
+		}
+	}()
+
+	// position follow the order of tags, the first spelling of a slug win
+	names := []string{}
+	slugs := []string{}
+	positions := []int64{}
+	seenSlugs := map[string]bool{}
+	for _, tag := range tags {
+		if tag.Slug == "" || seenSlugs[tag.Slug] {
+			continue
+		}
+		seenSlugs[tag.Slug] = true
+
+		names = append(names, tag.Name)
+		slugs = append(slugs, tag.Slug)
+		positions = append(positions, int64(len(positions)))
+	}
+
+	upsertQuery := `INSERT INTO tags (
+			name,
+			slug,
+			createdAt,
+			updatedAt
+		)
+		SELECT input.name, input.slug, now(), now()
+		FROM unnest($1::varchar[], $2::varchar[]) AS input(name, slug)
+		ON CONFLICT (slug) DO NOTHING`
+	if _, err = tx.Exec(upsertQuery, pq.Array(names), pq.Array(slugs)); err != nil {
+		return err
+	}
+
+	deleteQuery := `DELETE FROM story_tags
+		WHERE storyId=$1 AND tagId NOT IN (SELECT id FROM tags WHERE slug = ANY($2))`
+	if _, err = tx.Exec(deleteQuery, storyId, pq.Array(slugs)); err != nil {
+		return err
+	}
+
+	if len(slugs) == 0 {
+		return nil
+	}
+
+	insertQuery := `INSERT INTO story_tags (
+			storyId,
+			tagId,
+			position,
+			createdAt
+		)
+		SELECT $1, tags.id, input.position, now()
+		FROM unnest($2::varchar[], $3::int[]) AS input(slug, position)
+		INNER JOIN tags ON (tags.slug = input.slug)
+		ON CONFLICT (storyId, tagId) DO UPDATE SET position=EXCLUDED.position`
+	_, err = tx.Exec(insertQuery, storyId, pq.Array(slugs), pq.Array(positions))
+	return err
+}
+
+func (s StoryRepository) getTagsForStories(stories *chronicle.Stories) (err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.getTagsForStories))
+		}
+	}()
+
+	storyIds := []int64{}
+	storyTags := map[int]chronicle.Tags{}
+	for _, story := range *stories {
+		storyIds = append(storyIds, int64(story.ID))
+		storyTags[story.ID] = chronicle.Tags{}
+	}
+
+	tagQuery := `SELECT
+			story_tags.storyId,
+			tags.id,
+			tags.name,
+			tags.slug,
+			tags.createdat,
+			tags.updatedat
+		FROM story_tags
+		INNER JOIN tags ON (story_tags.tagId = tags.id)
+		WHERE story_tags.storyId = ANY($1)
+		ORDER BY story_tags.storyId, story_tags.position`
+
+	rows := []struct {
+		StoryID int `db:"storyid"`
+		chronicle.Tag
+	}{}
+	if err := s.db.Select(&rows, tagQuery, pq.Array(storyIds)); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		storyTags[row.StoryID] = append(storyTags[row.StoryID], row.Tag)
+	}
+
+	for idx, story := range *stories {
+		(*stories)[idx].Tags = storyTags[story.ID]
+	}
+
+	return nil
+}
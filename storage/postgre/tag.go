@@ -0,0 +1,102 @@
+package postgre
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	chronicle "github.com/AdhityaRamadhanus/chronicle"
+	function "github.com/AdhityaRamadhanus/chronicle/function"
+)
+
+/*
+TagRepository is implementation of TagRepository interface
+of chronicle domain using postgre
+*/
+type TagRepository struct {
+	db dbExecutor
+}
+
+//NewTagRepository is constructor to create tag repository
+func NewTagRepository(conn *sqlx.DB, tableName string) *TagRepository {
+	return &TagRepository{
+		db: conn,
+	}
+}
+
+//FindBySlug find tag by slug
+func (s TagRepository) FindBySlug(slug string) (tag chronicle.Tag, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.FindBySlug))
+		}
+	}()
+
+	tag = chronicle.Tag{}
+	query := `SELECT
+							id,
+							name,
+							slug,
+							createdAt,
+							updatedAt
+						FROM tags
+						WHERE slug=$1`
+
+	err = s.db.Get(&tag, query, slug)
+	return tag, err
+}
+
+//FindByPrefix find tags whose slug start with prefix, most used first
+func (s TagRepository) FindByPrefix(prefix string, limit int) (tags chronicle.Tags, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.FindByPrefix))
+		}
+	}()
+
+	// slugs only contain letters, digits and hyphens so the prefix need no escaping
+	query := `SELECT
+							tags.id,
+							tags.name,
+							tags.slug,
+							tags.createdAt,
+							tags.updatedAt
+						FROM tags
+						WHERE tags.slug LIKE $1 || '%'
+						ORDER BY (SELECT count(*) FROM story_tags WHERE story_tags.tagId=tags.id) DESC, tags.slug ASC
+						LIMIT $2`
+
+	tags = chronicle.Tags{}
+	err = s.db.Select(&tags, query, prefix, limit)
+	return tags, err
+}
+
+//CountSince count published stories tagged since a point of time for every tag, most used first
+func (s TagRepository) CountSince(since time.Time, limit int) (tagCounts chronicle.TagCounts, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.CountSince))
+		}
+	}()
+
+	query := `SELECT
+							tags.id,
+							tags.name,
+							tags.slug,
+							tags.createdAt,
+							tags.updatedAt,
+							count(*) AS count
+						FROM story_tags
+						INNER JOIN tags ON (story_tags.tagId = tags.id)
+						INNER JOIN stories ON (story_tags.storyId = stories.id)
+						WHERE story_tags.createdAt >= $1::timestamptz AND stories.status=$3
+						GROUP BY tags.id
+						ORDER BY count DESC, tags.slug ASC
+						LIMIT $2`
+
+	tagCounts = chronicle.TagCounts{}
+	err = s.db.Select(&tagCounts, query, since, limit, chronicle.StoryPublishStatus)
+	return tagCounts, err
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 
 	chronicle "github.com/AdhityaRamadhanus/chronicle"
@@ -16,7 +17,7 @@ TopicRepository is implementation of TopicRepository interface
 of chronicle domain using postgre
 */
 type TopicRepository struct {
-	db *sqlx.DB
+	db dbExecutor
 }
 
 //NewTopicRepository is constructor to create topic repository
@@ -37,6 +38,7 @@ func (s TopicRepository) Find(id int) (topic chronicle.Topic, err error) {
 	topic = chronicle.Topic{}
 	query := `SELECT
 							id,
+							parentId,
 							name,
 							slug,
 							createdAt,
@@ -45,6 +47,11 @@ func (s TopicRepository) Find(id int) (topic chronicle.Topic, err error) {
 						WHERE id=$1`
 
 	err = s.db.Get(&topic, query, id)
+	if err != nil {
+		return chronicle.Topic{}, err
+	}
+
+	topic.Breadcrumbs, err = s.getBreadcrumbsForTopic(topic.ID)
 	return topic, err
 }
 
@@ -59,6 +66,7 @@ func (s TopicRepository) FindBySlug(slug string) (topic chronicle.Topic, err err
 	topic = chronicle.Topic{}
 	query := `SELECT
 							id,
+							parentId,
 							name,
 							slug,
 							createdAt,
@@ -67,9 +75,47 @@ func (s TopicRepository) FindBySlug(slug string) (topic chronicle.Topic, err err
 						WHERE slug=$1`
 
 	err = s.db.Get(&topic, query, slug)
+	if err != nil {
+		return chronicle.Topic{}, err
+	}
+
+	topic.Breadcrumbs, err = s.getBreadcrumbsForTopic(topic.ID)
 	return topic, err
 }
 
+//FindByFormerSlug find topic that used to be reachable by slug
+func (s TopicRepository) FindByFormerSlug(slug string) (topic chronicle.Topic, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.FindByFormerSlug))
+		}
+	}()
+
+	var topicId int
+	err = s.db.Get(&topicId, `SELECT topicId FROM topic_slugs WHERE slug=$1`, slug)
+	if err != nil {
+		return chronicle.Topic{}, err
+	}
+
+	return s.Find(topicId)
+}
+
+//IsSlugTaken check whether slug is used, now or formerly, by a topic other than exceptID
+func (s TopicRepository) IsSlugTaken(slug string, exceptID int) (taken bool, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Wrap(err, function.GetFunctionName(s.IsSlugTaken))
+		}
+	}()
+
+	query := `SELECT
+							EXISTS (SELECT 1 FROM topics WHERE slug=$1 AND id<>$2) OR
+							EXISTS (SELECT 1 FROM topic_slugs WHERE slug=$1 AND topicId<>$2)`
+
+	err = s.db.Get(&taken, query, slug, exceptID)
+	return taken, err
+}
+
 //Delete delete topic by id
 func (s TopicRepository) Delete(id int) (err error) {
 	defer func() {
@@ -80,13 +126,89 @@ func (s TopicRepository) Delete(id int) (err error) {
 
 	query := `DELETE FROM topics where id=$1`
 
-	deleteStatement, err := s.db.Prepare(query)
-	if err != nil {
+	// children move up to the parent of deleted topic instead of becoming roots
+	reparentQuery := `UPDATE topics SET parentId=(SELECT parentId FROM topics WHERE id=$1) WHERE parentId=$1`
+
+	return runInTx(s.db, func(tx dbExecutor) error {
+		if _, err := tx.Exec(reparentQuery, id); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec(query, id)
 		return err
-	}
-	defer deleteStatement.Close()
-	_, err = deleteStatement.Exec(id)
-	return err
+	})
+}
+
+/*
+Merge fold topic x into target, stories of x are moved to target, children of x are moved under target
+and every slug x was reachable by redirect to target before x is deleted
+*/
+func (s TopicRepository) Merge(id int, targetID int) (err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.Merge))
+		}
+	}()
+
+	// stories already in target keep their row so the composite primary key is not violated
+	moveStoriesQuery := `INSERT INTO topic_stories (
+							topicId,
+							storyId,
+							createdAt,
+							updatedAt
+						)
+						SELECT $2, storyId, createdAt, now() FROM topic_stories WHERE topicId=$1
+						ON CONFLICT (topicId, storyId) DO NOTHING`
+
+	// pins whose story or slot is already pinned in target keep the target pin, expired pins are not worth moving
+	clearExpiredPinsQuery := `DELETE FROM story_pins WHERE topicId=$1 AND NOT ` + activePinCondition
+
+	movePinsQuery := `INSERT INTO story_pins (
+							storyId,
+							topicId,
+							slot,
+							expiresAt,
+							createdAt,
+							updatedAt
+						)
+						SELECT storyId, $2, slot, expiresAt, createdAt, now() FROM story_pins WHERE topicId=$1 AND ` + activePinCondition + `
+						ORDER BY slot
+						ON CONFLICT DO NOTHING`
+
+	moveChildrenQuery := `UPDATE topics SET parentId=$2 WHERE parentId=$1`
+
+	moveFormerSlugsQuery := `UPDATE topic_slugs SET topicId=$2 WHERE topicId=$1`
+
+	redirectSlugQuery := `INSERT INTO topic_slugs (
+							slug,
+							topicId,
+							createdAt
+						)
+						SELECT slug, $2, now() FROM topics WHERE id=$1
+						ON CONFLICT (slug) DO UPDATE SET topicId=EXCLUDED.topicId, createdAt=EXCLUDED.createdAt`
+
+	// remaining topic_stories and story_pins rows of x go away with it
+	deleteQuery := `DELETE FROM topics WHERE id=$1`
+
+	return runInTx(s.db, func(tx dbExecutor) error {
+		// children of x move under target
+		if err := ensureNotAncestor(tx, id, targetID); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(clearExpiredPinsQuery, targetID); err != nil {
+			return err
+		}
+
+		for _, query := range []string{moveStoriesQuery, movePinsQuery, moveChildrenQuery, moveFormerSlugsQuery, redirectSlugQuery} {
+			if _, err := tx.Exec(query, id, targetID); err != nil {
+				return err
+			}
+		}
+
+		_, err := tx.Exec(deleteQuery, id)
+		return err
+	})
 }
 
 //All get all topic
@@ -101,6 +223,7 @@ func (s TopicRepository) All(option chronicle.PagingOptions) (topics chronicle.T
 	selectQuery := fmt.Sprintf(
 		`SELECT
 			id,
+			parentId,
 			name,
 			slug,
 			createdAt,
@@ -123,7 +246,55 @@ func (s TopicRepository) All(option chronicle.PagingOptions) (topics chronicle.T
 	countQuery := `SELECT count(*) FROM topics`
 	row := s.db.QueryRow(countQuery)
 	err = row.Scan(&topicsCount)
+	if err != nil {
+		return chronicle.Topics{}, 0, err
+	}
+
+	err = s.getBreadcrumbsForTopics(&topics)
+	return topics, topicsCount, err
+}
+
+//FindChildren get topics directly under topic x
+func (s TopicRepository) FindChildren(id int, option chronicle.PagingOptions) (topics chronicle.Topics, topicsCount int, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.FindChildren))
+		}
+	}()
+
+	topics = chronicle.Topics{}
+	selectQuery := fmt.Sprintf(
+		`SELECT
+			id,
+			parentId,
+			name,
+			slug,
+			createdAt,
+			updatedAt
+		FROM topics
+		WHERE parentId=$1
+		ORDER BY %s %s
+		LIMIT %d
+		OFFSET %d`,
+		option.SortBy,
+		option.Order,
+		option.Limit,
+		option.Offset,
+	)
+
+	err = s.db.Select(&topics, selectQuery, id)
+	if err != nil {
+		return chronicle.Topics{}, 0, err
+	}
+
+	countQuery := `SELECT count(*) FROM topics WHERE parentId=$1`
+	row := s.db.QueryRow(countQuery, id)
+	err = row.Scan(&topicsCount)
+	if err != nil {
+		return chronicle.Topics{}, 0, err
+	}
 
+	err = s.getBreadcrumbsForTopics(&topics)
 	return topics, topicsCount, err
 }
 
@@ -136,11 +307,13 @@ func (s TopicRepository) Insert(topic chronicle.Topic) (createdTopic chronicle.T
 	}()
 
 	query := `INSERT INTO topics (
+							parentId,
 							name,
 							slug,
 							createdAt, 
 							updatedAt
 						) VALUES (
+							:parentid,
 							:name, 
 							:slug, 
 							now(), 
@@ -148,6 +321,9 @@ func (s TopicRepository) Insert(topic chronicle.Topic) (createdTopic chronicle.T
 						) RETURNING id`
 
 	rows, err := s.db.NamedQuery(query, topic)
+	if isUniqueViolation(err, "topics_unique_slug") {
+		return chronicle.Topic{}, chronicle.ErrSlugConflict
+	}
 	if err != nil {
 		return chronicle.Topic{}, err
 	}
@@ -155,6 +331,7 @@ func (s TopicRepository) Insert(topic chronicle.Topic) (createdTopic chronicle.T
 	if rows.Next() {
 		rows.Scan(&topic.ID)
 	}
+	rows.Close()
 
 	return s.Find(topic.ID)
 }
@@ -168,19 +345,146 @@ func (s TopicRepository) Update(topic chronicle.Topic) (updatedTopic chronicle.T
 	}()
 
 	query := `UPDATE topics SET (
+							parentId,
 							name,
 							slug,
 							updatedAt
 						) = (
+							:parentid,
 							:name, 
 							:slug, 
 							now()
 						) WHERE id=:id`
 
-	_, err = s.db.NamedQuery(query, topic)
+	// the replaced slug is kept so old urls can be redirected
+	historyQuery := `INSERT INTO topic_slugs (
+							slug,
+							topicId,
+							createdAt
+						)
+						SELECT slug, id, now() FROM topics WHERE id=$1 AND slug<>$2
+						ON CONFLICT (slug) DO UPDATE SET topicId=EXCLUDED.topicId, createdAt=EXCLUDED.createdAt`
+
+	err = runInTx(s.db, func(tx dbExecutor) error {
+		if topic.ParentID != nil {
+			if err := ensureNotAncestor(tx, topic.ID, *topic.ParentID); err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.Exec(historyQuery, topic.ID, topic.Slug); err != nil {
+			return err
+		}
+
+		_, err := tx.NamedExec(query, topic)
+		if isUniqueViolation(err, "topics_unique_slug") {
+			return chronicle.ErrSlugConflict
+		}
+		if err != nil {
+			return err
+		}
+
+		// a slug taken back is current again
+		_, err = tx.Exec(`DELETE FROM topic_slugs WHERE slug=$1`, topic.Slug)
+		return err
+	})
 	if err != nil {
 		return chronicle.Topic{}, err
 	}
 
 	return s.Find(topic.ID)
 }
+
+/*
+ensureNotAncestor walk up from parentID to the root and fail with chronicle.ErrTopicCycle when it pass topicID,
+or chronicle.ErrUnknownTopic when parentID does not exist.
+Topic and every ancestor is locked on the way, a concurrent move of one of them wait for this transaction
+or deadlock with it instead of both passing the check
+*/
+func ensureNotAncestor(tx dbExecutor, topicID int, parentID int) error {
+	if _, err := tx.Exec(`SELECT id FROM topics WHERE id=$1 FOR UPDATE`, topicID); err != nil {
+		return err
+	}
+
+	visited := map[int]bool{}
+	for ancestorID := &parentID; ancestorID != nil; {
+		if *ancestorID == topicID || visited[*ancestorID] {
+			return chronicle.ErrTopicCycle
+		}
+		visited[*ancestorID] = true
+
+		var nextID *int
+		err := tx.Get(&nextID, `SELECT parentId FROM topics WHERE id=$1 FOR UPDATE`, *ancestorID)
+		if err == sql.ErrNoRows {
+			return chronicle.ErrUnknownTopic
+		}
+		if err != nil {
+			return err
+		}
+		ancestorID = nextID
+	}
+
+	return nil
+}
+
+func (s TopicRepository) getBreadcrumbsForTopic(topicId int) (breadcrumbs chronicle.TopicBreadcrumbs, err error) {
+	topics := chronicle.Topics{chronicle.Topic{ID: topicId}}
+	if err := s.getBreadcrumbsForTopics(&topics); err != nil {
+		return chronicle.TopicBreadcrumbs{}, err
+	}
+
+	return topics[0].Breadcrumbs, nil
+}
+
+func (s TopicRepository) getBreadcrumbsForTopics(topics *chronicle.Topics) (err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.getBreadcrumbsForTopics))
+		}
+	}()
+
+	topicIds := []int64{}
+	topicBreadcrumbs := map[int]chronicle.TopicBreadcrumbs{}
+	for _, topic := range *topics {
+		topicIds = append(topicIds, int64(topic.ID))
+		topicBreadcrumbs[topic.ID] = chronicle.TopicBreadcrumbs{}
+	}
+
+	// walk up from every topic, depth is capped should the tree ever loop
+	breadcrumbQuery := `WITH RECURSIVE ancestors AS (
+			SELECT id AS topicId, parentId AS ancestorId, 1 AS depth
+			FROM topics
+			WHERE id = ANY($1) AND parentId IS NOT NULL
+			UNION ALL
+			SELECT ancestors.topicId, topics.parentId, ancestors.depth + 1
+			FROM ancestors
+			INNER JOIN topics ON (topics.id = ancestors.ancestorId)
+			WHERE topics.parentId IS NOT NULL AND ancestors.depth < 32
+		)
+		SELECT
+			ancestors.topicId,
+			topics.id,
+			topics.name,
+			topics.slug
+		FROM ancestors
+		INNER JOIN topics ON (topics.id = ancestors.ancestorId)
+		ORDER BY ancestors.topicId, ancestors.depth DESC`
+
+	rows := []struct {
+		TopicID int `db:"topicid"`
+		chronicle.TopicBreadcrumb
+	}{}
+	if err := s.db.Select(&rows, breadcrumbQuery, pq.Array(topicIds)); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		topicBreadcrumbs[row.TopicID] = append(topicBreadcrumbs[row.TopicID], row.TopicBreadcrumb)
+	}
+
+	for idx, topic := range *topics {
+		(*topics)[idx].Breadcrumbs = topicBreadcrumbs[topic.ID]
+	}
+
+	return nil
+}
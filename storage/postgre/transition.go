@@ -0,0 +1,104 @@
+package postgre
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	chronicle "github.com/AdhityaRamadhanus/chronicle"
+	function "github.com/AdhityaRamadhanus/chronicle/function"
+)
+
+/*
+StoryTransitionRepository is implementation of StoryTransitionRepository interface
+of chronicle domain using postgre
+*/
+type StoryTransitionRepository struct {
+	db dbExecutor
+}
+
+//NewStoryTransitionRepository is constructor to create story transition repository
+func NewStoryTransitionRepository(conn *sqlx.DB, tableName string) *StoryTransitionRepository {
+	return &StoryTransitionRepository{
+		db: conn,
+	}
+}
+
+//FindByStory find all transitions applied to story x
+func (s StoryTransitionRepository) FindByStory(storyID int, option chronicle.PagingOptions) (transitions chronicle.StoryTransitions, transitionsCount int, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.FindByStory))
+		}
+	}()
+
+	transitions = chronicle.StoryTransitions{}
+	selectQuery := fmt.Sprintf(
+		`SELECT
+			id,
+			storyId,
+			transition,
+			fromStatus,
+			toStatus,
+			clientId,
+			createdAt
+		FROM story_transitions
+		WHERE storyId=$1
+		ORDER BY id %s
+		LIMIT %d
+		OFFSET %d`,
+		option.Order,
+		option.Limit,
+		option.Offset,
+	)
+
+	err = s.db.Select(&transitions, selectQuery, storyID)
+	if err != nil {
+		return chronicle.StoryTransitions{}, 0, err
+	}
+
+	countQuery := `SELECT count(*) FROM story_transitions WHERE storyId=$1`
+	row := s.db.QueryRow(countQuery, storyID)
+	err = row.Scan(&transitionsCount)
+
+	return transitions, transitionsCount, err
+}
+
+//Insert record a story transition
+func (s StoryTransitionRepository) Insert(transition chronicle.StoryTransition) (createdTransition chronicle.StoryTransition, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.Insert))
+		}
+	}()
+
+	query := `INSERT INTO story_transitions (
+							storyId,
+							transition,
+							fromStatus,
+							toStatus,
+							clientId,
+							createdAt
+						) VALUES (
+							:storyid,
+							:transition,
+							:fromstatus,
+							:tostatus,
+							:clientid,
+							now()
+						) RETURNING id, createdAt`
+
+	rows, err := s.db.NamedQuery(query, transition)
+	if err != nil {
+		return chronicle.StoryTransition{}, err
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		rows.Scan(&transition.ID, &transition.CreatedAt)
+	}
+
+	return transition, rows.Err()
+}
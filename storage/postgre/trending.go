@@ -0,0 +1,137 @@
+package postgre
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	chronicle "github.com/AdhityaRamadhanus/chronicle"
+	function "github.com/AdhityaRamadhanus/chronicle/function"
+)
+
+/*
+RefreshTrending recompute score of published stories engaged within period before now,
+engagement is weighted views 1, likes 3, shares 5 and halved every quarter of period,
+the sum is halved again for every period since the story was published.
+Instances refreshing the same window at once are serialized by an advisory lock, the ones not getting it skip the refresh
+*/
+func (s StoryRepository) RefreshTrending(window string, period time.Duration, now time.Time) (err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Wrap(err, function.GetFunctionName(s.RefreshTrending))
+		}
+	}()
+
+	periodSeconds := period.Seconds()
+	insertQuery := `INSERT INTO story_trending (
+							storyId,
+							period,
+							score,
+							computedAt
+						)
+						SELECT
+							stories.id,
+							$1,
+							SUM(
+								(story_engagements.views + 3 * story_engagements.likes + 5 * story_engagements.shares) *
+								power(0.5, extract(epoch FROM ($2::timestamptz - story_engagements.bucket)) / $3::float8)
+							) * power(0.5, greatest(extract(epoch FROM ($2::timestamptz - COALESCE(stories.publishedAt, stories.createdAt))), 0) / $4::float8),
+							$2
+						FROM stories
+						INNER JOIN story_engagements ON (story_engagements.storyId = stories.id)
+						WHERE stories.status=$5 AND story_engagements.bucket >= $2::timestamptz - ($4::float8 * interval '1 second')
+						GROUP BY stories.id, stories.publishedAt, stories.createdAt`
+
+	return runInTx(s.db, func(tx dbExecutor) error {
+		// the lock is released with the transaction
+		locked := false
+		if err := tx.QueryRow(`SELECT pg_try_advisory_xact_lock(hashtext('story_trending:' || $1))`, window).Scan(&locked); err != nil {
+			return err
+		}
+
+		if !locked {
+			return nil
+		}
+
+		if _, err := tx.Exec(`DELETE FROM story_trending WHERE period=$1`, window); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec(insertQuery, window, now, periodSeconds/4, periodSeconds, chronicle.StoryPublishStatus)
+		return err
+	})
+}
+
+//FindTrending find published stories by descending trending score over window, optionally of topic x
+func (s StoryRepository) FindTrending(topic int, window string, option chronicle.PagingOptions) (stories chronicle.Stories, storiesCount int, err error) {
+	defer func() {
+		if err != nil && err != sql.ErrNoRows {
+			err = errors.Wrap(err, function.GetFunctionName(s.FindTrending))
+		}
+	}()
+
+	// building where statement and query arguments
+	queryArgs := []interface{}{window, chronicle.StoryPublishStatus}
+	whereStatement := "WHERE story_trending.period=$1 AND stories.status=$2"
+	if topic != 0 {
+		queryArgs = append(queryArgs, topic)
+		whereStatement += " AND EXISTS (SELECT 1 FROM topic_stories WHERE topic_stories.storyId=stories.id AND topic_stories.topicId=$3)"
+	}
+
+	stories = chronicle.Stories{}
+	selectQuery := fmt.Sprintf(
+		`SELECT
+			stories.id,
+			stories.title,
+			stories.slug,
+			stories.excerpt,
+			stories.author,
+			stories.status,
+			stories.media,
+			stories.likes,
+			stories.shares,
+			stories.views,
+			stories.publishat,
+			stories.unpublishat,
+			stories.publishedat,
+			stories.createdat,
+			stories.updatedat
+		FROM story_trending
+		INNER JOIN stories ON (story_trending.storyId = stories.id)
+		%s
+		ORDER BY story_trending.score DESC, stories.id DESC
+		LIMIT %d
+		OFFSET %d`,
+		whereStatement,
+		option.Limit,
+		option.Offset,
+	)
+
+	err = s.db.Select(&stories, selectQuery, queryArgs...)
+	if err != nil {
+		return chronicle.Stories{}, 0, err
+	}
+
+	if len(stories) == 0 {
+		return chronicle.Stories{}, 0, nil
+	}
+
+	countQuery := fmt.Sprintf(
+		`SELECT count(*)
+		FROM story_trending
+		INNER JOIN stories ON (story_trending.storyId = stories.id)
+		%s`,
+		whereStatement,
+	)
+
+	row := s.db.QueryRow(countQuery, queryArgs...)
+	row.Scan(&storiesCount)
+
+	if err := s.fillStoryAssociations(&stories); err != nil {
+		return chronicle.Stories{}, 0, err
+	}
+
+	return stories, storiesCount, nil
+}
@@ -0,0 +1,101 @@
+package postgre
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	chronicle "github.com/AdhityaRamadhanus/chronicle"
+)
+
+//dbExecutor is satisfied by both *sqlx.DB and *sqlx.Tx so repositories can run inside a unit of work
+type dbExecutor interface {
+	Get(dest interface{}, query string, args ...interface{}) error
+	Select(dest interface{}, query string, args ...interface{}) error
+	NamedQuery(query string, arg interface{}) (*sqlx.Rows, error)
+	NamedExec(query string, arg interface{}) (sql.Result, error)
+	Queryx(query string, args ...interface{}) (*sqlx.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Prepare(query string) (*sql.Stmt, error)
+}
+
+/*
+Transactor is implementation of Transactor interface
+of chronicle domain using postgre
+*/
+type Transactor struct {
+	db *sqlx.DB
+}
+
+//NewTransactor is constructor to create transactor
+func NewTransactor(conn *sqlx.DB) *Transactor {
+	return &Transactor{
+		db: conn,
+	}
+}
+
+//WithTx run fn with repositories bound to one transaction
+func (t Transactor) WithTx(fn func(uow chronicle.UnitOfWork) error) error {
+	return runInTx(t.db, func(tx dbExecutor) error {
+		return fn(unitOfWork{tx: tx})
+	})
+}
+
+type unitOfWork struct {
+	tx dbExecutor
+}
+
+func (u unitOfWork) Stories() chronicle.StoryRepository {
+	return &StoryRepository{db: u.tx}
+}
+
+func (u unitOfWork) StoryRevisions() chronicle.StoryRevisionRepository {
+	return &StoryRevisionRepository{db: u.tx}
+}
+
+func (u unitOfWork) StoryTransitions() chronicle.StoryTransitionRepository {
+	return &StoryTransitionRepository{db: u.tx}
+}
+
+func (u unitOfWork) Topics() chronicle.TopicRepository {
+	return &TopicRepository{db: u.tx}
+}
+
+func (u unitOfWork) Contributors() chronicle.ContributorRepository {
+	return &ContributorRepository{db: u.tx}
+}
+
+// runInTx begin a transaction unless db is already one, in that case the outer unit of work own commit and rollback
+func runInTx(db dbExecutor, fn func(tx dbExecutor) error) (err error) {
+	conn, ok := db.(*sqlx.DB)
+	if !ok {
+		return fn(db)
+	}
+
+	tx, err := conn.Beginx()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			tx.Rollback()
+			panic(recovered)
+		}
+	}()
+
+	if err = fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// isUniqueViolation tell whether err is a violation of the unique constraint
+func isUniqueViolation(err error, constraint string) bool {
+	pqErr, ok := err.(*pq.Error)
+	return ok && pqErr.Code == "23505" && pqErr.Constraint == constraint
+}
@@ -32,3 +32,88 @@ func (c CacheService) Set(key string, value []byte) (err error) {
 func (c CacheService) SetEx(key string, value []byte, expiration time.Duration) (err error) {
 	return c.redisClient.Set(key, value, expiration).Err()
 }
+
+//Del delete keys
+func (c CacheService) Del(keys ...string) (err error) {
+	return c.redisClient.Del(keys...).Err()
+}
+
+//Keys get every key matching pattern, scanned in batches so redis is not blocked like KEYS does
+func (c CacheService) Keys(pattern string) (result []string, err error) {
+	result = []string{}
+	iterator := c.redisClient.Scan(0, pattern, 100).Iterator()
+	for iterator.Next() {
+		result = append(result, iterator.Val())
+	}
+
+	return result, iterator.Err()
+}
+
+//HIncrBy increment field of hash key by increment and return the new value
+func (c CacheService) HIncrBy(key string, field string, increment int64) (result int64, err error) {
+	return c.redisClient.HIncrBy(key, field, increment).Result()
+}
+
+//HGetAll get every field of hash key, empty when key does not exist
+func (c CacheService) HGetAll(key string) (result map[string]string, err error) {
+	return c.redisClient.HGetAll(key).Result()
+}
+
+//HGetAllBatch get every field of each hash key, in order of keys, in one MULTI so no write land between the reads
+func (c CacheService) HGetAllBatch(keys ...string) (results []map[string]string, err error) {
+	results = []map[string]string{}
+	if len(keys) == 0 {
+		return results, nil
+	}
+
+	commands := []*redis.StringStringMapCmd{}
+	_, err = c.redisClient.TxPipelined(func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			commands = append(commands, pipe.HGetAll(key))
+		}
+		return nil
+	})
+	if err != nil {
+		return []map[string]string{}, err
+	}
+
+	for _, command := range commands {
+		results = append(results, command.Val())
+	}
+
+	return results, nil
+}
+
+//SAdd add members to set key
+func (c CacheService) SAdd(key string, members ...string) (err error) {
+	return c.redisClient.SAdd(key, stringsToInterfaces(members)...).Err()
+}
+
+//SRem remove members from set key
+func (c CacheService) SRem(key string, members ...string) (err error) {
+	return c.redisClient.SRem(key, stringsToInterfaces(members)...).Err()
+}
+
+//SMembers get every member of set key
+func (c CacheService) SMembers(key string) (result []string, err error) {
+	return c.redisClient.SMembers(key).Result()
+}
+
+//Rename atomically move key to newKey overwriting it, renamed is false when key does not exist
+func (c CacheService) Rename(key string, newKey string) (renamed bool, err error) {
+	err = c.redisClient.Rename(key, newKey).Err()
+	if err != nil && err.Error() == "ERR no such key" {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+func stringsToInterfaces(values []string) []interface{} {
+	interfaces := make([]interface{}, len(values))
+	for idx, value := range values {
+		interfaces[idx] = value
+	}
+
+	return interfaces
+}
@@ -12,11 +12,27 @@ var (
 	StoryDeletedStatus = "Deleted"
 	//StoryPublishStatus provide a uniform way to use publish status instead of literal string
 	StoryPublishStatus = "Publish"
+	//StoryInReviewStatus provide a uniform way to use in review status instead of literal string
+	StoryInReviewStatus = "InReview"
+	//StoryApprovedStatus provide a uniform way to use approved status instead of literal string
+	StoryApprovedStatus = "Approved"
+	//StoryArchivedStatus provide a uniform way to use archived status instead of literal string
+	StoryArchivedStatus = "Archived"
+)
+
+var (
+	//StoryContentHTML mark story content written as html, it is stored sanitized
+	StoryContentHTML = "html"
+	//StoryContentMarkdown mark story content written as markdown, it is stored along with its rendered html
+	StoryContentMarkdown = "markdown"
+	//StoryContentText mark story content written as plain text
+	StoryContentText = "text"
 )
 
 //Story is domain entity
 type Story struct {
-	ID       int
+	ID int
+	// deprecated free form media, kept for stories imported from WordPress, MediaAssets replace it
 	Media    json.RawMessage
 	Title    string
 	Slug     string
@@ -27,6 +43,22 @@ type Story struct {
 	Author   string
 	Status   string
 	Topics   Topics
+	Bylines  Bylines
+	// free form tags, in order
+	Tags Tags
+	// media assets referenced by the story, in order
+	MediaAssets MediaAssets
+	// collections containing the story, with its neighbours there
+	Collections StoryCollections
+	// format Content is written in, ContentHTML is rendered from it on every write
+	ContentFormat string
+	ContentHTML   string
+
+	// schedule
+	PublishAt   *time.Time
+	UnpublishAt *time.Time
+	// first time the story was published
+	PublishedAt *time.Time
 
 	// stats
 	Likes     int
@@ -43,16 +75,32 @@ type Stories []Story
 type StoryFilterOptions struct {
 	Status string
 	Topic  string
+	// stories of subtopics are included when filtering by topic
+	IncludeDescendants bool
 }
 
 //StoryRepository provide an interface to get story entities
 type StoryRepository interface {
 	Find(id int) (Story, error)
 	FindBySlug(slug string) (Story, error)
+	FindByFormerSlug(slug string) (Story, error)
+	IsSlugTaken(slug string, exceptID int) (bool, error)
 	FindByStatus(status string, option PagingOptions) (stories Stories, storiesCount int, err error)
-	FindByTopicAndStatus(topic int, status string, option PagingOptions) (stories Stories, storiesCount int, err error)
+	FindByTopicAndStatus(topic int, status string, includeDescendants bool, option PagingOptions) (stories Stories, storiesCount int, err error)
+	FindByContributor(contributor int, option PagingOptions) (stories Stories, storiesCount int, err error)
+	FindByTag(tag int, option PagingOptions) (stories Stories, storiesCount int, err error)
 	All(option PagingOptions) (stories Stories, storiesCount int, err error)
 	Insert(story Story) (createdStory Story, err error)
 	Update(story Story) (updatedStory Story, err error)
+	UpdateStatus(id int, fromStatus string, toStatus string) (updatedStory Story, err error)
 	Delete(id int) error
+	FindScheduled(option PagingOptions) (stories Stories, storiesCount int, err error)
+	PublishDue(now time.Time) (publishedStories Stories, err error)
+	UnpublishDue(now time.Time) (unpublishedStories Stories, err error)
+	Search(filter StorySearchOptions, option PagingOptions) (hits StorySearchHits, hitsCount int, facets StorySearchFacets, err error)
+	AddEngagements(engagements StoryEngagements) error
+	RefreshTrending(window string, period time.Duration, now time.Time) error
+	FindTrending(topic int, window string, option PagingOptions) (stories Stories, storiesCount int, err error)
+	FindRelated(id int, weights RelatedStoryWeights, limit int) (stories Stories, err error)
+	FindForFeed(topic int, limit int) (stories Stories, err error)
 }
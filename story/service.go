@@ -3,8 +3,10 @@ package story
 import (
 	"database/sql"
 	"strconv"
+	"time"
 
 	"github.com/AdhityaRamadhanus/chronicle"
+	"github.com/AdhityaRamadhanus/chronicle/content"
 	"github.com/AdhityaRamadhanus/chronicle/function"
 	"github.com/pkg/errors"
 )
@@ -12,6 +14,16 @@ import (
 var (
 	//ErrNoStoryFound sub-domain specific error
 	ErrNoStoryFound = errors.New("Cannot find Story")
+	//ErrNoStoryRevisionFound sub-domain specific error
+	ErrNoStoryRevisionFound = errors.New("Cannot find Story Revision")
+	//ErrUnknownTopic sub-domain specific error
+	ErrUnknownTopic = errors.New("Cannot find one or more Topics of Story")
+	//ErrUnknownContributor sub-domain specific error
+	ErrUnknownContributor = errors.New("Cannot find one or more Contributors of Story")
+	//ErrUnknownMedia sub-domain specific error
+	ErrUnknownMedia = errors.New("Cannot find one or more Media of Story")
+	//ErrUnknownContentFormat sub-domain specific error
+	ErrUnknownContentFormat = errors.New("Unknown content format of Story")
 )
 
 //Service provide an interface to story domain service
@@ -19,39 +31,146 @@ type Service interface {
 	CreateStory(story chronicle.Story) (createdStory chronicle.Story, err error)
 	UpdateStory(story chronicle.Story) (updatedStory chronicle.Story, err error)
 	GetStories(filter chronicle.StoryFilterOptions, option chronicle.PagingOptions) (chronicle.Stories, int, error)
+	SearchStories(filter chronicle.StorySearchOptions, option chronicle.PagingOptions) (chronicle.StorySearchHits, int, chronicle.StorySearchFacets, error)
+	GetTrendingStories(topic int, window string, option chronicle.PagingOptions) (chronicle.Stories, int, error)
+	RefreshTrendingStories(now time.Time) error
+	GetRelatedStories(id int, limit int) (chronicle.Stories, error)
+	GetFeedStories(topic int, limit int) (chronicle.Stories, error)
 	GetStoryByID(id int) (chronicle.Story, error)
 	GetStoryBySlug(slug string) (chronicle.Story, error)
+	GetStoryByFormerSlug(slug string) (chronicle.Story, error)
 	DeleteStoryByID(id int) error
+	GetStoryRevisions(storyID int, option chronicle.PagingOptions) (chronicle.StoryRevisions, int, error)
+	GetStoryRevision(storyID int, revision int) (chronicle.StoryRevision, error)
+	DiffStoryRevisions(storyID int, from int, to int) (chronicle.StoryRevisionDiff, error)
+	RestoreStoryRevision(storyID int, revision int) (chronicle.Story, error)
+	GetScheduledStories(option chronicle.PagingOptions) (chronicle.Stories, int, error)
+	PublishScheduledStories(now time.Time) (chronicle.Stories, error)
+	UnpublishScheduledStories(now time.Time) (chronicle.Stories, error)
+	TransitionStory(storyID int, name string, clientID string) (chronicle.Story, error)
+	GetStoryTransitions(storyID int, option chronicle.PagingOptions) (chronicle.StoryTransitions, int, error)
 }
 
-func NewService(storyRepository chronicle.StoryRepository) Service {
+func NewService(
+	storyRepository chronicle.StoryRepository,
+	storyRevisionRepository chronicle.StoryRevisionRepository,
+	storyTransitionRepository chronicle.StoryTransitionRepository,
+	transactor chronicle.Transactor,
+) Service {
 	return &service{
-		storyRepository: storyRepository,
+		storyRepository:           storyRepository,
+		storyRevisionRepository:   storyRevisionRepository,
+		storyTransitionRepository: storyTransitionRepository,
+		transactor:                transactor,
 	}
 }
 
 type service struct {
-	storyRepository chronicle.StoryRepository
+	storyRepository           chronicle.StoryRepository
+	storyRevisionRepository   chronicle.StoryRevisionRepository
+	storyTransitionRepository chronicle.StoryTransitionRepository
+	transactor                chronicle.Transactor
 }
 
 func (s *service) CreateStory(story chronicle.Story) (createdStory chronicle.Story, err error) {
 	defer func() {
-		if err != nil && err != ErrNoStoryFound {
+		if err != nil && err != ErrNoStoryFound && err != ErrUnknownTopic && err != ErrUnknownContributor && err != ErrUnknownMedia && err != ErrUnknownContentFormat {
 			err = errors.Wrap(err, function.GetFunctionName(s.CreateStory))
 		}
 	}()
 
-	return s.storyRepository.Insert(story)
+	if err := renderStoryContent(&story); err != nil {
+		return chronicle.Story{}, err
+	}
+
+	// stories created published, like imported ones, are dated now unless they carry their date
+	if story.Status == chronicle.StoryPublishStatus && story.PublishedAt == nil {
+		now := time.Now().UTC()
+		story.PublishedAt = &now
+	}
+
+	// story, topics and first revision are committed together, again with the next free slug when another write took it meanwhile
+	err = chronicle.RetrySlugConflict(func() error {
+		return s.transactor.WithTx(func(uow chronicle.UnitOfWork) error {
+			story := story
+			if err := resolveStorySlug(uow, &story); err != nil {
+				return err
+			}
+
+			insertedStory, err := uow.Stories().Insert(story)
+			if err != nil {
+				return err
+			}
+
+			if _, err := uow.StoryRevisions().Insert(insertedStory); err != nil {
+				return err
+			}
+
+			createdStory = insertedStory
+			return nil
+		})
+	})
+	if err != nil {
+		switch errors.Cause(err) {
+		case chronicle.ErrUnknownTopic:
+			return chronicle.Story{}, ErrUnknownTopic
+		case chronicle.ErrUnknownContributor:
+			return chronicle.Story{}, ErrUnknownContributor
+		case chronicle.ErrUnknownMedia:
+			return chronicle.Story{}, ErrUnknownMedia
+		default:
+			return chronicle.Story{}, err
+		}
+	}
+
+	return createdStory, nil
 }
 
 func (s *service) UpdateStory(story chronicle.Story) (updatedStory chronicle.Story, err error) {
 	defer func() {
-		if err != nil && err != ErrNoStoryFound {
+		if err != nil && err != ErrNoStoryFound && err != ErrUnknownTopic && err != ErrUnknownContributor && err != ErrUnknownMedia && err != ErrUnknownContentFormat {
 			err = errors.Wrap(err, function.GetFunctionName(s.UpdateStory))
 		}
 	}()
 
-	return s.storyRepository.Update(story)
+	if err := renderStoryContent(&story); err != nil {
+		return chronicle.Story{}, err
+	}
+
+	err = chronicle.RetrySlugConflict(func() error {
+		return s.transactor.WithTx(func(uow chronicle.UnitOfWork) error {
+			story := story
+			if err := resolveStorySlug(uow, &story); err != nil {
+				return err
+			}
+
+			savedStory, err := uow.Stories().Update(story)
+			if err != nil {
+				return err
+			}
+
+			if _, err := uow.StoryRevisions().Insert(savedStory); err != nil {
+				return err
+			}
+
+			updatedStory = savedStory
+			return nil
+		})
+	})
+	if err != nil {
+		switch errors.Cause(err) {
+		case chronicle.ErrUnknownTopic:
+			return chronicle.Story{}, ErrUnknownTopic
+		case chronicle.ErrUnknownContributor:
+			return chronicle.Story{}, ErrUnknownContributor
+		case chronicle.ErrUnknownMedia:
+			return chronicle.Story{}, ErrUnknownMedia
+		default:
+			return chronicle.Story{}, err
+		}
+	}
+
+	return updatedStory, nil
 }
 
 func (s *service) GetStories(filter chronicle.StoryFilterOptions, option chronicle.PagingOptions) (stories chronicle.Stories, storiesCount int, err error) {
@@ -69,12 +188,77 @@ func (s *service) GetStories(filter chronicle.StoryFilterOptions, option chronic
 	// filter by topic only
 	if filter.Topic != "" {
 		topicId, _ := strconv.Atoi(filter.Topic)
-		return s.storyRepository.FindByTopicAndStatus(topicId, filter.Status, option)
+		return s.storyRepository.FindByTopicAndStatus(topicId, filter.Status, filter.IncludeDescendants, option)
 	}
 
 	return s.storyRepository.All(option)
 }
 
+func (s *service) SearchStories(filter chronicle.StorySearchOptions, option chronicle.PagingOptions) (hits chronicle.StorySearchHits, hitsCount int, facets chronicle.StorySearchFacets, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Wrap(err, function.GetFunctionName(s.SearchStories))
+		}
+	}()
+
+	return s.storyRepository.Search(filter, option)
+}
+
+func (s *service) GetTrendingStories(topic int, window string, option chronicle.PagingOptions) (stories chronicle.Stories, storiesCount int, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Wrap(err, function.GetFunctionName(s.GetTrendingStories))
+		}
+	}()
+
+	if _, ok := chronicle.TrendingWindows[window]; !ok {
+		window = chronicle.DefaultTrendingWindow
+	}
+
+	return s.storyRepository.FindTrending(topic, window, option)
+}
+
+func (s *service) RefreshTrendingStories(now time.Time) (err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Wrap(err, function.GetFunctionName(s.RefreshTrendingStories))
+		}
+	}()
+
+	for window, period := range chronicle.TrendingWindows {
+		if err := s.storyRepository.RefreshTrending(window, period, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetRelatedStories rank published stories against story x, story x itself need not be published
+func (s *service) GetRelatedStories(id int, limit int) (stories chronicle.Stories, err error) {
+	defer func() {
+		if err != nil && err != ErrNoStoryFound {
+			err = errors.Wrap(err, function.GetFunctionName(s.GetRelatedStories))
+		}
+	}()
+
+	if _, err := s.GetStoryByID(id); err != nil {
+		return chronicle.Stories{}, err
+	}
+
+	return s.storyRepository.FindRelated(id, chronicle.DefaultRelatedStoryWeights, limit)
+}
+
+func (s *service) GetFeedStories(topic int, limit int) (stories chronicle.Stories, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Wrap(err, function.GetFunctionName(s.GetFeedStories))
+		}
+	}()
+
+	return s.storyRepository.FindForFeed(topic, limit)
+}
+
 func (s *service) GetStoryByID(id int) (story chronicle.Story, err error) {
 	defer func() {
 		if err != nil && err != ErrNoStoryFound {
@@ -115,6 +299,26 @@ func (s *service) GetStoryBySlug(slug string) (story chronicle.Story, err error)
 	return story, nil
 }
 
+func (s *service) GetStoryByFormerSlug(slug string) (story chronicle.Story, err error) {
+	defer func() {
+		if err != nil && err != ErrNoStoryFound {
+			err = errors.Wrap(err, function.GetFunctionName(s.GetStoryByFormerSlug))
+		}
+	}()
+
+	story, err = s.storyRepository.FindByFormerSlug(slug)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return story, ErrNoStoryFound
+		default:
+			return story, err
+		}
+	}
+
+	return story, nil
+}
+
 func (s *service) DeleteStoryByID(id int) (err error) {
 	defer func() {
 		if err != nil && err != ErrNoStoryFound {
@@ -124,3 +328,271 @@ func (s *service) DeleteStoryByID(id int) (err error) {
 
 	return s.storyRepository.Delete(id)
 }
+
+func (s *service) GetStoryRevisions(storyID int, option chronicle.PagingOptions) (revisions chronicle.StoryRevisions, revisionsCount int, err error) {
+	defer func() {
+		if err != nil && err != ErrNoStoryFound {
+			err = errors.Wrap(err, function.GetFunctionName(s.GetStoryRevisions))
+		}
+	}()
+
+	if _, err := s.GetStoryByID(storyID); err != nil {
+		return chronicle.StoryRevisions{}, 0, err
+	}
+
+	return s.storyRevisionRepository.FindByStory(storyID, option)
+}
+
+func (s *service) GetStoryRevision(storyID int, revision int) (storyRevision chronicle.StoryRevision, err error) {
+	defer func() {
+		if err != nil && err != ErrNoStoryRevisionFound {
+			err = errors.Wrap(err, function.GetFunctionName(s.GetStoryRevision))
+		}
+	}()
+
+	storyRevision, err = s.storyRevisionRepository.Find(storyID, revision)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return storyRevision, ErrNoStoryRevisionFound
+		default:
+			return storyRevision, err
+		}
+	}
+
+	return storyRevision, nil
+}
+
+func (s *service) DiffStoryRevisions(storyID int, from int, to int) (diff chronicle.StoryRevisionDiff, err error) {
+	defer func() {
+		if err != nil && err != ErrNoStoryRevisionFound {
+			err = errors.Wrap(err, function.GetFunctionName(s.DiffStoryRevisions))
+		}
+	}()
+
+	fromRevision, err := s.GetStoryRevision(storyID, from)
+	if err != nil {
+		return chronicle.StoryRevisionDiff{}, err
+	}
+
+	toRevision, err := s.GetStoryRevision(storyID, to)
+	if err != nil {
+		return chronicle.StoryRevisionDiff{}, err
+	}
+
+	return chronicle.StoryRevisionDiff{
+		StoryID: storyID,
+		From:    from,
+		To:      to,
+		Title:   chronicle.DiffWords(fromRevision.Title, toRevision.Title),
+		Excerpt: chronicle.DiffWords(fromRevision.Excerpt, toRevision.Excerpt),
+		Content: chronicle.DiffWords(fromRevision.Content, toRevision.Content),
+	}, nil
+}
+
+func (s *service) RestoreStoryRevision(storyID int, revision int) (restoredStory chronicle.Story, err error) {
+	defer func() {
+		if err != nil && err != ErrNoStoryFound && err != ErrNoStoryRevisionFound {
+			err = errors.Wrap(err, function.GetFunctionName(s.RestoreStoryRevision))
+		}
+	}()
+
+	story, err := s.GetStoryByID(storyID)
+	if err != nil {
+		return chronicle.Story{}, err
+	}
+
+	storyRevision, err := s.GetStoryRevision(storyID, revision)
+	if err != nil {
+		return chronicle.Story{}, err
+	}
+
+	// status is left untouched, restoring only bring back the written content
+	story.Title = storyRevision.Title
+	story.Slug = storyRevision.Slug
+	story.Excerpt = storyRevision.Excerpt
+	story.Content = storyRevision.Content
+	story.ContentFormat = storyRevision.ContentFormat
+	story.Reporter = storyRevision.Reporter
+	story.Editor = storyRevision.Editor
+	story.Author = storyRevision.Author
+	story.Media = storyRevision.Media
+
+	return s.UpdateStory(story)
+}
+
+func (s *service) GetScheduledStories(option chronicle.PagingOptions) (stories chronicle.Stories, storiesCount int, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Wrap(err, function.GetFunctionName(s.GetScheduledStories))
+		}
+	}()
+
+	return s.storyRepository.FindScheduled(option)
+}
+
+func (s *service) PublishScheduledStories(now time.Time) (publishedStories chronicle.Stories, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Wrap(err, function.GetFunctionName(s.PublishScheduledStories))
+		}
+	}()
+
+	// a failing record roll back the whole batch, the next tick pick it up again
+	err = s.transactor.WithTx(func(uow chronicle.UnitOfWork) error {
+		dueStories, err := uow.Stories().PublishDue(now)
+		if err != nil {
+			return err
+		}
+
+		for _, story := range dueStories {
+			if err := s.recordTransition(uow, story, Transitions["publish"], chronicle.StoryApprovedStatus, SchedulerClientID); err != nil {
+				return err
+			}
+		}
+
+		publishedStories = dueStories
+		return nil
+	})
+	if err != nil {
+		return chronicle.Stories{}, err
+	}
+
+	return publishedStories, nil
+}
+
+func (s *service) UnpublishScheduledStories(now time.Time) (unpublishedStories chronicle.Stories, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Wrap(err, function.GetFunctionName(s.UnpublishScheduledStories))
+		}
+	}()
+
+	// a failing record roll back the whole batch, the next tick pick it up again
+	err = s.transactor.WithTx(func(uow chronicle.UnitOfWork) error {
+		dueStories, err := uow.Stories().UnpublishDue(now)
+		if err != nil {
+			return err
+		}
+
+		for _, story := range dueStories {
+			if err := s.recordTransition(uow, story, Transitions["unpublish"], chronicle.StoryPublishStatus, SchedulerClientID); err != nil {
+				return err
+			}
+		}
+
+		unpublishedStories = dueStories
+		return nil
+	})
+	if err != nil {
+		return chronicle.Stories{}, err
+	}
+
+	return unpublishedStories, nil
+}
+
+func (s *service) TransitionStory(storyID int, name string, clientID string) (transitionedStory chronicle.Story, err error) {
+	defer func() {
+		if _, illegal := err.(IllegalTransitionError); illegal {
+			return
+		}
+		if err != nil && err != ErrNoStoryFound && err != ErrUnknownTransition {
+			err = errors.Wrap(err, function.GetFunctionName(s.TransitionStory))
+		}
+	}()
+
+	transition, ok := Transitions[name]
+	if !ok {
+		return chronicle.Story{}, ErrUnknownTransition
+	}
+
+	story, err := s.GetStoryByID(storyID)
+	if err != nil {
+		return chronicle.Story{}, err
+	}
+
+	if !transition.Allowed(story.Status) {
+		return chronicle.Story{}, IllegalTransitionError{Transition: transition.Name, Status: story.Status}
+	}
+
+	// status is compared again on write, a concurrent transition make this one illegal
+	err = s.transactor.WithTx(func(uow chronicle.UnitOfWork) error {
+		updatedStory, err := uow.Stories().UpdateStatus(story.ID, story.Status, transition.To)
+		if err != nil {
+			return err
+		}
+
+		transitionedStory = updatedStory
+		return s.recordTransition(uow, updatedStory, transition, story.Status, clientID)
+	})
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			current, findErr := s.GetStoryByID(storyID)
+			if findErr != nil {
+				return chronicle.Story{}, findErr
+			}
+			return chronicle.Story{}, IllegalTransitionError{Transition: transition.Name, Status: current.Status}
+		default:
+			return chronicle.Story{}, err
+		}
+	}
+
+	return transitionedStory, nil
+}
+
+func (s *service) GetStoryTransitions(storyID int, option chronicle.PagingOptions) (transitions chronicle.StoryTransitions, transitionsCount int, err error) {
+	defer func() {
+		if err != nil && err != ErrNoStoryFound {
+			err = errors.Wrap(err, function.GetFunctionName(s.GetStoryTransitions))
+		}
+	}()
+
+	if _, err := s.GetStoryByID(storyID); err != nil {
+		return chronicle.StoryTransitions{}, 0, err
+	}
+
+	return s.storyTransitionRepository.FindByStory(storyID, option)
+}
+
+// recordTransition keep both the revision and the transition log of a story whose status changed
+func (s *service) recordTransition(uow chronicle.UnitOfWork, story chronicle.Story, transition Transition, fromStatus string, clientID string) error {
+	if _, err := uow.StoryRevisions().Insert(story); err != nil {
+		return err
+	}
+
+	_, err := uow.StoryTransitions().Insert(chronicle.StoryTransition{
+		StoryID:    story.ID,
+		Transition: transition.Name,
+		FromStatus: fromStatus,
+		ToStatus:   transition.To,
+		ClientID:   clientID,
+	})
+	return err
+}
+
+// renderStoryContent render ContentHTML from Content, html content itself is stored sanitized
+func renderStoryContent(story *chronicle.Story) error {
+	if story.ContentFormat == "" {
+		story.ContentFormat = chronicle.StoryContentHTML
+	}
+
+	rendered, err := content.Render(story.ContentFormat, story.Content)
+	if err != nil {
+		return ErrUnknownContentFormat
+	}
+
+	if story.ContentFormat == chronicle.StoryContentHTML {
+		story.Content = rendered
+	}
+	story.ContentHTML = rendered
+	return nil
+}
+
+// resolveStorySlug suffix the slug of story when another story use or used it
+func resolveStorySlug(uow chronicle.UnitOfWork, story *chronicle.Story) (err error) {
+	story.Slug, err = chronicle.UniqueSlug(story.Slug, func(candidate string) (bool, error) {
+		return uow.Stories().IsSlugTaken(candidate, story.ID)
+	})
+	return err
+}
@@ -3,6 +3,7 @@ package story_test
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
@@ -17,9 +18,12 @@ import (
 )
 
 var (
-	storyService    story.Service
-	storyRepository *postgre.StoryRepository
-	topicRepository *postgre.TopicRepository
+	storyService              story.Service
+	storyRepository           *postgre.StoryRepository
+	storyRevisionRepository   *postgre.StoryRevisionRepository
+	storyTransitionRepository *postgre.StoryTransitionRepository
+	transactor                *postgre.Transactor
+	topicRepository           *postgre.TopicRepository
 	// specific test case var
 	storyId int
 	topics  chronicle.Topics
@@ -71,8 +75,11 @@ func TestMain(m *testing.M) {
 	// Repositories
 	storyRepository = postgre.NewStoryRepository(db, "stories")
 	topicRepository = postgre.NewTopicRepository(db, "topics")
+	storyRevisionRepository = postgre.NewStoryRevisionRepository(db, "story_revisions")
+	storyTransitionRepository = postgre.NewStoryTransitionRepository(db, "story_transitions")
+	transactor = postgre.NewTransactor(db)
 
-	storyService = story.NewService(storyRepository)
+	storyService = story.NewService(storyRepository, storyRevisionRepository, storyTransitionRepository, transactor)
 
 	code := m.Run()
 	os.Exit(code)
@@ -183,6 +190,167 @@ func TestGetStoriesIntegration(t *testing.T) {
 	}
 }
 
+func TestSearchStoriesIntegration(t *testing.T) {
+	testCases := []struct {
+		ExpectedHitsCount int
+		SearchOption      chronicle.StorySearchOptions
+	}{
+		{
+			ExpectedHitsCount: 2,
+			SearchOption: chronicle.StorySearchOptions{
+				Query:    "jepang",
+				Language: chronicle.SearchLanguageIndonesian,
+			},
+		},
+		{
+			ExpectedHitsCount: 1,
+			SearchOption: chronicle.StorySearchOptions{
+				Query:    "jepang",
+				Language: chronicle.SearchLanguageIndonesian,
+				Status:   chronicle.StoryDraftStatus,
+			},
+		},
+		{
+			ExpectedHitsCount: 0,
+			SearchOption: chronicle.StorySearchOptions{
+				Query:    "prabowo",
+				Language: chronicle.SearchLanguageEnglish,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		hits, hitsCount, facets, err := storyService.SearchStories(testCase.SearchOption, chronicle.PagingOptions{Limit: 20})
+		if err != nil {
+			t.Error("Failed to search stories", err)
+		}
+		assert.Equal(t, hitsCount, testCase.ExpectedHitsCount)
+		assert.Equal(t, len(hits), testCase.ExpectedHitsCount)
+
+		// status facet ignore the status filter
+		statusFacetCount := 0
+		for _, facet := range facets.Statuses {
+			statusFacetCount += facet.Count
+		}
+		assert.True(t, statusFacetCount >= hitsCount)
+	}
+}
+
+func TestTrendingStoriesIntegration(t *testing.T) {
+	publishedStories, _, err := storyRepository.FindByStatus(chronicle.StoryPublishStatus, chronicle.PagingOptions{
+		Limit:  1,
+		SortBy: "createdAt",
+		Order:  "asc",
+	})
+	if err != nil || len(publishedStories) == 0 {
+		t.Fatal("Failed to get published story", err)
+	}
+	publishedStory := publishedStories[0]
+
+	err = storyRepository.AddEngagements(chronicle.StoryEngagements{
+		chronicle.StoryEngagement{StoryID: publishedStory.ID, Views: 5, Likes: 1},
+	})
+	if err != nil {
+		t.Error("Failed to add engagements", err)
+	}
+
+	// instances refreshing at once never collide
+	refreshErrs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			refreshErrs <- storyService.RefreshTrendingStories(time.Now().UTC())
+		}()
+	}
+	for i := 0; i < 2; i++ {
+		if err := <-refreshErrs; err != nil {
+			t.Error("Failed to refresh trending stories", err)
+		}
+	}
+
+	trendingStories, trendingStoriesCount, err := storyService.GetTrendingStories(0, "24h", chronicle.PagingOptions{Limit: 20})
+	if err != nil {
+		t.Error("Failed to get trending stories", err)
+	}
+	assert.Equal(t, trendingStoriesCount, 1)
+	assert.Equal(t, trendingStories[0].ID, publishedStory.ID)
+	assert.Equal(t, trendingStories[0].Views, 5)
+
+	// filtered by a topic no story has
+	_, trendingStoriesCount, err = storyService.GetTrendingStories(-1, "7d", chronicle.PagingOptions{Limit: 20})
+	if err != nil {
+		t.Error("Failed to get trending stories", err)
+	}
+	assert.Equal(t, trendingStoriesCount, 0)
+}
+
+func TestGetRelatedStoriesIntegration(t *testing.T) {
+	sourceStory, err := storyService.GetStoryByID(storyId)
+	if err != nil {
+		t.Fatal("Failed to get story", err)
+	}
+
+	// one story share a topic, one only share words and one share nothing, the draft source share
+	// every topic and its excerpt with the story published first
+	stories := chronicle.Stories{
+		chronicle.Story{
+			Title:   "Latihan Perdana Skuad Garuda",
+			Slug:    "latihan-perdana-skuad-garuda",
+			Media:   []byte("{}"),
+			Excerpt: "Laga persahabatan berakhir imbang",
+			Status:  chronicle.StoryPublishStatus,
+			Topics:  sourceStory.Topics[:1],
+		},
+		chronicle.Story{
+			Title:   "Jelang Undian Turnamen",
+			Slug:    "jelang-undian-turnamen",
+			Media:   []byte("{}"),
+			Excerpt: "Gagal melaju ke pialla dunia lagi",
+			Status:  chronicle.StoryPublishStatus,
+		},
+		chronicle.Story{
+			Title:   "Harga Cabai Naik",
+			Slug:    "harga-cabai-naik",
+			Media:   []byte("{}"),
+			Excerpt: "Pasar induk sepi pembeli",
+			Status:  chronicle.StoryPublishStatus,
+		},
+	}
+	for _, relatedStory := range stories {
+		if _, err := storyService.CreateStory(relatedStory); err != nil {
+			t.Fatal("Failed to create story", err)
+		}
+	}
+
+	relatedStories, err := storyService.GetRelatedStories(storyId, 5)
+	if err != nil {
+		t.Error("Failed to get related stories", err)
+	}
+
+	slugs := []string{}
+	for _, relatedStory := range relatedStories {
+		assert.Equal(t, chronicle.StoryPublishStatus, relatedStory.Status)
+		slugs = append(slugs, relatedStory.Slug)
+	}
+	assert.Equal(t, []string{
+		"dikalahkan-jepang-timnas-u-19-gagal-ke-piala-dunia",
+		"latihan-perdana-skuad-garuda",
+		"jelang-undian-turnamen",
+		"harga-cabai-naik",
+	}, slugs)
+
+	// limit keep the best ranked
+	relatedStories, err = storyService.GetRelatedStories(storyId, 2)
+	if err != nil {
+		t.Error("Failed to get related stories", err)
+	}
+	if assert.Len(t, relatedStories, 2) {
+		assert.Equal(t, "latihan-perdana-skuad-garuda", relatedStories[1].Slug)
+	}
+
+	_, err = storyService.GetRelatedStories(storyId+1, 5)
+	assert.Equal(t, story.ErrNoStoryFound, err)
+}
+
 func TestGetStoryByIDIntegration(t *testing.T) {
 	testCases := []struct {
 		StoryId           int
@@ -249,7 +417,6 @@ func TestUpdateStoryIntegration(t *testing.T) {
 		t.Error("Failed to get story to update", err)
 	}
 
-	story.Status = chronicle.StoryPublishStatus
 	story.Editor = "Bukan Adhitya Ramadhanus"
 
 	updatedStory, err := storyService.UpdateStory(story)
@@ -258,12 +425,236 @@ func TestUpdateStoryIntegration(t *testing.T) {
 		t.Error("Failed to update story", err)
 	}
 
-	assert.Equal(t, updatedStory.Status, story.Status)
 	assert.Equal(t, updatedStory.Editor, story.Editor)
 }
 
+func TestUpdateStoryTopicsIntegration(t *testing.T) {
+	foundStory, err := storyRepository.Find(storyId)
+	if err != nil {
+		t.Error("Failed to get story to update", err)
+	}
+
+	foundStory.Topics = foundStory.Topics[:1]
+	updatedStory, err := storyService.UpdateStory(foundStory)
+	if err != nil {
+		t.Error("Failed to update story topics", err)
+	}
+	assert.Equal(t, len(updatedStory.Topics), 1)
+
+	foundStory.Topics = append(foundStory.Topics, chronicle.Topic{ID: -1})
+	_, err = storyService.UpdateStory(foundStory)
+	assert.Equal(t, err, story.ErrUnknownTopic, "Should return ErrUnknownTopic")
+
+	// failed update must not touch the topics
+	unchangedStory, err := storyRepository.Find(storyId)
+	if err != nil {
+		t.Error("Failed to get story", err)
+	}
+	assert.Equal(t, len(unchangedStory.Topics), 1)
+}
+
+func TestStoryContentFormatIntegration(t *testing.T) {
+	foundStory, err := storyRepository.Find(storyId)
+	if err != nil {
+		t.Error("Failed to get story to update", err)
+	}
+
+	foundStory.ContentFormat = chronicle.StoryContentMarkdown
+	foundStory.Content = "Asa **Timnas** pupus <script>alert(1)</script>"
+	updatedStory, err := storyService.UpdateStory(foundStory)
+	if err != nil {
+		t.Error("Failed to update story content", err)
+	}
+	assert.Equal(t, updatedStory.Content, foundStory.Content)
+	assert.Equal(t, updatedStory.ContentHTML, "<p>Asa <strong>Timnas</strong> pupus &lt;script&gt;alert(1)&lt;/script&gt;</p>")
+
+	foundStory.ContentFormat = chronicle.StoryContentHTML
+	foundStory.Content = `<p onclick="alert(1)">Asa Timnas pupus<script>alert(1)</script></p>`
+	updatedStory, err = storyService.UpdateStory(foundStory)
+	if err != nil {
+		t.Error("Failed to update story content", err)
+	}
+	assert.Equal(t, updatedStory.Content, "<p>Asa Timnas pupus</p>")
+	assert.Equal(t, updatedStory.ContentHTML, "<p>Asa Timnas pupus</p>")
+
+	foundStory.ContentFormat = "rtf"
+	_, err = storyService.UpdateStory(foundStory)
+	assert.Equal(t, err, story.ErrUnknownContentFormat, "Should return ErrUnknownContentFormat")
+}
+
+func TestCreateStoryRollbackIntegration(t *testing.T) {
+	_, err := storyService.CreateStory(chronicle.Story{
+		Title:    "Rollback aja",
+		Slug:     "rollback-aja",
+		Content:  "Story with unknown topic",
+		Reporter: "Adhitya Ramadhanus",
+		Editor:   "Adhitya Ramadhanus",
+		Author:   "Adhitya Ramadhanus",
+		Media:    []byte("{}"),
+		Status:   chronicle.StoryDraftStatus,
+		Excerpt:  "Story with unknown topic",
+		Topics:   chronicle.Topics{chronicle.Topic{ID: -1}},
+	})
+	assert.Equal(t, err, story.ErrUnknownTopic, "Should return ErrUnknownTopic")
+
+	// story row is rolled back along with its topics and revision
+	_, err = storyService.GetStoryBySlug("rollback-aja")
+	assert.Equal(t, err, story.ErrNoStoryFound, "Should not leave story behind")
+}
+
+func TestTransitionStoryIntegration(t *testing.T) {
+	testCases := []struct {
+		Transition     string
+		ExpectedStatus string
+		ExpectedError  bool
+	}{
+		{
+			Transition:     "publish",
+			ExpectedStatus: chronicle.StoryDraftStatus,
+			ExpectedError:  true,
+		},
+		{
+			Transition:     "submit",
+			ExpectedStatus: chronicle.StoryInReviewStatus,
+		},
+		{
+			Transition:     "approve",
+			ExpectedStatus: chronicle.StoryApprovedStatus,
+		},
+		{
+			Transition:     "archive",
+			ExpectedStatus: chronicle.StoryApprovedStatus,
+			ExpectedError:  true,
+		},
+		{
+			Transition:     "publish",
+			ExpectedStatus: chronicle.StoryPublishStatus,
+		},
+	}
+
+	for _, test := range testCases {
+		transitionedStory, err := storyService.TransitionStory(storyId, test.Transition, "chronicle-test")
+		if test.ExpectedError {
+			assert.IsType(t, story.IllegalTransitionError{}, err, "Should return IllegalTransitionError")
+			continue
+		}
+
+		if err != nil {
+			t.Error("Failed to transition story", err)
+		}
+		assert.Equal(t, transitionedStory.Status, test.ExpectedStatus)
+		// only publishing date the story
+		assert.Equal(t, transitionedStory.PublishedAt != nil, test.ExpectedStatus == chronicle.StoryPublishStatus)
+	}
+
+	_, err := storyService.TransitionStory(storyId, "teleport", "chronicle-test")
+	assert.EqualError(t, err, story.ErrUnknownTransition.Error(), "Should return ErrUnknownTransition")
+
+	transitions, transitionsCount, err := storyService.GetStoryTransitions(storyId, chronicle.PagingOptions{
+		Limit: 20,
+		Order: "asc",
+	})
+	if err != nil {
+		t.Error("Failed to get story transitions", err)
+	}
+	assert.Equal(t, transitionsCount, 3)
+	assert.Equal(t, transitions[0].ClientID, "chronicle-test")
+}
+
+func TestStoryRevisionsIntegration(t *testing.T) {
+	revisions, revisionsCount, err := storyService.GetStoryRevisions(storyId, chronicle.PagingOptions{
+		Limit:  20,
+		Offset: 0,
+		Order:  "asc",
+	})
+	if err != nil {
+		t.Error("Failed to get story revisions", err)
+	}
+
+	// one revision from create, one for every update and one for every transition
+	assert.Equal(t, revisionsCount, 6)
+	assert.Equal(t, len(revisions), 6)
+
+	diff, err := storyService.DiffStoryRevisions(storyId, 1, 2)
+	if err != nil {
+		t.Error("Failed to diff story revisions", err)
+	}
+	assert.Equal(t, diff.Content, chronicle.WordDiffs{{Operation: chronicle.DiffEqual, Text: "Bertiga melawan jepang pada perempat final"}})
+
+	restoredStory, err := storyService.RestoreStoryRevision(storyId, 1)
+	if err != nil {
+		t.Error("Failed to restore story revision", err)
+	}
+	assert.Equal(t, restoredStory.Editor, "Adhitya Ramadhanus")
+
+	_, err = storyService.GetStoryRevision(storyId, 10)
+	assert.EqualError(t, err, story.ErrNoStoryRevisionFound.Error(), "Should return ErrNoStoryRevisionFound")
+}
+
 func TestDeleteStoryIntegration(t *testing.T) {
 	if err := storyService.DeleteStoryByID(storyId); err != nil {
 		t.Error("Failed to delete story", err)
 	}
 }
+
+func TestScheduledStoriesIntegration(t *testing.T) {
+	publishAt := time.Now().UTC().Add(-time.Minute)
+	unpublishAt := time.Now().UTC().Add(time.Hour)
+	scheduledStory, err := storyService.CreateStory(chronicle.Story{
+		Title:       "Rilis Pers Terjadwal",
+		Slug:        "rilis-pers-terjadwal",
+		Content:     "Embargo sampai pukul tujuh pagi",
+		Reporter:    "Adhitya Ramadhanus",
+		Editor:      "Adhitya Ramadhanus",
+		Author:      "Adhitya Ramadhanus",
+		Media:       []byte("{}"),
+		Excerpt:     "Embargo",
+		Status:      chronicle.StoryDraftStatus,
+		PublishAt:   &publishAt,
+		UnpublishAt: &unpublishAt,
+	})
+	if err != nil {
+		t.Error("Failed to create scheduled story", err)
+	}
+
+	// only approved stories are published by the scheduler, so drafts are not listed as scheduled
+	_, scheduledCount, err := storyService.GetScheduledStories(chronicle.PagingOptions{Limit: 20})
+	if err != nil {
+		t.Error("Failed to get scheduled stories", err)
+	}
+	assert.Equal(t, scheduledCount, 0)
+
+	for _, transition := range []string{"submit", "approve"} {
+		if _, err := storyService.TransitionStory(scheduledStory.ID, transition, "chronicle-test"); err != nil {
+			t.Error("Failed to transition scheduled story", err)
+		}
+	}
+
+	_, scheduledCount, err = storyService.GetScheduledStories(chronicle.PagingOptions{Limit: 20})
+	if err != nil {
+		t.Error("Failed to get scheduled stories", err)
+	}
+	assert.Equal(t, scheduledCount, 1)
+
+	publishedStories, err := storyService.PublishScheduledStories(time.Now().UTC())
+	if err != nil {
+		t.Error("Failed to publish scheduled stories", err)
+	}
+	assert.Equal(t, len(publishedStories), 1)
+	assert.Equal(t, publishedStories[0].ID, scheduledStory.ID)
+	assert.Equal(t, publishedStories[0].Status, chronicle.StoryPublishStatus)
+
+	// second run must not publish the same story again
+	publishedStories, err = storyService.PublishScheduledStories(time.Now().UTC())
+	if err != nil {
+		t.Error("Failed to publish scheduled stories", err)
+	}
+	assert.Equal(t, len(publishedStories), 0)
+
+	unpublishedStories, err := storyService.UnpublishScheduledStories(unpublishAt.Add(time.Second))
+	if err != nil {
+		t.Error("Failed to unpublish scheduled stories", err)
+	}
+	assert.Equal(t, len(unpublishedStories), 1)
+	assert.Equal(t, unpublishedStories[0].Status, chronicle.StoryDraftStatus)
+}
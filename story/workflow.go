@@ -0,0 +1,88 @@
+package story
+
+import (
+	"fmt"
+
+	"github.com/AdhityaRamadhanus/chronicle"
+	"github.com/pkg/errors"
+)
+
+var (
+	//ErrUnknownTransition sub-domain specific error
+	ErrUnknownTransition = errors.New("Unknown Story Transition")
+)
+
+//SchedulerClientID is the client recorded for transitions applied by the publishing scheduler
+const SchedulerClientID = "chronicle-scheduler"
+
+//Transition is a named move of a story from one of its source statuses to a destination status
+type Transition struct {
+	Name string
+	From []string
+	To   string
+}
+
+//Transitions is the editorial workflow, any status change outside of it is illegal
+var Transitions = map[string]Transition{
+	"submit": {
+		Name: "submit",
+		From: []string{chronicle.StoryDraftStatus},
+		To:   chronicle.StoryInReviewStatus,
+	},
+	"reject": {
+		Name: "reject",
+		From: []string{chronicle.StoryInReviewStatus},
+		To:   chronicle.StoryDraftStatus,
+	},
+	"approve": {
+		Name: "approve",
+		From: []string{chronicle.StoryInReviewStatus},
+		To:   chronicle.StoryApprovedStatus,
+	},
+	"publish": {
+		Name: "publish",
+		From: []string{chronicle.StoryApprovedStatus},
+		To:   chronicle.StoryPublishStatus,
+	},
+	"unpublish": {
+		Name: "unpublish",
+		From: []string{chronicle.StoryPublishStatus},
+		To:   chronicle.StoryDraftStatus,
+	},
+	"archive": {
+		Name: "archive",
+		From: []string{chronicle.StoryPublishStatus},
+		To:   chronicle.StoryArchivedStatus,
+	},
+	"reopen": {
+		Name: "reopen",
+		From: []string{chronicle.StoryArchivedStatus, chronicle.StoryDeletedStatus},
+		To:   chronicle.StoryDraftStatus,
+	},
+	"delete": {
+		Name: "delete",
+		From: []string{chronicle.StoryDraftStatus, chronicle.StoryArchivedStatus},
+		To:   chronicle.StoryDeletedStatus,
+	},
+}
+
+//IllegalTransitionError returned when a transition cannot be applied from the current status of a story
+type IllegalTransitionError struct {
+	Transition string
+	Status     string
+}
+
+func (e IllegalTransitionError) Error() string {
+	return fmt.Sprintf("Cannot %s Story with status %s", e.Transition, e.Status)
+}
+
+//Allowed check whether the transition can be applied to a story with status x
+func (t Transition) Allowed(status string) bool {
+	for _, from := range t.From {
+		if from == status {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,44 @@
+package chronicle
+
+import (
+	"time"
+)
+
+var (
+	//TagCountWindows are the periods tag usage is counted over, keyed by the window accepted by the api
+	TagCountWindows = map[string]time.Duration{
+		"24h": 24 * time.Hour,
+		"7d":  7 * 24 * time.Hour,
+		"30d": 30 * 24 * time.Hour,
+	}
+	//DefaultTagCountWindow is used when no window is requested
+	DefaultTagCountWindow = "7d"
+)
+
+//Tag is domain entity, unlike topics tags are created freely by tagging a story
+type Tag struct {
+	ID        int
+	Name      string
+	Slug      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+//Tags short way to define array of tag
+type Tags []Tag
+
+//TagCount is a tag with the number of stories tagged with it
+type TagCount struct {
+	Tag
+	Count int
+}
+
+//TagCounts short way to define array of tag count
+type TagCounts []TagCount
+
+//TagRepository provide an interface to get tag entities
+type TagRepository interface {
+	FindBySlug(slug string) (Tag, error)
+	FindByPrefix(prefix string, limit int) (Tags, error)
+	CountSince(since time.Time, limit int) (TagCounts, error)
+}
@@ -0,0 +1,102 @@
+package tag
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/AdhityaRamadhanus/chronicle"
+	"github.com/AdhityaRamadhanus/chronicle/function"
+	"github.com/pkg/errors"
+)
+
+var (
+	//ErrNoTagFound sub-domain specific error
+	ErrNoTagFound = errors.New("Cannot find Tag")
+)
+
+//Service provide an interface to tag domain service
+type Service interface {
+	GetTagBySlug(slug string) (chronicle.Tag, error)
+	AutocompleteTags(prefix string, limit int) (chronicle.Tags, error)
+	GetTagStories(slug string, option chronicle.PagingOptions) (chronicle.Stories, int, error)
+	GetTagCounts(window string, limit int) (chronicle.TagCounts, error)
+}
+
+func NewService(tagRepository chronicle.TagRepository, storyRepository chronicle.StoryRepository) Service {
+	return &service{
+		tagRepository:   tagRepository,
+		storyRepository: storyRepository,
+	}
+}
+
+type service struct {
+	tagRepository   chronicle.TagRepository
+	storyRepository chronicle.StoryRepository
+}
+
+func (s *service) GetTagBySlug(slug string) (tag chronicle.Tag, err error) {
+	defer func() {
+		if err != nil && err != ErrNoTagFound {
+			err = errors.Wrap(err, function.GetFunctionName(s.GetTagBySlug))
+		}
+	}()
+
+	tag, err = s.tagRepository.FindBySlug(slug)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return tag, ErrNoTagFound
+		default:
+			return tag, err
+		}
+	}
+
+	return tag, nil
+}
+
+// AutocompleteTags match prefix the way tags are slugged, so "jakarta ut" suggest "jakarta-utara"
+func (s *service) AutocompleteTags(prefix string, limit int) (tags chronicle.Tags, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Wrap(err, function.GetFunctionName(s.AutocompleteTags))
+		}
+	}()
+
+	slugPrefix := chronicle.Slugify(prefix)
+	if slugPrefix == "" {
+		return chronicle.Tags{}, nil
+	}
+
+	return s.tagRepository.FindByPrefix(slugPrefix, limit)
+}
+
+func (s *service) GetTagStories(slug string, option chronicle.PagingOptions) (stories chronicle.Stories, storiesCount int, err error) {
+	defer func() {
+		if err != nil && err != ErrNoTagFound {
+			err = errors.Wrap(err, function.GetFunctionName(s.GetTagStories))
+		}
+	}()
+
+	tag, err := s.GetTagBySlug(slug)
+	if err != nil {
+		return chronicle.Stories{}, 0, err
+	}
+
+	return s.storyRepository.FindByTag(tag.ID, option)
+}
+
+// GetTagCounts rank tags by stories tagged within window before now, unknown window fall back to the default
+func (s *service) GetTagCounts(window string, limit int) (tagCounts chronicle.TagCounts, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Wrap(err, function.GetFunctionName(s.GetTagCounts))
+		}
+	}()
+
+	period, ok := chronicle.TagCountWindows[window]
+	if !ok {
+		period = chronicle.TagCountWindows[chronicle.DefaultTagCountWindow]
+	}
+
+	return s.tagRepository.CountSince(time.Now().UTC().Add(-period), limit)
+}
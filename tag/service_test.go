@@ -0,0 +1,207 @@
+package tag_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	"github.com/AdhityaRamadhanus/chronicle"
+	"github.com/AdhityaRamadhanus/chronicle/config"
+	"github.com/AdhityaRamadhanus/chronicle/storage/postgre"
+	"github.com/AdhityaRamadhanus/chronicle/tag"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	tagService      tag.Service
+	tagRepository   *postgre.TagRepository
+	storyRepository *postgre.StoryRepository
+
+	// specific test case var
+	storyId int
+)
+
+// make test kind of idempotent
+func setupDatabase(db *sqlx.DB) {
+	_, err := db.Query("DELETE FROM story_tags")
+	if err != nil {
+		log.Fatal("Failed to setup database ", errors.Wrap(err, "Failed in delete from story_tags"))
+	}
+
+	_, err = db.Query("DELETE FROM tags")
+	if err != nil {
+		log.Fatal("Failed to setup database ", errors.Wrap(err, "Failed in delete from tags"))
+	}
+}
+
+func TestMain(m *testing.M) {
+	log.SetLevel(log.WarnLevel)
+	if err := config.Init("testing", []string{"../config/testing"}); err != nil {
+		log.Fatal(err)
+	}
+
+	pgConnString := postgre.GetConnString()
+
+	db, err := sqlx.Open("postgres", pgConnString)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	setupDatabase(db)
+
+	// Repositories
+	tagRepository = postgre.NewTagRepository(db, "tags")
+	storyRepository = postgre.NewStoryRepository(db, "stories")
+	tagService = tag.NewService(tagRepository, storyRepository)
+
+	code := m.Run()
+	os.Exit(code)
+}
+
+func TestTagStoryIntegration(t *testing.T) {
+	story := chronicle.Story{
+		Title:  "Banjir Jakarta Utara",
+		Slug:   "banjir-jakarta-utara",
+		Media:  []byte("{}"),
+		Status: chronicle.StoryPublishStatus,
+		Tags: chronicle.Tags{
+			{Name: "Jakarta Utara", Slug: "jakarta-utara"},
+			{Name: "Banjir", Slug: "banjir"},
+			{Name: "jakarta utara", Slug: "jakarta-utara"},
+		},
+	}
+
+	createdStory, err := storyRepository.Insert(story)
+	if err != nil {
+		t.Fatal("Failed to create story", err)
+	}
+	storyId = createdStory.ID
+
+	// a slug is tagged once, in the order given
+	if assert.Len(t, createdStory.Tags, 2) {
+		assert.Equal(t, "jakarta-utara", createdStory.Tags[0].Slug)
+		assert.Equal(t, "Jakarta Utara", createdStory.Tags[0].Name)
+		assert.Equal(t, "banjir", createdStory.Tags[1].Slug)
+	}
+
+	// existing tags are reused instead of created again
+	createdStory.Tags = chronicle.Tags{
+		{Name: "Banjir", Slug: "banjir"},
+		{Name: "Jakarta Barat", Slug: "jakarta-barat"},
+	}
+	updatedStory, err := storyRepository.Update(createdStory)
+	if err != nil {
+		t.Fatal("Failed to update story", err)
+	}
+
+	if assert.Len(t, updatedStory.Tags, 2) {
+		assert.Equal(t, createdStory.Tags[0].Slug, updatedStory.Tags[0].Slug)
+		assert.Equal(t, "jakarta-barat", updatedStory.Tags[1].Slug)
+	}
+}
+
+func TestAutocompleteTagsIntegration(t *testing.T) {
+	testCases := []struct {
+		Prefix        string
+		ExpectedSlugs []string
+	}{
+		{
+			Prefix:        "Jakarta",
+			ExpectedSlugs: []string{"jakarta-barat", "jakarta-utara"},
+		},
+		{
+			Prefix:        "jakarta ut",
+			ExpectedSlugs: []string{"jakarta-utara"},
+		},
+		{
+			Prefix:        "...",
+			ExpectedSlugs: []string{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		tags, err := tagService.AutocompleteTags(testCase.Prefix, 10)
+		if err != nil {
+			t.Error("Failed to autocomplete tags", err)
+		}
+
+		slugs := []string{}
+		for _, tag := range tags {
+			slugs = append(slugs, tag.Slug)
+		}
+		assert.Equal(t, testCase.ExpectedSlugs, slugs)
+	}
+}
+
+func TestGetTagStoriesIntegration(t *testing.T) {
+	// drafts are neither listed nor counted
+	draft := chronicle.Story{
+		Title:  "Banjir Jakarta Timur",
+		Slug:   "banjir-jakarta-timur",
+		Media:  []byte("{}"),
+		Status: chronicle.StoryDraftStatus,
+		Tags:   chronicle.Tags{{Name: "Banjir", Slug: "banjir"}},
+	}
+	if _, err := storyRepository.Insert(draft); err != nil {
+		t.Fatal("Failed to create story", err)
+	}
+
+	testCases := []struct {
+		Slug                 string
+		ExpectedStoriesCount int
+		ExpectedErrorType    error
+	}{
+		{
+			Slug:                 "banjir",
+			ExpectedStoriesCount: 1,
+		},
+		{
+			// untagged stories are no longer listed
+			Slug:                 "jakarta-utara",
+			ExpectedStoriesCount: 0,
+		},
+		{
+			Slug:              "random-slug",
+			ExpectedErrorType: tag.ErrNoTagFound,
+		},
+	}
+
+	for _, testCase := range testCases {
+		stories, storiesCount, err := tagService.GetTagStories(testCase.Slug, chronicle.PagingOptions{
+			SortBy: "createdAt",
+			Order:  "desc",
+			Limit:  10,
+			Offset: 0,
+		})
+
+		if testCase.ExpectedErrorType != nil {
+			assert.Equal(t, testCase.ExpectedErrorType, err)
+			continue
+		}
+
+		if err != nil {
+			t.Error("Failed to get tag stories", err)
+		}
+		assert.Equal(t, testCase.ExpectedStoriesCount, storiesCount)
+		if testCase.ExpectedStoriesCount > 0 && assert.Len(t, stories, testCase.ExpectedStoriesCount) {
+			assert.Equal(t, storyId, stories[0].ID)
+		}
+	}
+}
+
+func TestGetTagCountsIntegration(t *testing.T) {
+	tagCounts, err := tagService.GetTagCounts("24h", 10)
+	if err != nil {
+		t.Error("Failed to get tag counts", err)
+	}
+
+	counts := map[string]int{}
+	for _, tagCount := range tagCounts {
+		counts[tagCount.Slug] = tagCount.Count
+	}
+	assert.Equal(t, map[string]int{"banjir": 1, "jakarta-barat": 1}, counts)
+}
@@ -1,25 +1,52 @@
 package chronicle
 
-import "time"
+import (
+	"errors"
+	"time"
+)
 
-//Topic is domain entity
+var (
+	//ErrUnknownTopic returned by repositories when an entity reference a topic that does not exist
+	ErrUnknownTopic = errors.New("Unknown Topic")
+	//ErrTopicCycle returned by repositories when a topic would become its own ancestor
+	ErrTopicCycle = errors.New("Topic Cycle")
+)
+
+//Topic is domain entity, topics form a tree through their parent
 type Topic struct {
-	ID        int
-	Name      string
-	Slug      string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID       int
+	ParentID *int
+	Name     string
+	Slug     string
+	// ancestors of the topic from the root down to its parent
+	Breadcrumbs TopicBreadcrumbs
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
 }
 
 //Topics short way to define array of story
 type Topics []Topic
 
+//TopicBreadcrumb is an ancestor of a topic
+type TopicBreadcrumb struct {
+	ID   int
+	Name string
+	Slug string
+}
+
+//TopicBreadcrumbs short way to define array of topic breadcrumb
+type TopicBreadcrumbs []TopicBreadcrumb
+
 //TopicRepository provide an interface to get topic entities
 type TopicRepository interface {
 	Find(id int) (Topic, error)
 	FindBySlug(slug string) (Topic, error)
+	FindByFormerSlug(slug string) (Topic, error)
+	IsSlugTaken(slug string, exceptID int) (bool, error)
 	All(option PagingOptions) (topics Topics, topicsCount int, err error)
+	FindChildren(id int, option PagingOptions) (topics Topics, topicsCount int, err error)
 	Insert(topic Topic) (createdTopic Topic, err error)
 	Update(topic Topic) (updatedTopic Topic, err error)
 	Delete(id int) error
+	Merge(id int, targetID int) error
 }
@@ -11,6 +11,12 @@ import (
 var (
 	//ErrNoTopicFound sub-domain specific error
 	ErrNoTopicFound = errors.New("Cannot find Topic")
+	//ErrUnknownParentTopic sub-domain specific error
+	ErrUnknownParentTopic = errors.New("Cannot find parent of Topic")
+	//ErrTopicCycle sub-domain specific error
+	ErrTopicCycle = errors.New("Topic cannot be placed under itself or its descendants")
+	//ErrInvalidMergeTarget sub-domain specific error
+	ErrInvalidMergeTarget = errors.New("Topic cannot be merged into itself or its descendants")
 )
 
 //Service provide an interface to topic domain service
@@ -20,7 +26,10 @@ type Service interface {
 	GetTopics(option chronicle.PagingOptions) (chronicle.Topics, int, error)
 	GetTopicByID(id int) (chronicle.Topic, error)
 	GetTopicBySlug(slug string) (chronicle.Topic, error)
+	GetTopicByFormerSlug(slug string) (chronicle.Topic, error)
+	GetTopicChildren(id int, option chronicle.PagingOptions) (chronicle.Topics, int, error)
 	DeleteTopicByID(id int) error
+	MergeTopic(id int, targetID int) (mergedTopic chronicle.Topic, err error)
 }
 
 func NewService(topicRepository chronicle.TopicRepository) Service {
@@ -35,22 +44,65 @@ type service struct {
 
 func (s *service) CreateTopic(topic chronicle.Topic) (createdTopic chronicle.Topic, err error) {
 	defer func() {
-		if err != nil && err != ErrNoTopicFound {
+		if err != nil && err != ErrNoTopicFound && err != ErrUnknownParentTopic && err != ErrTopicCycle {
 			err = errors.Wrap(err, function.GetFunctionName(s.CreateTopic))
 		}
 	}()
 
-	return s.topicRepository.Insert(topic)
+	if err := s.validateTopicParent(topic); err != nil {
+		return chronicle.Topic{}, err
+	}
+
+	// a slug taken by another write after it was checked is resolved again
+	err = chronicle.RetrySlugConflict(func() error {
+		topic := topic
+		if err := s.resolveTopicSlug(&topic); err != nil {
+			return err
+		}
+
+		createdTopic, err = s.topicRepository.Insert(topic)
+		return err
+	})
+	if err != nil {
+		return chronicle.Topic{}, err
+	}
+
+	return createdTopic, nil
 }
 
 func (s *service) UpdateTopic(topic chronicle.Topic) (updatedTopic chronicle.Topic, err error) {
 	defer func() {
-		if err != nil && err != ErrNoTopicFound {
+		if err != nil && err != ErrNoTopicFound && err != ErrUnknownParentTopic && err != ErrTopicCycle {
 			err = errors.Wrap(err, function.GetFunctionName(s.UpdateTopic))
 		}
 	}()
 
-	return s.topicRepository.Update(topic)
+	if err := s.validateTopicParent(topic); err != nil {
+		return chronicle.Topic{}, err
+	}
+
+	err = chronicle.RetrySlugConflict(func() error {
+		topic := topic
+		if err := s.resolveTopicSlug(&topic); err != nil {
+			return err
+		}
+
+		updatedTopic, err = s.topicRepository.Update(topic)
+		return err
+	})
+	if err != nil {
+		switch errors.Cause(err) {
+		case chronicle.ErrTopicCycle:
+			return chronicle.Topic{}, ErrTopicCycle
+		case chronicle.ErrUnknownTopic:
+			// parent deleted meanwhile
+			return chronicle.Topic{}, ErrUnknownParentTopic
+		default:
+			return chronicle.Topic{}, err
+		}
+	}
+
+	return updatedTopic, nil
 }
 
 func (s *service) GetTopics(option chronicle.PagingOptions) (topics chronicle.Topics, topicsCount int, err error) {
@@ -103,6 +155,40 @@ func (s *service) GetTopicBySlug(slug string) (topic chronicle.Topic, err error)
 	return topic, nil
 }
 
+func (s *service) GetTopicByFormerSlug(slug string) (topic chronicle.Topic, err error) {
+	defer func() {
+		if err != nil && err != ErrNoTopicFound {
+			err = errors.Wrap(err, function.GetFunctionName(s.GetTopicByFormerSlug))
+		}
+	}()
+
+	topic, err = s.topicRepository.FindByFormerSlug(slug)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return topic, ErrNoTopicFound
+		default:
+			return topic, err
+		}
+	}
+
+	return topic, nil
+}
+
+func (s *service) GetTopicChildren(id int, option chronicle.PagingOptions) (topics chronicle.Topics, topicsCount int, err error) {
+	defer func() {
+		if err != nil && err != ErrNoTopicFound {
+			err = errors.Wrap(err, function.GetFunctionName(s.GetTopicChildren))
+		}
+	}()
+
+	if _, err := s.GetTopicByID(id); err != nil {
+		return chronicle.Topics{}, 0, err
+	}
+
+	return s.topicRepository.FindChildren(id, option)
+}
+
 func (s *service) DeleteTopicByID(id int) (err error) {
 	defer func() {
 		if err != nil && err != ErrNoTopicFound {
@@ -112,3 +198,67 @@ func (s *service) DeleteTopicByID(id int) (err error) {
 
 	return s.topicRepository.Delete(id)
 }
+
+func (s *service) MergeTopic(id int, targetID int) (mergedTopic chronicle.Topic, err error) {
+	defer func() {
+		if err != nil && err != ErrNoTopicFound && err != ErrInvalidMergeTarget {
+			err = errors.Wrap(err, function.GetFunctionName(s.MergeTopic))
+		}
+	}()
+
+	if id == targetID {
+		return chronicle.Topic{}, ErrInvalidMergeTarget
+	}
+
+	if _, err := s.GetTopicByID(id); err != nil {
+		return chronicle.Topic{}, err
+	}
+
+	if _, err := s.GetTopicByID(targetID); err != nil {
+		return chronicle.Topic{}, err
+	}
+
+	// children of the merged topic move under target, target cannot be one of them
+	if err := s.topicRepository.Merge(id, targetID); err != nil {
+		switch errors.Cause(err) {
+		case chronicle.ErrTopicCycle:
+			return chronicle.Topic{}, ErrInvalidMergeTarget
+		case chronicle.ErrUnknownTopic:
+			return chronicle.Topic{}, ErrNoTopicFound
+		default:
+			return chronicle.Topic{}, err
+		}
+	}
+
+	return s.GetTopicByID(targetID)
+}
+
+// resolveTopicSlug suffix the slug of topic when another topic use or used it
+func (s *service) resolveTopicSlug(topic *chronicle.Topic) (err error) {
+	topic.Slug, err = chronicle.UniqueSlug(topic.Slug, func(candidate string) (bool, error) {
+		return s.topicRepository.IsSlugTaken(candidate, topic.ID)
+	})
+	return err
+}
+
+// validateTopicParent reject a parent that does not exist, cycles are checked by the repository while the tree is locked
+func (s *service) validateTopicParent(topic chronicle.Topic) error {
+	if topic.ParentID == nil {
+		return nil
+	}
+
+	if *topic.ParentID == topic.ID {
+		return ErrTopicCycle
+	}
+
+	if _, err := s.topicRepository.Find(*topic.ParentID); err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return ErrUnknownParentTopic
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
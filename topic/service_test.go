@@ -1,6 +1,7 @@
 package topic_test
 
 import (
+	"fmt"
 	"os"
 	"testing"
 
@@ -19,6 +20,8 @@ import (
 var (
 	topicService    topic.Service
 	topicRepository *postgre.TopicRepository
+	storyRepository *postgre.StoryRepository
+	pinRepository   *postgre.StoryPinRepository
 
 	// specific test case var
 	topicId int
@@ -59,6 +62,8 @@ func TestMain(m *testing.M) {
 
 	// Repositories
 	topicRepository = postgre.NewTopicRepository(db, "topics")
+	storyRepository = postgre.NewStoryRepository(db, "stories")
+	pinRepository = postgre.NewStoryPinRepository(db, "story_pins")
 	topicService = topic.NewService(topicRepository)
 
 	code := m.Run()
@@ -240,6 +245,233 @@ func TestUpdateTopicIntegration(t *testing.T) {
 	assert.Equal(t, updatedTopic.Slug, newTopic.Slug)
 }
 
+func TestTopicSlugHistoryIntegration(t *testing.T) {
+	movedTopic, err := topicService.GetTopicByFormerSlug("sepakbola-dalam-negeri")
+	if err != nil {
+		t.Error("Failed to get topic by former slug", err)
+	}
+	assert.Equal(t, movedTopic.ID, topicId)
+
+	_, err = topicService.GetTopicByFormerSlug("pemilu-2019")
+	assert.EqualError(t, err, topic.ErrNoTopicFound.Error(), "Should return ErrTopicNotFound")
+
+	// current and former slugs are both taken
+	testCases := []struct {
+		Topic        chronicle.Topic
+		ExpectedSlug string
+	}{
+		{
+			Topic:        chronicle.Topic{Name: "Pemilu 2019", Slug: "pemilu-2019"},
+			ExpectedSlug: "pemilu-2019-2",
+		},
+		{
+			Topic:        chronicle.Topic{Name: "Sepakbola Dalam Negeri", Slug: "sepakbola-dalam-negeri"},
+			ExpectedSlug: "sepakbola-dalam-negeri-2",
+		},
+	}
+
+	for _, testCase := range testCases {
+		createdTopic, err := topicService.CreateTopic(testCase.Topic)
+		if err != nil {
+			t.Error("Failed to create topic", err)
+		}
+		assert.Equal(t, createdTopic.Slug, testCase.ExpectedSlug)
+	}
+}
+
+func TestTopicHierarchyIntegration(t *testing.T) {
+	politics, err := topicService.CreateTopic(chronicle.Topic{Name: "Politics", Slug: "politics"})
+	if err != nil {
+		t.Fatal("Failed to create topic", err)
+	}
+
+	elections, err := topicService.CreateTopic(chronicle.Topic{Name: "Elections", Slug: "elections", ParentID: &politics.ID})
+	if err != nil {
+		t.Fatal("Failed to create topic", err)
+	}
+
+	pemilu, err := topicService.CreateTopic(chronicle.Topic{Name: "Pemilu 2024", Slug: "pemilu-2024", ParentID: &elections.ID})
+	if err != nil {
+		t.Fatal("Failed to create topic", err)
+	}
+
+	assert.Equal(t, chronicle.TopicBreadcrumbs{
+		{ID: politics.ID, Name: "Politics", Slug: "politics"},
+		{ID: elections.ID, Name: "Elections", Slug: "elections"},
+	}, pemilu.Breadcrumbs)
+
+	children, childrenCount, err := topicService.GetTopicChildren(politics.ID, chronicle.PagingOptions{
+		SortBy: "name",
+		Order:  "asc",
+		Limit:  10,
+		Offset: 0,
+	})
+	if err != nil {
+		t.Error("Failed to get topic children", err)
+	}
+	assert.Equal(t, 1, childrenCount)
+	if assert.Len(t, children, 1) {
+		assert.Equal(t, elections.ID, children[0].ID)
+	}
+
+	// a topic cannot move under itself or its descendants
+	testCases := []struct {
+		ParentID          int
+		ExpectedErrorType error
+	}{
+		{
+			ParentID:          politics.ID,
+			ExpectedErrorType: topic.ErrTopicCycle,
+		},
+		{
+			ParentID:          pemilu.ID,
+			ExpectedErrorType: topic.ErrTopicCycle,
+		},
+		{
+			ParentID:          pemilu.ID + 1000,
+			ExpectedErrorType: topic.ErrUnknownParentTopic,
+		},
+	}
+
+	for _, testCase := range testCases {
+		parentID := testCase.ParentID
+		politics.ParentID = &parentID
+		_, err := topicService.UpdateTopic(politics)
+		assert.Equal(t, testCase.ExpectedErrorType, err)
+	}
+
+	// cycles are found however deep the new parent is, deeper than breadcrumbs go
+	deepest := pemilu
+	for depth := 0; depth < 40; depth++ {
+		parentID := deepest.ID
+		deepest, err = topicService.CreateTopic(chronicle.Topic{Name: fmt.Sprintf("Level %d", depth), Slug: fmt.Sprintf("level-%d", depth), ParentID: &parentID})
+		if err != nil {
+			t.Fatal("Failed to create topic", err)
+		}
+	}
+
+	deepestID := deepest.ID
+	politics.ParentID = &deepestID
+	_, err = topicService.UpdateTopic(politics)
+	assert.Equal(t, topic.ErrTopicCycle, err)
+	politics.ParentID = nil
+
+	// children of a deleted topic move up to its parent
+	if err := topicService.DeleteTopicByID(elections.ID); err != nil {
+		t.Error("Failed to delete topic", err)
+	}
+
+	movedTopic, err := topicService.GetTopicByID(pemilu.ID)
+	if err != nil {
+		t.Error("Failed to get topic by id", err)
+	}
+	if assert.NotNil(t, movedTopic.ParentID) {
+		assert.Equal(t, politics.ID, *movedTopic.ParentID)
+	}
+}
+
+func TestMergeTopicIntegration(t *testing.T) {
+	duplicate, err := topicService.CreateTopic(chronicle.Topic{Name: "Jokowi Widodo 2019", Slug: "jokowi-widodo-2019"})
+	if err != nil {
+		t.Fatal("Failed to create topic", err)
+	}
+
+	target, err := topicService.CreateTopic(chronicle.Topic{Name: "Joko Widodo", Slug: "joko-widodo"})
+	if err != nil {
+		t.Fatal("Failed to create topic", err)
+	}
+
+	// one story is tagged with both topics, merging must not duplicate its row
+	stories := chronicle.Stories{
+		chronicle.Story{
+			Title:  "Jokowi Menang",
+			Slug:   "jokowi-menang",
+			Media:  []byte("{}"),
+			Status: chronicle.StoryDraftStatus,
+			Topics: chronicle.Topics{duplicate, target},
+		},
+		chronicle.Story{
+			Title:  "Jokowi Dilantik",
+			Slug:   "jokowi-dilantik",
+			Media:  []byte("{}"),
+			Status: chronicle.StoryDraftStatus,
+			Topics: chronicle.Topics{duplicate},
+		},
+		chronicle.Story{
+			Title:  "Jokowi Blusukan",
+			Slug:   "jokowi-blusukan",
+			Media:  []byte("{}"),
+			Status: chronicle.StoryDraftStatus,
+			Topics: chronicle.Topics{duplicate},
+		},
+	}
+	for idx, story := range stories {
+		createdStory, err := storyRepository.Insert(story)
+		if err != nil {
+			t.Fatal("Failed to create story", err)
+		}
+		stories[idx] = createdStory
+	}
+
+	// the first pin conflict on story and the last on slot with the pin of target, only the second is moved
+	pins := chronicle.StoryPins{
+		{StoryID: stories[0].ID, TopicID: target.ID, Slot: 3},
+		{StoryID: stories[0].ID, TopicID: duplicate.ID, Slot: 0},
+		{StoryID: stories[1].ID, TopicID: duplicate.ID, Slot: 1},
+		{StoryID: stories[2].ID, TopicID: duplicate.ID, Slot: 3},
+	}
+	for _, pin := range pins {
+		if _, err := pinRepository.Insert(pin); err != nil {
+			t.Fatal("Failed to pin story", err)
+		}
+	}
+
+	_, err = topicService.MergeTopic(duplicate.ID, duplicate.ID)
+	assert.Equal(t, topic.ErrInvalidMergeTarget, err)
+
+	_, err = topicService.MergeTopic(duplicate.ID, target.ID+1000)
+	assert.Equal(t, topic.ErrNoTopicFound, err)
+
+	mergedTopic, err := topicService.MergeTopic(duplicate.ID, target.ID)
+	if err != nil {
+		t.Fatal("Failed to merge topic", err)
+	}
+	assert.Equal(t, target.ID, mergedTopic.ID)
+
+	_, storiesCount, err := storyRepository.FindByTopicAndStatus(target.ID, "", false, chronicle.PagingOptions{
+		SortBy: "createdAt",
+		Order:  "desc",
+		Limit:  10,
+		Offset: 0,
+	})
+	if err != nil {
+		t.Error("Failed to get topic stories", err)
+	}
+	assert.Equal(t, 3, storiesCount)
+
+	targetPins, err := pinRepository.FindByTopic(target.ID)
+	if err != nil {
+		t.Error("Failed to get topic pins", err)
+	}
+	assert.Len(t, targetPins, 2)
+	if len(targetPins) == 2 {
+		assert.Equal(t, stories[1].ID, targetPins[0].StoryID)
+		assert.Equal(t, 1, targetPins[0].Slot)
+		assert.Equal(t, stories[0].ID, targetPins[1].StoryID)
+		assert.Equal(t, 3, targetPins[1].Slot)
+	}
+
+	_, err = topicService.GetTopicByID(duplicate.ID)
+	assert.Equal(t, topic.ErrNoTopicFound, err)
+
+	// the merged slug redirect to target
+	redirectedTopic, err := topicService.GetTopicByFormerSlug("jokowi-widodo-2019")
+	if err != nil {
+		t.Error("Failed to get topic by former slug", err)
+	}
+	assert.Equal(t, target.ID, redirectedTopic.ID)
+}
+
 func TestDeleteTopicIntegration(t *testing.T) {
 	if err := topicService.DeleteTopicByID(topicId); err != nil {
 		t.Error("Failed to delete topic", err)
@@ -0,0 +1,159 @@
+package transfer
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/AdhityaRamadhanus/chronicle"
+	"github.com/AdhityaRamadhanus/chronicle/function"
+	"github.com/pkg/errors"
+)
+
+//ExportPageSize is how many entities are listed from repositories at a time
+var ExportPageSize = 100
+
+//ExportReport count records written by Export
+type ExportReport struct {
+	Topics      int
+	Stories     int
+	StoryTopics int
+}
+
+/*
+Export write every topic, story and story topic link as NDJSON records to w, topics first with parents before their children
+then each story followed by its links. Stories carry their tags as those are matched by slug, bylines, media and collections are left out
+*/
+func Export(w io.Writer, topicRepository chronicle.TopicRepository, storyRepository chronicle.StoryRepository) (report ExportReport, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Wrap(err, function.GetFunctionName(Export))
+		}
+	}()
+
+	encoder := json.NewEncoder(w)
+
+	topics, err := allTopics(topicRepository)
+	if err != nil {
+		return report, err
+	}
+
+	for _, topic := range SortParentsFirst(topics) {
+		topic.Breadcrumbs = nil
+		if err := encoder.Encode(Record{Type: TopicRecord, Topic: &topic}); err != nil {
+			return report, err
+		}
+		report.Topics++
+	}
+
+	storyIds, err := allStoryIds(storyRepository)
+	if err != nil {
+		return report, err
+	}
+
+	for _, storyID := range storyIds {
+		story, err := storyRepository.Find(storyID)
+		if err != nil {
+			return report, err
+		}
+
+		storyTopics := story.Topics
+		story.Topics = nil
+		story.Bylines = nil
+		story.MediaAssets = nil
+		story.Collections = nil
+		if err := encoder.Encode(Record{Type: StoryRecord, Story: &story}); err != nil {
+			return report, err
+		}
+		report.Stories++
+
+		for _, topic := range storyTopics {
+			link := StoryTopic{StoryID: story.ID, TopicID: topic.ID}
+			if err := encoder.Encode(Record{Type: StoryTopicRecord, StoryTopic: &link}); err != nil {
+				return report, err
+			}
+			report.StoryTopics++
+		}
+	}
+
+	return report, nil
+}
+
+func allTopics(topicRepository chronicle.TopicRepository) (chronicle.Topics, error) {
+	topics := chronicle.Topics{}
+	for offset := 0; ; offset += ExportPageSize {
+		page, topicsCount, err := topicRepository.All(chronicle.PagingOptions{
+			Limit:  ExportPageSize,
+			Offset: offset,
+			SortBy: "id",
+			Order:  "asc",
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		topics = append(topics, page...)
+		if len(page) == 0 || offset+ExportPageSize >= topicsCount {
+			return topics, nil
+		}
+	}
+}
+
+// allStoryIds list ids only, stories are listed without their content so each is fetched on its own
+func allStoryIds(storyRepository chronicle.StoryRepository) ([]int, error) {
+	storyIds := []int{}
+	// pinned stories are listed on the page of their slot, they must not be exported twice
+	seen := map[int]bool{}
+	for offset := 0; ; offset += ExportPageSize {
+		page, storiesCount, err := storyRepository.All(chronicle.PagingOptions{
+			Limit:  ExportPageSize,
+			Offset: offset,
+			SortBy: "id",
+			Order:  "asc",
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, story := range page {
+			if !seen[story.ID] {
+				seen[story.ID] = true
+				storyIds = append(storyIds, story.ID)
+			}
+		}
+		if len(page) == 0 || offset+ExportPageSize >= storiesCount {
+			return storyIds, nil
+		}
+	}
+}
+
+//SortParentsFirst order topics so a topic come after its parent, siblings keep their order
+func SortParentsFirst(topics chronicle.Topics) chronicle.Topics {
+	children := map[int][]chronicle.Topic{}
+	exists := map[int]bool{}
+	for _, topic := range topics {
+		exists[topic.ID] = true
+	}
+
+	roots := chronicle.Topics{}
+	for _, topic := range topics {
+		if topic.ParentID == nil || !exists[*topic.ParentID] {
+			roots = append(roots, topic)
+			continue
+		}
+		children[*topic.ParentID] = append(children[*topic.ParentID], topic)
+	}
+
+	sorted := chronicle.Topics{}
+	var visit func(topic chronicle.Topic)
+	visit = func(topic chronicle.Topic) {
+		sorted = append(sorted, topic)
+		for _, child := range children[topic.ID] {
+			visit(child)
+		}
+	}
+	for _, root := range roots {
+		visit(root)
+	}
+
+	return sorted
+}
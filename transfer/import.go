@@ -0,0 +1,351 @@
+package transfer
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/AdhityaRamadhanus/chronicle"
+	"github.com/AdhityaRamadhanus/chronicle/content"
+	"github.com/AdhityaRamadhanus/chronicle/function"
+	"github.com/pkg/errors"
+)
+
+//ProgressInterval is how many records are imported between two saves of the progress
+var ProgressInterval = 100
+
+//ImportOptions configure Import
+type ImportOptions struct {
+	// report what would be imported without writing anything, progress is read but not saved
+	DryRun bool
+	// file keeping the progress so an interrupted import resume where it stopped, empty disable it
+	ProgressPath string
+}
+
+//ImportReport count what Import created, what already existed and what was skipped
+type ImportReport struct {
+	// records imported by an earlier run of a resumed import
+	Resumed         int
+	TopicsCreated   int
+	TopicsExisting  int
+	StoriesCreated  int
+	StoriesExisting int
+	LinksCreated    int
+	LinksExisting   int
+	// reasons records were skipped
+	Skipped []string
+}
+
+//Progress of an import, ids of the export mapped to those they were imported as
+type Progress struct {
+	// records done, an import resume from the next one
+	Records int
+	Topics  map[int]int
+	Stories map[int]int
+}
+
+type importer struct {
+	topicRepository chronicle.TopicRepository
+	storyRepository chronicle.StoryRepository
+	options         ImportOptions
+	progress        Progress
+	report          ImportReport
+	// ids handed to entities a dry run would create, negative so they never collide with stored ones
+	dryRunID int
+	// topics linked to the current story, written together once its links are read
+	pendingStory  int
+	pendingTopics []int
+}
+
+/*
+Import read NDJSON records written by Export, topics and stories that already exist by slug are reused instead of created
+and links are added to stories missing them, so an import can be run again. Ids are remapped to those of the importing environment
+*/
+func Import(r io.Reader, topicRepository chronicle.TopicRepository, storyRepository chronicle.StoryRepository, options ImportOptions) (report ImportReport, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Wrap(err, function.GetFunctionName(Import))
+		}
+	}()
+
+	i := &importer{
+		topicRepository: topicRepository,
+		storyRepository: storyRepository,
+		options:         options,
+	}
+
+	if i.progress, err = loadProgress(options.ProgressPath); err != nil {
+		return i.report, err
+	}
+
+	decoder := json.NewDecoder(r)
+	lastSaved := i.progress.Records
+	for idx := 0; ; idx++ {
+		record := Record{}
+		if err := decoder.Decode(&record); err == io.EOF {
+			break
+		} else if err != nil {
+			return i.report, errors.Wrapf(err, "record %d", idx+1)
+		}
+
+		if idx < i.progress.Records {
+			i.report.Resumed++
+			continue
+		}
+
+		// links of a story end with the next record that is not one
+		if record.Type != StoryTopicRecord {
+			if err := i.flushLinks(); err != nil {
+				return i.report, err
+			}
+
+			if i.progress.Records-lastSaved >= ProgressInterval {
+				if err := i.saveProgress(); err != nil {
+					return i.report, err
+				}
+				lastSaved = i.progress.Records
+			}
+		}
+
+		if err := i.importRecord(idx+1, record); err != nil {
+			return i.report, errors.Wrapf(err, "record %d", idx+1)
+		}
+		i.progress.Records = idx + 1
+	}
+
+	if err := i.flushLinks(); err != nil {
+		return i.report, err
+	}
+
+	return i.report, i.saveProgress()
+}
+
+func (i *importer) importRecord(line int, record Record) error {
+	switch {
+	case record.Type == TopicRecord && record.Topic != nil:
+		return i.importTopic(line, *record.Topic)
+	case record.Type == StoryRecord && record.Story != nil:
+		return i.importStory(line, *record.Story)
+	case record.Type == StoryTopicRecord && record.StoryTopic != nil:
+		i.importStoryTopic(line, *record.StoryTopic)
+		return nil
+	default:
+		i.skip(line, "unknown record type %q", record.Type)
+		return nil
+	}
+}
+
+func (i *importer) importTopic(line int, topic chronicle.Topic) error {
+	exportedID := topic.ID
+	if topic.ParentID != nil {
+		parentID, ok := i.progress.Topics[*topic.ParentID]
+		if !ok {
+			i.skip(line, "topic %s: parent %d was not imported", topic.Slug, *topic.ParentID)
+			return nil
+		}
+		topic.ParentID = &parentID
+	}
+
+	existingTopic, err := i.topicRepository.FindBySlug(topic.Slug)
+	if err == nil {
+		i.progress.Topics[exportedID] = existingTopic.ID
+		i.report.TopicsExisting++
+		return nil
+	}
+	if err != sql.ErrNoRows {
+		return err
+	}
+
+	if i.options.DryRun {
+		i.progress.Topics[exportedID] = i.nextDryRunID()
+		i.report.TopicsCreated++
+		return nil
+	}
+
+	topic.ID = 0
+	topic.Breadcrumbs = nil
+	createdTopic, err := i.topicRepository.Insert(topic)
+	if err != nil {
+		return err
+	}
+
+	i.progress.Topics[exportedID] = createdTopic.ID
+	i.report.TopicsCreated++
+	return nil
+}
+
+func (i *importer) importStory(line int, story chronicle.Story) error {
+	exportedID := story.ID
+
+	existingStory, err := i.storyRepository.FindBySlug(story.Slug)
+	if err == nil {
+		i.progress.Stories[exportedID] = existingStory.ID
+		i.report.StoriesExisting++
+		return nil
+	}
+	if err != sql.ErrNoRows {
+		return err
+	}
+
+	// content is rendered again as an export file is not trusted, html is stored sanitized like stories written through the api
+	if story.ContentFormat == "" {
+		story.ContentFormat = chronicle.StoryContentHTML
+	}
+	contentHTML, err := content.Render(story.ContentFormat, story.Content)
+	if err != nil {
+		i.skip(line, "story %s: %s", story.Slug, err.Error())
+		return nil
+	}
+	story.ContentHTML = contentHTML
+	if story.ContentFormat == chronicle.StoryContentHTML {
+		story.Content = contentHTML
+	}
+
+	if i.options.DryRun {
+		i.progress.Stories[exportedID] = i.nextDryRunID()
+		i.report.StoriesCreated++
+		return nil
+	}
+
+	// topics come with the links that follow, the rest reference entities not exported
+	story.ID = 0
+	story.Topics = nil
+	story.Bylines = nil
+	story.MediaAssets = nil
+	story.Collections = nil
+	createdStory, err := i.storyRepository.Insert(story)
+	if err != nil {
+		return err
+	}
+
+	i.progress.Stories[exportedID] = createdStory.ID
+	i.report.StoriesCreated++
+	return nil
+}
+
+func (i *importer) importStoryTopic(line int, link StoryTopic) {
+	storyID, ok := i.progress.Stories[link.StoryID]
+	if !ok {
+		i.skip(line, "link of story %d: story was not imported", link.StoryID)
+		return
+	}
+
+	topicID, ok := i.progress.Topics[link.TopicID]
+	if !ok {
+		i.skip(line, "link of story %d: topic %d was not imported", link.StoryID, link.TopicID)
+		return
+	}
+
+	if storyID != i.pendingStory {
+		i.pendingStory = storyID
+		i.pendingTopics = []int{}
+	}
+	i.pendingTopics = append(i.pendingTopics, topicID)
+}
+
+// flushLinks add the pending topics the story is not linked to yet
+func (i *importer) flushLinks() error {
+	storyID, topicIds := i.pendingStory, i.pendingTopics
+	i.pendingStory, i.pendingTopics = 0, nil
+	if len(topicIds) == 0 {
+		return nil
+	}
+
+	// a story a dry run would create has no link yet
+	if storyID < 0 {
+		i.report.LinksCreated += len(topicIds)
+		return nil
+	}
+
+	story, err := i.storyRepository.Find(storyID)
+	if err != nil {
+		return err
+	}
+
+	linked := map[int]bool{}
+	for _, topic := range story.Topics {
+		linked[topic.ID] = true
+	}
+
+	topics := story.Topics
+	for _, topicID := range topicIds {
+		if linked[topicID] {
+			i.report.LinksExisting++
+			continue
+		}
+		linked[topicID] = true
+		topics = append(topics, chronicle.Topic{ID: topicID})
+		i.report.LinksCreated++
+	}
+
+	if len(topics) == len(story.Topics) || i.options.DryRun {
+		return nil
+	}
+
+	// nil tags, bylines and media assets are left untouched by Update
+	story.Topics = topics
+	story.Tags = nil
+	story.Bylines = nil
+	story.MediaAssets = nil
+	_, err = i.storyRepository.Update(story)
+	return err
+}
+
+func (i *importer) skip(line int, format string, args ...interface{}) {
+	i.report.Skipped = append(i.report.Skipped, fmt.Sprintf("record %d: ", line)+fmt.Sprintf(format, args...))
+}
+
+func (i *importer) nextDryRunID() int {
+	i.dryRunID--
+	return i.dryRunID
+}
+
+// loadProgress read progress saved by an earlier run, there is none to resume when the file does not exist
+func loadProgress(path string) (Progress, error) {
+	progress := Progress{Topics: map[int]int{}, Stories: map[int]int{}}
+	if path == "" {
+		return progress, nil
+	}
+
+	saved, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return progress, nil
+	}
+	if err != nil {
+		return progress, err
+	}
+
+	if err := json.Unmarshal(saved, &progress); err != nil {
+		return progress, err
+	}
+	if progress.Topics == nil {
+		progress.Topics = map[int]int{}
+	}
+	if progress.Stories == nil {
+		progress.Stories = map[int]int{}
+	}
+
+	return progress, nil
+}
+
+// saveProgress replace the progress file at once so an interrupted save never leave it half written
+func (i *importer) saveProgress() error {
+	if i.options.ProgressPath == "" || i.options.DryRun {
+		return nil
+	}
+
+	saved, err := json.Marshal(i.progress)
+	if err != nil {
+		return err
+	}
+
+	tmpPath := i.options.ProgressPath + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, saved, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, i.options.ProgressPath)
+}
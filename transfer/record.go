@@ -0,0 +1,28 @@
+package transfer
+
+import (
+	"github.com/AdhityaRamadhanus/chronicle"
+)
+
+var (
+	//TopicRecord is a line holding a topic, parents come before their children
+	TopicRecord = "topic"
+	//StoryRecord is a line holding a story without its topics
+	StoryRecord = "story"
+	//StoryTopicRecord is a line linking a story to a topic, it follow the story
+	StoryTopicRecord = "story_topic"
+)
+
+//Record is a line of an export, ids are those of the exporting environment and only the field matching Type is set
+type Record struct {
+	Type       string
+	Topic      *chronicle.Topic `json:",omitempty"`
+	Story      *chronicle.Story `json:",omitempty"`
+	StoryTopic *StoryTopic      `json:",omitempty"`
+}
+
+//StoryTopic link a story to one of its topics
+type StoryTopic struct {
+	StoryID int
+	TopicID int
+}
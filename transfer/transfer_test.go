@@ -0,0 +1,302 @@
+package transfer_test
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AdhityaRamadhanus/chronicle"
+	"github.com/AdhityaRamadhanus/chronicle/transfer"
+	"github.com/stretchr/testify/assert"
+)
+
+// in memory topic repository, only methods used by export and import are implemented
+type fakeTopicRepository struct {
+	chronicle.TopicRepository
+	topics chronicle.Topics
+	nextID int
+}
+
+func (r *fakeTopicRepository) FindBySlug(slug string) (chronicle.Topic, error) {
+	for _, topic := range r.topics {
+		if topic.Slug == slug {
+			return topic, nil
+		}
+	}
+	return chronicle.Topic{}, sql.ErrNoRows
+}
+
+func (r *fakeTopicRepository) All(option chronicle.PagingOptions) (chronicle.Topics, int, error) {
+	end := option.Offset + option.Limit
+	if end > len(r.topics) {
+		end = len(r.topics)
+	}
+	if option.Offset >= end {
+		return chronicle.Topics{}, len(r.topics), nil
+	}
+	return r.topics[option.Offset:end], len(r.topics), nil
+}
+
+func (r *fakeTopicRepository) Insert(topic chronicle.Topic) (chronicle.Topic, error) {
+	r.nextID++
+	topic.ID = r.nextID
+	r.topics = append(r.topics, topic)
+	return topic, nil
+}
+
+// in memory story repository, only methods used by export and import are implemented
+type fakeStoryRepository struct {
+	chronicle.StoryRepository
+	stories chronicle.Stories
+	nextID  int
+	updates int
+}
+
+func (r *fakeStoryRepository) Find(id int) (chronicle.Story, error) {
+	for _, story := range r.stories {
+		if story.ID == id {
+			return story, nil
+		}
+	}
+	return chronicle.Story{}, sql.ErrNoRows
+}
+
+func (r *fakeStoryRepository) FindBySlug(slug string) (chronicle.Story, error) {
+	for _, story := range r.stories {
+		if story.Slug == slug {
+			return story, nil
+		}
+	}
+	return chronicle.Story{}, sql.ErrNoRows
+}
+
+func (r *fakeStoryRepository) All(option chronicle.PagingOptions) (chronicle.Stories, int, error) {
+	end := option.Offset + option.Limit
+	if end > len(r.stories) {
+		end = len(r.stories)
+	}
+	if option.Offset >= end {
+		return chronicle.Stories{}, len(r.stories), nil
+	}
+	// listings leave content out
+	page := chronicle.Stories{}
+	for _, story := range r.stories[option.Offset:end] {
+		story.Content = ""
+		page = append(page, story)
+	}
+	return page, len(r.stories), nil
+}
+
+func (r *fakeStoryRepository) Insert(story chronicle.Story) (chronicle.Story, error) {
+	r.nextID++
+	story.ID = r.nextID
+	r.stories = append(r.stories, story)
+	return story, nil
+}
+
+func (r *fakeStoryRepository) Update(story chronicle.Story) (chronicle.Story, error) {
+	r.updates++
+	for idx := range r.stories {
+		if r.stories[idx].ID == story.ID {
+			r.stories[idx].Topics = story.Topics
+		}
+	}
+	return story, nil
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func newSourceRepositories() (*fakeTopicRepository, *fakeStoryRepository) {
+	topicRepository := &fakeTopicRepository{
+		topics: chronicle.Topics{
+			// child listed before its parent
+			{ID: 12, ParentID: intPtr(11), Name: "Liga Inggris", Slug: "liga-inggris"},
+			{ID: 11, Name: "Sepak Bola", Slug: "sepak-bola"},
+			{ID: 13, Name: "Politik", Slug: "politik"},
+		},
+	}
+	storyRepository := &fakeStoryRepository{
+		stories: chronicle.Stories{
+			{
+				ID:      21,
+				Title:   "Liverpool Juara",
+				Slug:    "liverpool-juara",
+				Content: "<p>Liverpool juara</p>",
+				Status:  chronicle.StoryPublishStatus,
+				Topics:  chronicle.Topics{{ID: 11}, {ID: 12}},
+				Bylines: chronicle.Bylines{{Contributor: chronicle.Contributor{ID: 5}}},
+			},
+			{
+				ID:      22,
+				Title:   "Pemilu",
+				Slug:    "pemilu",
+				Content: "<p>Pemilu</p>",
+				Status:  chronicle.StoryDraftStatus,
+				Topics:  chronicle.Topics{{ID: 13}},
+			},
+		},
+	}
+	return topicRepository, storyRepository
+}
+
+func TestExport(t *testing.T) {
+	topicRepository, storyRepository := newSourceRepositories()
+	transfer.ExportPageSize = 2
+
+	output := &bytes.Buffer{}
+	report, err := transfer.Export(output, topicRepository, storyRepository)
+	assert.NoError(t, err)
+	assert.Equal(t, transfer.ExportReport{Topics: 3, Stories: 2, StoryTopics: 3}, report)
+
+	types := []string{}
+	records := []transfer.Record{}
+	for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+		record := transfer.Record{}
+		assert.NoError(t, json.Unmarshal([]byte(line), &record))
+		types = append(types, record.Type)
+		records = append(records, record)
+	}
+
+	assert.Equal(t, []string{"topic", "topic", "topic", "story", "story_topic", "story_topic", "story", "story_topic"}, types)
+	assert.Equal(t, "sepak-bola", records[0].Topic.Slug)
+	assert.Equal(t, "liga-inggris", records[1].Topic.Slug)
+	assert.Equal(t, "<p>Liverpool juara</p>", records[3].Story.Content)
+	assert.Nil(t, records[3].Story.Topics)
+	assert.Nil(t, records[3].Story.Bylines)
+	assert.Equal(t, transfer.StoryTopic{StoryID: 21, TopicID: 12}, *records[5].StoryTopic)
+}
+
+func TestImport(t *testing.T) {
+	sourceTopicRepository, sourceStoryRepository := newSourceRepositories()
+	exported := &bytes.Buffer{}
+	_, err := transfer.Export(exported, sourceTopicRepository, sourceStoryRepository)
+	assert.NoError(t, err)
+
+	// politik already exist in the target environment under another id
+	topicRepository := &fakeTopicRepository{
+		topics: chronicle.Topics{{ID: 1, Name: "Politik", Slug: "politik"}},
+		nextID: 1,
+	}
+	storyRepository := &fakeStoryRepository{}
+
+	report, err := transfer.Import(bytes.NewReader(exported.Bytes()), topicRepository, storyRepository, transfer.ImportOptions{DryRun: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.TopicsCreated)
+	assert.Equal(t, 1, report.TopicsExisting)
+	assert.Equal(t, 2, report.StoriesCreated)
+	assert.Equal(t, 3, report.LinksCreated)
+	assert.Len(t, topicRepository.topics, 1, "dry run should not write")
+	assert.Len(t, storyRepository.stories, 0, "dry run should not write")
+
+	report, err = transfer.Import(bytes.NewReader(exported.Bytes()), topicRepository, storyRepository, transfer.ImportOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.TopicsCreated)
+	assert.Equal(t, 2, report.StoriesCreated)
+	assert.Equal(t, 3, report.LinksCreated)
+	assert.Empty(t, report.Skipped)
+
+	ligaInggris, _ := topicRepository.FindBySlug("liga-inggris")
+	sepakBola, _ := topicRepository.FindBySlug("sepak-bola")
+	assert.Equal(t, sepakBola.ID, *ligaInggris.ParentID, "parent should be remapped")
+
+	liverpool, _ := storyRepository.FindBySlug("liverpool-juara")
+	assert.Equal(t, chronicle.Topics{{ID: sepakBola.ID}, {ID: ligaInggris.ID}}, liverpool.Topics)
+	assert.Nil(t, liverpool.Bylines)
+	pemilu, _ := storyRepository.FindBySlug("pemilu")
+	assert.Equal(t, chronicle.Topics{{ID: 1}}, pemilu.Topics)
+
+	// importing again find everything by slug
+	updates := storyRepository.updates
+	report, err = transfer.Import(bytes.NewReader(exported.Bytes()), topicRepository, storyRepository, transfer.ImportOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, transfer.ImportReport{TopicsExisting: 3, StoriesExisting: 2, LinksExisting: 3}, report)
+	assert.Len(t, topicRepository.topics, 3)
+	assert.Len(t, storyRepository.stories, 2)
+	assert.Equal(t, updates, storyRepository.updates)
+}
+
+func TestImportResume(t *testing.T) {
+	sourceTopicRepository, sourceStoryRepository := newSourceRepositories()
+	exported := &bytes.Buffer{}
+	_, err := transfer.Export(exported, sourceTopicRepository, sourceStoryRepository)
+	assert.NoError(t, err)
+
+	dir, err := ioutil.TempDir("", "chronicle-import")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	progressPath := filepath.Join(dir, "import.progress")
+
+	// an import interrupted after the topics
+	lines := strings.SplitAfter(exported.String(), "\n")
+	topicRepository := &fakeTopicRepository{}
+	storyRepository := &fakeStoryRepository{}
+	options := transfer.ImportOptions{ProgressPath: progressPath}
+	_, err = transfer.Import(strings.NewReader(strings.Join(lines[:3], "")), topicRepository, storyRepository, options)
+	assert.NoError(t, err)
+
+	report, err := transfer.Import(bytes.NewReader(exported.Bytes()), topicRepository, storyRepository, options)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, report.Resumed)
+	assert.Equal(t, 0, report.TopicsCreated+report.TopicsExisting)
+	assert.Equal(t, 2, report.StoriesCreated)
+	assert.Equal(t, 3, report.LinksCreated)
+	assert.Empty(t, report.Skipped)
+
+	saved, err := ioutil.ReadFile(progressPath)
+	assert.NoError(t, err)
+	progress := transfer.Progress{}
+	assert.NoError(t, json.Unmarshal(saved, &progress))
+	assert.Equal(t, 8, progress.Records)
+	assert.Len(t, progress.Topics, 3)
+	assert.Len(t, progress.Stories, 2)
+}
+
+func TestImportRenderContent(t *testing.T) {
+	input := strings.Join([]string{
+		`{"Type":"story","Story":{"ID":1,"Slug":"html","Content":"<p onclick=\"x()\">Hai</p><script>alert(1)</script>","ContentHTML":"<script>alert(1)</script>"}}`,
+		`{"Type":"story","Story":{"ID":2,"Slug":"markdown","ContentFormat":"markdown","Content":"**Hai**","ContentHTML":"<script>alert(1)</script>"}}`,
+		`{"Type":"story","Story":{"ID":3,"Slug":"unknown","ContentFormat":"docx","Content":"Hai"}}`,
+	}, "\n")
+
+	storyRepository := &fakeStoryRepository{}
+	report, err := transfer.Import(strings.NewReader(input), &fakeTopicRepository{}, storyRepository, transfer.ImportOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.StoriesCreated)
+	assert.Len(t, report.Skipped, 1)
+	assert.Contains(t, report.Skipped[0], "story unknown")
+
+	html, _ := storyRepository.FindBySlug("html")
+	assert.Equal(t, chronicle.StoryContentHTML, html.ContentFormat)
+	assert.Equal(t, "<p>Hai</p>", html.Content)
+	assert.Equal(t, "<p>Hai</p>", html.ContentHTML)
+
+	markdown, _ := storyRepository.FindBySlug("markdown")
+	assert.Equal(t, "**Hai**", markdown.Content)
+	assert.Equal(t, "<p><strong>Hai</strong></p>", markdown.ContentHTML)
+}
+
+func TestImportSkipped(t *testing.T) {
+	input := strings.Join([]string{
+		`{"Type":"topic","Topic":{"ID":2,"ParentID":99,"Name":"Orphan","Slug":"orphan"}}`,
+		`{"Type":"story_topic","StoryTopic":{"StoryID":5,"TopicID":2}}`,
+		`{"Type":"tag"}`,
+	}, "\n")
+
+	topicRepository := &fakeTopicRepository{}
+	storyRepository := &fakeStoryRepository{}
+	report, err := transfer.Import(strings.NewReader(input), topicRepository, storyRepository, transfer.ImportOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, report.Skipped, 3)
+	assert.Contains(t, report.Skipped[0], "record 1")
+	assert.Empty(t, topicRepository.topics)
+
+	_, err = transfer.Import(strings.NewReader("{not json"), topicRepository, storyRepository, transfer.ImportOptions{})
+	assert.Error(t, err)
+}
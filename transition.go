@@ -0,0 +1,23 @@
+package chronicle
+
+import "time"
+
+//StoryTransition record a workflow transition applied to a story
+type StoryTransition struct {
+	ID         int
+	StoryID    int
+	Transition string
+	FromStatus string
+	ToStatus   string
+	ClientID   string
+	CreatedAt  time.Time
+}
+
+//StoryTransitions short way to define array of story transition
+type StoryTransitions []StoryTransition
+
+//StoryTransitionRepository provide an interface to get story transition entities
+type StoryTransitionRepository interface {
+	FindByStory(storyID int, option PagingOptions) (transitions StoryTransitions, transitionsCount int, err error)
+	Insert(transition StoryTransition) (createdTransition StoryTransition, err error)
+}
@@ -0,0 +1,16 @@
+package chronicle
+
+import (
+	"time"
+)
+
+var (
+	//TrendingWindows are the periods stories are ranked over, keyed by the window accepted by the api
+	TrendingWindows = map[string]time.Duration{
+		"6h":  6 * time.Hour,
+		"24h": 24 * time.Hour,
+		"7d":  7 * 24 * time.Hour,
+	}
+	//DefaultTrendingWindow is used when no window is requested
+	DefaultTrendingWindow = "24h"
+)
@@ -0,0 +1,15 @@
+package chronicle
+
+//UnitOfWork expose repositories sharing a single transaction
+type UnitOfWork interface {
+	Stories() StoryRepository
+	StoryRevisions() StoryRevisionRepository
+	StoryTransitions() StoryTransitionRepository
+	Topics() TopicRepository
+	Contributors() ContributorRepository
+}
+
+//Transactor run fn in a unit of work, commit when fn return nil and rollback otherwise
+type Transactor interface {
+	WithTx(fn func(uow UnitOfWork) error) error
+}
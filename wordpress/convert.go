@@ -0,0 +1,303 @@
+package wordpress
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AdhityaRamadhanus/chronicle"
+	"github.com/AdhityaRamadhanus/chronicle/content"
+	"github.com/AdhityaRamadhanus/chronicle/transfer"
+)
+
+// format of WXR dates, a date never set is all zeroes
+const wxrDateLayout = "2006-01-02 15:04:05"
+
+var (
+	// post statuses imported and the story status they map to, scheduled posts are approved so the scheduler publishes them at their date
+	postStatuses = map[string]string{
+		"publish": chronicle.StoryPublishStatus,
+		"future":  chronicle.StoryApprovedStatus,
+		"draft":   chronicle.StoryDraftStatus,
+		"pending": chronicle.StoryDraftStatus,
+		"private": chronicle.StoryDraftStatus,
+	}
+
+	htmlComment = regexp.MustCompile(`(?s)<!--.*?-->`)
+	preBlock    = regexp.MustCompile(`(?is)<pre[\s>].*?</pre>`)
+	blankLines  = regexp.MustCompile(`\n[ \t]*\n`)
+	blockStart  = regexp.MustCompile(`(?i)^<(address|blockquote|div|dl|figure|h[1-6]|hr|ol|p|pre|table|ul)[\s/>]`)
+)
+
+/*
+Attachment is a file attached to a post, kept by url in the legacy Media of its story.
+Import only read the export, files are not downloaded so no media asset can be created for them
+*/
+type Attachment struct {
+	URL     string `json:"url"`
+	Title   string `json:"title,omitempty"`
+	Caption string `json:"caption,omitempty"`
+}
+
+//Media of a story imported from a post, the featured image and the files attached to the post
+type Media struct {
+	Featured    *Attachment  `json:"featured,omitempty"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+}
+
+/*
+Convert turn categories into topics and posts into stories with their topic links, as records for transfer.Import which remap
+ids and reuse what already exist by slug. It also return why items and terms were left out
+*/
+func Convert(export Export) (records []transfer.Record, skipped []string) {
+	authors := map[string]Author{}
+	authorsByID := map[int]Author{}
+	for _, author := range export.Authors {
+		authors[author.Login] = author
+		authorsByID[author.ID] = author
+	}
+
+	topics, topicIds, skippedCategories := convertCategories(export.Categories)
+	skipped = append(skipped, skippedCategories...)
+	for idx := range topics {
+		records = append(records, transfer.Record{Type: transfer.TopicRecord, Topic: &topics[idx]})
+	}
+
+	attachments := map[int]Item{}
+	attachmentsByPost := map[int][]Item{}
+	for _, item := range export.Items {
+		if item.Type == "attachment" {
+			attachments[item.ID] = item
+			attachmentsByPost[item.Parent] = append(attachmentsByPost[item.Parent], item)
+		}
+	}
+	usedAttachments := map[int]bool{}
+
+	slugs := map[string]int{}
+	for _, item := range export.Items {
+		if item.Type == "attachment" {
+			continue
+		}
+		if item.Type != "post" {
+			skipped = append(skipped, fmt.Sprintf("item %d %q: post type %s is not imported", item.ID, item.Title, item.Type))
+			continue
+		}
+
+		status, ok := postStatuses[item.Status]
+		if !ok {
+			skipped = append(skipped, fmt.Sprintf("item %d %q: post status %s is not imported", item.ID, item.Title, item.Status))
+			continue
+		}
+
+		slug := unescapeSlug(item.Slug)
+		if slug == "" {
+			slug = chronicle.Slugify(item.Title)
+		}
+		if slug == "" {
+			skipped = append(skipped, fmt.Sprintf("item %d: post has neither slug nor title", item.ID))
+			continue
+		}
+		if otherID, taken := slugs[slug]; taken {
+			skipped = append(skipped, fmt.Sprintf("item %d %q: slug %s is taken by item %d", item.ID, item.Title, slug, otherID))
+			continue
+		}
+		slugs[slug] = item.ID
+
+		story := chronicle.Story{
+			ID:            item.ID,
+			Title:         item.Title,
+			Slug:          slug,
+			Excerpt:       content.HTMLToText(item.Excerpt),
+			ContentFormat: chronicle.StoryContentHTML,
+			Status:        status,
+			Tags:          chronicle.Tags{},
+		}
+		// html content is stored sanitized, like stories written through the api, paragraphs left empty by it are dropped
+		story.Content = strings.TrimSpace(strings.Replace(content.Sanitize(autop(item.Content)), "<p></p>", "", -1))
+		story.ContentHTML = story.Content
+
+		author := authorName(authors, item.Creator)
+		story.Author = author
+		story.Reporter = author
+		if editorID, err := strconv.Atoi(item.Meta["_edit_last"]); err == nil {
+			if editor, ok := authorsByID[editorID]; ok {
+				story.Editor = authorName(authors, editor.Login)
+			}
+		}
+
+		if date, ok := itemDate(item); ok {
+			switch item.Status {
+			case "publish":
+				story.PublishedAt = &date
+			case "future":
+				story.PublishAt = &date
+			}
+		}
+
+		media := Media{}
+		if thumbnailID, err := strconv.Atoi(item.Meta["_thumbnail_id"]); err == nil {
+			if thumbnail, ok := attachments[thumbnailID]; ok && thumbnail.AttachmentURL != "" {
+				featured := newAttachment(thumbnail)
+				media.Featured = &featured
+				usedAttachments[thumbnailID] = true
+			}
+		}
+		for _, attachment := range attachmentsByPost[item.ID] {
+			if attachment.AttachmentURL == "" {
+				continue
+			}
+			media.Attachments = append(media.Attachments, newAttachment(attachment))
+			usedAttachments[attachment.ID] = true
+		}
+		story.Media, _ = json.Marshal(media)
+
+		links := []transfer.StoryTopic{}
+		for _, term := range item.Terms {
+			switch term.Domain {
+			case "category":
+				topicID, ok := topicIds[unescapeSlug(term.Slug)]
+				if !ok {
+					skipped = append(skipped, fmt.Sprintf("item %d %q: category %s is not imported", item.ID, item.Title, term.Slug))
+					continue
+				}
+				links = append(links, transfer.StoryTopic{StoryID: item.ID, TopicID: topicID})
+			case "post_tag":
+				tagSlug := unescapeSlug(term.Slug)
+				if tagSlug == "" {
+					tagSlug = chronicle.Slugify(term.Name)
+				}
+				story.Tags = append(story.Tags, chronicle.Tag{Name: term.Name, Slug: tagSlug})
+			}
+		}
+
+		records = append(records, transfer.Record{Type: transfer.StoryRecord, Story: &story})
+		for idx := range links {
+			records = append(records, transfer.Record{Type: transfer.StoryTopicRecord, StoryTopic: &links[idx]})
+		}
+	}
+
+	for _, item := range export.Items {
+		if item.Type == "attachment" && !usedAttachments[item.ID] {
+			skipped = append(skipped, fmt.Sprintf("item %d %q: attachment is not attached to an imported post", item.ID, item.Title))
+		}
+	}
+
+	return records, skipped
+}
+
+// convertCategories return topics parents first and their ids by slug
+func convertCategories(categories []Category) (topics chronicle.Topics, topicIds map[string]int, skipped []string) {
+	topicIds = map[string]int{}
+	for _, category := range categories {
+		slug := unescapeSlug(category.Slug)
+		if slug == "" {
+			slug = chronicle.Slugify(category.Name)
+		}
+		if slug == "" || category.ID == 0 {
+			skipped = append(skipped, fmt.Sprintf("category %q: it has neither id nor slug", category.Name))
+			continue
+		}
+		if otherID, taken := topicIds[slug]; taken {
+			skipped = append(skipped, fmt.Sprintf("category %d %q: slug %s is taken by category %d", category.ID, category.Name, slug, otherID))
+			continue
+		}
+		topicIds[slug] = category.ID
+	}
+
+	for _, category := range categories {
+		slug := unescapeSlug(category.Slug)
+		if slug == "" {
+			slug = chronicle.Slugify(category.Name)
+		}
+		if topicIds[slug] != category.ID {
+			continue
+		}
+
+		topic := chronicle.Topic{ID: category.ID, Name: category.Name, Slug: slug}
+		if category.ParentSlug != "" {
+			parentID, ok := topicIds[unescapeSlug(category.ParentSlug)]
+			if !ok {
+				skipped = append(skipped, fmt.Sprintf("category %s: parent %s is not in the export", slug, category.ParentSlug))
+				delete(topicIds, slug)
+				continue
+			}
+			topic.ParentID = &parentID
+		}
+		topics = append(topics, topic)
+	}
+
+	return transfer.SortParentsFirst(topics), topicIds, skipped
+}
+
+// unescapeSlug decode non ascii slugs, WordPress keep them percent encoded
+func unescapeSlug(slug string) string {
+	if unescaped, err := url.PathUnescape(slug); err == nil {
+		return strings.ToLower(unescaped)
+	}
+	return strings.ToLower(slug)
+}
+
+// authorName is the display name of the author with login, the login itself when the author is not in the export
+func authorName(authors map[string]Author, login string) string {
+	if author, ok := authors[login]; ok && author.DisplayName != "" {
+		return author.DisplayName
+	}
+	return login
+}
+
+// itemDate is the utc date of the item, drafts only have a local date which is taken as utc
+func itemDate(item Item) (time.Time, bool) {
+	for _, date := range []string{item.DateGMT, item.Date} {
+		if parsed, err := time.Parse(wxrDateLayout, date); err == nil && !strings.HasPrefix(date, "0000") {
+			return parsed, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func newAttachment(item Item) Attachment {
+	return Attachment{
+		URL:     item.AttachmentURL,
+		Title:   item.Title,
+		Caption: content.HTMLToText(item.Excerpt),
+	}
+}
+
+// autop wrap paragraphs of classic editor content, which are separated by blank lines instead of tags
+func autop(input string) string {
+	input = strings.Replace(input, "\r\n", "\n", -1)
+	input = htmlComment.ReplaceAllString(input, "")
+
+	paragraphs := []string{}
+	last := 0
+	for _, loc := range preBlock.FindAllStringIndex(input, -1) {
+		paragraphs = append(paragraphs, splitParagraphs(input[last:loc[0]])...)
+		paragraphs = append(paragraphs, input[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	paragraphs = append(paragraphs, splitParagraphs(input[last:])...)
+
+	return strings.Join(paragraphs, "\n")
+}
+
+// splitParagraphs wrap text separated by blank lines in <p>, line breaks within it become <br>, blocks already in a tag are kept
+func splitParagraphs(input string) []string {
+	paragraphs := []string{}
+	for _, chunk := range blankLines.Split(input, -1) {
+		chunk = strings.TrimSpace(chunk)
+		if chunk == "" {
+			continue
+		}
+
+		if blockStart.MatchString(chunk) {
+			paragraphs = append(paragraphs, chunk)
+			continue
+		}
+		paragraphs = append(paragraphs, "<p>"+strings.Replace(chunk, "\n", "<br>\n", -1)+"</p>")
+	}
+	return paragraphs
+}
@@ -0,0 +1,178 @@
+package wordpress_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AdhityaRamadhanus/chronicle"
+	"github.com/AdhityaRamadhanus/chronicle/transfer"
+	"github.com/AdhityaRamadhanus/chronicle/wordpress"
+	"github.com/stretchr/testify/assert"
+)
+
+var testWXR = `<?xml version="1.0" encoding="UTF-8" ?>
+<rss version="2.0"
+	xmlns:excerpt="http://wordpress.org/export/1.2/excerpt/"
+	xmlns:content="http://purl.org/rss/1.0/modules/content/"
+	xmlns:dc="http://purl.org/dc/elements/1.1/"
+	xmlns:wp="http://wordpress.org/export/1.2/">
+<channel>
+	<title>Desk Bandung</title>
+	<wp:wxr_version>1.2</wp:wxr_version>
+	<wp:author><wp:author_id>1</wp:author_id><wp:author_login><![CDATA[jane]]></wp:author_login><wp:author_display_name><![CDATA[Jane Doe]]></wp:author_display_name></wp:author>
+	<wp:author><wp:author_id>2</wp:author_id><wp:author_login><![CDATA[john]]></wp:author_login><wp:author_display_name><![CDATA[John Doe]]></wp:author_display_name></wp:author>
+	<wp:category><wp:term_id>5</wp:term_id><wp:category_nicename><![CDATA[liga-inggris]]></wp:category_nicename><wp:category_parent><![CDATA[sepak-bola]]></wp:category_parent><wp:cat_name><![CDATA[Liga Inggris]]></wp:cat_name></wp:category>
+	<wp:category><wp:term_id>4</wp:term_id><wp:category_nicename><![CDATA[sepak-bola]]></wp:category_nicename><wp:category_parent><![CDATA[]]></wp:category_parent><wp:cat_name><![CDATA[Sepak Bola &amp; Futsal]]></wp:cat_name></wp:category>
+	<wp:category><wp:term_id>6</wp:term_id><wp:category_nicename><![CDATA[orphan]]></wp:category_nicename><wp:category_parent><![CDATA[missing]]></wp:category_parent><wp:cat_name><![CDATA[Orphan]]></wp:cat_name></wp:category>
+	<item>
+		<title>Liverpool Juara</title>
+		<dc:creator><![CDATA[jane]]></dc:creator>
+		<content:encoded><![CDATA[Liverpool juara
+liga.
+
+<!-- wp:paragraph --><p>Salah mencetak gol</p><!-- /wp:paragraph -->
+
+<script>alert(1)</script>]]></content:encoded>
+		<excerpt:encoded><![CDATA[<em>Liverpool</em> juara]]></excerpt:encoded>
+		<wp:post_id>10</wp:post_id>
+		<wp:post_date><![CDATA[2018-10-25 17:00:00]]></wp:post_date>
+		<wp:post_date_gmt><![CDATA[2018-10-25 10:00:00]]></wp:post_date_gmt>
+		<wp:post_name><![CDATA[liverpool-juara-liga]]></wp:post_name>
+		<wp:status><![CDATA[publish]]></wp:status>
+		<wp:post_parent>0</wp:post_parent>
+		<wp:post_type><![CDATA[post]]></wp:post_type>
+		<category domain="category" nicename="liga-inggris"><![CDATA[Liga Inggris]]></category>
+		<category domain="category" nicename="orphan"><![CDATA[Orphan]]></category>
+		<category domain="post_tag" nicename="liverpool"><![CDATA[Liverpool]]></category>
+		<wp:postmeta><wp:meta_key><![CDATA[_thumbnail_id]]></wp:meta_key><wp:meta_value><![CDATA[30]]></wp:meta_value></wp:postmeta>
+		<wp:postmeta><wp:meta_key><![CDATA[_edit_last]]></wp:meta_key><wp:meta_value><![CDATA[2]]></wp:meta_value></wp:postmeta>
+	</item>
+	<item>
+		<title>Jadwal Pekan Depan</title>
+		<dc:creator><![CDATA[ghost]]></dc:creator>
+		<content:encoded><![CDATA[<p>Jadwal</p>]]></content:encoded>
+		<excerpt:encoded><![CDATA[]]></excerpt:encoded>
+		<wp:post_id>11</wp:post_id>
+		<wp:post_date><![CDATA[2018-11-01 08:00:00]]></wp:post_date>
+		<wp:post_date_gmt><![CDATA[0000-00-00 00:00:00]]></wp:post_date_gmt>
+		<wp:post_name><![CDATA[]]></wp:post_name>
+		<wp:status><![CDATA[future]]></wp:status>
+		<wp:post_type><![CDATA[post]]></wp:post_type>
+	</item>
+	<item>
+		<title>Dihapus</title>
+		<wp:post_id>12</wp:post_id>
+		<wp:post_name><![CDATA[dihapus]]></wp:post_name>
+		<wp:status><![CDATA[trash]]></wp:status>
+		<wp:post_type><![CDATA[post]]></wp:post_type>
+	</item>
+	<item>
+		<title>Tentang Kami</title>
+		<wp:post_id>13</wp:post_id>
+		<wp:status><![CDATA[publish]]></wp:status>
+		<wp:post_type><![CDATA[page]]></wp:post_type>
+	</item>
+	<item>
+		<title>Stadion Anfield</title>
+		<excerpt:encoded><![CDATA[Anfield saat laga]]></excerpt:encoded>
+		<wp:post_id>30</wp:post_id>
+		<wp:post_parent>0</wp:post_parent>
+		<wp:post_type><![CDATA[attachment]]></wp:post_type>
+		<wp:attachment_url><![CDATA[https://example.com/anfield.jpg]]></wp:attachment_url>
+	</item>
+	<item>
+		<title>Skor</title>
+		<wp:post_id>31</wp:post_id>
+		<wp:post_parent>10</wp:post_parent>
+		<wp:post_type><![CDATA[attachment]]></wp:post_type>
+		<wp:attachment_url><![CDATA[https://example.com/skor.pdf]]></wp:attachment_url>
+	</item>
+	<item>
+		<title>Logo</title>
+		<wp:post_id>32</wp:post_id>
+		<wp:post_parent>13</wp:post_parent>
+		<wp:post_type><![CDATA[attachment]]></wp:post_type>
+		<wp:attachment_url><![CDATA[https://example.com/logo.png]]></wp:attachment_url>
+	</item>
+</channel>
+</rss>`
+
+func TestParse(t *testing.T) {
+	export, err := wordpress.Parse(strings.NewReader(testWXR))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []wordpress.Author{{ID: 1, Login: "jane", DisplayName: "Jane Doe"}, {ID: 2, Login: "john", DisplayName: "John Doe"}}, export.Authors)
+	assert.Len(t, export.Categories, 3)
+	assert.Equal(t, wordpress.Category{ID: 4, Slug: "sepak-bola", Name: "Sepak Bola & Futsal"}, export.Categories[1])
+	assert.Len(t, export.Items, 7)
+
+	post := export.Items[0]
+	assert.Equal(t, 10, post.ID)
+	assert.Equal(t, "post", post.Type)
+	assert.Equal(t, "liverpool-juara-liga", post.Slug)
+	assert.Equal(t, "jane", post.Creator)
+	assert.Equal(t, "<em>Liverpool</em> juara", post.Excerpt)
+	assert.Contains(t, post.Content, "Liverpool juara\nliga.")
+	assert.Equal(t, "30", post.Meta["_thumbnail_id"])
+	assert.Equal(t, wordpress.Term{Domain: "post_tag", Slug: "liverpool", Name: "Liverpool"}, post.Terms[2])
+	assert.Equal(t, 10, export.Items[5].Parent)
+
+	_, err = wordpress.Parse(strings.NewReader("<rss><channel>"))
+	assert.Error(t, err)
+}
+
+func TestConvert(t *testing.T) {
+	export, err := wordpress.Parse(strings.NewReader(testWXR))
+	assert.NoError(t, err)
+
+	records, skipped := wordpress.Convert(export)
+
+	types := []string{}
+	for _, record := range records {
+		types = append(types, record.Type)
+	}
+	assert.Equal(t, []string{"topic", "topic", "story", "story_topic", "story"}, types)
+
+	// parents come first
+	assert.Equal(t, "sepak-bola", records[0].Topic.Slug)
+	assert.Equal(t, "Sepak Bola & Futsal", records[0].Topic.Name)
+	assert.Equal(t, 4, *records[1].Topic.ParentID)
+
+	published := records[2].Story
+	assert.Equal(t, 10, published.ID)
+	assert.Equal(t, "liverpool-juara-liga", published.Slug)
+	assert.Equal(t, chronicle.StoryPublishStatus, published.Status)
+	assert.Equal(t, "Liverpool juara", published.Excerpt)
+	assert.Equal(t, "Jane Doe", published.Author)
+	assert.Equal(t, "Jane Doe", published.Reporter)
+	assert.Equal(t, "John Doe", published.Editor)
+	assert.Equal(t, chronicle.StoryContentHTML, published.ContentFormat)
+	assert.Equal(t, "<p>Liverpool juara<br>\nliga.</p>\n<p>Salah mencetak gol</p>", published.Content)
+	assert.Equal(t, published.Content, published.ContentHTML)
+	assert.Equal(t, time.Date(2018, 10, 25, 10, 0, 0, 0, time.UTC), *published.PublishedAt)
+	assert.Equal(t, chronicle.Tags{{Name: "Liverpool", Slug: "liverpool"}}, published.Tags)
+	assert.Equal(t, transfer.StoryTopic{StoryID: 10, TopicID: 5}, *records[3].StoryTopic)
+
+	media := wordpress.Media{}
+	assert.NoError(t, json.Unmarshal(published.Media, &media))
+	assert.Equal(t, &wordpress.Attachment{URL: "https://example.com/anfield.jpg", Title: "Stadion Anfield", Caption: "Anfield saat laga"}, media.Featured)
+	assert.Equal(t, []wordpress.Attachment{{URL: "https://example.com/skor.pdf", Title: "Skor"}}, media.Attachments)
+
+	// missing slug fall back to the title, scheduled posts are approved so the scheduler publishes them at their date
+	scheduled := records[4].Story
+	assert.Equal(t, chronicle.Slugify("Jadwal Pekan Depan"), scheduled.Slug)
+	assert.Equal(t, chronicle.StoryApprovedStatus, scheduled.Status)
+	assert.Equal(t, time.Date(2018, 11, 1, 8, 0, 0, 0, time.UTC), *scheduled.PublishAt)
+	assert.Nil(t, scheduled.PublishedAt)
+	assert.Equal(t, "ghost", scheduled.Author)
+	assert.Equal(t, "{}", string(scheduled.Media))
+
+	assert.Len(t, skipped, 5)
+	assert.Contains(t, skipped[0], "parent missing")
+	assert.Contains(t, skipped[1], "category orphan")
+	assert.Contains(t, skipped[2], "post status trash")
+	assert.Contains(t, skipped[3], "post type page")
+	assert.Contains(t, skipped[4], `"Logo"`)
+}
@@ -0,0 +1,204 @@
+package wordpress
+
+import (
+	"encoding/xml"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/AdhityaRamadhanus/chronicle/function"
+	"github.com/pkg/errors"
+)
+
+//Export is the content of a WordPress eXtended RSS (WXR) file
+type Export struct {
+	Authors    []Author
+	Categories []Category
+	// posts, pages, attachments and every other post type
+	Items []Item
+}
+
+//Author is a user of the exporting site
+type Author struct {
+	ID          int
+	Login       string
+	DisplayName string
+}
+
+//Category is a post category, categories form a tree through the slug of their parent
+type Category struct {
+	ID         int
+	Slug       string
+	ParentSlug string
+	Name       string
+}
+
+//Item is a post of any type
+type Item struct {
+	ID      int
+	Type    string
+	Status  string
+	Title   string
+	Slug    string
+	Content string
+	Excerpt string
+	// login of the author
+	Creator string
+	// local and utc dates formatted as 2006-01-02 15:04:05, utc is zero for drafts that were never saved with a date
+	Date    string
+	DateGMT string
+	// post an attachment is attached to, 0 for none
+	Parent        int
+	AttachmentURL string
+	// categories and tags of the post
+	Terms []Term
+	Meta  map[string]string
+}
+
+//Term is a category or a tag of a post, Domain is category or post_tag
+type Term struct {
+	Domain string
+	Slug   string
+	Name   string
+}
+
+// wordpress elements are matched by local name, their namespace url change with the WXR version
+type wxrDocument struct {
+	Channel struct {
+		Items    []wxrItem    `xml:"item"`
+		Elements []wxrElement `xml:",any"`
+	} `xml:"channel"`
+}
+
+// an author or a category of the channel, told apart by XMLName
+type wxrElement struct {
+	XMLName           xml.Name
+	AuthorID          string `xml:"author_id"`
+	AuthorLogin       string `xml:"author_login"`
+	AuthorDisplayName string `xml:"author_display_name"`
+	TermID            string `xml:"term_id"`
+	CategoryNicename  string `xml:"category_nicename"`
+	CategoryParent    string `xml:"category_parent"`
+	CategoryName      string `xml:"cat_name"`
+}
+
+type wxrItem struct {
+	Title   string `xml:"title"`
+	Creator string `xml:"creator"`
+	// content:encoded and excerpt:encoded share a local name
+	Encoded       []wxrText     `xml:"encoded"`
+	Terms         []wxrTerm     `xml:"category"`
+	PostID        string        `xml:"post_id"`
+	PostName      string        `xml:"post_name"`
+	PostType      string        `xml:"post_type"`
+	Status        string        `xml:"status"`
+	PostDate      string        `xml:"post_date"`
+	PostDateGMT   string        `xml:"post_date_gmt"`
+	PostParent    string        `xml:"post_parent"`
+	AttachmentURL string        `xml:"attachment_url"`
+	Meta          []wxrPostMeta `xml:"postmeta"`
+}
+
+type wxrText struct {
+	XMLName xml.Name
+	Text    string `xml:",chardata"`
+}
+
+type wxrTerm struct {
+	Domain   string `xml:"domain,attr"`
+	Nicename string `xml:"nicename,attr"`
+	Name     string `xml:",chardata"`
+}
+
+type wxrPostMeta struct {
+	Key   string `xml:"meta_key"`
+	Value string `xml:"meta_value"`
+}
+
+//Parse read a WXR file, names and titles are unescaped as WordPress export them escaped
+func Parse(r io.Reader) (export Export, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Wrap(err, function.GetFunctionName(Parse))
+		}
+	}()
+
+	document := wxrDocument{}
+	decoder := xml.NewDecoder(r)
+	// exports often carry html entities outside of CDATA
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	if err := decoder.Decode(&document); err != nil {
+		return Export{}, err
+	}
+
+	for _, element := range document.Channel.Elements {
+		if !strings.Contains(element.XMLName.Space, "wordpress.org/export") {
+			continue
+		}
+
+		switch element.XMLName.Local {
+		case "author":
+			export.Authors = append(export.Authors, Author{
+				ID:          atoi(element.AuthorID),
+				Login:       strings.TrimSpace(element.AuthorLogin),
+				DisplayName: html.UnescapeString(strings.TrimSpace(element.AuthorDisplayName)),
+			})
+		case "category":
+			export.Categories = append(export.Categories, Category{
+				ID:         atoi(element.TermID),
+				Slug:       strings.TrimSpace(element.CategoryNicename),
+				ParentSlug: strings.TrimSpace(element.CategoryParent),
+				Name:       html.UnescapeString(strings.TrimSpace(element.CategoryName)),
+			})
+		}
+	}
+
+	for _, wxrItem := range document.Channel.Items {
+		item := Item{
+			ID:            atoi(wxrItem.PostID),
+			Type:          strings.TrimSpace(wxrItem.PostType),
+			Status:        strings.TrimSpace(wxrItem.Status),
+			Title:         html.UnescapeString(strings.TrimSpace(wxrItem.Title)),
+			Slug:          strings.TrimSpace(wxrItem.PostName),
+			Creator:       strings.TrimSpace(wxrItem.Creator),
+			Date:          strings.TrimSpace(wxrItem.PostDate),
+			DateGMT:       strings.TrimSpace(wxrItem.PostDateGMT),
+			Parent:        atoi(wxrItem.PostParent),
+			AttachmentURL: strings.TrimSpace(wxrItem.AttachmentURL),
+			Meta:          map[string]string{},
+		}
+
+		for _, encoded := range wxrItem.Encoded {
+			switch {
+			case strings.Contains(encoded.XMLName.Space, "/excerpt/"):
+				item.Excerpt = encoded.Text
+			case strings.Contains(encoded.XMLName.Space, "/content/"):
+				item.Content = encoded.Text
+			}
+		}
+
+		for _, term := range wxrItem.Terms {
+			item.Terms = append(item.Terms, Term{
+				Domain: term.Domain,
+				Slug:   strings.TrimSpace(term.Nicename),
+				Name:   html.UnescapeString(strings.TrimSpace(term.Name)),
+			})
+		}
+
+		for _, meta := range wxrItem.Meta {
+			item.Meta[strings.TrimSpace(meta.Key)] = meta.Value
+		}
+
+		export.Items = append(export.Items, item)
+	}
+
+	return export, nil
+}
+
+// atoi is 0 for missing or malformed ids, which no WordPress entity has
+func atoi(s string) int {
+	i, _ := strconv.Atoi(strings.TrimSpace(s))
+	return i
+}